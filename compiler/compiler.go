@@ -0,0 +1,127 @@
+// Package compiler embeds hexe as a library, it parses and validates the schema
+// and generates the code of any target into an io.Writer, e.g. a buffer, an archive
+// or an http.ResponseWriter, the same as the gen command of the hexe's CLI
+package compiler
+
+import (
+	"errors"
+	"io"
+
+	"github.com/hexe-dev/hexe/internal/compiler/ast"
+	"github.com/hexe-dev/hexe/internal/compiler/gen"
+	"github.com/hexe-dev/hexe/internal/compiler/parser"
+)
+
+// Target is the generated language or document, see TargetFromFilename
+type Target = gen.Target
+
+const (
+	TargetGo         = gen.TargetGo
+	TargetTypescript = gen.TargetTypescript
+	TargetZod        = gen.TargetZod
+	TargetJson       = gen.TargetJson // only constants
+	TargetEnv        = gen.TargetEnv  // only constants
+	TargetOpenAPI    = gen.TargetOpenAPI
+	TargetProto      = gen.TargetProto
+	TargetJsonSchema = gen.TargetJsonSchema
+	TargetAst        = gen.TargetAst
+	TargetGoCli      = gen.TargetGoCli // a main package of the go code with a command line client of http services
+)
+
+// TargetFromFilename returns the target of the output file's extension,
+// e.g. .go, .ts, .zod.ts or .openapi.json
+func TargetFromFilename(filename string) (Target, error) {
+	return gen.TargetFromFilename(filename)
+}
+
+// Option changes the generated code, the same as the flags of hexe gen
+type Option = gen.Option
+
+// WithTsConstEnums generates the Typescript enums as const objects, see --ts-enums=const
+func WithTsConstEnums() Option {
+	return gen.WithTsConstEnums()
+}
+
+// WithGoTypedUnits generates the duration and bytes constants as typed units, see --go-typed-units
+func WithGoTypedUnits() Option {
+	return gen.WithGoTypedUnits()
+}
+
+// WithGoConfig generates a Config of the constants loaded from env overrides, see --go-config
+func WithGoConfig() Option {
+	return gen.WithGoConfig()
+}
+
+// WithGoPresence tracks the optional model fields by a bitset and generates the models as values, see --go-presence
+func WithGoPresence() Option {
+	return gen.WithGoPresence()
+}
+
+// WithGoVersion generates the Go code for an older go1.minor, see --go-version
+func WithGoVersion(minor int) Option {
+	return gen.WithGoVersion(minor)
+}
+
+// WithApiVersion generates the api as it is at the version, see --api-version
+func WithApiVersion(version Version) Option {
+	return gen.WithApiVersion(version)
+}
+
+// WithWarnings writes the warnings of the generation into w, e.g. os.Stderr
+func WithWarnings(w io.Writer) Option {
+	return gen.WithWarnings(w)
+}
+
+// Version is a semantic version of the api, see ParseVersion
+type Version = ast.Version
+
+// ParseVersion parses a major.minor.patch version, e.g. 1.2.0 or v1.2.0
+func ParseVersion(value string) (Version, error) {
+	return ast.ParseVersion(value)
+}
+
+// Schema is a parsed and validated schema, which can generate any number of targets
+type Schema struct {
+	docs []*ast.Document
+}
+
+// Load parses the files and all the files they import, and validates them together
+func Load(filenames ...string) (*Schema, error) {
+	docs, err := parser.LoadDocuments(filenames...)
+	if err != nil {
+		return nil, err
+	}
+
+	return newSchema(docs)
+}
+
+// Parse parses the schema of input and validates it, its imports are
+// resolved relative to the current working directory
+func Parse(input string) (*Schema, error) {
+	doc, errs := parser.ParseDocumentAll(parser.NewParser(input))
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	docs, err := parser.LoadDocumentImports("", doc)
+	if err != nil {
+		return nil, err
+	}
+
+	return newSchema(docs)
+}
+
+// newSchema validates docs, all the errors are reported at once
+func newSchema(docs []*ast.Document) (*Schema, error) {
+	if err := errors.Join(parser.ValidateAll(docs...)...); err != nil {
+		return nil, err
+	}
+
+	return &Schema{docs: docs}, nil
+}
+
+// Generate generates the code of the target into w, pkg is the package
+// name of the Go code and it's ignored by the other targets
+func (s *Schema) Generate(w io.Writer, target Target, pkg string, opts ...Option) error {
+	return gen.GenerateTo(w, target, pkg, s.docs, opts...)
+}
@@ -0,0 +1,71 @@
+package compiler_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hexe-dev/hexe/compiler"
+)
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"role.hexe": `enum Role { Admin }`,
+		"user.hexe": `
+import "role.hexe"
+
+model User {
+	Name: string
+	Role: Role
+}`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	schema, err := compiler.Load(filepath.Join(dir, "user.hexe"))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// the same schema generates any number of targets
+	for _, target := range []compiler.Target{compiler.TargetGo, compiler.TargetTypescript, compiler.TargetOpenAPI} {
+		var buf bytes.Buffer
+		if assert.NoError(t, schema.Generate(&buf, target, "users"), target.String()) {
+			assert.Contains(t, buf.String(), "Role", target.String())
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	testCases := []struct {
+		input string
+		error string
+	}{
+		{
+			input: `model User {`,
+			error: "expected",
+		},
+		{
+			input: `model User { Role: Role }`,
+			error: "type is not defined",
+		},
+		{
+			input: `import "missing.hexe"`,
+			error: "missing.hexe",
+		},
+	}
+
+	for _, tc := range testCases {
+		_, err := compiler.Parse(tc.input)
+		if assert.Error(t, err, tc.input) {
+			assert.Contains(t, err.Error(), tc.error, tc.input)
+		}
+	}
+}
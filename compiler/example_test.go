@@ -0,0 +1,56 @@
+package compiler_test
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hexe-dev/hexe/compiler"
+)
+
+func Example() {
+	schema, err := compiler.Parse(`
+const Version = "1.0.0"
+const Retries = 3
+`)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	if err := schema.Generate(os.Stdout, compiler.TargetJson, ""); err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// {
+	//   "Version": "1.0.0",
+	//   "Retries": 3
+	// }
+}
+
+func ExampleTargetFromFilename() {
+	schema, err := compiler.Parse(`
+model User {
+	Name: string
+}
+`)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	target, err := compiler.TargetFromFilename("user.go")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	var sb strings.Builder
+	if err := schema.Generate(&sb, target, "users"); err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	fmt.Println(strings.Contains(sb.String(), "type User struct"))
+	// Output: true
+}
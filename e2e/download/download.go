@@ -13,3 +13,7 @@ var _ HttpDownloadService = (*HttpDownloadServiceImpl)(nil)
 func (s *HttpDownloadServiceImpl) Get(ctx context.Context) (asset io.Reader, assetFilename string, assetContentType string, err error) {
 	return strings.NewReader("Hello, World!"), "hello.txt", "text/plain", nil
 }
+
+func (s *HttpDownloadServiceImpl) View(ctx context.Context) (asset io.Reader, assetFilename string, assetContentType string, err error) {
+	return strings.NewReader("<h1>Hello, World!</h1>"), "hello world.html", "text/html", nil
+}
@@ -33,3 +33,37 @@ func TestCallHttpMethod(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "Hello, World!", string(data))
 }
+
+func TestCallHttpMethodDisposition(t *testing.T) {
+	mem := NewMemoryHandleRegistry()
+
+	RegisterHttpDownloadServiceServer(mem, &HttpDownloadServiceImpl{})
+
+	var dispositions []string
+	handler := NewHttpHandler(mem)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler.ServeHTTP(w, r)
+		dispositions = append(dispositions, w.Header().Get("Content-Disposition"))
+	}))
+	defer server.Close()
+
+	client := CreateHttpDownloadServiceClient(NewHttpClient(server.URL, &http.Client{}))
+
+	_, filename, _, err := client.Get(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "hello.txt", filename)
+
+	r, filename, contentType, err := client.View(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "hello world.html", filename)
+	assert.Equal(t, "text/html", contentType)
+
+	data, err := io.ReadAll(r)
+	assert.NoError(t, err)
+	assert.Equal(t, "<h1>Hello, World!</h1>", string(data))
+
+	assert.Equal(t, []string{
+		`attachment; filename=hello.txt`,
+		`inline; filename="hello world.html"`,
+	}, dispositions)
+}
@@ -2,9 +2,21 @@ package http
 
 import (
 	"context"
+	"sync/atomic"
+	"time"
 )
 
+// WaitForCancelResult is reported once WaitForCancel's context is done
+type WaitForCancelResult struct {
+	HasDeadline bool
+	Deadline    time.Time
+	CanceledAt  time.Time
+}
+
 type HttpPeopleServiceImpl struct {
+	WaitForCancelResults chan WaitForCancelResult
+	CacheControl         string // if set, it is sent as GetByName's Cache-Control header
+	GetByNameCalls       atomic.Int32
 }
 
 var _ HttpPeopleService = (*HttpPeopleServiceImpl)(nil)
@@ -20,3 +32,78 @@ func (s *HttpPeopleServiceImpl) GetRandom(ctx context.Context, age int8) (person
 		Emotion: Emotion_Excited,
 	}, nil
 }
+
+func (s *HttpPeopleServiceImpl) WaitForCancel(ctx context.Context) (err error) {
+	deadline, hasDeadline := ctx.Deadline()
+
+	<-ctx.Done()
+
+	s.WaitForCancelResults <- WaitForCancelResult{
+		HasDeadline: hasDeadline,
+		Deadline:    deadline,
+		CanceledAt:  time.Now(),
+	}
+
+	return ctx.Err()
+}
+
+func (s *HttpPeopleServiceImpl) WaitForTimeout(ctx context.Context) (err error) {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(5 * time.Second):
+		return nil
+	}
+}
+
+func (s *HttpPeopleServiceImpl) Echo(ctx context.Context, person *Person) (result *Person, err error) {
+	if person.Age < 0 {
+		return nil, ErrAgen
+	}
+
+	return person, nil
+}
+
+func (s *HttpPeopleServiceImpl) EchoEvent(ctx context.Context, event *Event) (result *Event, err error) {
+	return event, nil
+}
+
+func (s *HttpPeopleServiceImpl) Ping(ctx context.Context) (pong string, err error) {
+	return "pong", nil
+}
+
+func (s *HttpPeopleServiceImpl) EchoHeader(ctx context.Context, name string) (value string, err error) {
+	r, _, ok := GetHttpContext(ctx)
+	if !ok {
+		return "", nil
+	}
+
+	return r.Header.Get(name), nil
+}
+
+func (s *HttpPeopleServiceImpl) GetByName(ctx context.Context, name string) (person *Person, err error) {
+	s.GetByNameCalls.Add(1)
+
+	// created by its code only, the server maps it to the declared HttpStatus
+	if name == "unknown" {
+		return nil, newError(ErrUserNotFound.Code, "user %s not found", name)
+	}
+
+	if _, w, ok := GetHttpContext(ctx); ok && s.CacheControl != "" {
+		w.Header().Set("Cache-Control", s.CacheControl)
+	}
+
+	return &Person{
+		Name:    name,
+		Age:     30,
+		Emotion: Emotion_Happy,
+	}, nil
+}
+
+func (s *HttpPeopleServiceImpl) Pay(ctx context.Context, payment *Payment) (id string, err error) {
+	return "paid:" + payment.Method, nil
+}
+
+func (s *HttpPeopleServiceImpl) Signup(ctx context.Context, signup *Signup) (username string, err error) {
+	return signup.Username, nil
+}
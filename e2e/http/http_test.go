@@ -2,10 +2,17 @@ package http
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/hexe-dev/hexe/sse"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -35,3 +42,750 @@ func TestCallHttpMethod(t *testing.T) {
 	assert.Error(t, err)
 	assert.Equal(t, &Person{}, result)
 }
+
+func TestEnumString(t *testing.T) {
+	assert.Equal(t, "Excited", Emotion_Excited.String())
+	assert.Equal(t, "Emotion(10)", Emotion(10).String())
+
+	emotion, err := ParseEmotion("Excited")
+	assert.NoError(t, err)
+	assert.Equal(t, Emotion_Excited, emotion)
+
+	_, err = ParseEmotion("Angry")
+	assert.Error(t, err)
+
+	text, err := Emotion_Excited.MarshalText()
+	assert.NoError(t, err)
+	assert.Equal(t, "excited", string(text))
+}
+
+func TestEnumJson(t *testing.T) {
+	data, err := json.Marshal(&Person{Name: "HEXE", Emotion: Emotion_Excited})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"name":"HEXE","emotion":"excited"}`, string(data))
+
+	var person Person
+	assert.NoError(t, json.Unmarshal(data, &person))
+	assert.Equal(t, Emotion_Excited, person.Emotion)
+
+	// numbers are still accepted
+	assert.NoError(t, json.Unmarshal([]byte(`{"emotion":2}`), &person))
+	assert.Equal(t, Emotion_Happy, person.Emotion)
+
+	assert.Error(t, json.Unmarshal([]byte(`{"emotion":"angry"}`), &person))
+}
+
+func TestCallHttpMethodErrorStatus(t *testing.T) {
+	mem := NewMemoryHandleRegistry()
+
+	RegisterHttpPeopleServiceServer(mem, &HttpPeopleServiceImpl{})
+
+	var status atomic.Int32
+
+	handler := NewHttpHandler(mem)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, r)
+		status.Store(int32(rec.Code))
+
+		for key, values := range rec.Header() {
+			w.Header()[key] = values
+		}
+		w.WriteHeader(rec.Code)
+		w.Write(rec.Body.Bytes())
+	}))
+	defer server.Close()
+
+	client := CreateHttpPeopleServiceClient(NewHttpClient(server.URL, &http.Client{}))
+
+	// custom error's HttpStatus is used for the response
+	_, err := client.GetRandom(context.Background(), -1)
+	assert.ErrorIs(t, err, ErrAgen)
+	assert.Equal(t, int32(http.StatusBadRequest), status.Load())
+
+	_, err = client.GetRandom(context.Background(), 10)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(http.StatusOK), status.Load())
+
+	// an error with a custom error's code is mapped to its HttpStatus, and the
+	// client decodes it back into the custom error
+	_, err = client.GetByName(context.Background(), "unknown")
+	assert.Equal(t, int32(http.StatusNotFound), status.Load())
+	assert.ErrorIs(t, err, ErrUserNotFound)
+
+	var rpcErr *Error
+	if assert.ErrorAs(t, err, &rpcErr) {
+		assert.Equal(t, int64(1000), rpcErr.Code)
+		assert.Equal(t, "user unknown not found", rpcErr.Message)
+		assert.Equal(t, http.StatusNotFound, rpcErr.HttpStatus)
+	}
+}
+
+func TestCallHttpMethodRequires(t *testing.T) {
+	mem := NewMemoryHandleRegistry()
+
+	RegisterHttpPeopleServiceServer(mem, &HttpPeopleServiceImpl{})
+
+	server := httptest.NewServer(NewHttpHandler(mem))
+	defer server.Close()
+
+	client := CreateHttpPeopleServiceClient(NewHttpClient(server.URL, &http.Client{}))
+
+	assert.ErrorIs(t, (&Payment{Method: "card"}).Validate(), ErrValidation)
+	assert.NoError(t, (&Payment{}).Validate())
+
+	id, err := client.Pay(context.Background(), &Payment{Method: "card", Card: "4242"})
+	assert.NoError(t, err)
+	assert.Equal(t, "paid:card", id)
+
+	id, err = client.Pay(context.Background(), &Payment{})
+	assert.NoError(t, err)
+	assert.Equal(t, "paid:", id)
+
+	// Card is required when Method is set
+	_, err = client.Pay(context.Background(), &Payment{Method: "card"})
+	assert.ErrorIs(t, err, ErrValidation)
+	assert.ErrorContains(t, err, "Payment.Card is required when Method is set")
+}
+
+func TestCallHttpMethodFieldConstraints(t *testing.T) {
+	mem := NewMemoryHandleRegistry()
+
+	RegisterHttpPeopleServiceServer(mem, &HttpPeopleServiceImpl{})
+
+	server := httptest.NewServer(NewHttpHandler(mem))
+	defer server.Close()
+
+	client := CreateHttpPeopleServiceClient(NewHttpClient(server.URL, &http.Client{}))
+
+	username, err := client.Signup(context.Background(), &Signup{Username: "ada", Age: 36})
+	assert.NoError(t, err)
+	assert.Equal(t, "ada", username)
+
+	testCases := []struct {
+		signup *Signup
+		error  string
+	}{
+		{signup: &Signup{Age: 36}, error: "Signup.Username is required"},
+		{signup: &Signup{Username: "ada1", Age: 36}, error: "Signup.Username should match ^[a-zA-Z]+$"},
+		{signup: &Signup{Username: "ada", Age: 12}, error: "Signup.Age should be greater than or equal to 13"},
+		{signup: &Signup{Username: "ada", Age: 121}, error: "Signup.Age should be less than or equal to 120"},
+		{signup: &Signup{Username: "ada", Age: 36, Bio: "<b>"}, error: "Signup.Bio should match ^[^<>]*$"},
+	}
+
+	for _, tc := range testCases {
+		assert.ErrorIs(t, tc.signup.Validate(), ErrValidation)
+
+		_, err := client.Signup(context.Background(), tc.signup)
+		assert.ErrorIs(t, err, ErrValidation)
+		assert.ErrorContains(t, err, tc.error)
+	}
+}
+
+func TestCallHttpMethodRateLimit(t *testing.T) {
+	mem := NewMemoryHandleRegistry()
+
+	RegisterHttpPeopleServiceServer(mem, &HttpPeopleServiceImpl{})
+
+	server := httptest.NewServer(NewHttpHandler(mem))
+	defer server.Close()
+
+	client := CreateHttpPeopleServiceClient(NewHttpClient(server.URL, &http.Client{}))
+
+	for range 2 {
+		pong, err := client.Ping(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, "pong", pong)
+	}
+
+	_, err := client.Ping(context.Background())
+	assert.ErrorIs(t, err, ErrTooManyRequests)
+
+	resp, err := http.Post(server.URL, "application/json", strings.NewReader(`{"method":"HttpPeopleService.Ping","params":{}}`))
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(t, "1", resp.Header.Get("Retry-After"))
+
+	// the other methods are not limited
+	_, err = client.GetRandom(context.Background(), 10)
+	assert.NoError(t, err)
+
+	// the retry client waits for Retry-After and calls again
+	retryClient, err := sse.NewRetryClient(sse.WithMaxRetries(1))
+	assert.NoError(t, err)
+
+	pong, err := CreateHttpPeopleServiceClient(NewHttpClient(server.URL, retryClient)).Ping(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "pong", pong)
+}
+
+func TestCallHttpMethodRateLimiter(t *testing.T) {
+	mem := NewMemoryHandleRegistry()
+
+	RegisterHttpPeopleServiceServer(mem, &HttpPeopleServiceImpl{})
+
+	server := httptest.NewServer(NewHttpHandler(mem, WithRateLimiter(nil)))
+	defer server.Close()
+
+	client := CreateHttpPeopleServiceClient(NewHttpClient(server.URL, &http.Client{}))
+
+	for range 5 {
+		_, err := client.Ping(context.Background())
+		assert.NoError(t, err)
+	}
+}
+
+func TestTimeFormat(t *testing.T) {
+	at := time.Date(2024, 5, 1, 10, 30, 15, 123_000_000, time.UTC)
+	created := time.Date(2024, 5, 1, 10, 30, 15, 0, time.FixedZone("", 2*60*60))
+
+	event := &Event{At: at, Created: created}
+
+	data, err := json.Marshal(event)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"at":1714559415123,"created":"2024-05-01T10:30:15+02:00"}`, string(data))
+
+	var decoded Event
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+	assert.True(t, at.Equal(decoded.At))
+	assert.True(t, created.Equal(decoded.Created))
+	assert.True(t, decoded.Seen.IsZero())
+
+	// the optional field is only sent once it's set, the sub seconds are dropped by unix
+	event.Seen = at
+	data, err = json.Marshal(event)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"at":1714559415123,"seen":1714559415,"created":"2024-05-01T10:30:15+02:00"}`, string(data))
+
+	mem := NewMemoryHandleRegistry()
+
+	RegisterHttpPeopleServiceServer(mem, &HttpPeopleServiceImpl{})
+
+	server := httptest.NewServer(NewHttpHandler(mem))
+	defer server.Close()
+
+	client := CreateHttpPeopleServiceClient(NewHttpClient(server.URL, &http.Client{}))
+
+	result, err := client.EchoEvent(context.Background(), event)
+	assert.NoError(t, err)
+	assert.True(t, at.Equal(result.At))
+	assert.True(t, at.Truncate(time.Second).Equal(result.Seen))
+	assert.True(t, created.Equal(result.Created))
+}
+
+func TestCallHttpMethodRoutes(t *testing.T) {
+	mem := NewMemoryHandleRegistry()
+
+	RegisterHttpPeopleServiceServer(mem, &HttpPeopleServiceImpl{})
+
+	// any router which supports method and path patterns can be used
+	mux := http.NewServeMux()
+	for _, route := range mem.Routes() {
+		mux.Handle(route.Method+" /api"+route.Path, route.Handler)
+	}
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := CreateHttpPeopleServiceClient(NewHttpClient(server.URL+"/api", &http.Client{}, WithRoutes()))
+
+	result, err := client.GetRandom(context.Background(), 10)
+	assert.NoError(t, err)
+	assert.Equal(t, "HEXE", result.Name)
+
+	result, err = client.GetByName(context.Background(), "Ella")
+	assert.NoError(t, err)
+	assert.Equal(t, "Ella", result.Name)
+
+	// the route's method is used, regardless of the request's method
+	resp, err := http.Post(server.URL+"/api/HttpPeopleService.GetRandom", "application/json", strings.NewReader(`{"method":"HttpPeopleService.GetByName","params":{"age":5}}`))
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// only the methods with GET option have a GET route
+	resp, err = http.Get(server.URL + "/api/HttpPeopleService.GetRandom")
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+}
+
+func TestCallHttpMethodCache(t *testing.T) {
+	mem := NewMemoryHandleRegistry()
+
+	impl := &HttpPeopleServiceImpl{}
+	RegisterHttpPeopleServiceServer(mem, impl)
+
+	var statuses []int
+	var methods []string
+
+	handler := NewHttpHandler(mem)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, r)
+		statuses = append(statuses, rec.Code)
+		methods = append(methods, r.Method)
+
+		for key, values := range rec.Header() {
+			w.Header()[key] = values
+		}
+		w.WriteHeader(rec.Code)
+		w.Write(rec.Body.Bytes())
+	}))
+	defer server.Close()
+
+	client := CreateHttpPeopleServiceClient(NewHttpClient(server.URL, &http.Client{}, WithCache(NewMemoryCacheStore())))
+
+	expected := &Person{
+		Name:    "HEXE",
+		Age:     30,
+		Emotion: Emotion_Happy,
+	}
+
+	result, err := client.GetByName(context.Background(), "HEXE")
+	assert.NoError(t, err)
+	assert.Equal(t, expected, result)
+
+	// the second call is revalidated using ETag and the cached body is served on 304
+	result, err = client.GetByName(context.Background(), "HEXE")
+	assert.NoError(t, err)
+	assert.Equal(t, expected, result)
+
+	assert.Equal(t, []string{http.MethodGet, http.MethodGet}, methods)
+	assert.Equal(t, []int{http.StatusOK, http.StatusNotModified}, statuses)
+
+	// different params are cached separately
+	result, err = client.GetByName(context.Background(), "Other")
+	assert.NoError(t, err)
+	assert.Equal(t, "Other", result.Name)
+	assert.Equal(t, http.StatusOK, statuses[len(statuses)-1])
+
+	// a fresh entry is served without calling the server
+	impl.CacheControl = "max-age=60"
+
+	result, err = client.GetByName(context.Background(), "Fresh")
+	assert.NoError(t, err)
+	assert.Equal(t, "Fresh", result.Name)
+
+	calls := impl.GetByNameCalls.Load()
+
+	result, err = client.GetByName(context.Background(), "Fresh")
+	assert.NoError(t, err)
+	assert.Equal(t, "Fresh", result.Name)
+	assert.Equal(t, calls, impl.GetByNameCalls.Load())
+
+	// no-store responses are not cached
+	impl.CacheControl = "no-store"
+
+	_, err = client.GetByName(context.Background(), "NoStore")
+	assert.NoError(t, err)
+	_, err = client.GetByName(context.Background(), "NoStore")
+	assert.NoError(t, err)
+	assert.Equal(t, []int{http.StatusOK, http.StatusOK}, statuses[len(statuses)-2:])
+}
+
+func TestCallHttpMethodGetNotAllowed(t *testing.T) {
+	mem := NewMemoryHandleRegistry()
+
+	RegisterHttpPeopleServiceServer(mem, &HttpPeopleServiceImpl{})
+
+	server := httptest.NewServer(NewHttpHandler(mem))
+	defer server.Close()
+
+	// GetRandom doesn't have HttpMethod = "GET", so it can't be called using GET
+	resp, err := http.Get(server.URL + `?method=HttpPeopleService.GetRandom&params={"age":10}`)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer resp.Body.Close()
+
+	err = parseCallerResponse(resp.Body)
+	assert.Error(t, err)
+}
+
+func TestCallHttpMethodDeadline(t *testing.T) {
+	mem := NewMemoryHandleRegistry()
+
+	impl := &HttpPeopleServiceImpl{
+		WaitForCancelResults: make(chan WaitForCancelResult, 1),
+	}
+
+	RegisterHttpPeopleServiceServer(mem, impl)
+
+	server := httptest.NewServer(NewHttpHandler(mem))
+	defer server.Close()
+
+	client := CreateHttpPeopleServiceClient(NewHttpClient(server.URL, &http.Client{}))
+
+	timeout := 300 * time.Millisecond
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := client.WaitForCancel(ctx)
+	assert.Error(t, err)
+
+	select {
+	case result := <-impl.WaitForCancelResults:
+		// server's context should carry the client's deadline
+		assert.True(t, result.HasDeadline)
+		assert.WithinDuration(t, start.Add(timeout), result.Deadline, 100*time.Millisecond)
+		assert.WithinDuration(t, start.Add(timeout), result.CanceledAt, 200*time.Millisecond)
+	case <-time.After(5 * time.Second):
+		t.Fatal("server's context was not canceled")
+	}
+}
+
+func TestCallHttpMethodTimeout(t *testing.T) {
+	mem := NewMemoryHandleRegistry()
+
+	RegisterHttpPeopleServiceServer(mem, &HttpPeopleServiceImpl{})
+
+	server := httptest.NewServer(NewHttpHandler(mem))
+	defer server.Close()
+
+	client := CreateHttpPeopleServiceClient(NewHttpClient(server.URL, &http.Client{}))
+
+	start := time.Now()
+	err := client.WaitForTimeout(context.Background())
+	assert.ErrorContains(t, err, context.DeadlineExceeded.Error())
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestCallHttpMethodMsgPack(t *testing.T) {
+	mem := NewMemoryHandleRegistry()
+
+	RegisterHttpPeopleServiceServer(mem, &HttpPeopleServiceImpl{})
+
+	var requestTypes, responseTypes []string
+
+	handler := NewHttpHandler(mem)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestTypes = append(requestTypes, r.Header.Get("Content-Type"))
+		handler.ServeHTTP(w, r)
+		responseTypes = append(responseTypes, w.Header().Get("Content-Type"))
+	}))
+	defer server.Close()
+
+	client := CreateHttpPeopleServiceClient(NewHttpClient(server.URL, &http.Client{}))
+
+	person := &Person{Name: "HEXE", Age: 42, Emotion: Emotion_Sad}
+
+	result, err := client.Echo(context.Background(), person)
+	assert.NoError(t, err)
+	assert.Equal(t, person, result)
+
+	// errors are encoded as MessagePack as well
+	_, err = client.Echo(context.Background(), &Person{Name: "HEXE", Age: -1, Emotion: Emotion_Sad})
+	assert.ErrorIs(t, err, ErrAgen)
+
+	// the methods without MsgPack option keep using json
+	_, err = client.GetRandom(context.Background(), 10)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"application/msgpack", "application/msgpack", "application/json"}, requestTypes)
+	assert.Equal(t, []string{"application/msgpack", "application/msgpack", "application/json"}, responseTypes)
+
+	// json clients, e.g. Typescript, can still call the method
+	resp, err := http.Post(server.URL, "application/json", strings.NewReader(`{"method":"HttpPeopleService.Echo","params":{"person":{"name":"json","age":1,"emotion":"happy"}}}`))
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	assert.JSONEq(t, `{"result":[{"name":"json","age":1,"emotion":"happy"}]}`, string(body))
+}
+
+func TestCallHttpMethodCircuitBreaker(t *testing.T) {
+	mem := NewMemoryHandleRegistry()
+
+	RegisterHttpPeopleServiceServer(mem, &HttpPeopleServiceImpl{})
+
+	var healthy atomic.Bool
+	var hits atomic.Int32
+
+	handler := NewHttpHandler(mem)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	caller := NewHttpClient(server.URL, &http.Client{}, WithCircuitBreaker(CircuitBreakerConfig{
+		FailureThreshold: 2,
+		OpenTimeout:      200 * time.Millisecond,
+	}))
+
+	client := CreateHttpPeopleServiceClient(caller)
+
+	// consecutive failures open the circuit
+	for range 2 {
+		_, err := client.GetRandom(context.Background(), 10)
+		assert.Error(t, err)
+		assert.False(t, errors.Is(err, ErrCircuitOpen))
+	}
+	assert.Equal(t, int32(2), hits.Load())
+
+	// open circuit fails fast without calling the server
+	start := time.Now()
+	_, err := client.GetRandom(context.Background(), 10)
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Less(t, time.Since(start), 50*time.Millisecond)
+	assert.Equal(t, int32(2), hits.Load())
+
+	// after the open timeout, a successful probe closes the circuit
+	healthy.Store(true)
+	time.Sleep(250 * time.Millisecond)
+
+	result, err := client.GetRandom(context.Background(), 10)
+	assert.NoError(t, err)
+	assert.Equal(t, "HEXE", result.Name)
+
+	_, err = client.GetRandom(context.Background(), 10)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(4), hits.Load())
+
+	// custom errors are not server failures and don't open the circuit
+	for range 3 {
+		_, err = client.GetRandom(context.Background(), -1)
+		assert.ErrorIs(t, err, ErrAgen)
+	}
+	assert.Equal(t, int32(7), hits.Load())
+}
+
+func TestCallHttpMethodCircuitBreakerProbeNotSent(t *testing.T) {
+	mem := NewMemoryHandleRegistry()
+
+	RegisterHttpPeopleServiceServer(mem, &HttpPeopleServiceImpl{})
+
+	var healthy atomic.Bool
+	var hits atomic.Int32
+
+	handler := NewHttpHandler(mem)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	caller := NewHttpClient(server.URL, &http.Client{}, WithCircuitBreaker(CircuitBreakerConfig{
+		FailureThreshold: 1,
+		OpenTimeout:      100 * time.Millisecond,
+	}))
+
+	client := CreateHttpPeopleServiceClient(caller)
+
+	_, err := client.GetRandom(context.Background(), 10)
+	assert.Error(t, err)
+	assert.False(t, errors.Is(err, ErrCircuitOpen))
+
+	// the probes whose body can't be marshaled, as json or MessagePack,
+	// are never sent, so they don't keep the circuit half-open
+	for _, msgPack := range []bool{false, true} {
+		time.Sleep(150 * time.Millisecond)
+
+		body, _ := caller.Call(context.Background(), &Request{
+			Method:      "HttpPeopleService.Echo",
+			Params:      json.RawMessage(`{"person":`),
+			ContentType: "application/json",
+			MsgPack:     msgPack,
+		})
+		err = parseCallerResponse(body, nil)
+		assert.Error(t, err)
+		assert.False(t, errors.Is(err, ErrCircuitOpen))
+		assert.Equal(t, int32(1), hits.Load())
+	}
+
+	// the next call is still allowed as the probe
+	healthy.Store(true)
+
+	result, err := client.GetRandom(context.Background(), 10)
+	assert.NoError(t, err)
+	assert.Equal(t, "HEXE", result.Name)
+	assert.Equal(t, int32(2), hits.Load())
+}
+
+func TestCallHttpMethodMiddleware(t *testing.T) {
+	mem := NewMemoryHandleRegistry()
+
+	RegisterHttpPeopleServiceServer(mem, &HttpPeopleServiceImpl{})
+
+	var order []string
+	var paths []string
+
+	logger := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			order = append(order, "logger")
+			paths = append(paths, r.URL.Path)
+			next.ServeHTTP(w, r)
+		})
+	}
+
+	auth := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			order = append(order, "auth")
+			if r.Header.Get("Authorization") == "" {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+
+	server := httptest.NewServer(NewHttpHandler(mem, WithMiddleware(logger, auth)))
+	defer server.Close()
+
+	client := CreateHttpPeopleServiceClient(NewHttpClient(server.URL, &http.Client{}))
+
+	// the middlewares run before the method, so the request is rejected
+	_, err := client.GetRandom(context.Background(), 10)
+	assert.Error(t, err)
+	assert.Equal(t, []string{"logger", "auth"}, order)
+
+	authorized := &http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		r.Header.Set("Authorization", "Bearer token")
+		return http.DefaultTransport.RoundTrip(r)
+	})}
+
+	client = CreateHttpPeopleServiceClient(NewHttpClient(server.URL, authorized))
+
+	result, err := client.GetRandom(context.Background(), 10)
+	assert.NoError(t, err)
+	assert.Equal(t, "HEXE", result.Name)
+
+	result, err = client.GetByName(context.Background(), "Ella")
+	assert.NoError(t, err)
+	assert.Equal(t, "Ella", result.Name)
+
+	assert.Equal(t, []string{"logger", "auth", "logger", "auth", "logger", "auth"}, order)
+	assert.Len(t, paths, 3)
+
+	// every route of the registry is wrapped as well
+	mux := http.NewServeMux()
+	for _, route := range mem.Routes(WithMiddleware(logger)) {
+		mux.Handle(route.Method+" "+route.Path, route.Handler)
+	}
+
+	routes := httptest.NewServer(mux)
+	defer routes.Close()
+
+	client = CreateHttpPeopleServiceClient(NewHttpClient(routes.URL, &http.Client{}, WithRoutes()))
+
+	_, err = client.GetRandom(context.Background(), 10)
+	assert.NoError(t, err)
+	assert.Equal(t, "/HttpPeopleService.GetRandom", paths[len(paths)-1])
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestCallHttpMethodInterceptor(t *testing.T) {
+	mem := NewMemoryHandleRegistry()
+
+	RegisterHttpPeopleServiceServer(mem, &HttpPeopleServiceImpl{})
+
+	server := httptest.NewServer(NewHttpHandler(mem))
+	defer server.Close()
+
+	type traceKey struct{}
+
+	auth := func(r *http.Request) error {
+		r.Header.Set("Authorization", "Bearer token")
+		return nil
+	}
+
+	// the request's context is the call's context
+	trace := func(r *http.Request) error {
+		if id, ok := r.Context().Value(traceKey{}).(string); ok {
+			r.Header.Set("X-Trace-Id", id)
+		}
+		return nil
+	}
+
+	client := CreateHttpPeopleServiceClient(NewHttpClient(server.URL, &http.Client{}, WithInterceptor(auth, trace)))
+
+	value, err := client.EchoHeader(context.Background(), "Authorization")
+	assert.NoError(t, err)
+	assert.Equal(t, "Bearer token", value)
+
+	ctx := context.WithValue(context.Background(), traceKey{}, "abc")
+	value, err = client.EchoHeader(ctx, "X-Trace-Id")
+	assert.NoError(t, err)
+	assert.Equal(t, "abc", value)
+
+	// an interceptor's error stops the call
+	failed := errors.New("no token")
+	client = CreateHttpPeopleServiceClient(NewHttpClient(server.URL, &http.Client{}, WithInterceptor(func(r *http.Request) error {
+		return failed
+	})))
+
+	_, err = client.EchoHeader(context.Background(), "Authorization")
+	assert.ErrorContains(t, err, "no token")
+}
+
+func TestCallHttpMethodRequestId(t *testing.T) {
+	mem := NewMemoryHandleRegistry()
+
+	RegisterHttpPeopleServiceServer(mem, &HttpPeopleServiceImpl{})
+
+	server := httptest.NewServer(NewHttpHandler(mem))
+	defer server.Close()
+
+	var header string
+	recorder := &http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		resp, err := http.DefaultTransport.RoundTrip(r)
+		if err == nil {
+			header = resp.Header.Get("X-Request-Id")
+		}
+		return resp, err
+	})}
+
+	client := CreateHttpPeopleServiceClient(NewHttpClient(server.URL, recorder))
+
+	_, err := client.GetRandom(context.Background(), -1)
+	assert.ErrorIs(t, err, ErrAgen)
+
+	var rpcErr *Error
+	if assert.ErrorAs(t, err, &rpcErr) {
+		assert.NotEmpty(t, rpcErr.RequestId)
+		assert.Equal(t, header, rpcErr.RequestId)
+	}
+
+	// each request has its own id
+	first := header
+	_, err = client.GetRandom(context.Background(), -1)
+	if assert.ErrorAs(t, err, &rpcErr) {
+		assert.Equal(t, header, rpcErr.RequestId)
+		assert.NotEqual(t, first, rpcErr.RequestId)
+	}
+
+	// the incoming id is used, so the requests can be correlated across services
+	client = CreateHttpPeopleServiceClient(NewHttpClient(server.URL, recorder, WithInterceptor(func(r *http.Request) error {
+		r.Header.Set("X-Request-Id", "req-1")
+		return nil
+	})))
+
+	_, err = client.GetRandom(context.Background(), -1)
+	if assert.ErrorAs(t, err, &rpcErr) {
+		assert.Equal(t, "req-1", rpcErr.RequestId)
+		assert.Equal(t, "req-1", header)
+	}
+
+	// the successful responses have the header as well
+	_, err = client.GetRandom(context.Background(), 10)
+	assert.NoError(t, err)
+	assert.Equal(t, "req-1", header)
+}
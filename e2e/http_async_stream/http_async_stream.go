@@ -24,6 +24,22 @@ func (h *HttpSignalServiceImpl) Recv(ctx context.Context, inbox string) (msgs <-
 	return h.bus.Recv(ctx, inbox)
 }
 
+func (h *HttpSignalServiceImpl) Echo(ctx context.Context, prefix string, msgs <-chan string) (replies <-chan string, errs <-chan error) {
+	out := make(chan string)
+	go func() {
+		defer close(out)
+		for msg := range msgs {
+			select {
+			case out <- prefix + msg:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out, nil
+}
+
 func NewHttpSignalServiceImpl(bus Bus[string]) *HttpSignalServiceImpl {
 	return &HttpSignalServiceImpl{
 		bus: bus,
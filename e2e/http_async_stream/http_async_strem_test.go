@@ -70,3 +70,45 @@ func TestStreamWithAsync(t *testing.T) {
 
 	wg.Wait()
 }
+
+func TestBidirectionalStream(t *testing.T) {
+	mem := NewMemoryHandleRegistry()
+
+	RegisterHttpSignalServiceServer(mem, NewHttpSignalServiceImpl(NewMemoryBus[string]()))
+
+	server := httptest.NewServer(NewHttpHandler(mem))
+	defer server.Close()
+
+	client := CreateHttpSignalServiceClient(NewHttpClient(server.URL, &http.Client{}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	msgs := make(chan string)
+	replies, errs := client.Echo(ctx, "echo: ", msgs)
+
+	// each message is sent only after the previous reply is received,
+	// so the call has to send and receive at the same time
+	for _, msg := range []string{"a", "b", "c"} {
+		msgs <- msg
+
+		select {
+		case reply := <-replies:
+			assert.Equal(t, "echo: "+msg, reply)
+		case err := <-errs:
+			assert.FailNow(t, err.Error())
+		case <-ctx.Done():
+			assert.FailNow(t, "timeout")
+		}
+	}
+
+	close(msgs)
+
+	// the replies are closed once the server's handler finishes
+	select {
+	case _, ok := <-replies:
+		assert.False(t, ok)
+	case <-ctx.Done():
+		assert.FailNow(t, "timeout")
+	}
+}
@@ -8,5 +8,9 @@ type RpcGreetingServiceImpl struct {
 var _ RpcGreetingService = (*RpcGreetingServiceImpl)(nil)
 
 func (s *RpcGreetingServiceImpl) SayHello(ctx context.Context, name string) (string, error) {
+	if name == "" {
+		return "", ErrNameRequired
+	}
+
 	return "Hello " + name, nil
 }
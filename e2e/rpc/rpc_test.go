@@ -2,6 +2,7 @@ package rpc
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -20,3 +21,73 @@ func TestRpcCall(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "Hello World", resp)
 }
+
+type greetingWithError struct {
+	err error
+}
+
+func (s *greetingWithError) SayHello(ctx context.Context, name string) (string, error) {
+	return "", s.err
+}
+
+func TestRpcCallError(t *testing.T) {
+	mem := NewMemoryHandleRegistry()
+
+	RegisterRpcGreetingServiceServer(mem, &RpcGreetingServiceImpl{})
+
+	client := CreateRpcGreetingServiceClient(NewRpcCallerMemory(mem))
+
+	// the decoded error matches the declared error by its code
+	_, err := client.SayHello(context.Background(), "")
+	assert.ErrorIs(t, err, ErrNameRequired)
+	assert.Equal(t, "2000: name is required", err.Error())
+
+	// an unknown code is still decoded as a generic error
+	mem = NewMemoryHandleRegistry()
+	RegisterRpcGreetingServiceServer(mem, &greetingWithError{err: newError(3000, "greeting is not available")})
+
+	_, err = CreateRpcGreetingServiceClient(NewRpcCallerMemory(mem)).SayHello(context.Background(), "World")
+	assert.NotErrorIs(t, err, ErrNameRequired)
+
+	var rpcErr *Error
+	if assert.ErrorAs(t, err, &rpcErr) {
+		assert.Equal(t, int64(3000), rpcErr.Code)
+		assert.Equal(t, "greeting is not available", rpcErr.Message)
+	}
+}
+
+type greetingFromContext struct{}
+
+type greetingKey struct{}
+
+func (s *greetingFromContext) SayHello(ctx context.Context, name string) (string, error) {
+	greeting, _ := ctx.Value(greetingKey{}).(string)
+	return greeting + " " + name, nil
+}
+
+func TestRpcCallInterceptor(t *testing.T) {
+	mem := NewMemoryHandleRegistry()
+
+	RegisterRpcGreetingServiceServer(mem, &greetingFromContext{})
+
+	var methods []string
+
+	caller := InterceptCaller(NewRpcCallerMemory(mem), func(ctx context.Context, req *Request) (context.Context, error) {
+		methods = append(methods, req.Method)
+		return context.WithValue(ctx, greetingKey{}, "Hi"), nil
+	})
+
+	client := CreateRpcGreetingServiceClient(caller)
+
+	resp, err := client.SayHello(context.Background(), "World")
+	assert.NoError(t, err)
+	assert.Equal(t, "Hi World", resp)
+	assert.Equal(t, []string{"RpcGreetingService.SayHello"}, methods)
+
+	caller = InterceptCaller(NewRpcCallerMemory(mem), func(ctx context.Context, req *Request) (context.Context, error) {
+		return nil, errors.New("not allowed")
+	})
+
+	_, err = CreateRpcGreetingServiceClient(caller).SayHello(context.Background(), "World")
+	assert.ErrorContains(t, err, "not allowed")
+}
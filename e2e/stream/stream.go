@@ -11,6 +11,15 @@ type HttpEventServiceImpl struct{}
 var _ HttpEventService = (*HttpEventServiceImpl)(nil)
 
 func (s *HttpEventServiceImpl) GetRandomValues(ctx context.Context) (values <-chan string, errs <-chan error) {
+	return generateValues(ctx, 500*time.Millisecond)
+}
+
+func (s *HttpEventServiceImpl) GetLimitedValues(ctx context.Context) (values <-chan string, errs <-chan error) {
+	return generateValues(ctx, 100*time.Millisecond)
+}
+
+// generateValues sends a value every interval until the context is done
+func generateValues(ctx context.Context, interval time.Duration) (values <-chan string, errs <-chan error) {
 	results := make(chan string, 10)
 
 	go func() {
@@ -23,7 +32,7 @@ func (s *HttpEventServiceImpl) GetRandomValues(ctx context.Context) (values <-ch
 				return
 			case results <- fmt.Sprintf("Hello %d", count):
 				count++
-				time.Sleep(500 * time.Millisecond)
+				time.Sleep(interval)
 			}
 		}
 	}()
@@ -2,6 +2,9 @@ package stream
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -43,3 +46,141 @@ func TestHttpStream(t *testing.T) {
 		}
 	}
 }
+
+func TestHttpStreamTimeout(t *testing.T) {
+	mem := NewMemoryHandleRegistry()
+
+	RegisterHttpEventServiceServer(mem, &HttpEventServiceImpl{})
+
+	server := httptest.NewServer(NewHttpHandler(mem))
+	defer server.Close()
+
+	client := CreateHttpEventServiceClient(NewHttpClient(server.URL, &http.Client{}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	results, errs := client.GetLimitedValues(ctx)
+
+	count := 0
+	for results != nil || errs != nil {
+		select {
+		case _, ok := <-errs:
+			if !ok {
+				errs = nil
+			}
+		case _, ok := <-results:
+			if !ok {
+				results = nil
+				continue
+			}
+			count++
+		}
+	}
+
+	// the server should end the stream once the method's timeout is passed
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("stream was not ended by the timeout, took %s", elapsed)
+	}
+
+	if count == 0 {
+		t.Fatal("expected some values before the timeout")
+	}
+}
+
+func TestHttpStreamSeq(t *testing.T) {
+	mem := NewMemoryHandleRegistry()
+
+	RegisterHttpEventServiceServer(mem, &HttpEventServiceImpl{})
+
+	server := httptest.NewServer(NewHttpHandler(mem))
+	defer server.Close()
+
+	client := CreateHttpEventServiceClient(NewHttpClient(server.URL, &http.Client{}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// breaking out of the loop cancels the call
+	var values []string
+	for value, err := range client.GetRandomValuesSeq(ctx) {
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		values = append(values, value)
+		if len(values) == 3 {
+			break
+		}
+	}
+
+	if fmt.Sprint(values) != "[Hello 0 Hello 1 Hello 2]" {
+		t.Fatalf("unexpected values: %v", values)
+	}
+
+	// the loop ends once the server ends the stream
+	count := 0
+	for _, err := range client.GetLimitedValuesSeq(ctx) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		count++
+	}
+
+	if count == 0 {
+		t.Fatal("expected some values before the timeout")
+	}
+}
+
+func TestHttpStreamCut(t *testing.T) {
+	// the connection is closed in the middle of the second event
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, "event: data\ndata: \"Hello 0\"\n\nevent: data\ndata: \"Hel")
+		w.(http.Flusher).Flush()
+
+		conn, _, err := http.NewResponseController(w).Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		conn.Close()
+	}))
+	defer server.Close()
+
+	client := CreateHttpEventServiceClient(NewHttpClient(server.URL, &http.Client{}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	results, errs := client.GetRandomValues(ctx)
+
+	var values []string
+	var received []error
+	for results != nil || errs != nil {
+		select {
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			received = append(received, err)
+		case value, ok := <-results:
+			if !ok {
+				results = nil
+				continue
+			}
+			values = append(values, value)
+		}
+	}
+
+	if fmt.Sprint(values) != "[Hello 0]" {
+		t.Fatalf("unexpected values: %v", values)
+	}
+
+	if len(received) != 1 || !errors.Is(received[0], io.ErrUnexpectedEOF) {
+		t.Fatalf("expected the cut to be reported as io.ErrUnexpectedEOF, got %v", received)
+	}
+}
@@ -11,6 +11,10 @@ type HttpStorageServiceImpl struct{}
 
 var _ HttpStorageService = (*HttpStorageServiceImpl)(nil)
 
+func (s *HttpStorageServiceImpl) UploadSmallFiles(ctx context.Context, id string, files func() (string, io.Reader, error)) (results []*File, err error) {
+	return s.UploadFiles(ctx, id, files)
+}
+
 func (s *HttpStorageServiceImpl) UploadFiles(ctx context.Context, id string, files func() (string, io.Reader, error)) (results []*File, err error) {
 	results = make([]*File, 0)
 
@@ -60,3 +60,37 @@ func TestUpload(t *testing.T) {
 		// },
 	}, results)
 }
+
+func TestUploadMaxSize(t *testing.T) {
+	mem := NewMemoryHandleRegistry()
+
+	RegisterHttpStorageServiceServer(mem, &HttpStorageServiceImpl{})
+
+	server := httptest.NewServer(NewHttpHandler(mem))
+	defer server.Close()
+
+	client := CreateHttpStorageServiceClient(NewHttpClient(server.URL, &http.Client{}))
+
+	singleFile := func(content string) func() (string, io.Reader, error) {
+		sent := false
+		return func() (string, io.Reader, error) {
+			if sent {
+				return "", nil, io.EOF
+			}
+			sent = true
+			return "test.txt", strings.NewReader(content), nil
+		}
+	}
+
+	results, err := client.UploadSmallFiles(context.Background(), "test", singleFile("Hello World"))
+	assert.NoError(t, err)
+	assert.Equal(t, []*File{{Name: "test.txt", Size: 11}}, results)
+
+	_, err = client.UploadSmallFiles(context.Background(), "test", singleFile(strings.Repeat("a", 2048)))
+	assert.ErrorIs(t, err, ErrRequestTooLarge)
+
+	// the limit only applies to the method with MaxSize option
+	results, err = client.UploadFiles(context.Background(), "test", singleFile(strings.Repeat("a", 2048)))
+	assert.NoError(t, err)
+	assert.Equal(t, []*File{{Name: "test.txt", Size: 2048}}, results)
+}
@@ -15,6 +15,7 @@ type CommentPosition int
 const (
 	CommentTop CommentPosition = iota
 	CommentBottom
+	CommentTrailing // on the same line after the element, e.g. Age: int32 # the user's age
 )
 
 type Comment struct {
@@ -28,3 +29,16 @@ func (c *Comment) Format(sb *strings.Builder) {
 	sb.WriteString("# ")
 	sb.WriteString(strings.TrimSpace(c.Token.Value))
 }
+
+// formatTrailingComments writes the trailing comment of an element after it,
+// on the same line
+func formatTrailingComments(sb *strings.Builder, comments []*Comment) {
+	for _, comment := range comments {
+		if comment.Position != CommentTrailing {
+			continue
+		}
+
+		sb.WriteString(" ")
+		comment.Format(sb)
+	}
+}
@@ -13,6 +13,7 @@ import (
 type Const struct {
 	Token      *token.Token
 	Identifier *Identifier
+	Type       Type // optional, the model of an object literal, e.g. const A: User = { ... }
 	Value      Value
 	Comments   []*Comment
 }
@@ -21,14 +22,22 @@ var _ (Expr) = (*Const)(nil)
 
 func (c *Const) Format(sb *strings.Builder) {
 	for _, comment := range c.Comments {
+		if comment.Position == CommentTrailing {
+			continue
+		}
 		comment.Format(sb)
 		sb.WriteString("\n")
 	}
 
 	sb.WriteString("const ")
 	c.Identifier.Format(sb)
+	if c.Type != nil {
+		sb.WriteString(": ")
+		c.Type.Format(sb)
+	}
 	sb.WriteString(" = ")
 	c.Value.Format(sb)
+	formatTrailingComments(sb, c.Comments)
 }
 
 func (c *Const) AddComments(comments ...*Comment) {
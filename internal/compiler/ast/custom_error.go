@@ -12,22 +12,28 @@ import (
 //
 
 type CustomError struct {
-	Token    *token.Token
-	Name     *Identifier
-	Code     int64
-	Msg      *ValueString
-	Comments []*Comment
+	Token      *token.Token
+	Name       *Identifier
+	Code       int64
+	HttpStatus Value // optional, either *ValueInt or *ValueVariable with the name of the status, e.g. NotFound
+	Msg        *ValueString
+	Comments   []*Comment
 }
 
 var _ (Expr) = (*CustomError)(nil)
 
 func (c *CustomError) Format(sb *strings.Builder) {
+	var leading bool
 	for _, comment := range c.Comments {
+		if comment.Position == CommentTrailing {
+			continue
+		}
 		sb.WriteString("\n")
 		comment.Format(sb)
+		leading = true
 	}
 
-	if len(c.Comments) > 0 {
+	if leading {
 		sb.WriteString("\n")
 	}
 	sb.WriteString("error ")
@@ -40,9 +46,20 @@ func (c *CustomError) Format(sb *strings.Builder) {
 		sb.WriteString(" ")
 	}
 
+	if c.HttpStatus != nil {
+		sb.WriteString("HttpStatus = ")
+		c.HttpStatus.Format(sb)
+		sb.WriteString(" ")
+	}
+
+	// surrounding whitespaces in the message are usually copy-paste mistakes
+	msg := *c.Msg
+	msg.Value = strings.TrimSpace(msg.Value)
+
 	sb.WriteString("Msg = ")
-	c.Msg.Format(sb)
+	msg.Format(sb)
 	sb.WriteString(" }")
+	formatTrailingComments(sb, c.Comments)
 }
 
 func (c *CustomError) AddComments(comments ...*Comment) {
@@ -8,6 +8,7 @@ import "strings"
 
 type Document struct {
 	Comments []*Comment
+	Imports  []*Import
 	Consts   []*Const
 	Enums    []*Enum
 	Models   []*Model
@@ -18,6 +19,19 @@ type Document struct {
 var _ (Expr) = (*Document)(nil)
 
 func (d *Document) Format(sb *strings.Builder) {
+	// Imports
+	//
+	for i, imp := range d.Imports {
+		if i != 0 {
+			sb.WriteString("\n")
+		}
+		imp.Format(sb)
+	}
+
+	if len(d.Imports) > 0 && (len(d.Consts) > 0 || len(d.Enums) > 0 || len(d.Models) > 0 || len(d.Services) > 0 || len(d.Errors) > 0) {
+		sb.WriteString("\n\n")
+	}
+
 	// Consts
 	//
 	for i, c := range d.Consts {
@@ -87,7 +101,7 @@ func (d *Document) Format(sb *strings.Builder) {
 	}
 
 	// Comments (Remaining)
-	neededNewline := (len(d.Consts) > 0 || len(d.Enums) > 0 || len(d.Services) > 0 || len(d.Errors) > 0) && len(d.Comments) > 0
+	neededNewline := (len(d.Imports) > 0 || len(d.Consts) > 0 || len(d.Enums) > 0 || len(d.Services) > 0 || len(d.Errors) > 0) && len(d.Comments) > 0
 
 	if neededNewline {
 		sb.WriteString("\n")
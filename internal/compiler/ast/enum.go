@@ -14,6 +14,7 @@ type EnumSet struct {
 	Name     *Identifier
 	Value    *ValueInt
 	Defined  bool
+	Options  *Options
 	Comments []*Comment
 }
 
@@ -21,6 +22,9 @@ var _ (Expr) = (*EnumSet)(nil)
 
 func (e *EnumSet) Format(sb *strings.Builder) {
 	for _, comment := range e.Comments {
+		if comment.Position == CommentTrailing {
+			continue
+		}
 		sb.WriteString("    ")
 		comment.Format(sb)
 		sb.WriteString("\n")
@@ -32,17 +36,74 @@ func (e *EnumSet) Format(sb *strings.Builder) {
 		sb.WriteString(" = ")
 		e.Value.Format(sb)
 	}
+
+	if e.Options != nil && (len(e.Options.List) > 0 || len(e.Options.Comments) > 0) {
+		e.Options.Format(sb)
+	}
+
+	formatTrailingComments(sb, e.Comments)
 }
 
 func (e *EnumSet) AddComments(comments ...*Comment) {
 	e.Comments = append(e.Comments, comments...)
 }
 
+// EnumReservedRange is a single reserved value if End is nil,
+// otherwise it's an inclusive range of values, e.g. 20..30
+type EnumReservedRange struct {
+	Start *ValueInt
+	End   *ValueInt
+}
+
+func (r *EnumReservedRange) Format(sb *strings.Builder) {
+	r.Start.Format(sb)
+	if r.End != nil {
+		sb.WriteString("..")
+		r.End.Format(sb)
+	}
+}
+
+type EnumReserved struct {
+	Token    *token.Token
+	Ranges   []*EnumReservedRange
+	Comments []*Comment
+}
+
+var _ (Expr) = (*EnumReserved)(nil)
+
+func (e *EnumReserved) Format(sb *strings.Builder) {
+	for _, comment := range e.Comments {
+		if comment.Position == CommentTrailing {
+			continue
+		}
+		sb.WriteString("    ")
+		comment.Format(sb)
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("    reserved ")
+	for i, r := range e.Ranges {
+		if i != 0 {
+			sb.WriteString(", ")
+		}
+		r.Format(sb)
+	}
+
+	formatTrailingComments(sb, e.Comments)
+}
+
+func (e *EnumReserved) AddComments(comments ...*Comment) {
+	e.Comments = append(e.Comments, comments...)
+}
+
 type Enum struct {
 	Token    *token.Token
 	Name     *Identifier
-	Size     int // 8, 16, 32, 64 selected by compiler based on the largest and smallest values
+	Type     Type // optional explicit base type, either *Int or *Uint
+	Size     int  // 8, 16, 32, 64 selected by compiler based on the largest and smallest values if Type is not set
+	Reserved []*EnumReserved
 	Sets     []*EnumSet
+	Options  *Options // enum's options, defined after the enum's body, e.g. { JsonNumber = true }
 	Comments []*Comment
 }
 
@@ -59,8 +120,24 @@ func (e *Enum) Format(sb *strings.Builder) {
 
 	sb.WriteString("enum ")
 	e.Name.Format(sb)
+	if e.Type != nil {
+		sb.WriteString(" ")
+		e.Type.Format(sb)
+	}
 	sb.WriteString(" {\n")
 
+	for i, reserved := range e.Reserved {
+		if i != 0 {
+			sb.WriteString("\n")
+		}
+
+		reserved.Format(sb)
+	}
+
+	if len(e.Reserved) > 0 && len(e.Sets) > 0 {
+		sb.WriteString("\n")
+	}
+
 	for i, set := range e.Sets {
 		if i != 0 {
 			sb.WriteString("\n")
@@ -80,6 +157,28 @@ func (e *Enum) Format(sb *strings.Builder) {
 	}
 
 	sb.WriteString("\n}")
+
+	if e.Options == nil || (len(e.Options.List) == 0 && len(e.Options.Comments) == 0) {
+		formatTrailingComments(sb, e.Comments)
+		return
+	}
+
+	// enum's options are at the top level, so they are indented
+	// one level less than the options of the enum's keys
+	sb.WriteString(" {")
+	for _, option := range e.Options.List {
+		var osb strings.Builder
+		option.Format(&osb)
+		sb.WriteString(strings.ReplaceAll(osb.String(), "\n        ", "\n    "))
+	}
+
+	for _, comment := range e.Options.Comments {
+		sb.WriteString("\n    ")
+		comment.Format(sb)
+	}
+
+	sb.WriteString("\n}")
+	formatTrailingComments(sb, e.Comments)
 }
 
 func (e *Enum) AddComments(comments ...*Comment) {
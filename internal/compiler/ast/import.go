@@ -0,0 +1,37 @@
+package ast
+
+import (
+	"strings"
+
+	"github.com/hexe-dev/hexe/internal/compiler/token"
+)
+
+//
+// Import
+//
+
+type Import struct {
+	Token    *token.Token
+	Path     *ValueString
+	Comments []*Comment
+}
+
+var _ (Expr) = (*Import)(nil)
+
+func (i *Import) Format(sb *strings.Builder) {
+	for _, comment := range i.Comments {
+		if comment.Position == CommentTrailing {
+			continue
+		}
+		comment.Format(sb)
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("import ")
+	i.Path.Format(sb)
+	formatTrailingComments(sb, i.Comments)
+}
+
+func (i *Import) AddComments(comments ...*Comment) {
+	i.Comments = append(i.Comments, comments...)
+}
@@ -21,15 +21,12 @@ type Field struct {
 var _ (Expr) = (*Field)(nil)
 
 func (f *Field) Format(sb *strings.Builder) {
-	for i, comment := range f.Comments {
-		if i != 0 {
-			sb.WriteString("\n")
+	for _, comment := range f.Comments {
+		if comment.Position == CommentTrailing {
+			continue
 		}
 		sb.WriteString("    ")
 		comment.Format(sb)
-	}
-
-	if len(f.Comments) > 0 {
 		sb.WriteString("\n")
 	}
 
@@ -41,11 +38,11 @@ func (f *Field) Format(sb *strings.Builder) {
 	sb.WriteString(": ")
 	f.Type.Format(sb)
 
-	if len(f.Options.List) == 0 && len(f.Options.Comments) == 0 {
-		return
+	if len(f.Options.List) > 0 || len(f.Options.Comments) > 0 {
+		f.Options.Format(sb)
 	}
 
-	f.Options.Format(sb)
+	formatTrailingComments(sb, f.Comments)
 }
 
 func (f *Field) AddComments(comments ...*Comment) {
@@ -61,23 +58,177 @@ var _ (Expr) = (*Extend)(nil)
 
 func (e *Extend) Format(sb *strings.Builder) {
 	for _, comment := range e.Comments {
+		if comment.Position == CommentTrailing {
+			continue
+		}
 		sb.WriteString("\n    ")
 		comment.Format(sb)
 	}
 
 	sb.WriteString("    ...")
 	e.Name.Format(sb)
+	formatTrailingComments(sb, e.Comments)
 }
 
 func (e *Extend) AddComments(comments ...*Comment) {
 	e.Comments = append(e.Comments, comments...)
 }
 
+type OneOfVariant struct {
+	Name     *Identifier
+	Type     Type
+	Comments []*Comment
+}
+
+var _ (Expr) = (*OneOfVariant)(nil)
+
+func (v *OneOfVariant) Format(sb *strings.Builder) {
+	for _, comment := range v.Comments {
+		if comment.Position == CommentTrailing {
+			continue
+		}
+		sb.WriteString("        ")
+		comment.Format(sb)
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("        ")
+	v.Name.Format(sb)
+	sb.WriteString(": ")
+	v.Type.Format(sb)
+	formatTrailingComments(sb, v.Comments)
+}
+
+func (v *OneOfVariant) AddComments(comments ...*Comment) {
+	v.Comments = append(v.Comments, comments...)
+}
+
+// OneOf holds exactly one of its variants, and it is encoded
+// with a discriminator to know which variant is set
+type OneOf struct {
+	Token    *token.Token
+	Name     *Identifier
+	Variants []*OneOfVariant
+	Comments []*Comment
+}
+
+var _ (Expr) = (*OneOf)(nil)
+
+func (o *OneOf) Format(sb *strings.Builder) {
+	for _, comment := range o.Comments {
+		if comment.Position != CommentTop {
+			continue
+		}
+		sb.WriteString("    ")
+		comment.Format(sb)
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("    oneof ")
+	o.Name.Format(sb)
+	sb.WriteString(" {")
+
+	for _, variant := range o.Variants {
+		sb.WriteString("\n")
+		variant.Format(sb)
+	}
+
+	for _, comment := range o.Comments {
+		if comment.Position != CommentBottom {
+			continue
+		}
+
+		sb.WriteString("\n        ")
+		comment.Format(sb)
+	}
+
+	sb.WriteString("\n    }")
+	formatTrailingComments(sb, o.Comments)
+}
+
+func (o *OneOf) AddComments(comments ...*Comment) {
+	o.Comments = append(o.Comments, comments...)
+}
+
+// Requires makes the fields required when the When field is set,
+// e.g. requires(B, C, when: A)
+type Requires struct {
+	Token    *token.Token
+	Fields   []*Identifier
+	When     *Identifier
+	Comments []*Comment
+}
+
+var _ (Expr) = (*Requires)(nil)
+
+func (r *Requires) Format(sb *strings.Builder) {
+	for _, comment := range r.Comments {
+		if comment.Position == CommentTrailing {
+			continue
+		}
+		sb.WriteString("    ")
+		comment.Format(sb)
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("    requires(")
+	for _, field := range r.Fields {
+		field.Format(sb)
+		sb.WriteString(", ")
+	}
+	sb.WriteString("when: ")
+	r.When.Format(sb)
+	sb.WriteString(")")
+	formatTrailingComments(sb, r.Comments)
+}
+
+func (r *Requires) AddComments(comments ...*Comment) {
+	r.Comments = append(r.Comments, comments...)
+}
+
+// ModelReserved reserves the names of removed fields, so they can't be
+// reused by a new field with a different type, e.g. reserved "OldName"
+type ModelReserved struct {
+	Token    *token.Token
+	Names    []*ValueString
+	Comments []*Comment
+}
+
+var _ (Expr) = (*ModelReserved)(nil)
+
+func (r *ModelReserved) Format(sb *strings.Builder) {
+	for _, comment := range r.Comments {
+		if comment.Position == CommentTrailing {
+			continue
+		}
+		sb.WriteString("    ")
+		comment.Format(sb)
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("    reserved ")
+	for i, name := range r.Names {
+		if i != 0 {
+			sb.WriteString(", ")
+		}
+		name.Format(sb)
+	}
+
+	formatTrailingComments(sb, r.Comments)
+}
+
+func (r *ModelReserved) AddComments(comments ...*Comment) {
+	r.Comments = append(r.Comments, comments...)
+}
+
 type Model struct {
 	Token    *token.Token
 	Name     *Identifier
+	Reserved []*ModelReserved
 	Extends  []*Extend
 	Fields   []*Field
+	OneOfs   []*OneOf
+	Requires []*Requires
 	Comments []*Comment
 }
 
@@ -97,6 +248,11 @@ func (m *Model) Format(sb *strings.Builder) {
 
 	sb.WriteString(" {")
 
+	for _, reserved := range m.Reserved {
+		sb.WriteString("\n")
+		reserved.Format(sb)
+	}
+
 	for _, extend := range m.Extends {
 		sb.WriteString("\n")
 		extend.Format(sb)
@@ -107,6 +263,16 @@ func (m *Model) Format(sb *strings.Builder) {
 		field.Format(sb)
 	}
 
+	for _, oneOf := range m.OneOfs {
+		sb.WriteString("\n")
+		oneOf.Format(sb)
+	}
+
+	for _, requires := range m.Requires {
+		sb.WriteString("\n")
+		requires.Format(sb)
+	}
+
 	for _, comment := range m.Comments {
 		if comment.Position != CommentBottom {
 			continue
@@ -117,6 +283,7 @@ func (m *Model) Format(sb *strings.Builder) {
 	}
 
 	sb.WriteString("\n}")
+	formatTrailingComments(sb, m.Comments)
 }
 
 func (m *Model) AddComments(comments ...*Comment) {
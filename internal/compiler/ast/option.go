@@ -1,6 +1,12 @@
 package ast
 
-import "strings"
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
 
 //
 // Option
@@ -16,22 +22,23 @@ var _ (Expr) = (*Option)(nil)
 
 func (o *Option) Format(sb *strings.Builder) {
 	for _, comment := range o.Comments {
+		if comment.Position == CommentTrailing {
+			continue
+		}
 		sb.WriteString("\n        ")
 		comment.Format(sb)
 	}
 
 	sb.WriteString("\n        ")
 	o.Name.Format(sb)
-	if v, ok := o.Value.(*ValueBool); ok {
-		// it means that it's just a flag option without value
-		// so we don't need to print the value
-		if v.Token == nil {
-			return
-		}
+
+	// it's just a flag option without value, so the value is not printed
+	if v, ok := o.Value.(*ValueBool); !ok || v.Token != nil {
+		sb.WriteString(" = ")
+		o.Value.Format(sb)
 	}
 
-	sb.WriteString(" = ")
-	o.Value.Format(sb)
+	formatTrailingComments(sb, o.Comments)
 }
 
 func (o *Option) AddComments(comments ...*Comment) {
@@ -62,3 +69,96 @@ func (o *Options) Format(sb *strings.Builder) {
 func (o *Options) AddComments(comments ...*Comment) {
 	o.Comments = append(o.Comments, comments...)
 }
+
+// Deprecated reports whether the Deprecated option is set, it's either a flag,
+// e.g. { Deprecated }, or the message which points to the replacement,
+// e.g. { Deprecated = "use Email instead" }
+func (o *Options) Deprecated() (message string, ok bool) {
+	if o == nil {
+		return "", false
+	}
+
+	for _, option := range o.List {
+		if option.Name.Token.Value != "Deprecated" {
+			continue
+		}
+
+		switch v := option.Value.(type) {
+		case *ValueBool:
+			return "", v.Value
+		case *ValueString:
+			return v.Value, true
+		}
+	}
+
+	return "", false
+}
+
+// Lifecycle returns the versions of the Since and Until options, which are the
+// api versions that added and removed the field or method, nil if not set
+func (o *Options) Lifecycle() (since, until *Version) {
+	if o == nil {
+		return nil, nil
+	}
+
+	for _, option := range o.List {
+		v, ok := option.Value.(*ValueString)
+		if !ok {
+			continue
+		}
+
+		version, err := ParseVersion(v.Value)
+		if err != nil {
+			continue
+		}
+
+		switch option.Name.Token.Value {
+		case "Since":
+			since = &version
+		case "Until":
+			until = &version
+		}
+	}
+
+	return since, until
+}
+
+// Version is a semantic version of the api, e.g. 1.2.0
+type Version struct {
+	Major int
+	Minor int
+	Patch int
+}
+
+// ParseVersion parses a semantic version without pre-release or build
+// metadata, e.g. 1.2.0, a leading v is allowed, e.g. v1.2.0
+func ParseVersion(value string) (Version, error) {
+	parts := strings.Split(strings.TrimPrefix(value, "v"), ".")
+	if len(parts) != 3 {
+		return Version{}, errors.New("expected major.minor.patch")
+	}
+
+	var numbers [3]int
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 || strings.HasPrefix(part, "+") {
+			return Version{}, fmt.Errorf("invalid number %q", part)
+		}
+		numbers[i] = n
+	}
+
+	return Version{Major: numbers[0], Minor: numbers[1], Patch: numbers[2]}, nil
+}
+
+// Compare returns -1, 0 or +1 if v is less than, equal to or greater than other
+func (v Version) Compare(other Version) int {
+	return cmp.Or(
+		cmp.Compare(v.Major, other.Major),
+		cmp.Compare(v.Minor, other.Minor),
+		cmp.Compare(v.Patch, other.Patch),
+	)
+}
+
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
@@ -1,7 +1,10 @@
 package ast
 
 import (
+	"errors"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/hexe-dev/hexe/internal/compiler/token"
 )
@@ -56,6 +59,9 @@ var _ (Expr) = (*Method)(nil)
 
 func (m *Method) Format(sb *strings.Builder) {
 	for _, comment := range m.Comments {
+		if comment.Position == CommentTrailing {
+			continue
+		}
 		sb.WriteString("\n    ")
 		comment.Format(sb)
 	}
@@ -88,12 +94,40 @@ func (m *Method) Format(sb *strings.Builder) {
 	if len(m.Options.List) > 0 || len(m.Options.Comments) > 0 {
 		m.Options.Format(sb)
 	}
+
+	formatTrailingComments(sb, m.Comments)
 }
 
 func (m *Method) AddComments(comments ...*Comment) {
 	m.Comments = append(m.Comments, comments...)
 }
 
+// ParseRateLimit parses the method's RateLimit option, which is the number of
+// calls per window, e.g. 100/s, 1000/m, 5000/h or 10/30s
+func ParseRateLimit(value string) (count int64, window time.Duration, err error) {
+	countStr, windowStr, ok := strings.Cut(value, "/")
+	if !ok {
+		return 0, 0, errors.New("missing /")
+	}
+
+	count, err = strconv.ParseInt(countStr, 10, 64)
+	if err != nil || count <= 0 {
+		return 0, 0, errors.New("count should be a positive integer")
+	}
+
+	// a single unit means one of it, e.g. s is 1s
+	if windowStr != "" && (windowStr[0] < '0' || windowStr[0] > '9') {
+		windowStr = "1" + windowStr
+	}
+
+	window, err = time.ParseDuration(windowStr)
+	if err != nil || window <= 0 {
+		return 0, 0, errors.New("window should be a positive duration")
+	}
+
+	return count, window, nil
+}
+
 type ServiceType int
 
 const (
@@ -150,6 +184,7 @@ func (s *Service) Format(sb *strings.Builder) {
 	}
 
 	sb.WriteString("\n}")
+	formatTrailingComments(sb, s.Comments)
 }
 
 func (s *Service) AddComments(comments ...*Comment) {
@@ -141,6 +141,21 @@ func (m *Map) Format(sb *strings.Builder) {
 
 func (m *Map) typ() {}
 
+type Set struct {
+	Token *token.Token
+	Type  Type
+}
+
+var _ Type = (*Set)(nil)
+
+func (s *Set) Format(sb *strings.Builder) {
+	sb.WriteString("set<")
+	s.Type.Format(sb)
+	sb.WriteString(">")
+}
+
+func (s *Set) typ() {}
+
 type Timestamp struct {
 	Token *token.Token
 }
@@ -211,3 +211,54 @@ func (v *ValueVariable) Format(sb *strings.Builder) {
 }
 
 func (v *ValueVariable) value() {}
+
+// ValueObject is an object literal, e.g. { Name = "anon" Age = 0 }, the keys are
+// the fields of the model, which is set by Validate based on the const's type
+type ValueObject struct {
+	Token  *token.Token
+	Fields []*Option
+	Model  *Model
+}
+
+var _ Value = (*ValueObject)(nil)
+
+func (v *ValueObject) Format(sb *strings.Builder) {
+	if len(v.Fields) == 0 {
+		sb.WriteString("{}")
+		return
+	}
+
+	sb.WriteString("{")
+	for _, field := range v.Fields {
+		sb.WriteString(" ")
+		field.Name.Format(sb)
+		sb.WriteString(" = ")
+		field.Value.Format(sb)
+	}
+	sb.WriteString(" }")
+}
+
+func (v *ValueObject) value() {}
+
+// ValueList is a list literal, e.g. ["a", "b"], the type is the array
+// or set of the field, which is set by Validate
+type ValueList struct {
+	Token  *token.Token
+	Values []Value
+	Type   Type
+}
+
+var _ Value = (*ValueList)(nil)
+
+func (v *ValueList) Format(sb *strings.Builder) {
+	sb.WriteString("[")
+	for i, value := range v.Values {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		value.Format(sb)
+	}
+	sb.WriteString("]")
+}
+
+func (v *ValueList) value() {}
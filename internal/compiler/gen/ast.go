@@ -0,0 +1,315 @@
+package gen
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/hexe-dev/hexe/internal/compiler/ast"
+)
+
+// the JSON representation of the merged document, so the tools which are not written
+// in Go, e.g. plugins and linters, can use the schema without parsing it. The empty
+// lists are omitted
+
+type astJsonDocument struct {
+	Package  string            `json:"package"`
+	Consts   []*astJsonConst   `json:"consts,omitempty"`
+	Enums    []*astJsonEnum    `json:"enums,omitempty"`
+	Models   []*astJsonModel   `json:"models,omitempty"`
+	Services []*astJsonService `json:"services,omitempty"`
+	Errors   []*astJsonError   `json:"errors,omitempty"`
+}
+
+// astJsonType is the resolved type, custom types are either model or enum kinds
+type astJsonType struct {
+	Kind  string       `json:"kind"`           // int, uint, byte, float, string, bool, timestamp, any, array, set, map, model or enum
+	Name  string       `json:"name,omitempty"` // name of the model or enum
+	Size  int          `json:"size,omitempty"` // bits of int, uint and float
+	Elem  *astJsonType `json:"elem,omitempty"` // element of array and set
+	Key   *astJsonType `json:"key,omitempty"`
+	Value *astJsonType `json:"value,omitempty"`
+}
+
+type astJsonOption struct {
+	Name  string `json:"name"`
+	Value any    `json:"value"`
+}
+
+type astJsonConst struct {
+	Name     string       `json:"name"`
+	Type     *astJsonType `json:"type,omitempty"`  // only set for object and list literals
+	Expr     string       `json:"expr"`            // the value as written in the schema
+	Value    any          `json:"value,omitempty"` // resolved value, byte sizes in bytes and durations in nanoseconds
+	Comments []string     `json:"comments,omitempty"`
+}
+
+type astJsonEnumKey struct {
+	Name     string           `json:"name"`
+	Value    int64            `json:"value"`
+	Options  []*astJsonOption `json:"options,omitempty"`
+	Comments []string         `json:"comments,omitempty"`
+}
+
+type astJsonEnum struct {
+	Name     string            `json:"name"`
+	Size     int               `json:"size"`
+	Unsigned bool              `json:"unsigned,omitempty"`
+	Keys     []*astJsonEnumKey `json:"keys,omitempty"`
+	Options  []*astJsonOption  `json:"options,omitempty"`
+	Comments []string          `json:"comments,omitempty"`
+}
+
+type astJsonField struct {
+	Name     string           `json:"name"`
+	Type     *astJsonType     `json:"type"`
+	Optional bool             `json:"optional,omitempty"`
+	Options  []*astJsonOption `json:"options,omitempty"`
+	Comments []string         `json:"comments,omitempty"`
+}
+
+type astJsonOneOf struct {
+	Name     string          `json:"name"`
+	Variants []*astJsonField `json:"variants,omitempty"`
+}
+
+type astJsonRequires struct {
+	Fields []string `json:"fields,omitempty"`
+	When   string   `json:"when,omitempty"`
+}
+
+type astJsonModel struct {
+	Name     string             `json:"name"`
+	Fields   []*astJsonField    `json:"fields,omitempty"`
+	OneOfs   []*astJsonOneOf    `json:"oneofs,omitempty"`
+	Requires []*astJsonRequires `json:"requires,omitempty"`
+	Comments []string           `json:"comments,omitempty"`
+}
+
+type astJsonParam struct {
+	Name   string       `json:"name"`
+	Type   *astJsonType `json:"type"`
+	Stream bool         `json:"stream,omitempty"`
+}
+
+type astJsonMethod struct {
+	Name     string           `json:"name"`
+	Args     []*astJsonParam  `json:"args,omitempty"`
+	Returns  []*astJsonParam  `json:"returns,omitempty"`
+	Options  []*astJsonOption `json:"options,omitempty"`
+	Comments []string         `json:"comments,omitempty"`
+}
+
+type astJsonService struct {
+	Name     string           `json:"name"`
+	Type     string           `json:"type"` // http or rpc
+	Methods  []*astJsonMethod `json:"methods,omitempty"`
+	Comments []string         `json:"comments,omitempty"`
+}
+
+type astJsonError struct {
+	Name       string   `json:"name"`
+	Code       int64    `json:"code"`
+	HttpStatus any      `json:"httpStatus,omitempty"`
+	Msg        string   `json:"msg"`
+	Comments   []string `json:"comments,omitempty"`
+}
+
+// generateAst writes the merged document as JSON, after the validation, so the
+// extends of the models are already inlined and the enums' sizes are computed
+func generateAst(out io.Writer, pkg string, doc *ast.Document) error {
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(newAstJsonDocument(pkg, doc))
+}
+
+func newAstJsonDocument(pkg string, doc *ast.Document) *astJsonDocument {
+	constsMap := make(map[string]*ast.Const)
+	for _, c := range doc.Consts {
+		constsMap[c.Identifier.Token.Value] = c
+	}
+
+	isModelType := createIsModelTypeFunc(doc.Models)
+
+	getType := func(typ ast.Type) *astJsonType {
+		return getAstJsonType(typ, isModelType)
+	}
+
+	getOptions := func(options *ast.Options) []*astJsonOption {
+		if options == nil {
+			return nil
+		}
+
+		return mapperFunc(options.List, func(o *ast.Option) *astJsonOption {
+			return &astJsonOption{
+				Name:  o.Name.Token.Value,
+				Value: getAstJsonValue(constsMap, o.Value),
+			}
+		})
+	}
+
+	getParam := func(name *ast.Identifier, typ ast.Type, stream bool) *astJsonParam {
+		return &astJsonParam{
+			Name:   name.Token.Value,
+			Type:   getType(typ),
+			Stream: stream,
+		}
+	}
+
+	return &astJsonDocument{
+		Package: pkg,
+		Consts: mapperFunc(doc.Consts, func(c *ast.Const) *astJsonConst {
+			jc := &astJsonConst{
+				Name:     c.Identifier.Token.Value,
+				Expr:     formatExpr(c.Value),
+				Comments: getCommentLines(c.Comments, ast.CommentTop),
+			}
+
+			if c.Type != nil {
+				jc.Type = getType(c.Type)
+			} else {
+				jc.Value = getAstJsonValue(constsMap, c.Value)
+			}
+
+			return jc
+		}),
+		Enums: mapperFunc(doc.Enums, func(enum *ast.Enum) *astJsonEnum {
+			_, unsigned := enum.Type.(*ast.Uint)
+			return &astJsonEnum{
+				Name:     enum.Name.Token.Value,
+				Size:     enum.Size,
+				Unsigned: unsigned,
+				Keys: mapperFunc(enum.Sets, func(set *ast.EnumSet) *astJsonEnumKey {
+					return &astJsonEnumKey{
+						Name:     set.Name.Token.Value,
+						Value:    set.Value.Value,
+						Options:  getOptions(set.Options),
+						Comments: getCommentLines(set.Comments, ast.CommentTop),
+					}
+				}),
+				Options:  getOptions(enum.Options),
+				Comments: getCommentLines(enum.Comments, ast.CommentTop),
+			}
+		}),
+		Models: mapperFunc(doc.Models, func(model *ast.Model) *astJsonModel {
+			return &astJsonModel{
+				Name: model.Name.Token.Value,
+				Fields: mapperFunc(model.Fields, func(field *ast.Field) *astJsonField {
+					return &astJsonField{
+						Name:     field.Name.Token.Value,
+						Type:     getType(field.Type),
+						Optional: field.IsOptional,
+						Options:  getOptions(field.Options),
+						Comments: getCommentLines(field.Comments, ast.CommentTop),
+					}
+				}),
+				OneOfs: mapperFunc(model.OneOfs, func(oneOf *ast.OneOf) *astJsonOneOf {
+					return &astJsonOneOf{
+						Name: oneOf.Name.Token.Value,
+						Variants: mapperFunc(oneOf.Variants, func(variant *ast.OneOfVariant) *astJsonField {
+							return &astJsonField{
+								Name:     variant.Name.Token.Value,
+								Type:     getType(variant.Type),
+								Comments: getCommentLines(variant.Comments, ast.CommentTop),
+							}
+						}),
+					}
+				}),
+				Requires: mapperFunc(model.Requires, func(requires *ast.Requires) *astJsonRequires {
+					jr := &astJsonRequires{
+						Fields: mapperFunc(requires.Fields, func(field *ast.Identifier) string {
+							return field.Token.Value
+						}),
+					}
+					if requires.When != nil {
+						jr.When = requires.When.Token.Value
+					}
+					return jr
+				}),
+				Comments: getCommentLines(model.Comments, ast.CommentTop),
+			}
+		}),
+		Services: mapperFunc(doc.Services, func(service *ast.Service) *astJsonService {
+			return &astJsonService{
+				Name: service.Name.Token.Value,
+				Type: service.Type.String(),
+				Methods: mapperFunc(service.Methods, func(method *ast.Method) *astJsonMethod {
+					return &astJsonMethod{
+						Name: method.Name.Token.Value,
+						Args: mapperFunc(method.Args, func(arg *ast.Arg) *astJsonParam {
+							return getParam(arg.Name, arg.Type, arg.Stream)
+						}),
+						Returns: mapperFunc(method.Returns, func(ret *ast.Return) *astJsonParam {
+							return getParam(ret.Name, ret.Type, ret.Stream)
+						}),
+						Options:  getOptions(method.Options),
+						Comments: getCommentLines(method.Comments, ast.CommentTop),
+					}
+				}),
+				Comments: getCommentLines(service.Comments, ast.CommentTop),
+			}
+		}),
+		Errors: mapperFunc(doc.Errors, func(customError *ast.CustomError) *astJsonError {
+			je := &astJsonError{
+				Name:     customError.Name.Token.Value,
+				Code:     customError.Code,
+				Comments: getCommentLines(customError.Comments, ast.CommentTop),
+			}
+			if customError.Msg != nil {
+				je.Msg = customError.Msg.Value
+			}
+			if customError.HttpStatus != nil {
+				je.HttpStatus = getAstJsonValue(constsMap, customError.HttpStatus)
+			}
+			return je
+		}),
+	}
+}
+
+func getAstJsonType(typ ast.Type, isModelType func(value string) bool) *astJsonType {
+	switch typ := typ.(type) {
+	case *ast.CustomType:
+		if isModelType(typ.Token.Value) {
+			return &astJsonType{Kind: "model", Name: typ.Token.Value}
+		}
+		return &astJsonType{Kind: "enum", Name: typ.Token.Value}
+	case *ast.Int:
+		return &astJsonType{Kind: "int", Size: typ.Size}
+	case *ast.Uint:
+		return &astJsonType{Kind: "uint", Size: typ.Size}
+	case *ast.Float:
+		return &astJsonType{Kind: "float", Size: typ.Size}
+	case *ast.Byte:
+		return &astJsonType{Kind: "byte"}
+	case *ast.String:
+		return &astJsonType{Kind: "string"}
+	case *ast.Bool:
+		return &astJsonType{Kind: "bool"}
+	case *ast.Timestamp:
+		return &astJsonType{Kind: "timestamp"}
+	case *ast.Any:
+		return &astJsonType{Kind: "any"}
+	case *ast.Array:
+		return &astJsonType{Kind: "array", Elem: getAstJsonType(typ.Type, isModelType)}
+	case *ast.Set:
+		return &astJsonType{Kind: "set", Elem: getAstJsonType(typ.Type, isModelType)}
+	case *ast.Map:
+		return &astJsonType{
+			Kind:  "map",
+			Key:   getAstJsonType(typ.Key, isModelType),
+			Value: getAstJsonType(typ.Value, isModelType),
+		}
+	default:
+		// This shouldn't happen as the validator should catch this any errors
+		panic("unknown type: " + formatExpr(typ))
+	}
+}
+
+// getAstJsonValue resolves the value the same as the constants, the values which
+// are not flat constants, e.g. object and list literals, are kept as written
+func getAstJsonValue(constsMap map[string]*ast.Const, value ast.Value) any {
+	if v, err := getConstantValue(constsMap, value); err == nil {
+		return v
+	}
+
+	return formatExpr(value)
+}
@@ -0,0 +1,124 @@
+package gen
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/hexe-dev/hexe/internal/compiler/ast"
+	"github.com/hexe-dev/hexe/internal/strcase"
+)
+
+// generateConstants writes only the constants of the doc, either as a flat json object
+// or as an env file, so they can be used by tools which are not generated by hexe
+func generateConstants(out io.Writer, target Target, doc *ast.Document) error {
+	constsMap := make(map[string]*ast.Const)
+	for _, c := range doc.Consts {
+		constsMap[c.Identifier.Token.Value] = c
+	}
+
+	// object and list literals are not flat values, so they are skipped
+	consts := filterFunc(doc.Consts, func(c *ast.Const) bool {
+		return c.Type == nil
+	})
+
+	var sb strings.Builder
+
+	if target == TargetJson {
+		sb.WriteString("{")
+	}
+
+	for i, c := range consts {
+		value, err := getConstantValue(constsMap, c.Value)
+		if err != nil {
+			return err
+		}
+
+		switch target {
+		case TargetJson:
+			if i > 0 {
+				sb.WriteString(",")
+			}
+
+			name, err := json.Marshal(c.Identifier.Token.Value)
+			if err != nil {
+				return err
+			}
+
+			data, err := json.Marshal(value)
+			if err != nil {
+				return err
+			}
+
+			sb.WriteString("\n  ")
+			sb.Write(name)
+			sb.WriteString(": ")
+			sb.Write(data)
+		case TargetEnv:
+			sb.WriteString(strings.ToUpper(strcase.ToSnake(c.Identifier.Token.Value)))
+			sb.WriteString("=")
+			sb.WriteString(getEnvValue(value))
+			sb.WriteString("\n")
+		default:
+			return fmt.Errorf("unknown constants target: %s", target)
+		}
+	}
+
+	if target == TargetJson {
+		if len(consts) > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString("}\n")
+	}
+
+	_, err := io.WriteString(out, sb.String())
+	return err
+}
+
+// getConstantValue resolves the value of a constant, byte sizes and durations
+// are scaled the same as the generated Go constants, bytes and nanoseconds
+func getConstantValue(constsMap map[string]*ast.Const, value ast.Value) (any, error) {
+	switch v := value.(type) {
+	case *ast.ValueString:
+		return v.Value, nil
+	case *ast.ValueInt:
+		return v.Value, nil
+	case *ast.ValueUint:
+		return v.Value, nil
+	case *ast.ValueFloat:
+		return v.Value, nil
+	case *ast.ValueBool:
+		return v.Value, nil
+	case *ast.ValueByteSize:
+		return v.Value * int64(v.Scale), nil
+	case *ast.ValueDuration:
+		return v.Value * int64(v.Scale), nil
+	case *ast.ValueNull:
+		return nil, nil
+	case *ast.ValueVariable:
+		c, ok := constsMap[v.Token.Value]
+		if !ok {
+			return nil, fmt.Errorf("unknown constant: %s", v.Token.Value)
+		}
+		return getConstantValue(constsMap, c.Value)
+	default:
+		return nil, fmt.Errorf("unsupported constant value: %T", value)
+	}
+}
+
+// getEnvValue formats the value so it can be sourced by a shell as well,
+// strings are always single quoted to prevent any expansion
+func getEnvValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return "'" + strings.ReplaceAll(v, "'", `'\''`) + "'"
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,215 @@
+package gen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hexe-dev/hexe/internal/compiler/ast"
+)
+
+// Change is a difference between two versions of a schema, it's breaking if
+// the code generated from the old version can't work with the new version
+type Change struct {
+	Breaking bool
+	Message  string
+}
+
+func (c Change) String() string {
+	if c.Breaking {
+		return "breaking: " + c.Message
+	}
+	return "compatible: " + c.Message
+}
+
+// Diff walks the old and new versions of a schema and returns the changes between them,
+// removed or changed declarations are breaking and the additions are compatible, except
+// the required fields which the old clients don't send. docs are expected to be validated
+func Diff(oldDocs, newDocs []*ast.Document) []Change {
+	d := &differ{}
+
+	oldDoc := mergeDocuments(oldDocs)
+	newDoc := mergeDocuments(newDocs)
+
+	diffDecls(d, "const", oldDoc.Consts, newDoc.Consts, func(c *ast.Const) string { return c.Identifier.Token.Value }, d.diffConst, nil)
+	diffDecls(d, "enum", oldDoc.Enums, newDoc.Enums, func(e *ast.Enum) string { return e.Name.Token.Value }, d.diffEnum, nil)
+	diffDecls(d, "model", oldDoc.Models, newDoc.Models, func(m *ast.Model) string { return m.Name.Token.Value }, d.diffModel, nil)
+	diffDecls(d, "service", oldDoc.Services, newDoc.Services, func(s *ast.Service) string { return s.Name.Token.Value }, d.diffService, nil)
+	diffDecls(d, "error", oldDoc.Errors, newDoc.Errors, func(e *ast.CustomError) string { return e.Name.Token.Value }, d.diffError, nil)
+
+	return d.changes
+}
+
+type differ struct {
+	changes []Change
+}
+
+func (d *differ) breaking(format string, args ...any) {
+	d.changes = append(d.changes, Change{Breaking: true, Message: fmt.Sprintf(format, args...)})
+}
+
+func (d *differ) compatible(format string, args ...any) {
+	d.changes = append(d.changes, Change{Message: fmt.Sprintf(format, args...)})
+}
+
+// diffDecls reports the removed and added declarations by their names and calls diff
+// for the ones which exist in both versions, the additions are compatible unless
+// breakingAddition, which is optional, returns a reason for them
+func diffDecls[T any](d *differ, kind string, olds, news []T, name func(T) string, diff func(prefix string, old, new T), breakingAddition func(T) string) {
+	newsMap := make(map[string]T, len(news))
+	for _, n := range news {
+		newsMap[name(n)] = n
+	}
+
+	oldsMap := make(map[string]struct{}, len(olds))
+	for _, o := range olds {
+		oldsMap[name(o)] = struct{}{}
+
+		prefix := kind + " " + name(o)
+		n, ok := newsMap[name(o)]
+		if !ok {
+			d.breaking("%s is removed", prefix)
+			continue
+		}
+
+		diff(prefix, o, n)
+	}
+
+	for _, n := range news {
+		if _, ok := oldsMap[name(n)]; ok {
+			continue
+		}
+
+		if breakingAddition != nil {
+			if reason := breakingAddition(n); reason != "" {
+				d.breaking("%s %s is added %s", kind, name(n), reason)
+				continue
+			}
+		}
+
+		d.compatible("%s %s is added", kind, name(n))
+	}
+}
+
+func (d *differ) diffConst(prefix string, old, new *ast.Const) {
+	if oldValue, newValue := formatExpr(old.Value), formatExpr(new.Value); oldValue != newValue {
+		d.breaking("%s value is changed from %s to %s", prefix, oldValue, newValue)
+	}
+}
+
+func (d *differ) diffEnum(prefix string, old, new *ast.Enum) {
+	if oldType, newType := getGolangEnumType(old), getGolangEnumType(new); oldType != newType {
+		d.breaking("%s type is changed from %s to %s", prefix, oldType, newType)
+	}
+
+	if isEnumJsonNumber(old) != isEnumJsonNumber(new) {
+		d.breaking("%s json encoding is changed", prefix)
+	}
+
+	diffDecls(d, prefix+" value", old.Sets, new.Sets, func(s *ast.EnumSet) string { return s.Name.Token.Value }, func(prefix string, old, new *ast.EnumSet) {
+		if old.Value.Value != new.Value.Value {
+			d.breaking("%s is renumbered from %d to %d", prefix, old.Value.Value, new.Value.Value)
+		}
+	}, nil)
+}
+
+func (d *differ) diffModel(prefix string, old, new *ast.Model) {
+	// the required fields are not sent by the old clients
+	diffDecls(d, prefix+" field", old.Fields, new.Fields, func(f *ast.Field) string { return f.Name.Token.Value }, d.diffField, func(f *ast.Field) string {
+		if f.IsOptional {
+			return ""
+		}
+		return "as required"
+	})
+
+	diffDecls(d, prefix+" oneof", old.OneOfs, new.OneOfs, func(o *ast.OneOf) string { return o.Name.Token.Value }, func(prefix string, old, new *ast.OneOf) {
+		diffDecls(d, prefix+" variant", old.Variants, new.Variants, func(v *ast.OneOfVariant) string { return v.Name.Token.Value }, func(prefix string, old, new *ast.OneOfVariant) {
+			d.diffType(prefix, old.Type, new.Type)
+		}, nil)
+	}, nil)
+}
+
+func (d *differ) diffField(prefix string, old, new *ast.Field) {
+	d.diffType(prefix, old.Type, new.Type)
+
+	if old.IsOptional != new.IsOptional {
+		if new.IsOptional {
+			d.breaking("%s is changed to optional", prefix)
+		} else {
+			d.breaking("%s is changed to required", prefix)
+		}
+	}
+
+	if oldTag, newTag := getGolangModelFieldTag(old, true), getGolangModelFieldTag(new, true); oldTag != newTag {
+		d.breaking("%s json tag is changed from %s to %s", prefix, oldTag, newTag)
+	}
+}
+
+func (d *differ) diffType(prefix string, old, new ast.Type) {
+	if oldType, newType := formatExpr(old), formatExpr(new); oldType != newType {
+		d.breaking("%s type is changed from %s to %s", prefix, oldType, newType)
+	}
+}
+
+func (d *differ) diffService(prefix string, old, new *ast.Service) {
+	if old.Type != new.Type {
+		d.breaking("%s is changed from %s to %s service", prefix, old.Type, new.Type)
+	}
+
+	diffDecls(d, prefix+" method", old.Methods, new.Methods, func(m *ast.Method) string { return m.Name.Token.Value }, func(prefix string, old, new *ast.Method) {
+		if oldSignature, newSignature := formatMethodSignature(old), formatMethodSignature(new); oldSignature != newSignature {
+			d.breaking("%s signature is changed from %s to %s", prefix, oldSignature, newSignature)
+		}
+
+		// HttpMethod changes the route, the other options only change the server's behavior
+		if oldHttpMethod, newHttpMethod := getMethodHttpMethod(old), getMethodHttpMethod(new); oldHttpMethod != newHttpMethod {
+			d.breaking("%s HttpMethod is changed from %s to %s", prefix, oldHttpMethod, newHttpMethod)
+		}
+	}, nil)
+}
+
+// formatMethodSignature returns the arguments and returns of the method, e.g. (id: string) => (user: User)
+func formatMethodSignature(m *ast.Method) string {
+	var sb strings.Builder
+
+	sb.WriteString("(")
+	for i, arg := range m.Args {
+		if i != 0 {
+			sb.WriteString(", ")
+		}
+		arg.Format(&sb)
+	}
+	sb.WriteString(")")
+
+	if len(m.Returns) > 0 {
+		sb.WriteString(" => (")
+		for i, ret := range m.Returns {
+			if i != 0 {
+				sb.WriteString(", ")
+			}
+			ret.Format(&sb)
+		}
+		sb.WriteString(")")
+	}
+
+	return sb.String()
+}
+
+func getMethodHttpMethod(m *ast.Method) string {
+	for _, o := range m.Options.List {
+		if v, ok := o.Value.(*ast.ValueString); ok && o.Name.Token.Value == "HttpMethod" {
+			return v.Value
+		}
+	}
+
+	return "POST"
+}
+
+func (d *differ) diffError(prefix string, old, new *ast.CustomError) {
+	if old.Code != new.Code {
+		d.breaking("%s code is changed from %d to %d", prefix, old.Code, new.Code)
+	}
+
+	if oldStatus, newStatus := getGolangHttpStatus(old), getGolangHttpStatus(new); oldStatus != newStatus {
+		d.breaking("%s http status is changed from %s to %s", prefix, oldStatus, newStatus)
+	}
+}
@@ -0,0 +1,281 @@
+package gen
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/hexe-dev/hexe/internal/compiler/ast"
+	"github.com/hexe-dev/hexe/internal/strcase"
+)
+
+// Explain writes a resolved description of the constant, enum, model, service or
+// error with the given name, including the generated Go and Typescript types and
+// where it is used by the other models and services. docs are expected to be validated
+func Explain(w io.Writer, name string, docs []*ast.Document) error {
+	mainDoc := mergeDocuments(docs)
+
+	isModelType := createIsModelTypeFunc(mainDoc.Models)
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+	found := true
+
+	switch {
+	case explainConst(tw, mainDoc, name):
+	case explainEnum(tw, mainDoc, name):
+	case explainModel(tw, mainDoc, name, isModelType):
+	case explainService(tw, mainDoc, name, isModelType):
+	case explainError(tw, mainDoc, name):
+	default:
+		found = false
+	}
+
+	if !found {
+		return fmt.Errorf("%s is not defined as a constant, enum, model, service or error", name)
+	}
+
+	return tw.Flush()
+}
+
+func explainConst(w io.Writer, doc *ast.Document, name string) bool {
+	for _, c := range doc.Consts {
+		if c.Identifier.Token.Value != name {
+			continue
+		}
+
+		fmt.Fprintf(w, "const %s = %s\n", name, formatExpr(c.Value))
+		fmt.Fprintf(w, "  go:\t%s\n", getGolangValue(c.Value))
+		fmt.Fprintf(w, "  ts:\t%s\n", getTypescriptValue(c.Value))
+		return true
+	}
+
+	return false
+}
+
+func explainEnum(w io.Writer, doc *ast.Document, name string) bool {
+	for _, enum := range doc.Enums {
+		if enum.Name.Token.Value != name {
+			continue
+		}
+
+		jsonNumber := isEnumJsonNumber(enum)
+
+		fmt.Fprintf(w, "enum %s\n", name)
+		fmt.Fprintf(w, "  go:\t%s\n", getGolangEnumType(enum))
+		fmt.Fprintf(w, "  size:\t%d bits\n", enum.Size)
+		if jsonNumber {
+			fmt.Fprintf(w, "  json:\tnumber\n")
+		} else {
+			fmt.Fprintf(w, "  json:\tsnake case name\n")
+		}
+
+		fmt.Fprintf(w, "  values:\n")
+		for _, set := range enum.Sets {
+			if set.Name.Token.Value == "_" {
+				fmt.Fprintf(w, "    _\t%d\tnot encoded\n", set.Value.Value)
+				continue
+			}
+
+			encoded := `"` + strcase.ToSnake(set.Name.Token.Value) + `"`
+			if jsonNumber {
+				encoded = fmt.Sprint(set.Value.Value)
+			}
+			fmt.Fprintf(w, "    %s\t%d\t%s\n", set.Name.Token.Value, set.Value.Value, encoded)
+		}
+
+		explainUsedBy(w, doc, name)
+		return true
+	}
+
+	return false
+}
+
+func explainModel(w io.Writer, doc *ast.Document, name string, isModelType func(string) bool) bool {
+	for _, model := range doc.Models {
+		if model.Name.Token.Value != name {
+			continue
+		}
+
+		fmt.Fprintf(w, "model %s\n", name)
+
+		fmt.Fprintf(w, "  fields:\n")
+		for _, field := range model.Fields {
+			fieldName := field.Name.Token.Value
+			if field.IsOptional {
+				fieldName += "?"
+			}
+			explainType(w, "    "+fieldName, field.Type, isModelType)
+			if lifecycle := getLifecycleLabel(field.Options); lifecycle != "" {
+				fmt.Fprintf(w, "      %s\n", lifecycle)
+			}
+		}
+
+		for _, oneOf := range model.OneOfs {
+			fmt.Fprintf(w, "  oneof %s:\n", oneOf.Name.Token.Value)
+			for _, variant := range oneOf.Variants {
+				explainType(w, "    "+variant.Name.Token.Value, variant.Type, isModelType)
+			}
+		}
+
+		for _, requires := range model.Requires {
+			fields := make([]string, 0, len(requires.Fields))
+			for _, field := range requires.Fields {
+				fields = append(fields, field.Token.Value)
+			}
+			fmt.Fprintf(w, "  requires:\t%s when %s is set\n", strings.Join(fields, ", "), requires.When.Token.Value)
+		}
+
+		explainUsedBy(w, doc, name)
+		return true
+	}
+
+	return false
+}
+
+func explainService(w io.Writer, doc *ast.Document, name string, isModelType func(string) bool) bool {
+	for _, service := range doc.Services {
+		if service.Name.Token.Value != name {
+			continue
+		}
+
+		serviceType := "rpc"
+		if service.Type == ast.ServiceHTTP {
+			serviceType = "http"
+		}
+
+		fmt.Fprintf(w, "service %s (%s)\n", name, serviceType)
+
+		for _, method := range service.Methods {
+			fmt.Fprintf(w, "  %s\n", method.Name.Token.Value)
+
+			for _, arg := range method.Args {
+				label := "    arg " + arg.Name.Token.Value
+				if arg.Stream {
+					label += " stream"
+				}
+				explainType(w, label, arg.Type, isModelType)
+			}
+
+			for _, ret := range method.Returns {
+				label := "    return " + ret.Name.Token.Value
+				if ret.Stream {
+					label += " stream"
+				}
+				explainType(w, label, ret.Type, isModelType)
+			}
+
+			for _, opt := range method.Options.List {
+				fmt.Fprintf(w, "    option %s\t= %s\n", opt.Name.Token.Value, formatExpr(opt.Value))
+			}
+		}
+
+		return true
+	}
+
+	return false
+}
+
+func explainError(w io.Writer, doc *ast.Document, name string) bool {
+	for _, customErr := range doc.Errors {
+		if customErr.Name.Token.Value != name {
+			continue
+		}
+
+		fmt.Fprintf(w, "error %s\n", name)
+		fmt.Fprintf(w, "  code:\t%d\n", customErr.Code)
+		if status := getGolangHttpStatus(customErr); status != "" {
+			fmt.Fprintf(w, "  http status:\t%s\n", status)
+		}
+		fmt.Fprintf(w, "  message:\t%s\n", strings.TrimSpace(customErr.Msg.Value))
+		return true
+	}
+
+	return false
+}
+
+// getLifecycleLabel returns the versions of the Since and Until options,
+// e.g. since 1.2.0, until 2.0.0, empty if they are not set
+func getLifecycleLabel(options *ast.Options) string {
+	since, until := options.Lifecycle()
+
+	var parts []string
+	if since != nil {
+		parts = append(parts, "since "+since.String())
+	}
+	if until != nil {
+		parts = append(parts, "until "+until.String())
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// explainType writes the hexe type along with its generated Go and Typescript types
+func explainType(w io.Writer, label string, typ ast.Type, isModelType func(string) bool) {
+	fmt.Fprintf(w, "%s:\t%s\tgo: %s\tts: %s\n", label, formatExpr(typ), getGolangType(typ, isModelType), getTypescriptType(typ))
+}
+
+// explainUsedBy writes the models' fields and variants, and the services' arguments
+// and returns which use the name as their type, including the nested types
+func explainUsedBy(w io.Writer, doc *ast.Document, name string) {
+	var usages []string
+
+	uses := func(typ ast.Type) bool {
+		found := false
+		walkType(typ, func(typ ast.Type) {
+			if custom, ok := typ.(*ast.CustomType); ok && custom.Token.Value == name {
+				found = true
+			}
+		})
+		return found
+	}
+
+	for _, model := range doc.Models {
+		for _, field := range model.Fields {
+			if uses(field.Type) {
+				usages = append(usages, fmt.Sprintf("model %s field %s", model.Name.Token.Value, field.Name.Token.Value))
+			}
+		}
+
+		for _, oneOf := range model.OneOfs {
+			for _, variant := range oneOf.Variants {
+				if uses(variant.Type) {
+					usages = append(usages, fmt.Sprintf("model %s oneof %s variant %s", model.Name.Token.Value, oneOf.Name.Token.Value, variant.Name.Token.Value))
+				}
+			}
+		}
+	}
+
+	for _, service := range doc.Services {
+		for _, method := range service.Methods {
+			for _, arg := range method.Args {
+				if uses(arg.Type) {
+					usages = append(usages, fmt.Sprintf("service %s method %s arg %s", service.Name.Token.Value, method.Name.Token.Value, arg.Name.Token.Value))
+				}
+			}
+
+			for _, ret := range method.Returns {
+				if uses(ret.Type) {
+					usages = append(usages, fmt.Sprintf("service %s method %s return %s", service.Name.Token.Value, method.Name.Token.Value, ret.Name.Token.Value))
+				}
+			}
+		}
+	}
+
+	if len(usages) == 0 {
+		fmt.Fprintf(w, "  used by:\tnone\n")
+		return
+	}
+
+	fmt.Fprintf(w, "  used by:\n")
+	for _, usage := range usages {
+		fmt.Fprintf(w, "    %s\n", usage)
+	}
+}
+
+func formatExpr(expr interface{ Format(*strings.Builder) }) string {
+	var sb strings.Builder
+	expr.Format(&sb)
+	return sb.String()
+}
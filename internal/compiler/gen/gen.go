@@ -1,53 +1,366 @@
 package gen
 
 import (
+	"bufio"
+	"cmp"
 	"fmt"
 	"html/template"
+	"io"
+	"os"
 	"reflect"
+	"slices"
 	"strings"
 
 	"github.com/hexe-dev/hexe/internal/compiler/ast"
 	"github.com/hexe-dev/hexe/internal/strcase"
 )
 
-func Generate(pkg, output string, docs []*ast.Document) error {
-	mainDoc := &ast.Document{
-		Consts:   make([]*ast.Const, 0),
-		Enums:    make([]*ast.Enum, 0),
-		Models:   make([]*ast.Model, 0),
-		Services: make([]*ast.Service, 0),
-		Errors:   make([]*ast.CustomError, 0),
+// Target is the language of the generated code
+type Target int
+
+const (
+	_ Target = iota
+	TargetGo
+	TargetTypescript
+	TargetZod
+	TargetJson // only constants
+	TargetEnv  // only constants
+	TargetOpenAPI
+	TargetProto
+	TargetJsonSchema
+	TargetAst
+	TargetGoCli // a main package of the go code with a command line client of http services
+)
+
+func (t Target) String() string {
+	switch t {
+	case TargetGo:
+		return "go"
+	case TargetTypescript:
+		return "typescript"
+	case TargetZod:
+		return "zod"
+	case TargetJson:
+		return "json"
+	case TargetEnv:
+		return "env"
+	case TargetOpenAPI:
+		return "openapi"
+	case TargetProto:
+		return "proto"
+	case TargetJsonSchema:
+		return "jsonschema"
+	case TargetAst:
+		return "ast"
+	case TargetGoCli:
+		return "cli"
+	default:
+		return fmt.Sprintf("Target(%d)", int(t))
 	}
+}
 
-	for _, doc := range docs {
-		for _, c := range doc.Consts {
-			mainDoc.Consts = append(mainDoc.Consts, c)
+// TargetFromFilename returns the target based on the extension of the output file,
+// .go, .cli.go, .ts, .zod.ts, .openapi.json, .schema.json, .ast.json, .json, .env and .proto are supported
+func TargetFromFilename(filename string) (Target, error) {
+	switch {
+	case strings.HasSuffix(filename, ".ast.json"):
+		return TargetAst, nil
+	case strings.HasSuffix(filename, ".openapi.json"):
+		return TargetOpenAPI, nil
+	case strings.HasSuffix(filename, ".schema.json"):
+		return TargetJsonSchema, nil
+	case strings.HasSuffix(filename, ".json"):
+		return TargetJson, nil
+	case strings.HasSuffix(filename, ".env"):
+		return TargetEnv, nil
+	case strings.HasSuffix(filename, ".cli.go"):
+		return TargetGoCli, nil
+	case strings.HasSuffix(filename, ".go"):
+		return TargetGo, nil
+	case strings.HasSuffix(filename, ".zod.ts"):
+		return TargetZod, nil
+	case strings.HasSuffix(filename, ".ts"):
+		return TargetTypescript, nil
+	case strings.HasSuffix(filename, ".proto"):
+		return TargetProto, nil
+	default:
+		return 0, fmt.Errorf("unknown output file type: %s", filename)
+	}
+}
+
+// Option changes the generated code of the targets which support it
+type Option func(*options)
+
+type options struct {
+	tsConstEnums bool
+	goTypedUnits bool
+	goConfig     bool
+	goPresence   bool
+	goCli        bool         // set by TargetGoCli, the go code is a main package with a cli
+	goVersion    int          // minor version of the targeted go1.x, zero is the latest
+	plugin       string       // path of the plugin executable, see WithPlugin
+	warnings     io.Writer    // optional, see WithWarnings
+	apiVersion   *ast.Version // optional, see WithApiVersion
+}
+
+// WithTsConstEnums generates the Typescript enums as const objects with a union type of
+// their values, for the environments which don't allow enums, e.g. erasableSyntaxOnly
+func WithTsConstEnums() Option {
+	return func(o *options) {
+		o.tsConstEnums = true
+	}
+}
+
+// WithGoTypedUnits generates the byte size and duration constants in Go as ByteSize
+// and Duration types, their String method returns the value with its unit, e.g. 10mb
+func WithGoTypedUnits() Option {
+	return func(o *options) {
+		o.goTypedUnits = true
+	}
+}
+
+// WithGoConfig generates a Config struct of the Go constants, and a LoadConfig function
+// which overrides them by the environment variables, named the same as the .env output
+func WithGoConfig() Option {
+	return func(o *options) {
+		o.goConfig = true
+	}
+}
+
+// WithGoPresence tracks the optional fields of the Go models by a present bitset, with
+// HasX and SetX methods, so a zero value which is set, or decoded, is still encoded in json.
+// The model typed fields are values instead of pointers, except the recursive ones
+func WithGoPresence() Option {
+	return func(o *options) {
+		o.goPresence = true
+	}
+}
+
+// WithPlugin generates the code by the plugin executable instead of the built-in
+// targets, the output is a directory which the plugin's files are written into
+func WithPlugin(path string) Option {
+	return func(o *options) {
+		o.plugin = path
+	}
+}
+
+// WithWarnings writes the warnings of the generation into w, e.g. the declarations
+// which are skipped by the target, they don't stop the code from being generated
+func WithWarnings(w io.Writer) Option {
+	return func(o *options) {
+		o.warnings = w
+	}
+}
+
+// WithApiVersion generates the api as it is at version, the fields and methods which
+// are added after it, by the Since option, or removed at or before it, by the Until
+// option, are excluded
+func WithApiVersion(version ast.Version) Option {
+	return func(o *options) {
+		o.apiVersion = &version
+	}
+}
+
+// WithGoVersion targets an older Go version, minor is the minor version of go1.x, e.g. 22
+// for go1.22, so the generated code doesn't use the newer features, e.g. omitzero tag
+// which is added in go1.24. The generated code requires at least go1.21
+func WithGoVersion(minor int) Option {
+	return func(o *options) {
+		o.goVersion = minor
+	}
+}
+
+// warn writes the warning if WithWarnings is set
+func (o *options) warn(format string, args ...any) {
+	if o.warnings == nil {
+		return
+	}
+
+	fmt.Fprintf(o.warnings, "Warning: "+format+"\n", args...)
+}
+
+// goOmitZero reports whether the targeted go version supports omitzero json tag
+func (o *options) goOmitZero() bool {
+	return o.goVersion == 0 || o.goVersion >= 24
+}
+
+// goIter reports whether the targeted go version supports range over func iterators
+func (o *options) goIter() bool {
+	return o.goVersion == 0 || o.goVersion >= 23
+}
+
+// Generate generates the code for docs into the output file, the target is selected
+// based on the output file's extension, or by WithPlugin, which output is a directory
+func Generate(pkg, output string, docs []*ast.Document, opts ...Option) (err error) {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if o.plugin != "" {
+		mainDoc := mergeDocuments(docs)
+		if o.apiVersion != nil {
+			mainDoc = filterApiVersion(mainDoc, *o.apiVersion)
 		}
 
-		for _, e := range doc.Enums {
-			mainDoc.Enums = append(mainDoc.Enums, e)
+		return generatePlugin(o.plugin, pkg, output, mainDoc)
+	}
+
+	target, err := TargetFromFilename(output)
+	if err != nil {
+		return err
+	}
+
+	out, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if closeErr := out.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	// templates write many small chunks, buffering them
+	// prevents a syscall per chunk for large schemas
+	w := bufio.NewWriter(out)
+
+	if err = GenerateTo(w, target, pkg, docs, opts...); err != nil {
+		return err
+	}
+
+	return w.Flush()
+}
+
+// GenerateTo generates the code for docs into w, docs are expected to be validated
+func GenerateTo(w io.Writer, target Target, pkg string, docs []*ast.Document, opts ...Option) error {
+	mainDoc := mergeDocuments(docs)
+
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if o.plugin != "" {
+		return fmt.Errorf("plugin writes its files into a directory, it can't write into a single output")
+	}
+
+	if o.apiVersion != nil {
+		mainDoc = filterApiVersion(mainDoc, *o.apiVersion)
+	}
+
+	switch target {
+	case TargetGo:
+		return generateGo(w, pkg, mainDoc, &o)
+	case TargetGoCli:
+		// the cli is only runnable as a main package, so pkg is ignored
+		o.goCli = true
+		return generateGo(w, "main", mainDoc, &o)
+	case TargetTypescript:
+		return generateTypescript(w, pkg, "main", mainDoc, &o)
+	case TargetZod:
+		return generateTypescript(w, pkg, "zod", mainDoc, &o)
+	case TargetJson, TargetEnv:
+		return generateConstants(w, target, mainDoc)
+	case TargetOpenAPI:
+		return generateOpenAPI(w, pkg, mainDoc)
+	case TargetProto:
+		return generateProto(w, pkg, mainDoc)
+	case TargetJsonSchema:
+		return generateJsonSchema(w, pkg, mainDoc)
+	case TargetAst:
+		return generateAst(w, pkg, mainDoc)
+	default:
+		return fmt.Errorf("unknown target: %s", target)
+	}
+}
+
+// mergeDocuments returns a document which holds the declarations of all the docs
+func mergeDocuments(docs []*ast.Document) *ast.Document {
+	mainDoc := &ast.Document{}
+
+	for _, doc := range docs {
+		mainDoc.Consts = append(mainDoc.Consts, doc.Consts...)
+		mainDoc.Enums = append(mainDoc.Enums, doc.Enums...)
+		mainDoc.Models = append(mainDoc.Models, doc.Models...)
+		mainDoc.Services = append(mainDoc.Services, doc.Services...)
+		mainDoc.Errors = append(mainDoc.Errors, doc.Errors...)
+	}
+
+	return mainDoc
+}
+
+// filterApiVersion returns the document without the fields and methods which don't
+// exist at version, the filtered models and services are copied, so docs are intact
+func filterApiVersion(doc *ast.Document, version ast.Version) *ast.Document {
+	filtered := *doc
+
+	filtered.Models = mapperFunc(doc.Models, func(model *ast.Model) *ast.Model {
+		fields := filterFunc(model.Fields, func(field *ast.Field) bool {
+			return inApiVersion(field.Options, version)
+		})
+		if len(fields) == len(model.Fields) {
+			return model
 		}
 
-		for _, m := range doc.Models {
-			mainDoc.Models = append(mainDoc.Models, m)
+		exists := make(map[string]struct{}, len(fields))
+		for _, field := range fields {
+			exists[field.Name.Token.Value] = struct{}{}
 		}
 
-		for _, s := range doc.Services {
-			mainDoc.Services = append(mainDoc.Services, s)
+		copied := *model
+		copied.Fields = fields
+		// the requires constraints can only refer to the remaining fields
+		copied.Requires = nil
+		for _, requires := range model.Requires {
+			if _, ok := exists[requires.When.Token.Value]; !ok {
+				continue
+			}
+
+			requiresFields := filterFunc(requires.Fields, func(field *ast.Identifier) bool {
+				_, ok := exists[field.Token.Value]
+				return ok
+			})
+			if len(requiresFields) == 0 {
+				continue
+			}
+
+			copiedRequires := *requires
+			copiedRequires.Fields = requiresFields
+			copied.Requires = append(copied.Requires, &copiedRequires)
 		}
 
-		for _, e := range doc.Errors {
-			mainDoc.Errors = append(mainDoc.Errors, e)
+		return &copied
+	})
+
+	filtered.Services = mapperFunc(doc.Services, func(service *ast.Service) *ast.Service {
+		methods := filterFunc(service.Methods, func(method *ast.Method) bool {
+			return inApiVersion(method.Options, version)
+		})
+		if len(methods) == len(service.Methods) {
+			return service
 		}
-	}
 
-	if strings.HasSuffix(output, ".go") {
-		return generateGo(pkg, output, mainDoc)
-	} else if strings.HasSuffix(output, ".ts") {
-		return generateTypescript(pkg, output, mainDoc)
-	}
+		copied := *service
+		copied.Methods = methods
+		return &copied
+	})
 
-	return fmt.Errorf("unknown output file type: %s", output)
+	return &filtered
+}
+
+// inApiVersion reports whether a field or method exists at version, based on its
+// Since and Until options, Until is the version which removes it
+func inApiVersion(options *ast.Options, version ast.Version) bool {
+	since, until := options.Lifecycle()
+	if since != nil && since.Compare(version) > 0 {
+		return false
+	}
+	if until != nil && until.Compare(version) <= 0 {
+		return false
+	}
+	return true
 }
 
 var defaultFuncsMap = template.FuncMap{
@@ -85,6 +398,23 @@ func getServicesByType(services []*ast.Service, typ ast.ServiceType) []*ast.Serv
 	})
 }
 
+// isBidirectionalStream reports if the method's stream argument is messages, other than []byte,
+// which are sent while its stream return is received, validation makes sure it has a stream return
+func isBidirectionalStream(method *ast.Method) bool {
+	for _, arg := range method.Args {
+		if !arg.Stream {
+			continue
+		}
+		if a, ok := arg.Type.(*ast.Array); ok {
+			if _, ok := a.Type.(*ast.Byte); ok {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
 func mapperFunc[I, O any](list []I, f func(I) O) []O {
 	var results []O
 
@@ -119,6 +449,124 @@ func createIsModelTypeFunc(models []*ast.Model) func(value string) bool {
 	}
 }
 
+func createIsEnumTypeFunc(enums []*ast.Enum) func(value string) bool {
+	set := make(map[string]struct{})
+	for _, enum := range enums {
+		set[enum.Name.Token.Value] = struct{}{}
+	}
+
+	return func(value string) bool {
+		_, ok := set[value]
+		return ok
+	}
+}
+
+// getCommentLines returns the text of the comments at the given position, each
+// comment is a single line in the schema
+func getCommentLines(comments []*ast.Comment, position ast.CommentPosition) []string {
+	lines := make([]string, 0)
+	for _, comment := range comments {
+		if comment.Position != position {
+			continue
+		}
+		lines = append(lines, strings.TrimSpace(comment.Token.Value))
+	}
+	return lines
+}
+
+// getEnumSetLabel returns the value of set's Label option,
+// or the set's name if the label is not defined
+func getEnumSetLabel(set *ast.EnumSet) string {
+	if set.Options != nil {
+		for _, opt := range set.Options.List {
+			if v, ok := opt.Value.(*ast.ValueString); ok && opt.Name.Token.Value == "Label" {
+				return v.Value
+			}
+		}
+	}
+
+	return set.Name.Token.Value
+}
+
+func hasEnumLabels(enum *ast.Enum) bool {
+	for _, set := range enum.Sets {
+		if set.Name.Token.Value == "_" || set.Options == nil {
+			continue
+		}
+
+		for _, opt := range set.Options.List {
+			if opt.Name.Token.Value == "Label" {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// isEnumJsonNumber reports whether the enum is encoded as its number in json,
+// by default enums are encoded as the snake case name of their keys
+func isEnumJsonNumber(enum *ast.Enum) bool {
+	if enum.Options == nil {
+		return false
+	}
+
+	for _, opt := range enum.Options.List {
+		if v, ok := opt.Value.(*ast.ValueBool); ok && opt.Name.Token.Value == "JsonNumber" {
+			return v.Value
+		}
+	}
+
+	return false
+}
+
+// walkTypes calls fn for every type, including the nested ones,
+// used in models' fields and services' arguments and returns
+func walkTypes(doc *ast.Document, fn func(ast.Type)) {
+	walk := func(typ ast.Type) {
+		walkType(typ, fn)
+	}
+
+	for _, model := range doc.Models {
+		for _, field := range model.Fields {
+			walk(field.Type)
+		}
+
+		for _, oneOf := range model.OneOfs {
+			for _, variant := range oneOf.Variants {
+				walk(variant.Type)
+			}
+		}
+	}
+
+	for _, service := range doc.Services {
+		for _, method := range service.Methods {
+			for _, arg := range method.Args {
+				walk(arg.Type)
+			}
+
+			for _, ret := range method.Returns {
+				walk(ret.Type)
+			}
+		}
+	}
+}
+
+// walkType calls fn for the type and its nested types, e.g. map's key and value
+func walkType(typ ast.Type, fn func(ast.Type)) {
+	fn(typ)
+
+	switch t := typ.(type) {
+	case *ast.Map:
+		walkType(t.Key, fn)
+		walkType(t.Value, fn)
+	case *ast.Array:
+		walkType(t.Type, fn)
+	case *ast.Set:
+		walkType(t.Type, fn)
+	}
+}
+
 type set[T comparable] map[T]struct{}
 
 func (s set[T]) add(value T) {
@@ -128,3 +576,16 @@ func (s set[T]) add(value T) {
 func newSet[T comparable]() set[T] {
 	return make(map[T]struct{})
 }
+
+// sortedKeys returns the keys of the map in ascending order, it should be used
+// whenever a map needs to be iterated to keep the generated output stable
+func sortedKeys[K cmp.Ordered, V any](m map[K]V) []K {
+	keys := make([]K, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	slices.Sort(keys)
+
+	return keys
+}
@@ -0,0 +1,1178 @@
+package gen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode"
+
+	"github.com/hexe-dev/hexe/internal/compiler/ast"
+	"github.com/hexe-dev/hexe/internal/compiler/parser"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	// the test binary is the plugin of TestGeneratePlugin, so no other executable is needed
+	if mode := os.Getenv("HEXE_TEST_PLUGIN"); mode != "" {
+		runTestPlugin(mode)
+		return
+	}
+
+	os.Exit(m.Run())
+}
+
+// runTestPlugin echoes the request back as echo.json and writes the models' names into
+// models/names.txt, mode selects the failures, i.e. error, exit and outside
+func runTestPlugin(mode string) {
+	input, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		os.Exit(1)
+	}
+
+	var req pluginRequest
+	if err = json.Unmarshal(input, &req); err != nil {
+		os.Exit(1)
+	}
+
+	names := mapperFunc(req.Schema.Models, func(model *astJsonModel) string {
+		return model.Name
+	})
+
+	resp := pluginResponse{
+		Files: []pluginFile{
+			{Name: "echo.json", Content: string(input)},
+			{Name: "models/names.txt", Content: strings.Join(names, "\n")},
+		},
+	}
+
+	switch mode {
+	case "error":
+		resp.Error = "unsupported schema"
+	case "exit":
+		fmt.Fprintln(os.Stderr, "something went wrong")
+		os.Exit(2)
+	case "outside":
+		resp.Files = append(resp.Files, pluginFile{Name: "../outside.txt"})
+	}
+
+	if err = json.NewEncoder(os.Stdout).Encode(resp); err != nil {
+		os.Exit(1)
+	}
+}
+
+// generateOutput parses and validates the input and returns the generated
+// code for the given output extension, e.g. ".go" or ".ts"
+func generateOutput(t *testing.T, ext string, input string, opts ...Option) string {
+	t.Helper()
+
+	doc, err := parser.ParseDocument(parser.NewParser(input))
+	require.NoError(t, err)
+	require.NoError(t, parser.Validate(doc))
+
+	output := filepath.Join(t.TempDir(), "output"+ext)
+	require.NoError(t, Generate("test", output, []*ast.Document{doc}, opts...))
+
+	result, err := os.ReadFile(output)
+	require.NoError(t, err)
+
+	return string(result)
+}
+
+func TestGenerateDeterministic(t *testing.T) {
+	const input = `
+enum Role {
+	Admin
+	Member
+}
+
+model User {
+	Id: string
+	Role: Role
+	Tags: set<string>
+	Meta: map<string, string>
+}
+
+service HttpUserService {
+	Get(id: string) => (user: User)
+	List() => (users: []User, total: int64)
+	Stats() => (a: int64, b: int64, c: int64)
+	Upload(files: stream []byte) => (ids: []string)
+	Import(files: stream []byte) => (ids: []string, total: int64)
+	Ping()
+}
+
+service RpcUserService {
+	Count() => (total: int64, active: int64, deleted: int64, banned: int64)
+}
+`
+
+	for _, ext := range []string{".go", ".ts", ".zod.ts"} {
+		first := generateOutput(t, ext, input)
+		for range 10 {
+			require.Equal(t, first, generateOutput(t, ext, input), "output of %s is not stable", ext)
+		}
+	}
+}
+
+func TestGenerateTo(t *testing.T) {
+	const input = `
+model User {
+	Id: string
+}
+
+service HttpUserService {
+	Get(id: string) => (user: User)
+}
+`
+
+	doc, err := parser.ParseDocument(parser.NewParser(input))
+	require.NoError(t, err)
+	require.NoError(t, parser.Validate(doc))
+
+	testCases := []struct {
+		target   Target
+		ext      string
+		contains string
+	}{
+		{target: TargetGo, ext: ".go", contains: "package test"},
+		{target: TargetTypescript, ext: ".ts", contains: "export interface User"},
+		{target: TargetZod, ext: ".zod.ts", contains: "export const UserSchema = z.object({"},
+		{target: TargetJson, ext: ".json", contains: "{}"},
+		{target: TargetEnv, ext: ".env", contains: ""},
+		{target: TargetOpenAPI, ext: ".openapi.json", contains: `"openapi": "3.0.3"`},
+		{target: TargetProto, ext: ".proto", contains: `syntax = "proto3";`},
+		{target: TargetJsonSchema, ext: ".schema.json", contains: `"$schema": "https://json-schema.org/draft/2020-12/schema"`},
+		{target: TargetAst, ext: ".ast.json", contains: `"package": "test"`},
+		{target: TargetGoCli, ext: ".cli.go", contains: "package main"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.target.String(), func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, GenerateTo(&buf, tc.target, "test", []*ast.Document{doc}))
+			require.Contains(t, buf.String(), tc.contains)
+
+			// file based generation should produce the same output
+			require.Equal(t, generateOutput(t, tc.ext, input), buf.String())
+
+			target, err := TargetFromFilename("output" + tc.ext)
+			require.NoError(t, err)
+			require.Equal(t, tc.target, target)
+		})
+	}
+
+	require.Error(t, GenerateTo(&bytes.Buffer{}, Target(0), "test", []*ast.Document{doc}))
+
+	_, err = TargetFromFilename("output.rs")
+	require.Error(t, err)
+}
+
+func TestGenerateConstants(t *testing.T) {
+	const input = `
+const MaxUpload = 10mb
+const Timeout = 2s
+const Name = "hexe"
+const Quote = "it's"
+const Ratio = 1.5
+const Enabled = true
+const Count = 1_000
+const Upload = MaxUpload
+const DefaultUser: User = { Id = "1" }
+
+model User {
+	Id: string
+}
+`
+
+	require.Equal(t, `{
+  "MaxUpload": 10485760,
+  "Timeout": 2000000000,
+  "Name": "hexe",
+  "Quote": "it's",
+  "Ratio": 1.5,
+  "Enabled": true,
+  "Count": 1000,
+  "Upload": 10485760
+}
+`, generateOutput(t, ".json", input))
+
+	require.Equal(t, `MAX_UPLOAD=10485760
+TIMEOUT=2000000000
+NAME='hexe'
+QUOTE='it'\''s'
+RATIO=1.5
+ENABLED=true
+COUNT=1000
+UPLOAD=10485760
+`, generateOutput(t, ".env", input))
+}
+
+func TestGenerateEnumJsonNames(t *testing.T) {
+	const input = `
+enum Emotion {
+	_
+	Sad
+	VeryHappy
+}
+
+enum Level {
+	Low = 1
+	High
+} { JsonNumber = true }
+`
+
+	goOutput := generateOutput(t, ".go", input)
+	tsOutput := generateOutput(t, ".ts", input)
+	zodOutput := generateOutput(t, ".zod.ts", input)
+
+	// go encodes the same snake case names which typescript uses as the enum's values
+	for name, value := range map[string]string{"Sad": "sad", "VeryHappy": "very_happy"} {
+		require.Contains(t, goOutput, "case Emotion_"+name+":\n\t\treturn []byte(\""+value+"\"), nil")
+		require.Contains(t, goOutput, "case \""+value+"\":\n\t\t*e = Emotion_"+name)
+		require.Contains(t, tsOutput, "    "+name+" = \""+value+"\",")
+		require.Contains(t, zodOutput, "\""+value+"\"")
+	}
+	require.Contains(t, goOutput, "func (e Emotion) MarshalJSON() ([]byte, error) {\n\ttext, err := e.MarshalText()")
+
+	// JsonNumber keeps the numbers on both sides
+	require.Contains(t, goOutput, "func (e Level) MarshalJSON() ([]byte, error) {\n\treturn json.Marshal(int8(e))")
+	require.Contains(t, tsOutput, "    Low = 1,\n    High = 2,")
+	require.Contains(t, zodOutput, "export const LevelSchema = z.union([z.literal(1), z.literal(2)]);")
+}
+
+func TestGenerateOpenAPI(t *testing.T) {
+	const input = `
+const Version = "2.1.0"
+
+enum Role {
+	_
+	# can manage the other users
+	Admin
+	Member
+}
+
+enum Level {
+	Low = 1
+	High = 2
+}
+
+# user of the system
+model User {
+	Id: string
+	Role?: Role
+	Tags: []string
+	Internal: string { Json = false }
+}
+
+service HttpUserService {
+	# returns the user by its id
+	GetById(id: string) => (user: User) {
+		HttpMethod = "GET"
+	}
+	Watch() => (users: stream User) {
+		RateLimit = "10/m"
+	}
+	Download(id: string) => (file: stream []byte)
+	Upload(id: string, files: stream []byte) => (count: int64) {
+		MaxSize = 1mb
+	}
+}
+
+service RpcUserService {
+	Ping()
+}
+
+error ErrNotFound { HttpStatus = NotFound Msg = "user not found" }
+error ErrUnknown { Msg = "unknown" }
+`
+
+	var spec struct {
+		Info struct {
+			Version string `json:"version"`
+		} `json:"info"`
+		Paths      map[string]map[string]map[string]any `json:"paths"`
+		Components struct {
+			Schemas map[string]map[string]any `json:"schemas"`
+		} `json:"components"`
+	}
+
+	output := generateOutput(t, ".openapi.json", input)
+	require.NoError(t, json.Unmarshal([]byte(output), &spec))
+
+	require.Equal(t, "2.1.0", spec.Info.Version)
+	require.ElementsMatch(t, []string{
+		"/HttpUserService.GetById",
+		"/HttpUserService.Watch",
+		"/HttpUserService.Download",
+		"/HttpUserService.Upload",
+	}, slices.Collect(maps.Keys(spec.Paths)))
+
+	getById := spec.Paths["/HttpUserService.GetById"]["get"]
+	require.Equal(t, "returns the user by its id", getById["description"])
+	require.Contains(t, output, `"$ref": "#/components/schemas/User"`)
+
+	responses := getById["responses"].(map[string]any)
+	require.Contains(t, responses, "200")
+	require.Equal(t, "ErrNotFound (code 1): user not found", responses["404"].(map[string]any)["description"])
+	require.Equal(t, "the errors without HttpStatus\nErrUnknown (code 2): unknown", responses["417"].(map[string]any)["description"])
+
+	require.Contains(t, spec.Paths["/HttpUserService.Watch"]["post"]["responses"].(map[string]any)["200"].(map[string]any)["content"], "text/event-stream")
+	require.Contains(t, spec.Paths["/HttpUserService.Download"]["post"]["responses"].(map[string]any)["200"].(map[string]any)["content"], "application/octet-stream")
+	require.Contains(t, spec.Paths["/HttpUserService.Upload"]["post"]["requestBody"].(map[string]any)["content"], "multipart/form-data")
+	require.Contains(t, spec.Paths["/HttpUserService.Upload"]["post"]["responses"], "413")
+	require.Equal(t, "ErrTooManyRequests (code -4): too many requests", spec.Paths["/HttpUserService.Watch"]["post"]["responses"].(map[string]any)["429"].(map[string]any)["description"])
+	require.NotContains(t, spec.Paths["/HttpUserService.Upload"]["post"]["responses"], "429")
+
+	user := spec.Components.Schemas["User"]
+	require.Equal(t, "user of the system", user["description"])
+	require.Equal(t, []any{"id", "tags"}, user["required"])
+	require.NotContains(t, user["properties"], "internal")
+	require.Equal(t, map[string]any{"type": "array", "items": map[string]any{"type": "string"}, "nullable": true}, user["properties"].(map[string]any)["tags"])
+
+	require.Equal(t, map[string]any{
+		"type":                "string",
+		"enum":                []any{"admin", "member"},
+		"x-enum-descriptions": []any{"can manage the other users", ""},
+	}, spec.Components.Schemas["Role"])
+	require.NotContains(t, spec.Components.Schemas["Level"], "x-enum-descriptions")
+	require.Contains(t, spec.Components.Schemas, "Error")
+}
+
+func TestGenerateApiVersion(t *testing.T) {
+	const input = `
+model User {
+	Id: string
+	Name: string { Until = "2.0.0" }
+	FullName?: string { Since = "1.2.0" }
+	Title?: string
+	requires(Name, when: Title)
+}
+
+service HttpUserService {
+	GetById(id: string) => (user: User)
+	GetByName(name: string) => (user: User) { Since = "1.0.0" Until = "1.5.0" }
+}
+`
+
+	output := generateOutput(t, ".go", input)
+	require.Contains(t, output, "\tName     string")
+	require.Contains(t, output, "GetByName(")
+
+	output = generateOutput(t, ".go", input, WithApiVersion(ast.Version{Major: 1, Minor: 2}))
+	require.Contains(t, output, "\tName     string")
+	require.Contains(t, output, "\tFullName string")
+	require.Contains(t, output, "GetByName(")
+
+	// Name and GetByName are removed by 2.0.0, so is the requires of Name
+	output = generateOutput(t, ".go", input, WithApiVersion(ast.Version{Major: 2}))
+	require.NotContains(t, output, "\tName ")
+	require.NotContains(t, output, ".Name")
+	require.Contains(t, output, "\tFullName string")
+	require.NotContains(t, output, "GetByName(")
+
+	// FullName is not added yet in 1.1.0
+	output = generateOutput(t, ".ts", input, WithApiVersion(ast.Version{Major: 1, Minor: 1}))
+	require.NotContains(t, output, "fullName")
+	require.Contains(t, output, "getByName(")
+
+	var spec struct {
+		Paths      map[string]map[string]map[string]any `json:"paths"`
+		Components struct {
+			Schemas map[string]map[string]any `json:"schemas"`
+		} `json:"components"`
+	}
+
+	output = generateOutput(t, ".openapi.json", input)
+	require.NoError(t, json.Unmarshal([]byte(output), &spec))
+
+	getByName := spec.Paths["/HttpUserService.GetByName"]["post"]
+	require.Equal(t, "1.0.0", getByName["x-since"])
+	require.Equal(t, "1.5.0", getByName["x-until"])
+
+	properties := spec.Components.Schemas["User"]["properties"].(map[string]any)
+	require.Equal(t, "2.0.0", properties["name"].(map[string]any)["x-until"])
+	require.Equal(t, "1.2.0", properties["fullName"].(map[string]any)["x-since"])
+}
+
+func TestGenerateProto(t *testing.T) {
+	const input = `
+# role of the user
+enum Role {
+	_
+	Admin
+	Member
+}
+
+enum Level {
+	Low = 1
+	High = 2
+}
+
+model Base {
+	Id: string
+}
+
+# user of the system
+model User {
+	...Base
+	Name: string { Proto = 5 }
+	Age?: int32
+	Roles: []Role
+	Tags: set<string>
+	Meta: map<string, any>
+	Scores: map<Role, float64>
+	CreatedAt: timestamp
+	Level: Level
+	oneof Contact {
+		Email: string
+		Phone: uint64
+	}
+}
+
+service RpcUserService {
+	# returns the user by its id
+	GetById(id: string) => (user: User)
+	Ping()
+}
+
+service HttpUserService {
+	Get(id: string) => (user: User)
+}
+`
+
+	output := generateOutput(t, ".proto", input)
+	require.NoError(t, checkProtoSyntax(output))
+
+	require.Equal(t, `// generated by hexe compiler; DO NOT EDIT
+
+syntax = "proto3";
+
+package test;
+
+import "google/protobuf/struct.proto";
+import "google/protobuf/timestamp.proto";
+
+// role of the user
+enum Role {
+  ROLE_UNSPECIFIED = 0;
+  ROLE_ADMIN = 1;
+  ROLE_MEMBER = 2;
+}
+
+enum Level {
+  LEVEL_UNSPECIFIED = 0;
+  LEVEL_LOW = 1;
+  LEVEL_HIGH = 2;
+}
+
+message Base {
+  string id = 1;
+}
+
+// user of the system
+message User {
+  string id = 1;
+  string name = 5;
+  optional int32 age = 2;
+  repeated Role roles = 3;
+  repeated string tags = 4;
+  map<string, google.protobuf.Value> meta = 6;
+  map<int32, double> scores = 7;
+  google.protobuf.Timestamp created_at = 8;
+  Level level = 9;
+  oneof contact {
+    string email = 10;
+    uint64 phone = 11;
+  }
+}
+
+message RpcUserServiceGetByIdRequest {
+  string id = 1;
+}
+
+message RpcUserServiceGetByIdResponse {
+  User user = 1;
+}
+
+message RpcUserServicePingRequest {
+}
+
+message RpcUserServicePingResponse {
+}
+
+service RpcUserService {
+  // returns the user by its id
+  rpc GetById(RpcUserServiceGetByIdRequest) returns (RpcUserServiceGetByIdResponse);
+  rpc Ping(RpcUserServicePingRequest) returns (RpcUserServicePingResponse);
+}
+`, output)
+
+	t.Run("unsupported types", func(t *testing.T) {
+		testCases := []struct {
+			input string
+			error string
+		}{
+			{
+				input: `enum Big int64 { A = 1 }`,
+				error: "enum Big is 64 bits",
+			},
+			{
+				input: `model Matrix { Rows: [][]float64 }`,
+				error: "nested repeated and map fields",
+			},
+			{
+				input: `model Groups { Users: map<string, []string> }`,
+				error: "map's value can't be repeated or map",
+			},
+		}
+
+		for _, tc := range testCases {
+			doc, err := parser.ParseDocument(parser.NewParser(tc.input))
+			require.NoError(t, err)
+			require.NoError(t, parser.Validate(doc))
+
+			err = GenerateTo(&bytes.Buffer{}, TargetProto, "test", []*ast.Document{doc})
+			require.ErrorContains(t, err, tc.error)
+		}
+	})
+}
+
+// checkProtoSyntax parses the subset of proto3 used by the generated files and
+// checks the field numbers are unique and the referenced types are defined
+func checkProtoSyntax(src string) error {
+	var toks []string
+	for _, line := range strings.Split(src, "\n") {
+		line, _, _ = strings.Cut(line, "//")
+		for _, r := range "{}()<>;=," {
+			line = strings.ReplaceAll(line, string(r), " "+string(r)+" ")
+		}
+		toks = append(toks, strings.Fields(line)...)
+	}
+
+	pos := 0
+	next := func() string {
+		if pos >= len(toks) {
+			return ""
+		}
+		pos++
+		return toks[pos-1]
+	}
+	expect := func(want ...string) error {
+		for _, w := range want {
+			if got := next(); got != w && !(w == "<ident>" && isProtoIdent(got)) && !(w == "<number>" && isProtoNumber(got)) {
+				return fmt.Errorf("expected %s, got %q at token %d", w, got, pos)
+			}
+		}
+		return nil
+	}
+
+	if err := expect("syntax", "=", `"proto3"`, ";", "package", "<ident>", ";"); err != nil {
+		return err
+	}
+
+	for pos < len(toks) && toks[pos] == "import" {
+		pos++
+		if !strings.HasPrefix(next(), `"google/protobuf/`) {
+			return fmt.Errorf("unexpected import at token %d", pos)
+		}
+		if err := expect(";"); err != nil {
+			return err
+		}
+	}
+
+	defined := map[string]bool{"google.protobuf.Value": true, "google.protobuf.Timestamp": true}
+	var refs []string
+
+	for pos < len(toks) {
+		kind, name := next(), next()
+		if !isProtoIdent(name) || defined[name] {
+			return fmt.Errorf("invalid or duplicate %s name %q", kind, name)
+		}
+		defined[name] = true
+
+		if err := expect("{"); err != nil {
+			return err
+		}
+
+		numbers := make(map[string]bool)
+		first := true
+
+		for pos < len(toks) && toks[pos] != "}" {
+			switch kind {
+			case "enum":
+				if toks[pos] == "option" {
+					if err := expect("option", "allow_alias", "=", "true", ";"); err != nil {
+						return err
+					}
+					continue
+				}
+				if err := expect("<ident>", "="); err != nil {
+					return err
+				}
+				if value := next(); first && value != "0" {
+					return fmt.Errorf("enum %s's first value should be 0", name)
+				}
+				first = false
+				if err := expect(";"); err != nil {
+					return err
+				}
+			case "message":
+				oneOf := toks[pos] == "oneof"
+				if oneOf {
+					if err := expect("oneof", "<ident>", "{"); err != nil {
+						return err
+					}
+				}
+				for pos < len(toks) && toks[pos] != "}" {
+					if !oneOf && (toks[pos] == "repeated" || toks[pos] == "optional") {
+						pos++
+					}
+					if toks[pos] == "map" {
+						if err := expect("map", "<", "<ident>", ","); err != nil {
+							return err
+						}
+						refs = append(refs, next())
+						if err := expect(">"); err != nil {
+							return err
+						}
+					} else {
+						refs = append(refs, next())
+					}
+					if err := expect("<ident>", "="); err != nil {
+						return err
+					}
+					number := next()
+					if !isProtoNumber(number) || numbers[number] {
+						return fmt.Errorf("invalid or duplicate field number %s in message %s", number, name)
+					}
+					numbers[number] = true
+					if err := expect(";"); err != nil {
+						return err
+					}
+					if !oneOf {
+						break
+					}
+				}
+				if oneOf {
+					if err := expect("}"); err != nil {
+						return err
+					}
+				}
+			case "service":
+				if err := expect("rpc", "<ident>", "("); err != nil {
+					return err
+				}
+				refs = append(refs, next())
+				if err := expect(")", "returns", "("); err != nil {
+					return err
+				}
+				refs = append(refs, next())
+				if err := expect(")", ";"); err != nil {
+					return err
+				}
+			default:
+				return fmt.Errorf("unexpected %q at token %d", kind, pos)
+			}
+		}
+
+		if err := expect("}"); err != nil {
+			return err
+		}
+	}
+
+	scalars := []string{"bool", "int32", "int64", "uint32", "uint64", "float", "double", "string", "bytes"}
+	for _, ref := range refs {
+		if !defined[ref] && !slices.Contains(scalars, ref) {
+			return fmt.Errorf("type %s is not defined", ref)
+		}
+	}
+
+	return nil
+}
+
+func isProtoIdent(s string) bool {
+	if s == "" || !unicode.IsLetter(rune(s[0])) {
+		return false
+	}
+	return strings.IndexFunc(s, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '.' }) == -1
+}
+
+func isProtoNumber(s string) bool {
+	_, err := strconv.ParseUint(s, 10, 32)
+	return err == nil
+}
+
+func TestDiff(t *testing.T) {
+	parse := func(input string) []*ast.Document {
+		doc, err := parser.ParseDocument(parser.NewParser(input))
+		require.NoError(t, err)
+		require.NoError(t, parser.Validate(doc))
+		return []*ast.Document{doc}
+	}
+
+	const oldInput = `
+enum Role {
+	_
+	Admin
+	Member
+}
+
+model User {
+	Id: string
+	Name: string
+	Age: int32
+}
+
+service HttpUserService {
+	GetById(id: string) => (user: User)
+	Delete(id: string)
+}
+
+error ErrNotFound { Msg = "not found" }
+`
+
+	testCases := []struct {
+		name    string
+		input   string
+		changes []string
+	}{
+		{
+			name:  "same",
+			input: oldInput,
+		},
+		{
+			name: "removed field",
+			input: strings.Replace(oldInput, `
+	Name: string`, "", 1),
+			changes: []string{"breaking: model User field Name is removed"},
+		},
+		{
+			name: "added optional field",
+			input: strings.Replace(oldInput, `
+	Age: int32`, `
+	Age: int32
+	Nickname?: string`, 1),
+			changes: []string{"compatible: model User field Nickname is added"},
+		},
+		{
+			name: "added required field",
+			input: strings.Replace(oldInput, `
+	Age: int32`, `
+	Age: int32
+	Email: string`, 1),
+			changes: []string{"breaking: model User field Email is added as required"},
+		},
+		{
+			name:    "changed field type",
+			input:   strings.Replace(oldInput, "Age: int32", "Age: int64", 1),
+			changes: []string{"breaking: model User field Age type is changed from int32 to int64"},
+		},
+		{
+			name: "renumbered enum value",
+			input: strings.Replace(oldInput, `
+	Admin
+	Member`, `
+	Member
+	Admin`, 1),
+			changes: []string{
+				"breaking: enum Role value Admin is renumbered from 1 to 2",
+				"breaking: enum Role value Member is renumbered from 2 to 1",
+			},
+		},
+		{
+			name: "removed and added methods",
+			input: strings.Replace(oldInput, `
+	Delete(id: string)`, `
+	Update(user: User)`, 1),
+			changes: []string{
+				"breaking: service HttpUserService method Delete is removed",
+				"compatible: service HttpUserService method Update is added",
+			},
+		},
+		{
+			name:    "changed method signature",
+			input:   strings.Replace(oldInput, "GetById(id: string)", "GetById(id: int64)", 1),
+			changes: []string{"breaking: service HttpUserService method GetById signature is changed from (id: string) => (user: User) to (id: int64) => (user: User)"},
+		},
+		{
+			name: "added error",
+			input: oldInput + `
+error ErrTimeout { Msg = "timeout" }`,
+			changes: []string{"compatible: error ErrTimeout is added"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var changes []string
+			for _, change := range Diff(parse(oldInput), parse(tc.input)) {
+				changes = append(changes, change.String())
+			}
+			require.Equal(t, tc.changes, changes)
+		})
+	}
+}
+
+func TestGenerateJsonSchema(t *testing.T) {
+	const input = `
+enum Role {
+	_
+	Admin
+	Member
+}
+
+enum Level {
+	Low = 1
+	High = 2
+} { JsonNumber = true }
+
+# user of the system
+model User {
+	Name: string { Pattern = "^[a-zA-Z]+$" }
+	Age: int32 { Min = 0 Max = 150 }
+	Score?: float64 { Max = 1.5 }
+	Role: Role
+	Level?: Level
+	Tags: set<string>
+	Meta: map<string, int64>
+	Friend: User
+	Internal: string { Json = false }
+	oneof Contact {
+		Email: string
+		Phone: uint64
+	}
+}
+`
+
+	var spec struct {
+		Schema string                    `json:"$schema"`
+		Defs   map[string]map[string]any `json:"$defs"`
+	}
+
+	output := generateOutput(t, ".schema.json", input)
+	require.NoError(t, json.Unmarshal([]byte(output), &spec))
+
+	require.Equal(t, "https://json-schema.org/draft/2020-12/schema", spec.Schema)
+	require.Equal(t, []any{"admin", "member"}, spec.Defs["Role"]["enum"])
+	require.Equal(t, []any{float64(1), float64(2)}, spec.Defs["Level"]["enum"])
+	require.Equal(t, []any{"name", "age", "role", "tags", "meta", "friend"}, spec.Defs["User"]["required"])
+	require.NotContains(t, spec.Defs["User"]["properties"], "internal")
+
+	testCases := []struct {
+		payload string
+		error   string
+	}{
+		{payload: `{"name": "Ada", "age": 36, "role": "admin", "tags": ["a", "b"], "meta": {"a": 1}, "friend": null}`},
+		{payload: `{"name": "Ada", "age": 36, "score": 1.5, "role": "member", "level": 2, "tags": null, "meta": null, "friend": {"name": "Bob", "age": 1, "role": "admin", "tags": [], "meta": {}, "friend": null}, "contact": {"type": "phone", "value": 1}}`},
+		{payload: `{"age": 36, "role": "admin", "tags": [], "meta": {}, "friend": null}`, error: "name is required"},
+		{payload: `{"name": "Ada1", "age": 36, "role": "admin", "tags": [], "meta": {}, "friend": null}`, error: "doesn't match pattern"},
+		{payload: `{"name": "Ada", "age": 151, "role": "admin", "tags": [], "meta": {}, "friend": null}`, error: "greater than maximum"},
+		{payload: `{"name": "Ada", "age": -1, "role": "admin", "tags": [], "meta": {}, "friend": null}`, error: "less than minimum"},
+		{payload: `{"name": "Ada", "age": 1.5, "role": "admin", "tags": [], "meta": {}, "friend": null}`, error: "should be integer"},
+		{payload: `{"name": "Ada", "age": 36, "role": "owner", "tags": [], "meta": {}, "friend": null}`, error: "is not one of the enum values"},
+		{payload: `{"name": "Ada", "age": 36, "role": "admin", "level": 3, "tags": [], "meta": {}, "friend": null}`, error: "is not one of the enum values"},
+		{payload: `{"name": "Ada", "age": 36, "role": "admin", "tags": ["a", "a"], "meta": {}, "friend": null}`, error: "items are not unique"},
+		{payload: `{"name": "Ada", "age": 36, "role": "admin", "tags": [], "meta": {"a": "b"}, "friend": null}`, error: "should be integer"},
+		{payload: `{"name": "Ada", "age": 36, "role": "admin", "tags": [], "meta": {}, "friend": {"name": "Bob"}}`, error: "doesn't match any of the schemas"},
+		{payload: `{"name": "Ada", "age": 36, "role": "admin", "tags": [], "meta": {}, "friend": null, "contact": {"type": "fax", "value": 1}}`, error: "doesn't match exactly one of the schemas"},
+	}
+
+	for _, tc := range testCases {
+		var payload any
+		require.NoError(t, json.Unmarshal([]byte(tc.payload), &payload))
+
+		err := validateJsonSchema(spec.Defs, map[string]any{"$ref": "#/$defs/User"}, payload)
+		if tc.error == "" {
+			require.NoError(t, err, tc.payload)
+		} else {
+			require.ErrorContains(t, err, tc.error, tc.payload)
+		}
+	}
+}
+
+// validateJsonSchema validates the value with the subset of JSON Schema keywords
+// used by the generated documents, the references are resolved from defs
+func validateJsonSchema(defs map[string]map[string]any, schema map[string]any, value any) error {
+	if ref, ok := schema["$ref"].(string); ok {
+		return validateJsonSchema(defs, defs[strings.TrimPrefix(ref, "#/$defs/")], value)
+	}
+
+	if anyOf, ok := schema["anyOf"].([]any); ok {
+		for _, s := range anyOf {
+			if validateJsonSchema(defs, s.(map[string]any), value) == nil {
+				return nil
+			}
+		}
+		return fmt.Errorf("%v doesn't match any of the schemas", value)
+	}
+
+	if oneOf, ok := schema["oneOf"].([]any); ok {
+		matches := 0
+		for _, s := range oneOf {
+			if validateJsonSchema(defs, s.(map[string]any), value) == nil {
+				matches++
+			}
+		}
+		if matches != 1 {
+			return fmt.Errorf("%v doesn't match exactly one of the schemas", value)
+		}
+		return nil
+	}
+
+	if typ, ok := schema["type"]; ok {
+		types, ok := typ.([]any)
+		if !ok {
+			types = []any{typ}
+		}
+
+		matched := false
+		for _, typ := range types {
+			switch v := value.(type) {
+			case nil:
+				matched = matched || typ == "null"
+			case bool:
+				matched = matched || typ == "boolean"
+			case float64:
+				matched = matched || typ == "number" || (typ == "integer" && v == float64(int64(v)))
+			case string:
+				matched = matched || typ == "string"
+			case []any:
+				matched = matched || typ == "array"
+			case map[string]any:
+				matched = matched || typ == "object"
+			}
+		}
+		if !matched {
+			return fmt.Errorf("%v should be %v", value, typ)
+		}
+	}
+
+	if c, ok := schema["const"]; ok && c != value {
+		return fmt.Errorf("%v should be %v", value, c)
+	}
+
+	if enum, ok := schema["enum"].([]any); ok && !slices.Contains(enum, value) {
+		return fmt.Errorf("%v is not one of the enum values", value)
+	}
+
+	switch v := value.(type) {
+	case string:
+		if pattern, ok := schema["pattern"].(string); ok && !regexp.MustCompile(pattern).MatchString(v) {
+			return fmt.Errorf("%q doesn't match pattern %s", v, pattern)
+		}
+	case float64:
+		if minimum, ok := schema["minimum"].(float64); ok && v < minimum {
+			return fmt.Errorf("%v is less than minimum %v", v, minimum)
+		}
+		if maximum, ok := schema["maximum"].(float64); ok && v > maximum {
+			return fmt.Errorf("%v is greater than maximum %v", v, maximum)
+		}
+	case []any:
+		items, _ := schema["items"].(map[string]any)
+		for i, item := range v {
+			if items != nil {
+				if err := validateJsonSchema(defs, items, item); err != nil {
+					return err
+				}
+			}
+			if schema["uniqueItems"] == true && slices.Contains(v[:i], item) {
+				return fmt.Errorf("items are not unique")
+			}
+		}
+	case map[string]any:
+		properties, _ := schema["properties"].(map[string]any)
+		additional, _ := schema["additionalProperties"].(map[string]any)
+		for key, item := range v {
+			s, ok := properties[key].(map[string]any)
+			if !ok {
+				s = additional
+			}
+			if s == nil {
+				continue
+			}
+			if err := validateJsonSchema(defs, s, item); err != nil {
+				return fmt.Errorf("%s: %w", key, err)
+			}
+		}
+
+		required, _ := schema["required"].([]any)
+		for _, name := range required {
+			if _, ok := v[name.(string)]; !ok {
+				return fmt.Errorf("%s is required", name)
+			}
+		}
+	}
+
+	return nil
+}
+
+func TestExplain(t *testing.T) {
+	const input = `
+enum Role {
+	_
+	Admin
+	Member
+}
+
+model User {
+	Id: string
+	Role?: Role
+	Tags: map<string, []Role>
+}
+
+model Team {
+	Members: []User
+}
+
+service HttpUserService {
+	GetById(id: string) => (user: User)
+}
+
+error ErrNotFound { HttpStatus = NotFound Msg = "not found" }
+`
+
+	doc, err := parser.ParseDocument(parser.NewParser(input))
+	require.NoError(t, err)
+	require.NoError(t, parser.Validate(doc))
+
+	explain := func(name string) string {
+		var sb strings.Builder
+		require.NoError(t, Explain(&sb, name, []*ast.Document{doc}))
+		return sb.String()
+	}
+
+	output := explain("User")
+	require.Contains(t, output, "model User\n")
+	require.Contains(t, output, "    Role?:  Role                 go: Role               ts: Role\n")
+	require.Contains(t, output, "    Tags:   map<string, []Role>  go: map[string][]Role  ts: { [key: string]: Role[] }\n")
+	require.Contains(t, output, "  used by:\n    model Team field Members\n    service HttpUserService method GetById return user\n")
+
+	output = explain("Role")
+	require.Contains(t, output, "  size:  8 bits\n")
+	require.Contains(t, output, "    Admin   1  \"admin\"\n")
+	require.Contains(t, output, "    model User field Role\n    model User field Tags\n")
+
+	output = explain("ErrNotFound")
+	require.Contains(t, output, "  code:         1\n  http status:  http.StatusNotFound\n")
+
+	output = explain("Team")
+	require.Contains(t, output, "  used by:    none\n")
+
+	var sb strings.Builder
+	require.Error(t, Explain(&sb, "Unknown", []*ast.Document{doc}))
+}
+
+func TestGenerateAst(t *testing.T) {
+	const input = `
+const Timeout = 2s
+
+enum Role {
+	Admin
+	Member
+}
+
+model Base {
+	Id: string
+}
+
+# user of the system
+model User {
+	...Base
+	Scores: map<string, []Role>
+	Friend?: User { Json = false }
+}
+
+service HttpUserService {
+	Get(id: string) => (user: User) { Timeout = Timeout }
+}
+
+error ErrUserNotFound { HttpStatus = NotFound Msg = "user not found" }
+`
+
+	var doc astJsonDocument
+	require.NoError(t, json.Unmarshal([]byte(generateOutput(t, ".ast.json", input)), &doc))
+
+	require.Equal(t, "test", doc.Package)
+	require.Equal(t, float64(2_000_000_000), doc.Consts[0].Value)
+	require.Equal(t, "2s", doc.Consts[0].Expr)
+	require.Equal(t, 8, doc.Enums[0].Size)
+
+	// the extended fields are inlined
+	user := doc.Models[1]
+	require.Equal(t, []string{"user of the system"}, user.Comments)
+	require.Equal(t, "Id", user.Fields[0].Name)
+	require.Equal(t, &astJsonType{
+		Kind:  "map",
+		Key:   &astJsonType{Kind: "string"},
+		Value: &astJsonType{Kind: "array", Elem: &astJsonType{Kind: "enum", Name: "Role"}},
+	}, user.Fields[1].Type)
+	require.Equal(t, &astJsonType{Kind: "model", Name: "User"}, user.Fields[2].Type)
+	require.True(t, user.Fields[2].Optional)
+	require.Equal(t, []*astJsonOption{{Name: "Json", Value: false}}, user.Fields[2].Options)
+
+	method := doc.Services[0].Methods[0]
+	require.Equal(t, "http", doc.Services[0].Type)
+	require.Equal(t, []*astJsonOption{{Name: "Timeout", Value: float64(2_000_000_000)}}, method.Options)
+
+	require.Equal(t, float64(404), doc.Errors[0].HttpStatus)
+	require.Equal(t, "user not found", doc.Errors[0].Msg)
+}
+
+func TestGeneratePlugin(t *testing.T) {
+	const input = `
+model User {
+	Id: string
+}
+
+model Account {
+	Users: []User
+}
+`
+
+	doc, err := parser.ParseDocument(parser.NewParser(input))
+	require.NoError(t, err)
+	require.NoError(t, parser.Validate(doc))
+
+	docs := []*ast.Document{doc}
+	outDir := filepath.Join(t.TempDir(), "out")
+
+	t.Setenv("HEXE_TEST_PLUGIN", "echo")
+	require.NoError(t, Generate("test", outDir, docs, WithPlugin(os.Args[0])))
+
+	names, err := os.ReadFile(filepath.Join(outDir, "models", "names.txt"))
+	require.NoError(t, err)
+	require.Equal(t, "User\nAccount", string(names))
+
+	echo, err := os.ReadFile(filepath.Join(outDir, "echo.json"))
+	require.NoError(t, err)
+
+	var req pluginRequest
+	require.NoError(t, json.Unmarshal(echo, &req))
+	require.Equal(t, pluginVersion, req.Version)
+	require.Equal(t, "test", req.Package)
+	require.Equal(t, &astJsonType{Kind: "array", Elem: &astJsonType{Kind: "model", Name: "User"}}, req.Schema.Models[1].Fields[0].Type)
+
+	// a plugin can't write into a single output
+	require.Error(t, GenerateTo(&bytes.Buffer{}, TargetGo, "test", docs, WithPlugin(os.Args[0])))
+
+	t.Setenv("HEXE_TEST_PLUGIN", "error")
+	err = Generate("test", outDir, docs, WithPlugin(os.Args[0]))
+	require.ErrorContains(t, err, "failed: unsupported schema")
+
+	t.Setenv("HEXE_TEST_PLUGIN", "exit")
+	err = Generate("test", outDir, docs, WithPlugin(os.Args[0]))
+	require.ErrorContains(t, err, "something went wrong")
+
+	t.Setenv("HEXE_TEST_PLUGIN", "outside")
+	err = Generate("test", outDir, docs, WithPlugin(os.Args[0]))
+	require.ErrorContains(t, err, "returned a file outside of the output directory: ../outside.txt")
+}
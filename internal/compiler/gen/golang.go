@@ -1,9 +1,14 @@
 package gen
 
 import (
+	"bytes"
 	"embed"
+	"errors"
 	"fmt"
-	"os"
+	"go/format"
+	"go/scanner"
+	"io"
+	"slices"
 	"strconv"
 	"strings"
 	"text/template"
@@ -16,7 +21,7 @@ import (
 //go:embed golang/*.go.tmpl
 var golangTemplateFiles embed.FS
 
-func generateGo(pkg, output string, doc *ast.Document) error {
+func generateGo(out io.Writer, pkg string, doc *ast.Document, opts *options) error {
 	// CONSTANTS
 
 	type MethodType int
@@ -28,37 +33,93 @@ func generateGo(pkg, output string, doc *ast.Document) error {
 		MethodBinaryToJson                     // 3
 		MethodBinaryToSSE                      // 4
 		MethodBinaryToBinary                   // 5
+		MethodStreamToSSE                      // 6 bidirectional stream of messages
 	)
 
 	type GoConst struct {
-		Name  string
-		Value string
+		Name     string
+		Type     string // ByteSize or Duration, based on WithGoTypedUnits, empty for untyped constants
+		Value    string
+		IsVar    bool // object and list literals are variables, as go has no struct or slice constants
+		Comments []string
+	}
+
+	type GoConfigField struct {
+		Name     string // the same as the constant
+		Type     string
+		Value    string // the constant converted to Type
+		Env      string // the environment variable which overrides the value
+		Parse    string // parses the environment variable into Type
+		Comments []string
 	}
 
 	// ENUMS
 
 	type GoEnumKeyValue struct {
-		Name  string
-		Value string
+		Name     string
+		Value    string
+		Label    string // quoted
+		IsAlias  bool   // same value as one of the previous keys, skipped in value's switches
+		Comments []string
 	}
 
 	type GoEnum struct {
-		Name string
-		Type string // int8, int16, int32, int64
-		Keys []GoEnumKeyValue
+		Name           string
+		Type           string // int8, int16, int32, int64
+		Keys           []GoEnumKeyValue
+		HasLabels      bool
+		JsonNumber     bool
+		Comments       []string
+		BottomComments []string
 	}
 
 	// MODELS
 
 	type GoModelField struct {
-		Name string
-		Type string
-		Tags string
+		Name     string
+		Type     string
+		Tags     string
+		Comments []string
 	}
 
-	type GoModel struct {
+	type GoOneOfVariant struct {
+		Name          string // wrapper type's name, e.g. ResultValueUser
+		Type          string
+		Discriminator string
+	}
+
+	type GoOneOf struct {
+		Name     string // interface's name, e.g. ResultValue
+		Field    string
+		JsonName string
+		Variants []GoOneOfVariant
+		Comments []string
+	}
+
+	type GoRequiresField struct {
 		Name   string
-		Fields []GoModelField
+		IsSet  string // go expression which is true if the field is set
+		IsZero string // go expression which is true if the field is not set
+	}
+
+	type GoModelRequires struct {
+		Fields []GoRequiresField
+		When   GoRequiresField
+	}
+
+	type GoModel struct {
+		Name           string
+		Fields         []GoModelField
+		OneOfs         []GoOneOf
+		Requires       []GoModelRequires
+		Checks         []GoFieldCheck
+		Patterns       []GoFieldPattern
+		TimeFields     []GoTimeField
+		PresenceFields []GoPresenceField
+		PresenceWords  int      // the size of the present bitset in uint64s
+		IsZero         []string // go expressions which are true if the fields are zero, for the models which are flat fields
+		Comments       []string
+		BottomComments []string
 	}
 
 	// SERVICES
@@ -87,22 +148,52 @@ func generateGo(pkg, output string, doc *ast.Document) error {
 		Returns     []GoMethodReturn
 		Options     []GoMethodOption
 
-		Type         MethodType
-		Timeout      int64
-		TotalMaxSize int64
+		Type            MethodType
+		Timeout         int64  // in nanoseconds, based on Timeout option, 0 means no timeout
+		TotalMaxSize    int64  // in bytes, based on MaxSize option, 0 means no limit
+		HttpMethod      string // GET or POST, based on HttpMethod option, default is POST
+		Cache           bool   // GET method's response can be cached by the client
+		MsgPack         bool   // request and response can be encoded as MessagePack, based on MsgPack option
+		RateLimit       int64  // number of calls per RateLimitWindow, based on RateLimit option, 0 means no limit
+		RateLimitWindow int64  // in nanoseconds
+		Disposition     string // attachment or inline, based on Disposition option, default is attachment
+		Seq             bool   // the client has an iter.Seq2 form of the stream return, based on the targeted go version
+		Deprecated      string // the message of the Deprecated option, empty if it's not deprecated
+		Comments        []string
 	}
 
 	type GoService struct {
-		Name    string
-		Methods []GoMethod
+		Name           string
+		Methods        []GoMethod
+		Comments       []string
+		BottomComments []string
+	}
+
+	// CLI
+
+	type GoCliFlag struct {
+		Name  string // kebab case of the argument, e.g. user-id
+		Field string // field of the parsed flags, e.g. UserId
+		Type  string
+		Usage string
+	}
+
+	type GoCliCommand struct {
+		Service string // kebab case of the service without Http prefix, e.g. user-service
+		Name    string // kebab case of the method, e.g. get-by-id
+		Usage   string // quoted first comment of the method
+		Method  GoMethod
+		Flags   []GoCliFlag
 	}
 
 	// ERRORS
 
 	type GoError struct {
-		Name    string
-		Code    int64
-		Message string
+		Name       string
+		Code       int64
+		HttpStatus string // e.g. http.StatusNotFound or 404, empty if not defined
+		Message    string
+		Comments   []string
 	}
 
 	type Data struct {
@@ -113,12 +204,26 @@ func generateGo(pkg, output string, doc *ast.Document) error {
 		HttpServices  []GoService
 		RpcServices   []GoService
 		Errors        []GoError
-		Json2Json     set[int] // set of method's returns size
+		Json2Json     []int // sorted method's returns sizes
 		Json2Binary   bool
 		Json2SSE      bool
-		Binary2Json   set[int] // set of method's returns size
+		Binary2Json   []int // sorted method's returns sizes
 		Binary2Binary bool
 		Binary2SSE    bool
+		Stream2SSE    bool
+		HasSet        bool
+		HasOneOf      bool
+		HasValidate   bool
+		HasPattern    bool
+		HasTimeFormat bool
+		HasByteSize   bool
+		HasDuration   bool
+		HasMsgPack    bool
+		HasRateLimit  bool
+		HasSeq        bool
+		Config        []GoConfigField
+		Cli           bool // a main package which calls the http services from the command line
+		CliCommands   []GoCliCommand
 	}
 
 	tmpl, err := template.
@@ -172,6 +277,8 @@ func generateGo(pkg, output string, doc *ast.Document) error {
 					return fmt.Sprintf("handleBinaryToJson%d", size)
 				case MethodBinaryToSSE:
 					return "handleBinaryToSSE"
+				case MethodStreamToSSE:
+					return "handleStreamToSSE"
 				case MethodBinaryToBinary:
 					return "handleBinaryToBinary"
 				default:
@@ -190,6 +297,9 @@ func generateGo(pkg, output string, doc *ast.Document) error {
 
 					if arg.Stream && arg.Type == "[]byte" {
 						sb.WriteString("func() (filename string, content io.Reader, err error)")
+					} else if arg.Stream {
+						sb.WriteString("<-chan ")
+						sb.WriteString(arg.Type)
 					} else {
 						sb.WriteString(arg.Type)
 					}
@@ -238,6 +348,18 @@ func generateGo(pkg, output string, doc *ast.Document) error {
 			"ToMethodReturnTypeIndex": func(idx int, returns []GoMethodReturn) string {
 				return returns[idx].Type
 			},
+			"ToGoComment": getGolangComment,
+			// ToGoComments writes the comments as // lines, each line is followed by
+			// the indent, so it can be placed right before the documented code
+			"ToGoComments": func(indent string, comments []string) string {
+				var sb strings.Builder
+				for _, comment := range comments {
+					sb.WriteString(getGolangComment(comment))
+					sb.WriteString("\n")
+					sb.WriteString(indent)
+				}
+				return sb.String()
+			},
 			"HasOption": func(options []GoMethodOption, name string) bool {
 				for _, opt := range options {
 					if opt.Name == name {
@@ -300,6 +422,14 @@ func generateGo(pkg, output string, doc *ast.Document) error {
 
 				return sb.String()
 			},
+			"ToStreamArgName": func(args []GoMethodArg) string {
+				for _, arg := range args {
+					if arg.Stream {
+						return arg.Name
+					}
+				}
+				return ""
+			},
 			"ToUploadNameArg": func(args []GoMethodArg) string {
 				for _, arg := range args {
 					if arg.Stream && arg.Type == "[]byte" {
@@ -314,23 +444,31 @@ func generateGo(pkg, output string, doc *ast.Document) error {
 		return err
 	}
 
-	out, err := os.Create(output)
-	if err != nil {
-		return err
-	}
-
 	// Helper functions
 
 	isModelType := createIsModelTypeFunc(doc.Models)
 
+	// the model typed fields are values in the presence mode, see getGolangFlatFields
+	var flatFields map[*ast.Field]bool
+	if opts.goPresence {
+		if err := checkGolangPresenceNames(doc.Models); err != nil {
+			return err
+		}
+		flatFields = getGolangFlatFields(doc.Models, isModelType)
+	}
+
 	getServicesByType := func(typ ast.ServiceType) []GoService {
 		return mapperFunc(getServicesByType(doc.Services, typ), func(service *ast.Service) GoService {
 			return GoService{
-				Name: service.Name.Token.Value,
+				Name:           service.Name.Token.Value,
+				Comments:       getCommentLines(service.Comments, ast.CommentTop),
+				BottomComments: getCommentLines(service.Comments, ast.CommentBottom),
 				Methods: mapperFunc(service.Methods, func(method *ast.Method) GoMethod {
 					goMethod := GoMethod{
 						Name:        method.Name.Token.Value,
 						ServiceName: service.Name.Token.Value,
+						Deprecated:  getGolangDeprecated(method.Options),
+						Comments:    getGolangDeprecatedComments(getCommentLines(method.Comments, ast.CommentTop), method.Options),
 						Args: mapperFunc(method.Args, func(arg *ast.Arg) GoMethodArg {
 							// func() (string, io.Reader, error)
 							return GoMethodArg{
@@ -355,6 +493,44 @@ func generateGo(pkg, output string, doc *ast.Document) error {
 						}),
 					}
 
+					goMethod.HttpMethod = "POST"
+					goMethod.Disposition = "attachment"
+					for _, opt := range method.Options.List {
+						switch opt.Name.Token.Value {
+						case "HttpMethod":
+							if v, ok := opt.Value.(*ast.ValueString); ok {
+								goMethod.HttpMethod = v.Value
+							}
+						case "Cache":
+							if v, ok := opt.Value.(*ast.ValueBool); ok {
+								goMethod.Cache = v.Value
+							}
+						case "Timeout":
+							if v, ok := opt.Value.(*ast.ValueDuration); ok {
+								goMethod.Timeout = v.Value * int64(v.Scale)
+							}
+						case "MaxSize":
+							if v, ok := opt.Value.(*ast.ValueByteSize); ok {
+								goMethod.TotalMaxSize = v.Value * int64(v.Scale)
+							}
+						case "MsgPack":
+							if v, ok := opt.Value.(*ast.ValueBool); ok {
+								goMethod.MsgPack = v.Value
+							}
+						case "RateLimit":
+							if v, ok := opt.Value.(*ast.ValueString); ok {
+								if count, window, err := ast.ParseRateLimit(v.Value); err == nil {
+									goMethod.RateLimit = count
+									goMethod.RateLimitWindow = int64(window)
+								}
+							}
+						case "Disposition":
+							if v, ok := opt.Value.(*ast.ValueString); ok {
+								goMethod.Disposition = v.Value
+							}
+						}
+					}
+
 					// Findout the method type
 					// NOTE: currently stream keyword can at most appear once in the arguments and returns
 					// if it appears more than once, it will be syntax error
@@ -376,7 +552,9 @@ func generateGo(pkg, output string, doc *ast.Document) error {
 						}
 					}
 
-					if argStreamType == "" && retStreamType == "" {
+					if argStreamType != "" && argStreamType != "[]byte" {
+						goMethod.Type = MethodStreamToSSE
+					} else if argStreamType == "" && retStreamType == "" {
 						goMethod.Type = MethodJsonToJson
 					} else if argStreamType == "" && retStreamType == "[]byte" {
 						goMethod.Type = MethodJsonToBinary
@@ -390,74 +568,269 @@ func generateGo(pkg, output string, doc *ast.Document) error {
 						goMethod.Type = MethodBinaryToSSE
 					}
 
+					switch goMethod.Type {
+					case MethodJsonToSSE, MethodBinaryToSSE, MethodStreamToSSE:
+						goMethod.Seq = opts.goIter()
+					}
+
 					return goMethod
 				}),
 			}
 		})
 	}
 
+	// the literals are skipped as they can't be set by an environment variable
+	var config []GoConfigField
+	if opts.goConfig {
+		for _, c := range doc.Consts {
+			if c.Type != nil {
+				continue
+			}
+
+			name := c.Identifier.Token.Value
+
+			field := GoConfigField{
+				Name:     name,
+				Env:      strings.ToUpper(strcase.ToSnake(name)),
+				Comments: getCommentLines(c.Comments, ast.CommentTop),
+			}
+
+			switch c.Value.(type) {
+			case *ast.ValueString:
+				field.Type, field.Parse = "string", "parseEnvString"
+			case *ast.ValueInt:
+				field.Type, field.Parse = "int64", "parseEnvInt"
+			case *ast.ValueUint:
+				field.Type, field.Parse = "uint64", "parseEnvUint"
+			case *ast.ValueFloat:
+				field.Type, field.Parse = "float64", "parseEnvFloat"
+			case *ast.ValueBool:
+				field.Type, field.Parse = "bool", "strconv.ParseBool"
+			case *ast.ValueByteSize:
+				field.Type, field.Parse = "int64", "parseEnvByteSize"
+			case *ast.ValueDuration:
+				field.Type, field.Parse = "time.Duration", "time.ParseDuration"
+			default:
+				continue
+			}
+
+			// the typed units, e.g. ByteSize, are converted to the field's type
+			field.Value = field.Type + "(" + name + ")"
+			if field.Type == "string" || field.Type == "bool" {
+				field.Value = name
+			}
+
+			config = append(config, field)
+		}
+	}
+
 	data := Data{
 		PackageName: pkg,
 		Constants: mapperFunc(doc.Consts, func(c *ast.Const) GoConst {
+			var typ string
+			if opts.goTypedUnits {
+				switch c.Value.(type) {
+				case *ast.ValueByteSize:
+					typ = "ByteSize"
+				case *ast.ValueDuration:
+					typ = "Duration"
+				}
+			}
+
 			return GoConst{
-				Name:  c.Identifier.Token.Value,
-				Value: getGolangValue(c.Value),
+				Name:     c.Identifier.Token.Value,
+				Type:     typ,
+				Value:    getGolangValue(c.Value),
+				IsVar:    c.Type != nil,
+				Comments: getCommentLines(c.Comments, ast.CommentTop),
 			}
 		}),
+		Config: config,
 		Enums: mapperFunc(doc.Enums, func(enum *ast.Enum) GoEnum {
+			values := newSet[int64]()
 			return GoEnum{
 				Name: enum.Name.Token.Value,
-				Type: fmt.Sprintf("int%d", enum.Size),
+				Type: getGolangEnumType(enum),
 				Keys: mapperFunc(enum.Sets, func(set *ast.EnumSet) GoEnumKeyValue {
+					_, isAlias := values[set.Value.Value]
+					if set.Name.Token.Value != "_" {
+						values.add(set.Value.Value)
+					}
+
 					return GoEnumKeyValue{
-						Name:  set.Name.Token.Value,
-						Value: fmt.Sprintf("%d", set.Value.Value),
+						Name:     set.Name.Token.Value,
+						Value:    fmt.Sprintf("%d", set.Value.Value),
+						Label:    strconv.Quote(getEnumSetLabel(set)),
+						IsAlias:  isAlias,
+						Comments: getCommentLines(set.Comments, ast.CommentTop),
 					}
 				}),
+				HasLabels:      hasEnumLabels(enum),
+				JsonNumber:     isEnumJsonNumber(enum),
+				Comments:       getCommentLines(enum.Comments, ast.CommentTop),
+				BottomComments: getCommentLines(enum.Comments, ast.CommentBottom),
 			}
 		}),
 		Models: mapperFunc(doc.Models, func(model *ast.Model) GoModel {
+			var presenceFields []GoPresenceField
+			if opts.goPresence {
+				presenceFields = getGolangPresenceFields(model, isModelType, flatFields)
+			}
+
+			// the optional fields of the presence mode are encoded by their own wrappers
+			timeFields := filterFunc(getGolangTimeFields(model, opts.goOmitZero()), func(field GoTimeField) bool {
+				return !slices.ContainsFunc(presenceFields, func(presence GoPresenceField) bool {
+					return presence.Name == field.Name
+				})
+			})
+
 			return GoModel{
 				Name: model.Name.Token.Value,
 				Fields: mapperFunc(model.Fields, func(field *ast.Field) GoModelField {
 					return GoModelField{
-						Name: field.Name.Token.Value,
-						Type: getGolangType(field.Type, isModelType),
-						Tags: getGolangModelFieldTag(field),
+						Name:     field.Name.Token.Value,
+						Type:     getGolangFieldType(field, isModelType, flatFields),
+						Tags:     getGolangModelFieldTag(field, opts.goOmitZero()),
+						Comments: getGolangDeprecatedComments(getCommentLines(field.Comments, ast.CommentTop), field.Options),
+					}
+				}),
+				OneOfs: mapperFunc(model.OneOfs, func(oneOf *ast.OneOf) GoOneOf {
+					name := model.Name.Token.Value + oneOf.Name.Token.Value
+					return GoOneOf{
+						Name:     name,
+						Field:    oneOf.Name.Token.Value,
+						JsonName: strcase.ToCamel(oneOf.Name.Token.Value),
+						Variants: mapperFunc(oneOf.Variants, func(variant *ast.OneOfVariant) GoOneOfVariant {
+							return GoOneOfVariant{
+								Name:          name + variant.Name.Token.Value,
+								Type:          getGolangType(variant.Type, isModelType),
+								Discriminator: strcase.ToSnake(variant.Name.Token.Value),
+							}
+						}),
+						Comments: getCommentLines(oneOf.Comments, ast.CommentTop),
+					}
+				}),
+				Requires: mapperFunc(model.Requires, func(requires *ast.Requires) GoModelRequires {
+					fieldsMap := make(map[string]*ast.Field)
+					for _, field := range model.Fields {
+						fieldsMap[field.Name.Token.Value] = field
+					}
+
+					getRequiresField := func(name string) GoRequiresField {
+						isSet, isZero := getGolangFieldIsSetExpr("m."+name, fieldsMap[name], isModelType, flatFields)
+						return GoRequiresField{
+							Name:   name,
+							IsSet:  isSet,
+							IsZero: isZero,
+						}
+					}
+
+					return GoModelRequires{
+						Fields: mapperFunc(requires.Fields, func(field *ast.Identifier) GoRequiresField {
+							return getRequiresField(field.Token.Value)
+						}),
+						When: getRequiresField(requires.When.Token.Value),
 					}
 				}),
+				Checks:         getGolangFieldChecks(model, isModelType, flatFields, opts.goPresence),
+				Patterns:       getGolangFieldPatterns(model),
+				TimeFields:     timeFields,
+				PresenceFields: presenceFields,
+				PresenceWords:  (len(presenceFields) + 63) / 64,
+				IsZero:         getGolangModelIsZero(model, isModelType, flatFields, len(presenceFields)),
+				Comments:       getCommentLines(model.Comments, ast.CommentTop),
+				BottomComments: getCommentLines(model.Comments, ast.CommentBottom),
 			}
 		}),
 		HttpServices: getServicesByType(ast.ServiceHTTP),
 		RpcServices:  getServicesByType(ast.ServiceRPC),
 		Errors: mapperFunc(doc.Errors, func(err *ast.CustomError) GoError {
 			return GoError{
-				Name:    err.Name.Token.Value,
-				Code:    err.Code,
-				Message: err.Msg.Value,
+				Name:       err.Name.Token.Value,
+				Code:       err.Code,
+				HttpStatus: getGolangHttpStatus(err),
+				Message:    err.Msg.Value,
+				Comments:   getCommentLines(err.Comments, ast.CommentTop),
 			}
 		}),
-		Json2Json:   newSet[int](),
-		Binary2Json: newSet[int](),
 	}
 
+	for _, model := range doc.Models {
+		if len(model.OneOfs) > 0 {
+			data.HasOneOf = true
+			break
+		}
+	}
+
+	for _, c := range data.Constants {
+		switch c.Type {
+		case "ByteSize":
+			data.HasByteSize = true
+		case "Duration":
+			data.HasDuration = true
+		}
+	}
+
+	for _, model := range data.Models {
+		if len(model.Requires) > 0 || len(model.Checks) > 0 {
+			data.HasValidate = true
+		}
+
+		if len(model.Patterns) > 0 {
+			data.HasPattern = true
+		}
+
+		if len(model.TimeFields) > 0 {
+			data.HasTimeFormat = true
+		}
+
+		for _, field := range model.PresenceFields {
+			if field.JsonType != field.Type {
+				data.HasTimeFormat = true
+			}
+		}
+	}
+
+	walkTypes(doc, func(typ ast.Type) {
+		if _, ok := typ.(*ast.Set); ok {
+			data.HasSet = true
+		}
+	})
+
+	json2json := newSet[int]()
+	binary2json := newSet[int]()
+
 	// adding some info about process functions
 	// so they can be generated in the correct order
 	for _, service := range data.HttpServices {
 		for _, method := range service.Methods {
 			switch method.Type {
 			case MethodJsonToJson:
-				data.Json2Json.add(len(method.Returns))
+				json2json.add(len(method.Returns))
 			case MethodJsonToBinary:
 				data.Json2Binary = true
 			case MethodJsonToSSE:
 				data.Json2SSE = true
 			case MethodBinaryToJson:
-				data.Binary2Json.add(len(method.Returns))
+				binary2json.add(len(method.Returns))
 			case MethodBinaryToBinary:
 				data.Binary2Binary = true
 			case MethodBinaryToSSE:
 				data.Binary2SSE = true
+			case MethodStreamToSSE:
+				data.Stream2SSE = true
+			}
+
+			if method.MsgPack {
+				data.HasMsgPack = true
+			}
+
+			if method.RateLimit > 0 {
+				data.HasRateLimit = true
+			}
+
+			if method.Seq {
+				data.HasSeq = true
 			}
 		}
 	}
@@ -468,13 +841,13 @@ func generateGo(pkg, output string, doc *ast.Document) error {
 		for _, method := range service.Methods {
 			switch method.Type {
 			case MethodJsonToJson:
-				data.Json2Json.add(len(method.Returns))
+				json2json.add(len(method.Returns))
 			case MethodJsonToBinary:
 				data.Json2Binary = true
 			case MethodJsonToSSE:
 				data.Json2SSE = true
 			case MethodBinaryToJson:
-				data.Binary2Json.add(len(method.Returns))
+				binary2json.add(len(method.Returns))
 			case MethodBinaryToBinary:
 				data.Binary2Binary = true
 			case MethodBinaryToSSE:
@@ -483,7 +856,85 @@ func generateGo(pkg, output string, doc *ast.Document) error {
 		}
 	}
 
-	return tmpl.ExecuteTemplate(out, "main", data)
+	// each http service is a command and each of its methods a subcommand, the uploads
+	// and the bidirectional streams are skipped as their arguments can't be flags
+	if opts.goCli {
+		data.Cli = true
+		for _, service := range data.HttpServices {
+			for _, method := range service.Methods {
+				if method.Type > MethodJsonToBinary {
+					continue
+				}
+
+				var usage string
+				if len(method.Comments) > 0 {
+					usage = method.Comments[0]
+				}
+
+				data.CliCommands = append(data.CliCommands, GoCliCommand{
+					Service: getKebabCase(strings.TrimPrefix(service.Name, "Http")),
+					Name:    getKebabCase(method.Name),
+					Usage:   strconv.Quote(usage),
+					Method:  method,
+					Flags: mapperFunc(method.Args, func(arg GoMethodArg) GoCliFlag {
+						return GoCliFlag{
+							Name:  getKebabCase(arg.Name),
+							Field: strcase.ToPascal(arg.Name),
+							Type:  arg.Type,
+							Usage: getGolangCliFlagUsage(arg.Type),
+						}
+					}),
+				})
+			}
+		}
+	}
+
+	// sets are converted to sorted slices so the generated
+	// code is stable across runs
+	data.Json2Json = sortedKeys(json2json)
+	data.Binary2Json = sortedKeys(binary2json)
+
+	var buf bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&buf, "main", data); err != nil {
+		return err
+	}
+
+	src, err := formatGolang(buf.Bytes())
+	if err != nil {
+		return err
+	}
+
+	_, err = out.Write(src)
+	return err
+}
+
+// formatGolang runs the generated code through gofmt, so the output is canonical
+// regardless of the templates' whitespace. If the code doesn't parse, the error
+// includes the lines around the first error to make it easier to find in templates
+func formatGolang(src []byte) ([]byte, error) {
+	formatted, err := format.Source(src)
+	if err == nil {
+		return formatted, nil
+	}
+
+	var errList scanner.ErrorList
+	if !errors.As(err, &errList) || len(errList) == 0 {
+		return nil, fmt.Errorf("failed to format generated go code: %w", err)
+	}
+
+	line := errList[0].Pos.Line
+	lines := strings.Split(string(src), "\n")
+
+	var sb strings.Builder
+	for i := max(line-3, 1); i <= min(line+3, len(lines)); i++ {
+		marker := "  "
+		if i == line {
+			marker = "> "
+		}
+		fmt.Fprintf(&sb, "%s%5d | %s\n", marker, i, lines[i-1])
+	}
+
+	return nil, fmt.Errorf("failed to format generated go code: %w\n%s", err, sb.String())
 }
 
 func getGolangValue(value ast.Value) string {
@@ -499,22 +950,144 @@ func getGolangValue(value ast.Value) string {
 		}
 	case *ast.ValueInt:
 		return strconv.FormatInt(v.Value, 10)
+	case *ast.ValueUint:
+		return strconv.FormatUint(v.Value, 10)
 	case *ast.ValueByteSize:
 		return fmt.Sprintf(`%d`, v.Value*int64(v.Scale))
 	case *ast.ValueDuration:
 		return fmt.Sprintf(`%d`, v.Value*int64(v.Scale))
+	case *ast.ValueObject:
+		return getGolangObjectValue(v, false)
+	case *ast.ValueList:
+		return getGolangListValue(v)
 	default:
 		value.Format(&sb)
 		return sb.String()
 	}
 }
 
+// getGolangObjectValue returns the struct literal of the object's model, the
+// nested objects are pointers, the same as the models' fields
+func getGolangObjectValue(object *ast.ValueObject, isPointer bool) string {
+	var sb strings.Builder
+
+	if isPointer {
+		sb.WriteString("&")
+	}
+
+	sb.WriteString(object.Model.Name.Token.Value)
+	sb.WriteString("{")
+	for i, field := range object.Fields {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(field.Name.Token.Value)
+		sb.WriteString(": ")
+		if v, ok := field.Value.(*ast.ValueObject); ok {
+			sb.WriteString(getGolangObjectValue(v, true))
+		} else {
+			sb.WriteString(getGolangValue(field.Value))
+		}
+	}
+	sb.WriteString("}")
+
+	return sb.String()
+}
+
+// getGolangListValue returns the slice or Set literal of the list, the elements of
+// sets are the keys of the map
+func getGolangListValue(list *ast.ValueList) string {
+	var sb strings.Builder
+
+	// only models are allowed as custom types in literals
+	sb.WriteString(getGolangType(list.Type, func(string) bool { return true }))
+	sb.WriteString("{")
+	_, isSet := list.Type.(*ast.Set)
+	for i, value := range list.Values {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		if v, ok := value.(*ast.ValueObject); ok {
+			sb.WriteString(getGolangObjectValue(v, true))
+		} else {
+			sb.WriteString(getGolangValue(value))
+		}
+		if isSet {
+			sb.WriteString(": {}")
+		}
+	}
+	sb.WriteString("}")
+
+	return sb.String()
+}
+
+func getGolangComment(comment string) string {
+	if comment == "" {
+		return "//"
+	}
+	return "// " + comment
+}
+
+// getGolangDeprecated returns the message of the Deprecated option, which
+// follows "Deprecated: " in the doc comment, so go vet and the editors warn
+// about the usages
+func getGolangDeprecated(options *ast.Options) string {
+	message, ok := options.Deprecated()
+	if !ok {
+		return ""
+	}
+
+	if message == "" {
+		return "it will be removed in a future version"
+	}
+
+	return message
+}
+
+// getGolangDeprecatedComments appends the Deprecated paragraph to the comments,
+// it should be a separate paragraph to be recognized by the go tools
+func getGolangDeprecatedComments(comments []string, options *ast.Options) []string {
+	deprecated := getGolangDeprecated(options)
+	if deprecated == "" {
+		return comments
+	}
+
+	if len(comments) > 0 {
+		comments = append(comments, "")
+	}
+
+	return append(comments, "Deprecated: "+deprecated)
+}
+
+func getGolangHttpStatus(err *ast.CustomError) string {
+	status, ok := err.HttpStatus.(*ast.ValueInt)
+	if !ok {
+		return ""
+	}
+
+	// status names are validated to be the same as net/http's constants
+	if status.Token.Type == token.Identifier {
+		return "http.Status" + status.Token.Value
+	}
+
+	return strconv.FormatInt(status.Value, 10)
+}
+
+func getGolangEnumType(enum *ast.Enum) string {
+	if _, ok := enum.Type.(*ast.Uint); ok {
+		return fmt.Sprintf("uint%d", enum.Size)
+	}
+	return fmt.Sprintf("int%d", enum.Size)
+}
+
 func getGolangType(typ ast.Type, isModelType func(value string) bool) string {
 	switch typ := typ.(type) {
 	case *ast.CustomType:
 		var sb strings.Builder
 		typ.Format(&sb)
 		val := sb.String()
+		// models are always referenced by pointer, including the elements of arrays, maps and
+		// the oneofs' variants, so recursive models, e.g. Parent: TreeNode, have a finite size
 		if isModelType(val) {
 			return "*" + val
 		}
@@ -539,13 +1112,400 @@ func getGolangType(typ ast.Type, isModelType func(value string) bool) string {
 		return fmt.Sprintf("map[%s]%s", getGolangType(typ.Key, isModelType), getGolangType(typ.Value, isModelType))
 	case *ast.Array:
 		return fmt.Sprintf("[]%s", getGolangType(typ.Type, isModelType))
+	case *ast.Set:
+		return fmt.Sprintf("Set[%s]", getGolangType(typ.Type, isModelType))
 	default:
 		// This shouldn't happen as the validator should catch this any errors
 		panic(fmt.Sprintf("unknown type: %T", typ))
 	}
 }
 
-func getGolangModelFieldTag(field *ast.Field) string {
+// getKebabCase returns the name of a command or a flag, e.g. GetById is get-by-id
+func getKebabCase(name string) string {
+	return strings.ReplaceAll(strcase.ToSnake(name), "_", "-")
+}
+
+// getGolangCliFlagUsage returns the usage of an argument's flag, the arrays, maps,
+// sets, models and any are passed as json, e.g. --user='{"name":"hexe"}'
+func getGolangCliFlagUsage(typ string) string {
+	switch {
+	case typ == "any" || strings.HasPrefix(typ, "*") || strings.HasPrefix(typ, "[]") ||
+		strings.HasPrefix(typ, "map[") || strings.HasPrefix(typ, "Set["):
+		return "json of " + strings.TrimPrefix(typ, "*")
+	case typ == "time.Time":
+		return "RFC 3339 timestamp"
+	default:
+		return typ
+	}
+}
+
+type GoFieldCheck struct {
+	Invalid string // go expression which is true if the field's value is invalid
+	Msg     string // quoted go string of the validation error's message
+}
+
+type GoFieldPattern struct {
+	Name    string // name of the package level variable of the compiled regexp
+	Pattern string // quoted go string of the regexp
+}
+
+type GoTimeField struct {
+	Name string
+	Type string // unixTime or unixMilliTime, based on TimeFormat option
+	Tags string
+}
+
+// isFieldJsonString reports whether the field's JsonString option is set, so its
+// number is encoded as a json string, e.g. "9007199254740993", which keeps the
+// precision of 64 bits integers in javascript
+func isFieldJsonString(field *ast.Field) bool {
+	for _, opt := range field.Options.List {
+		if v, ok := opt.Value.(*ast.ValueBool); ok && opt.Name.Token.Value == "JsonString" {
+			return v.Value
+		}
+	}
+
+	return false
+}
+
+// getFieldTimeFormat returns the TimeFormat option of the field, default is rfc3339
+func getFieldTimeFormat(field *ast.Field) string {
+	for _, opt := range field.Options.List {
+		if v, ok := opt.Value.(*ast.ValueString); ok && opt.Name.Token.Value == "TimeFormat" {
+			return v.Value
+		}
+	}
+
+	return "rfc3339"
+}
+
+// getGolangTimeFields returns the timestamp fields which are not encoded as rfc3339,
+// they are replaced by their wrapper types in the model's MarshalJSON and UnmarshalJSON
+func getGolangTimeFields(model *ast.Model, omitZero bool) []GoTimeField {
+	var fields []GoTimeField
+
+	for _, field := range model.Fields {
+		tags := getGolangModelFieldTag(field, omitZero)
+		if strings.HasPrefix(tags, `json:"-"`) {
+			continue
+		}
+
+		var typ string
+		switch getFieldTimeFormat(field) {
+		case "unix":
+			typ = "unixTime"
+		case "unixmilli":
+			typ = "unixMilliTime"
+		default:
+			continue
+		}
+
+		fields = append(fields, GoTimeField{
+			Name: field.Name.Token.Value,
+			Type: typ,
+			Tags: tags,
+		})
+	}
+
+	return fields
+}
+
+type GoPresenceField struct {
+	Name     string
+	Type     string
+	JsonType string // the encoded type, unixTime or unixMilliTime based on TimeFormat option
+	Tags     string // json tag with omitempty, empty if the field is not encoded in json
+	Word     int    // index of the field's bit in the present bitset
+	Bit      int
+	IsSet    string // go expression which is true if the field is not zero
+}
+
+// getGolangPresenceFields returns the optional fields of the model, which are tracked
+// by the present bitset, so a zero value which is set is still encoded in json
+func getGolangPresenceFields(model *ast.Model, isModelType func(value string) bool, flatFields map[*ast.Field]bool) []GoPresenceField {
+	var fields []GoPresenceField
+
+	for _, field := range model.Fields {
+		if !field.IsOptional {
+			continue
+		}
+
+		typ := getGolangFieldType(field, isModelType, flatFields)
+		jsonType := typ
+		switch getFieldTimeFormat(field) {
+		case "unix":
+			jsonType = "unixTime"
+		case "unixmilli":
+			jsonType = "unixMilliTime"
+		}
+
+		// the absent fields are nil pointers in the json wrapper, so they are
+		// always omitted, regardless of JsonOmitEmpty and omitzero
+		var tags string
+		tag := strings.TrimSuffix(strings.TrimPrefix(getGolangModelFieldTag(field, false), `json:"`), `"`)
+		if name, _, _ := strings.Cut(tag, ","); name != "-" {
+			tags = `json:"` + name + `,omitempty`
+			if isFieldJsonString(field) {
+				tags += ",string"
+			}
+			tags += `"`
+		}
+
+		isSet, _ := getGolangFieldIsSetExpr("m."+field.Name.Token.Value, field, isModelType, flatFields)
+
+		fields = append(fields, GoPresenceField{
+			Name:     field.Name.Token.Value,
+			Type:     typ,
+			JsonType: jsonType,
+			Tags:     tags,
+			Word:     len(fields) / 64,
+			Bit:      len(fields) % 64,
+			IsSet:    isSet,
+		})
+	}
+
+	return fields
+}
+
+// checkGolangPresenceNames returns an error if a field of the model has the same name as the
+// HasX or SetX methods of its optional fields, which are generated in the presence mode
+func checkGolangPresenceNames(models []*ast.Model) error {
+	for _, model := range models {
+		names := make(map[string]struct{})
+		for _, field := range model.Fields {
+			names[field.Name.Token.Value] = struct{}{}
+		}
+		for _, oneOf := range model.OneOfs {
+			names[oneOf.Name.Token.Value] = struct{}{}
+		}
+
+		for _, field := range model.Fields {
+			if !field.IsOptional {
+				continue
+			}
+
+			for _, method := range []string{"Has", "Set"} {
+				if _, ok := names[method+field.Name.Token.Value]; ok {
+					return fmt.Errorf("%s.%s%s is the same as the %s%s method of the optional %s field, which is generated with go presence", model.Name.Token.Value, method, field.Name.Token.Value, method, field.Name.Token.Value, field.Name.Token.Value)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// getGolangFlatFields returns the fields whose models are values instead of pointers in the
+// presence mode, including the elements of arrays and maps. The fields which reference their
+// own model, directly or through other model typed fields, e.g. Parent: TreeNode, stay
+// pointers so the models have a finite size
+func getGolangFlatFields(models []*ast.Model, isModelType func(value string) bool) map[*ast.Field]bool {
+	// the models which are referenced by each model's fields, excluding arrays, maps and sets
+	refs := make(map[string][]string)
+	for _, model := range models {
+		for _, field := range model.Fields {
+			if typ, ok := field.Type.(*ast.CustomType); ok && isModelType(typ.Token.Value) {
+				refs[model.Name.Token.Value] = append(refs[model.Name.Token.Value], typ.Token.Value)
+			}
+		}
+	}
+
+	var reaches func(from, to string, visited map[string]bool) bool
+	reaches = func(from, to string, visited map[string]bool) bool {
+		if from == to {
+			return true
+		}
+		if visited[from] {
+			return false
+		}
+		visited[from] = true
+
+		for _, ref := range refs[from] {
+			if reaches(ref, to, visited) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	flatFields := make(map[*ast.Field]bool)
+	for _, model := range models {
+		for _, field := range model.Fields {
+			switch typ := field.Type.(type) {
+			case *ast.CustomType:
+				if isModelType(typ.Token.Value) && !reaches(typ.Token.Value, model.Name.Token.Value, make(map[string]bool)) {
+					flatFields[field] = true
+				}
+			case *ast.Array, *ast.Map:
+				flatFields[field] = true
+			}
+		}
+	}
+
+	return flatFields
+}
+
+// getGolangFieldType returns the go type of the model's field, the models
+// of the flat fields are values instead of pointers, see getGolangFlatFields
+func getGolangFieldType(field *ast.Field, isModelType func(value string) bool, flatFields map[*ast.Field]bool) string {
+	if flatFields[field] {
+		return getGolangType(field.Type, func(string) bool { return false })
+	}
+	return getGolangType(field.Type, isModelType)
+}
+
+// getGolangFieldIsSetExpr is getGolangIsSetExpr of the model's field, the flat
+// model fields are checked by the isZero method of their model
+func getGolangFieldIsSetExpr(value string, field *ast.Field, isModelType func(value string) bool, flatFields map[*ast.Field]bool) (isSet string, isZero string) {
+	if _, ok := field.Type.(*ast.CustomType); ok && flatFields[field] {
+		return "!" + value + ".isZero()", value + ".isZero()"
+	}
+	return getGolangIsSetExpr(value, field.Type, isModelType)
+}
+
+// getGolangModelIsZero returns the expressions of the model's isZero method, which are true if
+// its fields are zero and none of them is present, only the models of the flat fields have it
+func getGolangModelIsZero(model *ast.Model, isModelType func(value string) bool, flatFields map[*ast.Field]bool, presenceFields int) []string {
+	var isFlat bool
+	for field := range flatFields {
+		if typ, ok := field.Type.(*ast.CustomType); ok && typ.Token.Value == model.Name.Token.Value {
+			isFlat = true
+			break
+		}
+	}
+
+	if !isFlat {
+		return nil
+	}
+
+	isZero := []string{}
+	for _, field := range model.Fields {
+		_, expr := getGolangFieldIsSetExpr("m."+field.Name.Token.Value, field, isModelType, flatFields)
+		isZero = append(isZero, expr)
+	}
+
+	for _, oneOf := range model.OneOfs {
+		isZero = append(isZero, "m."+oneOf.Name.Token.Value+" == nil")
+	}
+
+	if presenceFields > 0 {
+		isZero = append(isZero, fmt.Sprintf("m.present == [%d]uint64{}", (presenceFields+63)/64))
+	}
+
+	if len(isZero) == 0 {
+		isZero = append(isZero, "true")
+	}
+
+	return isZero
+}
+
+func getGolangPatternName(model *ast.Model, field *ast.Field) string {
+	return strcase.ToCamel(model.Name.Token.Value) + field.Name.Token.Value + "Pattern"
+}
+
+func getGolangFieldPatterns(model *ast.Model) []GoFieldPattern {
+	var patterns []GoFieldPattern
+
+	for _, field := range model.Fields {
+		for _, opt := range field.Options.List {
+			if v, ok := opt.Value.(*ast.ValueString); ok && opt.Name.Token.Value == "Pattern" {
+				patterns = append(patterns, GoFieldPattern{
+					Name:    getGolangPatternName(model, field),
+					Pattern: strconv.Quote(v.Value),
+				})
+			}
+		}
+	}
+
+	return patterns
+}
+
+// getGolangFieldChecks returns the checks of the fields' Required, Pattern, Min and Max
+// options in the order of the fields, the optional fields are only checked if they are set,
+// or if they are present when presence is true, see WithGoPresence
+func getGolangFieldChecks(model *ast.Model, isModelType func(value string) bool, flatFields map[*ast.Field]bool, presence bool) []GoFieldCheck {
+	var checks []GoFieldCheck
+
+	for _, field := range model.Fields {
+		value := "m." + field.Name.Token.Value
+		name := model.Name.Token.Value + "." + field.Name.Token.Value
+
+		isSet, isZero := getGolangFieldIsSetExpr(value, field, isModelType, flatFields)
+
+		for _, opt := range field.Options.List {
+			var check GoFieldCheck
+
+			switch v := opt.Value.(type) {
+			case *ast.ValueBool:
+				if opt.Name.Token.Value != "Required" || !v.Value {
+					continue
+				}
+				check = GoFieldCheck{Invalid: isZero, Msg: strconv.Quote(name + " is required")}
+			case *ast.ValueString:
+				if opt.Name.Token.Value != "Pattern" {
+					continue
+				}
+				check = GoFieldCheck{
+					Invalid: "!" + getGolangPatternName(model, field) + ".MatchString(" + value + ")",
+					Msg:     strconv.Quote(name + " should match " + v.Value),
+				}
+			case *ast.ValueInt, *ast.ValueUint, *ast.ValueFloat:
+				bound := getGolangValue(v)
+				switch opt.Name.Token.Value {
+				case "Min":
+					check = GoFieldCheck{Invalid: value + " < " + bound, Msg: strconv.Quote(name + " should be greater than or equal to " + bound)}
+				case "Max":
+					check = GoFieldCheck{Invalid: value + " > " + bound, Msg: strconv.Quote(name + " should be less than or equal to " + bound)}
+				default:
+					continue
+				}
+			default:
+				continue
+			}
+
+			if field.IsOptional && presence {
+				check.Invalid = "m.Has" + field.Name.Token.Value + "() && " + check.Invalid
+			} else if field.IsOptional {
+				check.Invalid = isSet + " && " + check.Invalid
+			}
+
+			checks = append(checks, check)
+		}
+	}
+
+	return checks
+}
+
+// getGolangIsSetExpr returns the go expressions which check if the value
+// is set or not, the zero value of the type is considered as not set
+func getGolangIsSetExpr(value string, typ ast.Type, isModelType func(value string) bool) (isSet string, isZero string) {
+	switch typ := typ.(type) {
+	case *ast.CustomType:
+		if isModelType(typ.Token.Value) {
+			return value + " != nil", value + " == nil"
+		}
+		// enums are always numbers
+		return value + " != 0", value + " == 0"
+	case *ast.Any:
+		return value + " != nil", value + " == nil"
+	case *ast.String:
+		return value + ` != ""`, value + ` == ""`
+	case *ast.Bool:
+		return value, "!" + value
+	case *ast.Timestamp:
+		return "!" + value + ".IsZero()", value + ".IsZero()"
+	case *ast.Map, *ast.Array, *ast.Set:
+		return "len(" + value + ") > 0", "len(" + value + ") == 0"
+	default:
+		// the rest are numbers
+		return value + " != 0", value + " == 0"
+	}
+}
+
+// getGolangModelFieldTag returns the field's struct tag, omitZero adds omitzero to
+// the optional fields, which is only supported since go1.24
+func getGolangModelFieldTag(field *ast.Field, omitZero bool) string {
 	var sb strings.Builder
 
 	mapper := make(map[string]ast.Value)
@@ -581,7 +1541,13 @@ func getGolangModelFieldTag(field *ast.Field) string {
 		if !isJsonOmitEmpty {
 			jsonTagValue += ",omitempty"
 		}
-		jsonTagValue += ",omitzero"
+		if omitZero {
+			jsonTagValue += ",omitzero"
+		}
+	}
+
+	if jsonTagValue != "-" && isFieldJsonString(field) {
+		jsonTagValue += ",string"
 	}
 
 	sb.WriteString(`json:"`)
@@ -0,0 +1,1008 @@
+package gen
+
+import (
+	"bytes"
+	"go/format"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hexe-dev/hexe/internal/compiler/ast"
+	"github.com/hexe-dev/hexe/internal/compiler/parser"
+)
+
+func TestGenerateGoFormatted(t *testing.T) {
+	const input = `
+enum Role {
+	Admin
+	Member
+}
+
+model User {
+	Id: string
+	Role: Role
+	Tags?: set<string>
+}
+
+service HttpUserService {
+	Get(id: string) => (user: User)
+	Download(id: string) => (data: stream []byte)
+}
+
+service RpcUserService {
+	Create(user: User) => (id: string)
+}
+`
+
+	output := generateOutput(t, ".go", input)
+
+	formatted, err := format.Source([]byte(output))
+	assert.NoError(t, err)
+	assert.Equal(t, string(formatted), output)
+}
+
+func TestFormatGolangError(t *testing.T) {
+	_, err := formatGolang([]byte("package test\n\nfunc main() {\n\tx := \n}\n"))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to format generated go code")
+	assert.Contains(t, err.Error(), ">     5 | }")
+}
+
+func TestGenerateGoSet(t *testing.T) {
+	const input = `
+enum Role {
+	Admin
+	Member
+}
+
+model User {
+	Tags: set<string>
+	Roles?: set<Role>
+}
+`
+
+	output := generateOutput(t, ".go", input)
+
+	assert.Contains(t, output, "Tags  Set[string]")
+	assert.Contains(t, output, "Roles Set[Role]")
+	assert.Contains(t, output, "type Set[T cmp.Ordered] map[T]struct{}")
+	assert.Contains(t, output, `"slices"`)
+
+	output = generateOutput(t, ".go", `model User { Tags: []string }`)
+
+	assert.NotContains(t, output, "type Set[T cmp.Ordered]")
+	assert.NotContains(t, output, `"slices"`)
+}
+
+func TestGenerateGoUintConst(t *testing.T) {
+	output := generateOutput(t, ".go", `const Mask = 18446744073709551615`)
+
+	assert.Contains(t, output, "Mask = 18446744073709551615")
+}
+
+func TestGenerateGoEnumType(t *testing.T) {
+	output := generateOutput(t, ".go", `
+enum Color uint8 {
+	Red
+	Green
+}
+
+enum Status {
+	Active
+}
+`)
+
+	assert.Contains(t, output, "type Color uint8")
+	assert.Contains(t, output, "var temp uint8")
+	assert.Contains(t, output, "type Status int8")
+}
+
+func TestGenerateGoOneOf(t *testing.T) {
+	const input = `
+model User {
+	Id: string
+}
+
+model Result {
+	oneof Value {
+		User: User
+		Count: int64
+	}
+}
+`
+
+	output := generateOutput(t, ".go", input)
+
+	assert.Contains(t, output, "Value ResultValue `json:\"value,omitempty\"`")
+	assert.Contains(t, output, "type ResultValue interface {")
+	assert.Contains(t, output, "func (ResultValueUser) isResultValue() {}")
+	assert.Contains(t, output, `oneOfValue[*User]{Type: "user", Value: v.Value}`)
+	assert.Contains(t, output, `case "count":`)
+	assert.Contains(t, output, "func (m *Result) UnmarshalJSON(data []byte) error {")
+
+	output = generateOutput(t, ".go", `model User { Id: string }`)
+
+	assert.NotContains(t, output, "type oneOfValue[T any]")
+}
+
+func TestGenerateGoEnumLabel(t *testing.T) {
+	output := generateOutput(t, ".go", `
+enum Status {
+	Active { Label = "Currently active" }
+	Pending
+}
+`)
+
+	assert.Contains(t, output, "func (e Status) Label() string {")
+	assert.Contains(t, output, `return "Currently active"`)
+	assert.Contains(t, output, `return "Pending"`)
+
+	output = generateOutput(t, ".go", `enum Status { Active }`)
+
+	assert.NotContains(t, output, "Label() string")
+}
+
+func TestGenerateGoErrorHttpStatus(t *testing.T) {
+	const input = `
+error ErrNotFound { Code = 1 HttpStatus = NotFound Msg = "not found" }
+error ErrConflict { Code = 2 HttpStatus = 409 Msg = "conflict" }
+error ErrOther { Code = 3 Msg = "other" }
+`
+
+	output := generateOutput(t, ".go", input)
+
+	assert.Contains(t, output, `var ErrNotFound = newError(1, "not found").withHttpStatus(http.StatusNotFound)`)
+	assert.Contains(t, output, `var ErrConflict = newError(2, "conflict").withHttpStatus(409)`)
+	assert.Contains(t, output, `var ErrOther = newError(3, "other")`+"\n")
+}
+
+func TestGenerateGoMethodCache(t *testing.T) {
+	const input = `
+service HttpUserService {
+	GetName(id: string) => (name: string) {
+		HttpMethod = "GET"
+		Cache = true
+	}
+	Count() => (count: int64) {
+		HttpMethod = "GET"
+	}
+	Create(name: string) => (id: string)
+}
+`
+
+	output := generateOutput(t, ".go", input)
+
+	assert.Contains(t, output, "\t\"HttpUserService.GetName\": {},\n\t\"HttpUserService.Count\":   {},\n}")
+	assert.NotContains(t, output, `"HttpUserService.Create": {}`)
+	assert.Contains(t, output, "HttpMethod: http.MethodGet,\n\t\tCache:      true,\n\t}")
+	assert.Contains(t, output, "HttpMethod: http.MethodGet,\n\t}")
+	assert.Contains(t, output, "func WithCache(store CacheStore) HttpClientOpt {")
+}
+
+func TestGenerateGoModelExtends(t *testing.T) {
+	const input = `
+model User {
+	Id: string
+}
+
+model Admin {
+	...User
+	Role: string
+}
+`
+
+	output := generateOutput(t, ".go", input)
+
+	assert.Contains(t, output, "type Admin struct {\n\tId   string `json:\"id\"`\n\tRole string `json:\"role\"`\n}")
+}
+
+func TestGenerateGoEnumString(t *testing.T) {
+	const input = `
+enum Status {
+	_
+	Active
+	Enabled = 1
+	Deleted
+}
+`
+
+	output := generateOutput(t, ".go", input)
+
+	assert.Contains(t, output, "\tcase Status_Active:\n\t\treturn \"Active\"\n\tcase Status_Deleted:\n\t\treturn \"Deleted\"\n\tdefault:\n\t\treturn fmt.Sprintf(\"Status(%d)\", int8(e))")
+	assert.NotContains(t, output, "case Status_Enabled:")
+	assert.Contains(t, output, "func ParseStatus(name string) (Status, error) {")
+	assert.Contains(t, output, "\tcase \"Enabled\":\n\t\treturn Status_Enabled, nil")
+}
+
+func TestGenerateGoModelRequires(t *testing.T) {
+	const input = `
+enum Method {
+	_
+	Card
+}
+
+model Payment {
+	Method?: Method
+	Card?: string
+	Tags?: []string
+	Paid?: bool
+	requires(Card, Tags, when: Method)
+	requires(Card, when: Paid)
+}
+`
+
+	output := generateOutput(t, ".go", input)
+
+	assert.Contains(t, output, "func (m *Payment) Validate() error {")
+	assert.Contains(t, output, "\tif m.Method != 0 {\n\t\tif m.Card == \"\" {\n\t\t\treturn ErrValidation.WithMsg(\"Payment.Card is required when Method is set\")\n\t\t}\n\t\tif len(m.Tags) == 0 {")
+	assert.Contains(t, output, "\tif m.Paid {\n\t\tif m.Card == \"\" {")
+	assert.Contains(t, output, "var ErrValidation = ")
+	assert.Contains(t, output, "err = validateParams(a)")
+
+	output = generateOutput(t, ".go", `model User { Id: string }`)
+	assert.NotContains(t, output, "Validate() error")
+	assert.NotContains(t, output, "validateParams")
+}
+
+func TestGenerateGoFieldConstraints(t *testing.T) {
+	const input = `
+model User {
+	Name: string { Required = true Pattern = "^[a-z]+$" }
+	Age: int8 { Min = 18 Max = 99 }
+	Score?: float64 { Max = 0.5 }
+}
+`
+
+	output := generateOutput(t, ".go", input)
+
+	assert.Contains(t, output, "\t\"regexp\"\n")
+	assert.Contains(t, output, "var userNamePattern = regexp.MustCompile(\"^[a-z]+$\")")
+	assert.Contains(t, output, "\tif m.Name == \"\" {\n\t\treturn ErrValidation.WithMsg(\"User.Name is required\")\n\t}")
+	assert.Contains(t, output, "\tif !userNamePattern.MatchString(m.Name) {\n\t\treturn ErrValidation.WithMsg(\"User.Name should match ^[a-z]+$\")\n\t}")
+	assert.Contains(t, output, "\tif m.Age < 18 {\n\t\treturn ErrValidation.WithMsg(\"User.Age should be greater than or equal to 18\")\n\t}")
+	assert.Contains(t, output, "\tif m.Age > 99 {\n\t\treturn ErrValidation.WithMsg(\"User.Age should be less than or equal to 99\")\n\t}")
+	assert.Contains(t, output, "\tif m.Score != 0 && m.Score > 0.5 {\n")
+
+	output = generateOutput(t, ".go", `model User { Name: string }`)
+	assert.NotContains(t, output, "regexp")
+	assert.NotContains(t, output, "Validate")
+}
+
+func TestGenerateGoTimeFormat(t *testing.T) {
+	const input = `
+model Event {
+	At: timestamp { TimeFormat = "unixmilli" }
+	Seen?: timestamp { TimeFormat = "unix" }
+	Created: timestamp
+}
+`
+
+	output := generateOutput(t, ".go", input)
+
+	assert.Contains(t, output, "\tAt      time.Time `json:\"at\"`\n")
+	assert.Contains(t, output, "func (m Event) MarshalJSON() ([]byte, error) {")
+	assert.Contains(t, output, "\t\tAt   unixMilliTime `json:\"at\"`\n\t\tSeen unixTime      `json:\"seen,omitempty,omitzero\"`\n\t}{")
+	assert.Contains(t, output, "\t\tAt:    (*unixMilliTime)(&m.At),\n")
+	assert.Contains(t, output, "func (t *unixMilliTime) UnmarshalJSON(data []byte) error {")
+
+	output = generateOutput(t, ".go", `model Event { At: timestamp { TimeFormat = "rfc3339" } }`)
+	assert.NotContains(t, output, "func (m Event) MarshalJSON")
+	assert.NotContains(t, output, "unixTime")
+}
+
+func TestGenerateGoTypedUnits(t *testing.T) {
+	const input = `
+const MaxUpload = 10mb
+const Timeout = 90s
+const Name = "hexe"
+`
+
+	output := generateOutput(t, ".go", input, WithGoTypedUnits())
+
+	assert.Contains(t, output, "const MaxUpload ByteSize = 10485760\n")
+	assert.Contains(t, output, "const Timeout Duration = 90000000000\n")
+	assert.Contains(t, output, "const Name = \"hexe\"\n")
+	assert.Contains(t, output, "func (b ByteSize) String() string {")
+	assert.Contains(t, output, "func (d Duration) String() string {")
+
+	// only the types of the used units are generated
+	output = generateOutput(t, ".go", `const MaxUpload = 10mb`, WithGoTypedUnits())
+	assert.Contains(t, output, "type ByteSize int64")
+	assert.NotContains(t, output, "type Duration")
+
+	output = generateOutput(t, ".go", input)
+	assert.Contains(t, output, "const MaxUpload = 10485760\n")
+	assert.NotContains(t, output, "ByteSize")
+}
+
+func TestGenerateGoMethodLimits(t *testing.T) {
+	const input = `
+service HttpUserService {
+	Get(id: string) => (name: string) {
+		Timeout = 1500ms
+	}
+	Upload(files: stream []byte) => (count: int64) {
+		Timeout = 1m
+		MaxSize = 5mb
+	}
+	Create(name: string) => (id: string)
+}
+`
+
+	output := generateOutput(t, ".go", input)
+
+	assert.Contains(t, output, "\tregisterHandleWithLimits(r, handleLimits{timeout: 1500000000},\n\t\t\"HttpUserService.Get\",")
+	assert.Contains(t, output, "\tregisterHandleWithLimits(r, handleLimits{timeout: 60000000000, maxSize: 5242880},\n\t\t\"HttpUserService.Upload\",")
+	assert.Contains(t, output, "\tr.RegisterHandle(\n\t\t\"HttpUserService.Create\",")
+}
+
+func TestGenerateGoMethodRateLimit(t *testing.T) {
+	const input = `
+service HttpUserService {
+	Get(id: string) => (name: string) {
+		RateLimit = "100/s"
+	}
+	Create(name: string) => (id: string) {
+		RateLimit = "10/30s"
+	}
+	Delete(id: string)
+}
+`
+
+	output := generateOutput(t, ".go", input)
+
+	assert.Contains(t, output, "var httpRateLimits = map[string]RateLimit{\n\t\"HttpUserService.Get\":    {Count: 100, Window: 1000000000},\n\t\"HttpUserService.Create\": {Count: 10, Window: 30000000000},\n}")
+	assert.Contains(t, output, "func WithRateLimiter(limiter RateLimiter) HttpHandlerOpt {")
+	assert.Contains(t, output, "\t\tlimiter: NewMemoryRateLimiter(),\n")
+
+	output = generateOutput(t, ".go", `service HttpUserService { Get(id: string) => (name: string) }`)
+	assert.Contains(t, output, "func NewHttpHandler(srv Handler, opts ...HttpHandlerOpt) http.Handler {")
+	assert.NotContains(t, output, "httpRateLimits")
+	assert.NotContains(t, output, "WithRateLimiter")
+}
+
+func TestGenerateGoMethodMsgPack(t *testing.T) {
+	const input = `
+service HttpUserService {
+	Get(id: string) => (name: string) {
+		MsgPack = true
+	}
+	Create(name: string) => (id: string)
+}
+`
+
+	output := generateOutput(t, ".go", input)
+
+	assert.Contains(t, output, "\t\"github.com/hexe-dev/hexe/msgpack\"\n")
+	assert.Contains(t, output, "var httpMsgPackMethods = map[string]struct{}{\n\t\"HttpUserService.Get\": {},\n}")
+	assert.Contains(t, output, "\t\tContentType: \"application/json\",\n\t\tMsgPack:     true,\n")
+	assert.Contains(t, output, "func RegisterCodec(codec Codec) {")
+
+	output = generateOutput(t, ".go", `service HttpUserService { Get(id: string) => (name: string) }`)
+	assert.NotContains(t, output, "msgpack")
+	assert.NotContains(t, output, "Codec")
+}
+
+func TestGenerateGoComments(t *testing.T) {
+	const input = `
+# Version of the api
+const Version = "1.0.0"
+
+# Role of a user
+#
+# with a second paragraph
+enum Role {
+	# can do anything
+	Admin
+	Member
+	# more roles later
+}
+
+# User is a user
+model User {
+	# unique id
+	Id: string
+	Name: string
+	# bottom of user
+}
+
+# manages users
+service HttpUserService {
+	# returns a user
+	Get(id: string) => (user: User)
+}
+
+# returned when missing
+error ErrNotFound { Msg = "not found" }
+`
+
+	output := generateOutput(t, ".go", input)
+
+	assert.Contains(t, output, "// Version of the api\nconst Version = \"1.0.0\"")
+	assert.Contains(t, output, "// Role of a user\n//\n// with a second paragraph\ntype Role int8")
+	assert.Contains(t, output, "\t// can do anything\n\tRole_Admin  Role = 0\n\tRole_Member Role = 1\n\t// more roles later\n)")
+	assert.Contains(t, output, "// User is a user\ntype User struct {\n\t// unique id\n\tId   string `json:\"id\"`\n\tName string `json:\"name\"`\n\t// bottom of user\n}")
+	assert.Contains(t, output, "// manages users\ntype HttpUserService interface {\n\t// returns a user\n\tGet(")
+	assert.Contains(t, output, "// returned when missing\nvar ErrNotFound = ")
+}
+
+func TestGenerateGoDeprecated(t *testing.T) {
+	const input = `
+model User {
+	# user's name
+	Name: string { Deprecated = "use FullName instead" }
+	Nickname: string { Deprecated }
+	FullName: string
+}
+
+service HttpUserService {
+	GetByName(name: string) => (user: User) { Deprecated = "use GetById instead" }
+	GetById(id: string) => (user: User)
+}
+`
+
+	output := generateOutput(t, ".go", input)
+
+	assert.Contains(t, output, "\t// user's name\n\t//\n\t// Deprecated: use FullName instead\n\tName ")
+	assert.Contains(t, output, "\t// Deprecated: it will be removed in a future version\n\tNickname ")
+	assert.Contains(t, output, "\t// Deprecated: use GetById instead\n\tGetByName(")
+	assert.Contains(t, output, "// Deprecated: use GetById instead\nfunc (s *httpUserServiceClient) GetByName(")
+	assert.NotContains(t, output, "// Deprecated: use GetById instead\nfunc (s *httpUserServiceClient) GetById(")
+}
+
+func TestGenerateGoMapValues(t *testing.T) {
+	const input = `
+model Flags {
+	Enabled: map<string, bool>
+	UpdatedAt: map<string, timestamp>
+	Extra?: map<string, any>
+	Values: []bool
+}
+`
+
+	output := generateOutput(t, ".go", input)
+
+	assert.Contains(t, output, "Enabled   map[string]bool      `json:\"enabled\"`")
+	assert.Contains(t, output, "UpdatedAt map[string]time.Time `json:\"updatedAt\"`")
+	assert.Contains(t, output, "Extra     map[string]any       `json:\"extra,omitempty,omitzero\"`")
+	assert.Contains(t, output, "Values    []bool               `json:\"values\"`")
+}
+
+func TestGenerateGoBidirectionalStream(t *testing.T) {
+	const input = `
+model Message {
+	Text: string
+}
+
+service HttpChatService {
+	Chat(room: string, msgs: stream Message) => (replies: stream Message)
+}
+`
+
+	output := generateOutput(t, ".go", input)
+
+	assert.Contains(t, output, "Chat(ctx context.Context, room string, msgs <-chan *Message) (replies <-chan *Message, errs <-chan error)")
+	assert.Contains(t, output, "handleStreamToSSE(")
+	assert.Contains(t, output, `ContentType: "application/x-ndjson",`)
+	assert.Contains(t, output, "EnableFullDuplex()")
+
+	output = generateOutput(t, ".ts", input)
+
+	assert.NotContains(t, output, "chat(")
+}
+
+func TestGenerateGoConstLiteral(t *testing.T) {
+	const input = `
+model Address {
+	City: string
+}
+
+model User {
+	Name: string
+	Age: uint8
+	Tags: []string
+	Roles: set<string>
+	Address: Address
+	Addresses: []Address
+}
+
+const DefaultUser: User = {
+	Name = "anon"
+	Age = 0
+	Tags = ["a", "b"]
+	Roles = ["admin"]
+	Address = { City = "Toronto" }
+	Addresses = [{ City = "Paris" }]
+}
+
+const Cities: []string = ["Tokyo", 'Paris']
+`
+
+	output := generateOutput(t, ".go", input)
+
+	assert.Contains(t, output, `var DefaultUser = User{Name: "anon", Age: 0, Tags: []string{"a", "b"}, Roles: Set[string]{"admin": {}}, Address: &Address{City: "Toronto"}, Addresses: []*Address{&Address{City: "Paris"}}}`)
+	assert.Contains(t, output, `var Cities = []string{"Tokyo", "Paris"}`)
+}
+
+func TestGenerateGoVersion(t *testing.T) {
+	const input = `
+model Event {
+	Name?: string
+	At?: timestamp { TimeFormat = "unix" }
+}
+`
+
+	output := generateOutput(t, ".go", input)
+	assert.Contains(t, output, "`json:\"name,omitempty,omitzero\"`")
+
+	// omitzero is added in go1.24
+	output = generateOutput(t, ".go", input, WithGoVersion(23))
+	assert.NotContains(t, output, "omitzero")
+	assert.Contains(t, output, "`json:\"name,omitempty\"`")
+	assert.Contains(t, output, "`json:\"at,omitempty\"`")
+
+	output = generateOutput(t, ".go", input, WithGoVersion(24))
+	assert.Contains(t, output, "`json:\"name,omitempty,omitzero\"`")
+}
+
+func TestGenerateGoStreamSeq(t *testing.T) {
+	const input = `
+service HttpEventService {
+	GetValues(limit: int32) => (values: stream string)
+	GetName() => (name: string)
+}
+`
+
+	output := generateOutput(t, ".go", input)
+	assert.Contains(t, output, "func (s *httpEventServiceClient) GetValuesSeq(ctx context.Context, limit int32) iter.Seq2[string, error] {")
+	assert.Contains(t, output, "\t\tresults, errs := s.GetValues(ctx, limit)\n")
+	assert.Contains(t, output, "\t\"iter\"\n")
+	assert.NotContains(t, output, "GetNameSeq")
+
+	// range over func is added in go1.23
+	output = generateOutput(t, ".go", input, WithGoVersion(22))
+	assert.NotContains(t, output, "GetValuesSeq")
+	assert.NotContains(t, output, "\"iter\"")
+	assert.NotContains(t, output, "yieldResults")
+
+	output = generateOutput(t, ".go", `service HttpUserService { Get(id: string) => (name: string) }`)
+	assert.NotContains(t, output, "\"iter\"")
+}
+
+func TestGenerateGoConfig(t *testing.T) {
+	const input = `
+const MaxUpload = 10mb
+const Timeout = 30s
+const AppName = "hexe"
+const Debug = false
+const Cities: []string = ["Tokyo"]
+`
+
+	output := generateOutput(t, ".go", input, WithGoConfig())
+	assert.Contains(t, output, "\tMaxUpload int64         // MAX_UPLOAD\n")
+	assert.Contains(t, output, "\tTimeout   time.Duration // TIMEOUT\n")
+	assert.Contains(t, output, "func LoadConfig() (*Config, error) {")
+	assert.NotContains(t, output, "Cities:")
+
+	output = generateOutput(t, ".go", input)
+	assert.NotContains(t, output, "LoadConfig")
+	assert.NotContains(t, output, "\t\"os\"\n")
+
+	if testing.Short() {
+		t.Skip("skipping building the generated code in short mode")
+	}
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go is not installed")
+	}
+
+	// the generated code imports the sse package, so it's built inside the module
+	dir, err := os.MkdirTemp(".", "config")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	const test = `package test
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoadConfig(t *testing.T) {
+	t.Setenv("MAX_UPLOAD", "20mb")
+	t.Setenv("TIMEOUT", "1m")
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.MaxUpload != 20<<20 || cfg.Timeout != time.Minute || cfg.AppName != "hexe" {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+
+	t.Setenv("DEBUG", "maybe")
+	if _, err := LoadConfig(); err == nil {
+		t.Fatal("expected an error of the invalid DEBUG")
+	}
+}
+`
+
+	output = generateOutput(t, ".go", input, WithGoConfig())
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "output.go"), []byte(output), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "output_test.go"), []byte(test), 0o644))
+
+	cmd := exec.Command(goBin, "test", "./"+filepath.Base(dir))
+	result, err := cmd.CombinedOutput()
+	assert.NoError(t, err, string(result))
+}
+
+func TestGenerateGoRecursiveModel(t *testing.T) {
+	const input = `
+model TreeNode {
+	Name: string
+	Parent?: TreeNode
+	Root: TreeNode
+	Children: []TreeNode
+	ByName: map<string, TreeNode>
+	oneof Link {
+		Next: TreeNode
+		Url: string
+	}
+}
+`
+
+	output := generateOutput(t, ".go", input)
+
+	assert.Contains(t, output, "\tParent   *TreeNode            `json:\"parent,omitempty,omitzero\"`\n")
+	assert.Contains(t, output, "\tRoot     *TreeNode            `json:\"root\"`\n")
+	assert.Contains(t, output, "\tChildren []*TreeNode          `json:\"children\"`\n")
+	assert.Contains(t, output, "\tByName   map[string]*TreeNode `json:\"byName\"`\n")
+	assert.Contains(t, output, "type TreeNodeLinkNext struct {\n\tValue *TreeNode\n}")
+}
+
+func TestGenerateGoJsonString(t *testing.T) {
+	const input = `
+model Account {
+	Id: int64 { JsonString }
+	Balance?: uint64 { JsonString }
+	Hidden: int64 { JsonString Json = false }
+	Count: int64
+}
+`
+
+	output := generateOutput(t, ".go", input)
+
+	assert.Contains(t, output, "`json:\"id,string\"`")
+	assert.Contains(t, output, "`json:\"balance,omitempty,omitzero,string\"`")
+	assert.Contains(t, output, "`json:\"-\"`")
+	assert.Contains(t, output, "`json:\"count\"`")
+}
+
+func TestGenerateGoPresence(t *testing.T) {
+	const input = `
+model User {
+	Id: string
+	Age?: int32 { Min = 18 }
+	Seen?: timestamp { TimeFormat = "unix" }
+	Balance?: int64 { JsonString = true }
+}
+
+model Address {
+	City: string
+	Zip?: int32
+}
+
+model Profile {
+	Home: Address { Required = true }
+	Work?: Address
+	Parent?: Profile
+	Past: []Address
+}
+`
+
+	output := generateOutput(t, ".go", input, WithGoPresence())
+	assert.Contains(t, output, "\n\tpresent [1]uint64\n}")
+	assert.Contains(t, output, "func (m *User) HasAge() bool {\n\treturn m != nil && (m.present[0]&(1<<0) != 0 || m.Age != 0)\n}")
+	assert.Contains(t, output, "func (m *User) SetSeen(v time.Time) {\n\tm.Seen = v\n\tm.present[0] |= 1 << 1\n}")
+	assert.Contains(t, output, "\tif m.HasAge() && m.Age < 18 {\n")
+	assert.Contains(t, output, "\t\tBalance *int64    `json:\"balance,omitempty,string\"`\n")
+
+	// the models are values, except the recursive ones which stay pointers
+	assert.Contains(t, output, "\tHome   Address   `json:\"home\"`\n")
+	assert.Contains(t, output, "\tParent *Profile  `json:\"parent,omitempty,omitzero\"`\n")
+	assert.Contains(t, output, "\tPast   []Address `json:\"past\"`\n")
+	assert.Contains(t, output, "func (m *Profile) HasWork() bool {\n\treturn m != nil && (m.present[0]&(1<<0) != 0 || !m.Work.isZero())\n}")
+	assert.Contains(t, output, "\tif m.Home.isZero() {\n")
+
+	output = generateOutput(t, ".go", input)
+	assert.NotContains(t, output, "present")
+	assert.NotContains(t, output, "HasAge")
+
+	if testing.Short() {
+		t.Skip("skipping building the generated code in short mode")
+	}
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go is not installed")
+	}
+
+	// the generated code imports the sse package, so it's built inside the module
+	dir, err := os.MkdirTemp(".", "presence")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	const test = `package test
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestPresence(t *testing.T) {
+	var user User
+	user.Id = "1"
+	user.SetAge(0)
+
+	data, err := json.Marshal(&user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != ` + "`" + `{"id":"1","age":0}` + "`" + ` {
+		t.Fatalf("unexpected json: %s", data)
+	}
+
+	var decoded User
+	if err := json.Unmarshal([]byte(` + "`" + `{"id":"1","age":0,"seen":0,"balance":"0"}` + "`" + `), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !decoded.HasAge() || !decoded.HasSeen() || !decoded.HasBalance() {
+		t.Fatalf("expected the decoded fields to be present: %+v", decoded)
+	}
+
+	data, err = json.Marshal(&decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != ` + "`" + `{"id":"1","age":0,"seen":0,"balance":"0"}` + "`" + ` {
+		t.Fatalf("unexpected json: %s", data)
+	}
+
+	decoded = User{}
+	if err := json.Unmarshal([]byte(` + "`" + `{"id":"1"}` + "`" + `), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.HasAge() || decoded.HasSeen() || decoded.HasBalance() {
+		t.Fatalf("expected the absent fields not to be present: %+v", decoded)
+	}
+}
+
+func TestPresenceFlatModels(t *testing.T) {
+	var profile Profile
+	if profile.Validate() == nil {
+		t.Fatal("expected the zero Home to be invalid")
+	}
+
+	profile.Home.SetZip(0)
+	profile.SetWork(Address{})
+	if err := profile.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := json.Marshal(&profile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != ` + "`" + `{"home":{"city":"","zip":0},"past":null,"work":{"city":""}}` + "`" + ` {
+		t.Fatalf("unexpected json: %s", data)
+	}
+
+	var decoded Profile
+	if err := json.Unmarshal([]byte(` + "`" + `{"home":{"city":"a"},"parent":{"home":{"city":"b"}},"past":[{"city":"c","zip":0}]}` + "`" + `), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.HasWork() || !decoded.HasParent() || decoded.Parent.Home.City != "b" || !decoded.Past[0].HasZip() {
+		t.Fatalf("unexpected decoded profile: %+v", decoded)
+	}
+}
+`
+
+	output = generateOutput(t, ".go", input, WithGoPresence())
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "output.go"), []byte(output), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "output_test.go"), []byte(test), 0o644))
+
+	cmd := exec.Command(goBin, "test", "./"+filepath.Base(dir))
+	result, err := cmd.CombinedOutput()
+	assert.NoError(t, err, string(result))
+}
+
+func TestGenerateGoPresenceNames(t *testing.T) {
+	const input = `
+model User {
+	Name?: string
+	HasName: bool
+}
+`
+
+	doc, err := parser.ParseDocument(parser.NewParser(input))
+	require.NoError(t, err)
+	require.NoError(t, parser.Validate(doc))
+
+	// the accessors are only generated in the presence mode
+	assert.NoError(t, GenerateTo(&bytes.Buffer{}, TargetGo, "test", []*ast.Document{doc}))
+
+	err = GenerateTo(&bytes.Buffer{}, TargetGo, "test", []*ast.Document{doc}, WithGoPresence())
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "User.HasName is the same as the HasName method of the optional Name field")
+	}
+}
+
+func TestGenerateGoCli(t *testing.T) {
+	const input = `
+enum Status {
+	Active
+	Banned
+}
+
+model User {
+	Id: string
+	Status: Status
+}
+
+service HttpUserService {
+	# GetById returns the user
+	GetById (userId: string, limit: int32, status: Status, filter: User) => (user: User, total: int64)
+	Watch (userId: string) => (user: stream User)
+	Upload (files: stream []byte) => (ok: bool)
+	Notify (message: string)
+	Count () => (total: int64)
+}
+
+service RpcUserService {
+	Ping () => ()
+}
+`
+
+	output := generateOutput(t, ".cli.go", input)
+	assert.Contains(t, output, "package main")
+	assert.Contains(t, output, "\t\tService: \"user-service\",\n\t\tName:    \"get-by-id\",\n\t\tUsage:   \"GetById returns the user\",\n")
+	assert.Contains(t, output, "\t\t\tcliFlag(fs, \"user-id\", \"string\", &params.UserId)\n")
+	assert.Contains(t, output, "\t\t\tcliFlag(fs, \"limit\", \"int32\", &params.Limit)\n")
+	assert.Contains(t, output, "\t\t\tcliFlag(fs, \"status\", \"Status\", &params.Status)\n")
+	assert.Contains(t, output, "\t\t\tcliFlag(fs, \"filter\", \"json of User\", &params.Filter)\n")
+	assert.Contains(t, output, "r0, r1, err := client.GetById(ctx, params.UserId, params.Limit, params.Status, params.Filter)")
+	assert.Contains(t, output, "\t\tName:    \"watch\",\n")
+	// the methods without results or arguments, which are compiled below
+	assert.Contains(t, output, "return client.Notify(ctx, params.Message)")
+	assert.Contains(t, output, "r0, err := client.Count(ctx)")
+	// the uploads and the rpc services are not commands
+	assert.NotContains(t, output, "\"upload\"")
+	assert.NotContains(t, output, "\"ping\"")
+
+	output = generateOutput(t, ".go", input)
+	assert.NotContains(t, output, "cliCommands")
+	assert.NotContains(t, output, "func main()")
+
+	if testing.Short() {
+		t.Skip("skipping building the generated code in short mode")
+	}
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go is not installed")
+	}
+
+	// the generated code imports the sse package, so it's built inside the module
+	dir, err := os.MkdirTemp(".", "cli")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	const test = `package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"testing"
+)
+
+type userService struct{}
+
+func (s *userService) GetById(ctx context.Context, userId string, limit int32, status Status, filter *User) (*User, int64, error) {
+	return &User{Id: userId + filter.Id, Status: status}, int64(limit), nil
+}
+
+func (s *userService) Watch(ctx context.Context, userId string) (<-chan *User, <-chan error) {
+	users := make(chan *User, 2)
+	users <- &User{Id: userId + "1"}
+	users <- &User{Id: userId + "2"}
+	close(users)
+
+	// the stream ends once users is closed
+	return users, make(chan error)
+}
+
+func (s *userService) Upload(ctx context.Context, files func() (string, io.Reader, error)) (bool, error) {
+	return true, nil
+}
+
+func (s *userService) Notify(ctx context.Context, message string) error {
+	if message == "" {
+		return errors.New("empty message")
+	}
+	return nil
+}
+
+func (s *userService) Count(ctx context.Context) (int64, error) {
+	return 7, nil
+}
+
+func TestCli(t *testing.T) {
+	registry := NewMemoryHandleRegistry()
+	RegisterHttpUserServiceServer(registry, &userService{})
+
+	server := httptest.NewServer(NewHttpHandler(registry))
+	defer server.Close()
+
+	var stdout, stderr bytes.Buffer
+	err := runCli(context.Background(), &stdout, &stderr, []string{
+		"--endpoint", server.URL, "user-service", "get-by-id",
+		"--user-id", "a", "--limit", "3", "--status", "banned", "--filter", ` + "`" + `{"id":"b"}` + "`" + `,
+	})
+	if err != nil {
+		t.Fatal(err, stderr.String())
+	}
+
+	expected := "{\n  \"user\": {\n    \"id\": \"ab\",\n    \"status\": \"banned\"\n  },\n  \"total\": 3\n}\n"
+	if stdout.String() != expected {
+		t.Fatalf("unexpected output: %s", stdout.String())
+	}
+
+	stdout.Reset()
+	err = runCli(context.Background(), &stdout, &stderr, []string{"--endpoint", server.URL, "user-service", "watch", "--user-id", "a"})
+	if err != nil {
+		t.Fatal(err, stderr.String())
+	}
+
+	expected = "{\"id\":\"a1\",\"status\":\"active\"}\n{\"id\":\"a2\",\"status\":\"active\"}\n"
+	if stdout.String() != expected {
+		t.Fatalf("unexpected output: %s", stdout.String())
+	}
+
+	stdout.Reset()
+	err = runCli(context.Background(), &stdout, &stderr, []string{"--endpoint", server.URL, "user-service", "notify", "--message", "hi"})
+	if err != nil || stdout.Len() != 0 {
+		t.Fatal(err, stdout.String(), stderr.String())
+	}
+
+	if err := runCli(context.Background(), &stdout, &stderr, []string{"--endpoint", server.URL, "user-service", "notify"}); err == nil {
+		t.Fatal("expected the error of the empty message")
+	}
+
+	err = runCli(context.Background(), &stdout, &stderr, []string{"--endpoint", server.URL, "user-service", "count"})
+	if err != nil {
+		t.Fatal(err, stderr.String())
+	}
+
+	if expected := "{\n  \"total\": 7\n}\n"; stdout.String() != expected {
+		t.Fatalf("unexpected output: %s", stdout.String())
+	}
+
+	if err := runCli(context.Background(), &stdout, &stderr, []string{"--endpoint", server.URL, "user-service", "upload"}); err == nil {
+		t.Fatal("expected an error of the unknown command")
+	}
+}
+`
+
+	output = generateOutput(t, ".cli.go", input)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "output.cli.go"), []byte(output), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "output_test.go"), []byte(test), 0o644))
+
+	cmd := exec.Command(goBin, "test", "./"+filepath.Base(dir))
+	result, err := cmd.CombinedOutput()
+	assert.NoError(t, err, string(result))
+}
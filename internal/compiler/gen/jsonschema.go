@@ -0,0 +1,248 @@
+package gen
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/hexe-dev/hexe/internal/compiler/ast"
+	"github.com/hexe-dev/hexe/internal/strcase"
+)
+
+type jsonSchemaDocument struct {
+	Schema string                 `json:"$schema"`
+	Title  string                 `json:"title"`
+	Defs   map[string]*jsonSchema `json:"$defs"`
+}
+
+type jsonSchema struct {
+	Ref                  string                 `json:"$ref,omitempty"`
+	AnyOf                []*jsonSchema          `json:"anyOf,omitempty"`
+	OneOf                []*jsonSchema          `json:"oneOf,omitempty"`
+	Type                 any                    `json:"type,omitempty"` // either a string or a list of strings
+	Format               string                 `json:"format,omitempty"`
+	ContentEncoding      string                 `json:"contentEncoding,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Const                any                    `json:"const,omitempty"`
+	Enum                 []any                  `json:"enum,omitempty"`
+	Pattern              string                 `json:"pattern,omitempty"`
+	Minimum              any                    `json:"minimum,omitempty"`
+	Maximum              any                    `json:"maximum,omitempty"`
+	Items                *jsonSchema            `json:"items,omitempty"`
+	UniqueItems          bool                   `json:"uniqueItems,omitempty"`
+	Properties           map[string]*jsonSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	AdditionalProperties *jsonSchema            `json:"additionalProperties,omitempty"`
+}
+
+// generateJsonSchema writes a JSON Schema (draft 2020-12) document, the models and enums are
+// defined in $defs, so each one can be referenced by #/$defs/<Name> to validate the json
+// payloads the same as the generated Go code decodes them
+func generateJsonSchema(out io.Writer, pkg string, doc *ast.Document) error {
+	isModelType := createIsModelTypeFunc(doc.Models)
+
+	spec := &jsonSchemaDocument{
+		Schema: "https://json-schema.org/draft/2020-12/schema",
+		Title:  pkg,
+		Defs:   make(map[string]*jsonSchema),
+	}
+
+	for _, enum := range doc.Enums {
+		spec.Defs[enum.Name.Token.Value] = getJsonSchemaEnum(enum)
+	}
+
+	for _, model := range doc.Models {
+		spec.Defs[model.Name.Token.Value] = getJsonSchemaModel(model, isModelType)
+	}
+
+	enc := json.NewEncoder(out)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(spec)
+}
+
+func getJsonSchemaEnum(enum *ast.Enum) *jsonSchema {
+	schema := &jsonSchema{
+		Description: strings.Join(getCommentLines(enum.Comments, ast.CommentTop), "\n"),
+	}
+
+	jsonNumber := isEnumJsonNumber(enum)
+	if jsonNumber {
+		schema.Type = "integer"
+	} else {
+		schema.Type = "string"
+	}
+
+	for _, set := range enum.Sets {
+		// the zero value can't be encoded
+		if set.Name.Token.Value == "_" {
+			continue
+		}
+
+		if jsonNumber {
+			if !slices.Contains(schema.Enum, any(set.Value.Value)) {
+				schema.Enum = append(schema.Enum, set.Value.Value)
+			}
+		} else {
+			schema.Enum = append(schema.Enum, strcase.ToSnake(set.Name.Token.Value))
+		}
+	}
+
+	return schema
+}
+
+func getJsonSchemaModel(model *ast.Model, isModelType func(string) bool) *jsonSchema {
+	schema := &jsonSchema{
+		Type:        "object",
+		Description: strings.Join(getCommentLines(model.Comments, ast.CommentTop), "\n"),
+		Properties:  make(map[string]*jsonSchema),
+	}
+
+	for _, field := range model.Fields {
+		// the json name and omitempty are the same as the generated go struct's tag
+		tag := strings.TrimSuffix(strings.TrimPrefix(getGolangModelFieldTag(field, true), `json:"`), `"`)
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "-" {
+			continue
+		}
+
+		fieldSchema := getJsonSchemaType(field.Type)
+		if getFieldTimeFormat(field) != "rfc3339" {
+			// unix and unixmilli timestamps are encoded as numbers
+			fieldSchema = &jsonSchema{Type: "integer"}
+		} else if isFieldJsonString(field) {
+			// JsonString numbers are encoded as strings
+			fieldSchema = &jsonSchema{Type: "string"}
+		}
+
+		applyJsonSchemaFieldOptions(fieldSchema, field)
+
+		if !field.IsOptional {
+			fieldSchema = getJsonSchemaNullable(fieldSchema, field.Type, isModelType)
+		}
+
+		if description := strings.Join(getCommentLines(field.Comments, ast.CommentTop), "\n"); description != "" {
+			if fieldSchema.Ref != "" {
+				fieldSchema = &jsonSchema{AnyOf: []*jsonSchema{fieldSchema}}
+			}
+			fieldSchema.Description = description
+		}
+
+		schema.Properties[name] = fieldSchema
+
+		if opts == "" {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+
+	for _, oneOf := range model.OneOfs {
+		variants := make([]*jsonSchema, 0, len(oneOf.Variants))
+
+		for _, variant := range oneOf.Variants {
+			variants = append(variants, &jsonSchema{
+				Type: "object",
+				Properties: map[string]*jsonSchema{
+					"type":  {Const: strcase.ToSnake(variant.Name.Token.Value)},
+					"value": getJsonSchemaType(variant.Type),
+				},
+				Required: []string{"type", "value"},
+			})
+		}
+
+		schema.Properties[strcase.ToCamel(oneOf.Name.Token.Value)] = &jsonSchema{OneOf: variants}
+	}
+
+	return schema
+}
+
+// applyJsonSchemaFieldOptions sets the constraints of the field's options, Pattern
+// is a regex for strings and Min and Max are the inclusive range of numbers. The
+// options' types are already checked by the validation
+func applyJsonSchemaFieldOptions(schema *jsonSchema, field *ast.Field) {
+	for _, opt := range field.Options.List {
+		switch opt.Name.Token.Value {
+		case "Pattern":
+			if v, ok := opt.Value.(*ast.ValueString); ok {
+				schema.Pattern = v.Value
+			}
+		case "Min", "Max":
+			var value any
+			switch v := opt.Value.(type) {
+			case *ast.ValueInt:
+				value = v.Value
+			case *ast.ValueUint:
+				value = v.Value
+			case *ast.ValueFloat:
+				value = v.Value
+			}
+
+			if opt.Name.Token.Value == "Min" {
+				schema.Minimum = value
+			} else {
+				schema.Maximum = value
+			}
+		}
+	}
+}
+
+// getJsonSchemaNullable returns the schema of non optional values, arrays, sets,
+// maps and models are encoded as null by the generated go code when they are not set
+func getJsonSchemaNullable(schema *jsonSchema, typ ast.Type, isModelType func(string) bool) *jsonSchema {
+	switch t := typ.(type) {
+	case *ast.Array:
+		// []byte is encoded as base64 string
+		if _, ok := t.Type.(*ast.Byte); !ok {
+			schema.Type = []string{"array", "null"}
+		}
+	case *ast.Set:
+		schema.Type = []string{"array", "null"}
+	case *ast.Map:
+		schema.Type = []string{"object", "null"}
+	case *ast.CustomType:
+		if isModelType(t.Token.Value) {
+			schema = &jsonSchema{AnyOf: []*jsonSchema{schema, {Type: "null"}}}
+		}
+	}
+
+	return schema
+}
+
+func getJsonSchemaType(typ ast.Type) *jsonSchema {
+	switch t := typ.(type) {
+	case *ast.Bool:
+		return &jsonSchema{Type: "boolean"}
+	case *ast.Int:
+		return &jsonSchema{Type: "integer"}
+	case *ast.Uint:
+		return &jsonSchema{Type: "integer", Minimum: 0}
+	case *ast.Byte:
+		return &jsonSchema{Type: "integer", Minimum: 0, Maximum: 255}
+	case *ast.Float:
+		return &jsonSchema{Type: "number"}
+	case *ast.String:
+		return &jsonSchema{Type: "string"}
+	case *ast.Timestamp:
+		return &jsonSchema{Type: "string", Format: "date-time"}
+	case *ast.Any:
+		return &jsonSchema{}
+	case *ast.Array:
+		// []byte is encoded as base64 string the same as encoding/json
+		if _, ok := t.Type.(*ast.Byte); ok {
+			return &jsonSchema{Type: "string", ContentEncoding: "base64"}
+		}
+		return &jsonSchema{Type: "array", Items: getJsonSchemaType(t.Type)}
+	case *ast.Set:
+		// sets are encoded as json arrays
+		return &jsonSchema{Type: "array", Items: getJsonSchemaType(t.Type), UniqueItems: true}
+	case *ast.Map:
+		// json object keys are always strings
+		return &jsonSchema{Type: "object", AdditionalProperties: getJsonSchemaType(t.Value)}
+	case *ast.CustomType:
+		return &jsonSchema{Ref: "#/$defs/" + t.Token.Value}
+	default:
+		panic(fmt.Errorf("unknown type: %T", t))
+	}
+}
@@ -0,0 +1,93 @@
+package gen
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/hexe-dev/hexe/internal/compiler/ast"
+)
+
+// Inventory is the names of the declarations, in the order they are defined
+type Inventory struct {
+	Consts   []string           `json:"consts"`
+	Enums    []string           `json:"enums"`
+	Models   []string           `json:"models"`
+	Services []InventoryService `json:"services"`
+	Errors   []string           `json:"errors"`
+}
+
+type InventoryService struct {
+	Name    string `json:"name"`
+	Type    string `json:"type"` // http or rpc
+	Methods int    `json:"methods"`
+}
+
+// NewInventory returns the declarations of all the docs, docs are expected to be validated
+func NewInventory(docs []*ast.Document) *Inventory {
+	mainDoc := mergeDocuments(docs)
+
+	// the slices are never nil, so they are encoded as empty arrays in JSON
+	inv := &Inventory{
+		Consts:   make([]string, 0, len(mainDoc.Consts)),
+		Enums:    make([]string, 0, len(mainDoc.Enums)),
+		Models:   make([]string, 0, len(mainDoc.Models)),
+		Services: make([]InventoryService, 0, len(mainDoc.Services)),
+		Errors:   make([]string, 0, len(mainDoc.Errors)),
+	}
+
+	for _, c := range mainDoc.Consts {
+		inv.Consts = append(inv.Consts, c.Identifier.Token.Value)
+	}
+
+	for _, enum := range mainDoc.Enums {
+		inv.Enums = append(inv.Enums, enum.Name.Token.Value)
+	}
+
+	for _, model := range mainDoc.Models {
+		inv.Models = append(inv.Models, model.Name.Token.Value)
+	}
+
+	for _, service := range mainDoc.Services {
+		inv.Services = append(inv.Services, InventoryService{
+			Name:    service.Name.Token.Value,
+			Type:    service.Type.String(),
+			Methods: len(service.Methods),
+		})
+	}
+
+	for _, customError := range mainDoc.Errors {
+		inv.Errors = append(inv.Errors, customError.Name.Token.Value)
+	}
+
+	return inv
+}
+
+// List writes the inventory of the docs into w, one line per kind of declaration
+// with its count and names, or as indented JSON if asJSON is set
+func List(w io.Writer, docs []*ast.Document, asJSON bool) error {
+	inv := NewInventory(docs)
+
+	if asJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(inv)
+	}
+
+	services := make([]string, 0, len(inv.Services))
+	for _, service := range inv.Services {
+		services = append(services, fmt.Sprintf("%s (%s, %d methods)", service.Name, service.Type, service.Methods))
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "consts\t%d\t%s\n", len(inv.Consts), strings.Join(inv.Consts, ", "))
+	fmt.Fprintf(tw, "enums\t%d\t%s\n", len(inv.Enums), strings.Join(inv.Enums, ", "))
+	fmt.Fprintf(tw, "models\t%d\t%s\n", len(inv.Models), strings.Join(inv.Models, ", "))
+	fmt.Fprintf(tw, "services\t%d\t%s\n", len(inv.Services), strings.Join(services, ", "))
+	fmt.Fprintf(tw, "errors\t%d\t%s\n", len(inv.Errors), strings.Join(inv.Errors, ", "))
+
+	return tw.Flush()
+}
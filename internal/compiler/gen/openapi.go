@@ -0,0 +1,597 @@
+package gen
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/hexe-dev/hexe/internal/compiler/ast"
+	"github.com/hexe-dev/hexe/internal/strcase"
+)
+
+type openapiDocument struct {
+	OpenAPI    string                      `json:"openapi"`
+	Info       openapiInfo                 `json:"info"`
+	Paths      map[string]*openapiPathItem `json:"paths"`
+	Components openapiComponents           `json:"components"`
+}
+
+type openapiInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type openapiComponents struct {
+	Schemas map[string]*openapiSchema `json:"schemas"`
+}
+
+type openapiPathItem struct {
+	Get  *openapiOperation `json:"get,omitempty"`
+	Post *openapiOperation `json:"post,omitempty"`
+}
+
+type openapiOperation struct {
+	OperationId string                      `json:"operationId"`
+	Description string                      `json:"description,omitempty"`
+	Tags        []string                    `json:"tags"`
+	Parameters  []*openapiParameter         `json:"parameters,omitempty"`
+	RequestBody *openapiRequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*openapiResponse `json:"responses"`
+	Since       string                      `json:"x-since,omitempty"` // the api version which added the method
+	Until       string                      `json:"x-until,omitempty"` // the api version which removes the method
+}
+
+type openapiParameter struct {
+	Name     string                       `json:"name"`
+	In       string                       `json:"in"`
+	Required bool                         `json:"required,omitempty"`
+	Content  map[string]*openapiMediaType `json:"content"`
+}
+
+type openapiRequestBody struct {
+	Required bool                         `json:"required"`
+	Content  map[string]*openapiMediaType `json:"content"`
+}
+
+type openapiResponse struct {
+	Description string                       `json:"description"`
+	Content     map[string]*openapiMediaType `json:"content,omitempty"`
+}
+
+type openapiMediaType struct {
+	Schema   *openapiSchema              `json:"schema"`
+	Encoding map[string]*openapiEncoding `json:"encoding,omitempty"`
+}
+
+type openapiEncoding struct {
+	ContentType string `json:"contentType"`
+}
+
+type openapiSchema struct {
+	Ref                  string                    `json:"$ref,omitempty"`
+	AllOf                []*openapiSchema          `json:"allOf,omitempty"`
+	OneOf                []*openapiSchema          `json:"oneOf,omitempty"`
+	Type                 string                    `json:"type,omitempty"`
+	Format               string                    `json:"format,omitempty"`
+	Description          string                    `json:"description,omitempty"`
+	Nullable             bool                      `json:"nullable,omitempty"`
+	Enum                 []any                     `json:"enum,omitempty"`
+	EnumDescriptions     []string                  `json:"x-enum-descriptions,omitempty"` // in the same order as Enum
+	Minimum              *int64                    `json:"minimum,omitempty"`
+	Maximum              *int64                    `json:"maximum,omitempty"`
+	Items                *openapiSchema            `json:"items,omitempty"`
+	MinItems             *int                      `json:"minItems,omitempty"`
+	MaxItems             *int                      `json:"maxItems,omitempty"`
+	UniqueItems          bool                      `json:"uniqueItems,omitempty"`
+	Properties           map[string]*openapiSchema `json:"properties,omitempty"`
+	Required             []string                  `json:"required,omitempty"`
+	AdditionalProperties *openapiSchema            `json:"additionalProperties,omitempty"`
+	Since                string                    `json:"x-since,omitempty"` // the api version which added the field
+	Until                string                    `json:"x-until,omitempty"` // the api version which removes the field
+}
+
+func openapiPtr[T any](v T) *T {
+	return &v
+}
+
+// defaultErrorHttpStatus is used by the go server for the errors without HttpStatus
+const defaultErrorHttpStatus = 417
+
+// generateOpenAPI writes an OpenAPI 3.0 document of the http services, each method
+// is a path the same as the routes of the generated Go server, POST /<Service>.<Method>
+func generateOpenAPI(out io.Writer, pkg string, doc *ast.Document) error {
+	isModelType := createIsModelTypeFunc(doc.Models)
+
+	spec := &openapiDocument{
+		OpenAPI: "3.0.3",
+		Info: openapiInfo{
+			Title:   pkg,
+			Version: getOpenAPIVersion(doc),
+		},
+		Paths: make(map[string]*openapiPathItem),
+		Components: openapiComponents{
+			Schemas: make(map[string]*openapiSchema),
+		},
+	}
+
+	for _, enum := range doc.Enums {
+		spec.Components.Schemas[enum.Name.Token.Value] = getOpenAPIEnumSchema(enum)
+	}
+
+	hasValidate := false
+
+	for _, model := range doc.Models {
+		spec.Components.Schemas[model.Name.Token.Value] = getOpenAPIModelSchema(model, isModelType)
+		hasValidate = hasValidate || len(model.Requires) > 0 || len(getGolangFieldChecks(model, isModelType, nil, false)) > 0
+	}
+
+	spec.Components.Schemas["Error"] = &openapiSchema{
+		Type: "object",
+		Properties: map[string]*openapiSchema{
+			"error": {
+				Type: "object",
+				Properties: map[string]*openapiSchema{
+					"code":    {Type: "integer", Format: "int64"},
+					"message": {Type: "string"},
+					"cause":   {Type: "string"},
+				},
+				Required: []string{"code", "message"},
+			},
+		},
+		Required: []string{"error"},
+	}
+
+	// custom errors are grouped by their http status
+	errorsByStatus := map[int64][]string{
+		defaultErrorHttpStatus: {"the errors without HttpStatus"},
+	}
+
+	for _, customErr := range doc.Errors {
+		status := int64(defaultErrorHttpStatus)
+		if v, ok := customErr.HttpStatus.(*ast.ValueInt); ok {
+			status = v.Value
+		}
+
+		errorsByStatus[status] = append(errorsByStatus[status], fmt.Sprintf("%s (code %d): %s", customErr.Name.Token.Value, customErr.Code, strings.TrimSpace(customErr.Msg.Value)))
+	}
+
+	if hasValidate {
+		errorsByStatus[400] = append(errorsByStatus[400], "ErrValidation (code -2): validation failed")
+	}
+
+	for _, service := range doc.Services {
+		if service.Type != ast.ServiceHTTP {
+			continue
+		}
+
+		for _, method := range service.Methods {
+			name := service.Name.Token.Value + "." + method.Name.Token.Value
+
+			operation := &openapiOperation{
+				OperationId: name,
+				Description: strings.Join(getCommentLines(method.Comments, ast.CommentTop), "\n"),
+				Tags:        []string{service.Name.Token.Value},
+				Responses:   make(map[string]*openapiResponse),
+			}
+			operation.Since, operation.Until = getOpenAPILifecycle(method.Options)
+
+			params := &openapiSchema{
+				Type:       "object",
+				Properties: make(map[string]*openapiSchema),
+			}
+
+			isUpload := false
+			var streamArg *ast.Arg
+
+			for _, arg := range method.Args {
+				if arg.Stream && isBidirectionalStream(method) {
+					streamArg = arg
+					continue
+				} else if arg.Stream {
+					isUpload = true
+					continue
+				}
+
+				argName := strcase.ToCamel(arg.Name.Token.Value)
+				params.Properties[argName] = getOpenAPINullableSchema(arg.Type, isModelType)
+				params.Required = append(params.Required, argName)
+			}
+
+			httpMethod, maxSize, rateLimit := "POST", false, false
+			for _, opt := range method.Options.List {
+				switch opt.Name.Token.Value {
+				case "HttpMethod":
+					if v, ok := opt.Value.(*ast.ValueString); ok {
+						httpMethod = v.Value
+					}
+				case "MaxSize":
+					maxSize = true
+				case "RateLimit":
+					rateLimit = true
+				}
+			}
+
+			switch {
+			case httpMethod == "GET":
+				operation.Parameters = []*openapiParameter{
+					{
+						Name:     "params",
+						In:       "query",
+						Required: len(params.Required) > 0,
+						Content: map[string]*openapiMediaType{
+							"application/json": {Schema: params},
+						},
+					},
+				}
+			case streamArg != nil:
+				operation.RequestBody = &openapiRequestBody{
+					Required: true,
+					Content: map[string]*openapiMediaType{
+						"application/x-ndjson": {
+							Schema: &openapiSchema{
+								Description: fmt.Sprintf(`newline delimited json, the first line is {"method": "%s", "params": ...} and each following line is one of %s, sent while the response is received`, name, streamArg.Name.Token.Value),
+								OneOf: []*openapiSchema{
+									{
+										Type:       "object",
+										Properties: map[string]*openapiSchema{"method": {Type: "string", Enum: []any{name}}, "params": params},
+										Required:   []string{"method", "params"},
+									},
+									getOpenAPISchema(streamArg.Type),
+								},
+							},
+						},
+					},
+				}
+			case isUpload:
+				operation.RequestBody = &openapiRequestBody{
+					Required: true,
+					Content: map[string]*openapiMediaType{
+						"multipart/form-data": {
+							Schema: &openapiSchema{
+								Type: "object",
+								Properties: map[string]*openapiSchema{
+									"method": {Type: "string", Enum: []any{name}},
+									"params": params,
+									"file": {
+										Type:  "array",
+										Items: &openapiSchema{Type: "string", Format: "binary"},
+									},
+								},
+								Required: []string{"method", "params"},
+							},
+							Encoding: map[string]*openapiEncoding{
+								"params": {ContentType: "application/json"},
+							},
+						},
+					},
+				}
+			default:
+				operation.RequestBody = &openapiRequestBody{
+					Required: true,
+					Content: map[string]*openapiMediaType{
+						"application/json": {
+							Schema: &openapiSchema{
+								Type:       "object",
+								Properties: map[string]*openapiSchema{"params": params},
+								Required:   []string{"params"},
+							},
+						},
+					},
+				}
+			}
+
+			operation.Responses["200"] = getOpenAPIMethodResponse(method, isModelType)
+
+			for status, errs := range errorsByStatus {
+				operation.Responses[fmt.Sprint(status)] = getOpenAPIErrorResponse(errs)
+			}
+
+			if maxSize {
+				errs := append(slices.Clone(errorsByStatus[413]), "ErrRequestTooLarge (code -3): request body is too large")
+				operation.Responses["413"] = getOpenAPIErrorResponse(errs)
+			}
+
+			if rateLimit {
+				errs := append(slices.Clone(errorsByStatus[429]), "ErrTooManyRequests (code -4): too many requests")
+				operation.Responses["429"] = getOpenAPIErrorResponse(errs)
+			}
+
+			pathItem := &openapiPathItem{}
+			if httpMethod == "GET" {
+				pathItem.Get = operation
+			} else {
+				pathItem.Post = operation
+			}
+
+			spec.Paths["/"+name] = pathItem
+		}
+	}
+
+	enc := json.NewEncoder(out)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(spec)
+}
+
+// getOpenAPIVersion returns the value of Version constant, if it's defined as a string
+func getOpenAPIVersion(doc *ast.Document) string {
+	for _, c := range doc.Consts {
+		if v, ok := c.Value.(*ast.ValueString); ok && c.Identifier.Token.Value == "Version" {
+			return v.Value
+		}
+	}
+
+	return "0.0.0"
+}
+
+func getOpenAPIMethodResponse(method *ast.Method, isModelType func(string) bool) *openapiResponse {
+	for _, ret := range method.Returns {
+		if !ret.Stream {
+			continue
+		}
+
+		if arr, ok := ret.Type.(*ast.Array); ok {
+			if _, ok := arr.Type.(*ast.Byte); ok {
+				return &openapiResponse{
+					Description: "the file of " + ret.Name.Token.Value,
+					Content: map[string]*openapiMediaType{
+						"application/octet-stream": {
+							Schema: &openapiSchema{Type: "string", Format: "binary"},
+						},
+					},
+				}
+			}
+		}
+
+		return &openapiResponse{
+			Description: fmt.Sprintf("server sent events, each data event holds one of %s as json, error events hold the Error and the stream finishes with an end event", ret.Name.Token.Value),
+			Content: map[string]*openapiMediaType{
+				"text/event-stream": {
+					Schema: &openapiSchema{Type: "string", Description: "items are " + formatExpr(ret.Type)},
+				},
+			},
+		}
+	}
+
+	names := make([]string, 0, len(method.Returns))
+	items := make([]*openapiSchema, 0, len(method.Returns))
+
+	for _, ret := range method.Returns {
+		names = append(names, ret.Name.Token.Value)
+		items = append(items, getOpenAPINullableSchema(ret.Type, isModelType))
+	}
+
+	result := &openapiSchema{
+		Type:     "array",
+		MinItems: openapiPtr(len(items)),
+		MaxItems: openapiPtr(len(items)),
+	}
+
+	switch len(items) {
+	case 0:
+		result.Items = &openapiSchema{}
+	case 1:
+		result.Items = items[0]
+	default:
+		result.Items = &openapiSchema{OneOf: items}
+	}
+
+	if len(names) > 0 {
+		result.Description = "the returns in order: " + strings.Join(names, ", ")
+	}
+
+	return &openapiResponse{
+		Description: "the results of the method",
+		Content: map[string]*openapiMediaType{
+			"application/json": {
+				Schema: &openapiSchema{
+					Type:       "object",
+					Properties: map[string]*openapiSchema{"result": result},
+					Required:   []string{"result"},
+				},
+			},
+		},
+	}
+}
+
+func getOpenAPIErrorResponse(errs []string) *openapiResponse {
+	return &openapiResponse{
+		Description: strings.Join(errs, "\n"),
+		Content: map[string]*openapiMediaType{
+			"application/json": {
+				Schema: &openapiSchema{Ref: "#/components/schemas/Error"},
+			},
+		},
+	}
+}
+
+func getOpenAPIEnumSchema(enum *ast.Enum) *openapiSchema {
+	schema := &openapiSchema{
+		Description: strings.Join(getCommentLines(enum.Comments, ast.CommentTop), "\n"),
+	}
+
+	var descriptions []string
+
+	jsonNumber := isEnumJsonNumber(enum)
+	if jsonNumber {
+		schema.Type = "integer"
+	} else {
+		schema.Type = "string"
+	}
+
+	for _, set := range enum.Sets {
+		if set.Name.Token.Value == "_" {
+			continue
+		}
+
+		if jsonNumber {
+			// the aliases share the description of the first value
+			if slices.Contains(schema.Enum, any(set.Value.Value)) {
+				continue
+			}
+			schema.Enum = append(schema.Enum, set.Value.Value)
+		} else {
+			schema.Enum = append(schema.Enum, strcase.ToSnake(set.Name.Token.Value))
+		}
+
+		descriptions = append(descriptions, strings.Join(getCommentLines(set.Comments, ast.CommentTop), "\n"))
+	}
+
+	// the descriptions are only added if at least one of the values is documented
+	if slices.ContainsFunc(descriptions, func(description string) bool { return description != "" }) {
+		schema.EnumDescriptions = descriptions
+	}
+
+	return schema
+}
+
+// getOpenAPILifecycle returns the versions of the Since and Until options,
+// empty if they are not set
+func getOpenAPILifecycle(options *ast.Options) (since, until string) {
+	sinceVersion, untilVersion := options.Lifecycle()
+	if sinceVersion != nil {
+		since = sinceVersion.String()
+	}
+	if untilVersion != nil {
+		until = untilVersion.String()
+	}
+	return since, until
+}
+
+func getOpenAPIModelSchema(model *ast.Model, isModelType func(string) bool) *openapiSchema {
+	schema := &openapiSchema{
+		Type:        "object",
+		Description: strings.Join(getCommentLines(model.Comments, ast.CommentTop), "\n"),
+		Properties:  make(map[string]*openapiSchema),
+	}
+
+	for _, field := range model.Fields {
+		// the json name and omitempty are the same as the generated go struct's tag
+		tag := strings.TrimSuffix(strings.TrimPrefix(getGolangModelFieldTag(field, true), `json:"`), `"`)
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "-" {
+			continue
+		}
+
+		var fieldSchema *openapiSchema
+		if getFieldTimeFormat(field) != "rfc3339" {
+			// unix and unixmilli timestamps are encoded as numbers
+			fieldSchema = &openapiSchema{Type: "integer", Format: "int64"}
+		} else if isFieldJsonString(field) {
+			// JsonString numbers are encoded as strings, the format keeps the number's type
+			fieldSchema = &openapiSchema{Type: "string", Format: getOpenAPISchema(field.Type).Format}
+		} else if field.IsOptional {
+			fieldSchema = getOpenAPISchema(field.Type)
+		} else {
+			fieldSchema = getOpenAPINullableSchema(field.Type, isModelType)
+		}
+
+		description := strings.Join(getCommentLines(field.Comments, ast.CommentTop), "\n")
+		since, until := getOpenAPILifecycle(field.Options)
+		if description != "" || since != "" || until != "" {
+			// the siblings of $ref are ignored, so it's wrapped
+			if fieldSchema.Ref != "" {
+				fieldSchema = &openapiSchema{AllOf: []*openapiSchema{fieldSchema}}
+			}
+			fieldSchema.Description = description
+			fieldSchema.Since = since
+			fieldSchema.Until = until
+		}
+
+		schema.Properties[name] = fieldSchema
+
+		if opts == "" {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+
+	for _, oneOf := range model.OneOfs {
+		variants := make([]*openapiSchema, 0, len(oneOf.Variants))
+
+		for _, variant := range oneOf.Variants {
+			variants = append(variants, &openapiSchema{
+				Type: "object",
+				Properties: map[string]*openapiSchema{
+					"type":  {Type: "string", Enum: []any{strcase.ToSnake(variant.Name.Token.Value)}},
+					"value": getOpenAPISchema(variant.Type),
+				},
+				Required: []string{"type", "value"},
+			})
+		}
+
+		schema.Properties[strcase.ToCamel(oneOf.Name.Token.Value)] = &openapiSchema{OneOf: variants}
+	}
+
+	return schema
+}
+
+// getOpenAPINullableSchema returns the schema of non optional values, arrays, sets,
+// maps and models are encoded as null by the go server when they are not set
+func getOpenAPINullableSchema(typ ast.Type, isModelType func(string) bool) *openapiSchema {
+	schema := getOpenAPISchema(typ)
+
+	switch t := typ.(type) {
+	case *ast.Array:
+		// []byte is encoded as base64 string
+		if _, ok := t.Type.(*ast.Byte); !ok {
+			schema.Nullable = true
+		}
+	case *ast.Set, *ast.Map:
+		schema.Nullable = true
+	case *ast.CustomType:
+		if isModelType(t.Token.Value) {
+			schema = &openapiSchema{AllOf: []*openapiSchema{schema}, Nullable: true}
+		}
+	}
+
+	return schema
+}
+
+func getOpenAPISchema(typ ast.Type) *openapiSchema {
+	switch t := typ.(type) {
+	case *ast.Bool:
+		return &openapiSchema{Type: "boolean"}
+	case *ast.Int:
+		if t.Size == 64 {
+			return &openapiSchema{Type: "integer", Format: "int64"}
+		}
+		return &openapiSchema{Type: "integer", Format: "int32"}
+	case *ast.Uint:
+		if t.Size >= 32 {
+			return &openapiSchema{Type: "integer", Format: "int64", Minimum: openapiPtr[int64](0)}
+		}
+		return &openapiSchema{Type: "integer", Format: "int32", Minimum: openapiPtr[int64](0)}
+	case *ast.Byte:
+		return &openapiSchema{Type: "integer", Minimum: openapiPtr[int64](0), Maximum: openapiPtr[int64](255)}
+	case *ast.Float:
+		if t.Size == 32 {
+			return &openapiSchema{Type: "number", Format: "float"}
+		}
+		return &openapiSchema{Type: "number", Format: "double"}
+	case *ast.String:
+		return &openapiSchema{Type: "string"}
+	case *ast.Timestamp:
+		return &openapiSchema{Type: "string", Format: "date-time"}
+	case *ast.Any:
+		return &openapiSchema{}
+	case *ast.Array:
+		// []byte is encoded as base64 string the same as encoding/json
+		if _, ok := t.Type.(*ast.Byte); ok {
+			return &openapiSchema{Type: "string", Format: "byte"}
+		}
+		return &openapiSchema{Type: "array", Items: getOpenAPISchema(t.Type)}
+	case *ast.Set:
+		// sets are encoded as json arrays
+		return &openapiSchema{Type: "array", Items: getOpenAPISchema(t.Type), UniqueItems: true}
+	case *ast.Map:
+		// json object keys are always strings
+		return &openapiSchema{Type: "object", AdditionalProperties: getOpenAPISchema(t.Value)}
+	case *ast.CustomType:
+		return &openapiSchema{Ref: "#/components/schemas/" + t.Token.Value}
+	default:
+		panic(fmt.Errorf("unknown type: %T", t))
+	}
+}
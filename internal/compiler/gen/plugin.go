@@ -0,0 +1,89 @@
+package gen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/hexe-dev/hexe/internal/compiler/ast"
+)
+
+// pluginVersion is the version of the plugin protocol, it changes only
+// if the request or the response changes in a non backward compatible way
+const pluginVersion = 1
+
+// pluginRequest is written as JSON into the plugin's stdin
+type pluginRequest struct {
+	Version int              `json:"version"`
+	Package string           `json:"package"`
+	Schema  *astJsonDocument `json:"schema"` // the same as the .ast.json output
+}
+
+// pluginResponse is read as JSON from the plugin's stdout, if Error is set,
+// the plugin failed to generate the code and none of the files are written
+type pluginResponse struct {
+	Files []pluginFile `json:"files"`
+	Error string       `json:"error,omitempty"`
+}
+
+type pluginFile struct {
+	Name    string `json:"name"` // relative to the output directory, e.g. api/User.kt
+	Content string `json:"content"`
+}
+
+// generatePlugin runs the plugin executable with the request in its stdin, and writes the
+// files of its response into the output directory, the same as protoc's plugins
+func generatePlugin(plugin, pkg, outDir string, doc *ast.Document) error {
+	req, err := json.Marshal(&pluginRequest{
+		Version: pluginVersion,
+		Package: pkg,
+		Schema:  newAstJsonDocument(pkg, doc),
+	})
+	if err != nil {
+		return err
+	}
+
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.Command(plugin)
+	cmd.Stdin = bytes.NewReader(req)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err = cmd.Run(); err != nil {
+		return fmt.Errorf("plugin %s failed: %w: %s", plugin, err, strings.TrimSpace(stderr.String()))
+	}
+
+	var resp pluginResponse
+	if err = json.Unmarshal(stdout.Bytes(), &resp); err != nil {
+		return fmt.Errorf("plugin %s returned an invalid response: %w", plugin, err)
+	}
+
+	if resp.Error != "" {
+		return fmt.Errorf("plugin %s failed: %s", plugin, resp.Error)
+	}
+
+	// all the names are checked before writing any file
+	for _, file := range resp.Files {
+		if !filepath.IsLocal(file.Name) {
+			return fmt.Errorf("plugin %s returned a file outside of the output directory: %s", plugin, file.Name)
+		}
+	}
+
+	for _, file := range resp.Files {
+		path := filepath.Join(outDir, file.Name)
+		if err = os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			return err
+		}
+
+		if err = os.WriteFile(path, []byte(file.Content), 0o644); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
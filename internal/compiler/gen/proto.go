@@ -0,0 +1,396 @@
+package gen
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/hexe-dev/hexe/internal/compiler/ast"
+	"github.com/hexe-dev/hexe/internal/strcase"
+)
+
+// protoReservedStart and protoReservedEnd are the field numbers reserved by protobuf's implementation
+const (
+	protoReservedStart = 19000
+	protoReservedEnd   = 19999
+)
+
+// generateProto writes a proto3 file of the enums, models and rpc services, http services
+// are skipped as they are served over http and json. Field numbers follow the declaration
+// order unless they are pinned by the Proto field option, e.g. Name: string { Proto = 5 }
+func generateProto(out io.Writer, pkg string, doc *ast.Document) error {
+	isEnumType := make(map[string]bool, len(doc.Enums))
+	for _, enum := range doc.Enums {
+		isEnumType[enum.Name.Token.Value] = true
+	}
+
+	g := &protoGenerator{isEnumType: isEnumType}
+
+	for _, enum := range doc.Enums {
+		if err := g.writeEnum(enum); err != nil {
+			return err
+		}
+	}
+
+	for _, model := range doc.Models {
+		if err := g.writeModel(model); err != nil {
+			return err
+		}
+	}
+
+	for _, service := range doc.Services {
+		if service.Type != ast.ServiceRPC {
+			continue
+		}
+
+		if err := g.writeService(service); err != nil {
+			return err
+		}
+	}
+
+	var sb strings.Builder
+
+	sb.WriteString("// generated by hexe compiler; DO NOT EDIT\n\n")
+	sb.WriteString("syntax = \"proto3\";\n\n")
+	fmt.Fprintf(&sb, "package %s;\n", pkg)
+
+	if g.hasTimestamp || g.hasAny {
+		sb.WriteString("\n")
+	}
+	if g.hasAny {
+		sb.WriteString("import \"google/protobuf/struct.proto\";\n")
+	}
+	if g.hasTimestamp {
+		sb.WriteString("import \"google/protobuf/timestamp.proto\";\n")
+	}
+
+	sb.WriteString(g.body.String())
+
+	_, err := io.WriteString(out, sb.String())
+	return err
+}
+
+type protoGenerator struct {
+	body         strings.Builder
+	isEnumType   map[string]bool
+	hasTimestamp bool
+	hasAny       bool
+}
+
+func (g *protoGenerator) writeComments(indent string, comments []*ast.Comment) {
+	for _, line := range getCommentLines(comments, ast.CommentTop) {
+		fmt.Fprintf(&g.body, "%s// %s\n", indent, line)
+	}
+}
+
+func (g *protoGenerator) writeEnum(enum *ast.Enum) error {
+	name := enum.Name.Token.Value
+
+	// protobuf enums are always int32, the wider enums can't be represented
+	if enum.Size == 64 {
+		return fmt.Errorf("enum %s is 64 bits, protobuf enums are limited to int32", name)
+	}
+
+	prefix := strings.ToUpper(strcase.ToSnake(name)) + "_"
+
+	type protoEnumValue struct {
+		set  *ast.EnumSet
+		name string
+	}
+
+	// proto3 requires the first value to be zero, which is also the default value
+	var zero *protoEnumValue
+	values := make([]*protoEnumValue, 0, len(enum.Sets))
+	seen := make(map[int64]bool, len(enum.Sets))
+	hasAlias := false
+
+	for _, set := range enum.Sets {
+		if set.Value.Value > math.MaxInt32 || set.Value.Value < math.MinInt32 {
+			return fmt.Errorf("enum %s's value %s doesn't fit in int32 of protobuf enums", name, set.Name.Token.Value)
+		}
+
+		value := &protoEnumValue{set: set, name: prefix + strings.ToUpper(strcase.ToSnake(set.Name.Token.Value))}
+		if set.Name.Token.Value == "_" {
+			value.name = prefix + "UNSPECIFIED"
+		}
+
+		if seen[set.Value.Value] {
+			hasAlias = true
+		}
+		seen[set.Value.Value] = true
+
+		if set.Value.Value == 0 && zero == nil {
+			zero = value
+			continue
+		}
+
+		values = append(values, value)
+	}
+
+	g.body.WriteString("\n")
+	g.writeComments("", enum.Comments)
+	fmt.Fprintf(&g.body, "enum %s {\n", name)
+
+	if hasAlias {
+		g.body.WriteString("  option allow_alias = true;\n")
+	}
+
+	if zero != nil {
+		g.writeComments("  ", zero.set.Comments)
+		fmt.Fprintf(&g.body, "  %s = 0;\n", zero.name)
+	} else {
+		fmt.Fprintf(&g.body, "  %sUNSPECIFIED = 0;\n", prefix)
+	}
+
+	for _, value := range values {
+		g.writeComments("  ", value.set.Comments)
+		fmt.Fprintf(&g.body, "  %s = %d;\n", value.name, value.set.Value.Value)
+	}
+
+	g.body.WriteString("}\n")
+
+	return nil
+}
+
+func (g *protoGenerator) writeModel(model *ast.Model) error {
+	return g.writeMessage(model.Name.Token.Value, model.Comments, model.Fields, model.OneOfs)
+}
+
+func (g *protoGenerator) writeMessage(name string, comments []*ast.Comment, fields []*ast.Field, oneOfs []*ast.OneOf) error {
+	numbers := newProtoFieldNumbers()
+	for _, field := range fields {
+		for _, opt := range field.Options.List {
+			if v, ok := opt.Value.(*ast.ValueInt); ok && opt.Name.Token.Value == "Proto" {
+				numbers.pin(field, v.Value)
+			}
+		}
+	}
+
+	g.body.WriteString("\n")
+	g.writeComments("", comments)
+	fmt.Fprintf(&g.body, "message %s {\n", name)
+
+	for _, field := range fields {
+		typ, err := g.getProtoFieldType(field.Type)
+		if err != nil {
+			return fmt.Errorf("message %s's field %s: %w", name, field.Name.Token.Value, err)
+		}
+
+		// optional is only allowed for singular fields, messages have presence already
+		if field.IsOptional && !strings.HasPrefix(typ, "repeated ") && !strings.HasPrefix(typ, "map<") && !g.isMessageType(field.Type) {
+			typ = "optional " + typ
+		}
+
+		g.writeComments("  ", field.Comments)
+		fmt.Fprintf(&g.body, "  %s %s = %d;\n", typ, strcase.ToSnake(field.Name.Token.Value), numbers.get(field))
+	}
+
+	for _, oneOf := range oneOfs {
+		g.writeComments("  ", oneOf.Comments)
+		fmt.Fprintf(&g.body, "  oneof %s {\n", strcase.ToSnake(oneOf.Name.Token.Value))
+
+		for _, variant := range oneOf.Variants {
+			typ, err := g.getProtoFieldType(variant.Type)
+			if err != nil {
+				return fmt.Errorf("message %s's oneof %s variant %s: %w", name, oneOf.Name.Token.Value, variant.Name.Token.Value, err)
+			}
+
+			if strings.HasPrefix(typ, "repeated ") || strings.HasPrefix(typ, "map<") {
+				return fmt.Errorf("message %s's oneof %s variant %s: protobuf oneof doesn't support repeated and map fields", name, oneOf.Name.Token.Value, variant.Name.Token.Value)
+			}
+
+			g.writeComments("    ", variant.Comments)
+			fmt.Fprintf(&g.body, "    %s %s = %d;\n", typ, strcase.ToSnake(variant.Name.Token.Value), numbers.get(variant))
+		}
+
+		g.body.WriteString("  }\n")
+	}
+
+	g.body.WriteString("}\n")
+
+	return nil
+}
+
+func (g *protoGenerator) writeService(service *ast.Service) error {
+	name := service.Name.Token.Value
+
+	var rpcs strings.Builder
+
+	for _, method := range service.Methods {
+		messageName := name + method.Name.Token.Value
+
+		// the arguments and returns are wrapped in the request and response messages
+		args := make([]*ast.Field, 0, len(method.Args))
+		for _, arg := range method.Args {
+			args = append(args, &ast.Field{Name: arg.Name, Type: arg.Type, Options: &ast.Options{}})
+		}
+
+		returns := make([]*ast.Field, 0, len(method.Returns))
+		for _, ret := range method.Returns {
+			returns = append(returns, &ast.Field{Name: ret.Name, Type: ret.Type, Options: &ast.Options{}})
+		}
+
+		if err := g.writeMessage(messageName+"Request", nil, args, nil); err != nil {
+			return fmt.Errorf("service %s's method %s: %w", name, method.Name.Token.Value, err)
+		}
+
+		if err := g.writeMessage(messageName+"Response", nil, returns, nil); err != nil {
+			return fmt.Errorf("service %s's method %s: %w", name, method.Name.Token.Value, err)
+		}
+
+		for _, line := range getCommentLines(method.Comments, ast.CommentTop) {
+			fmt.Fprintf(&rpcs, "  // %s\n", line)
+		}
+		fmt.Fprintf(&rpcs, "  rpc %s(%sRequest) returns (%sResponse);\n", method.Name.Token.Value, messageName, messageName)
+	}
+
+	g.body.WriteString("\n")
+	g.writeComments("", service.Comments)
+	fmt.Fprintf(&g.body, "service %s {\n", name)
+	g.body.WriteString(rpcs.String())
+	g.body.WriteString("}\n")
+
+	return nil
+}
+
+func (g *protoGenerator) isMessageType(typ ast.Type) bool {
+	switch t := typ.(type) {
+	case *ast.CustomType:
+		return !g.isEnumType[t.Token.Value]
+	case *ast.Timestamp, *ast.Any:
+		return true
+	default:
+		return false
+	}
+}
+
+// getProtoFieldType returns the type of a message's field, including repeated and map
+func (g *protoGenerator) getProtoFieldType(typ ast.Type) (string, error) {
+	switch t := typ.(type) {
+	case *ast.Array:
+		// []byte is a single bytes field
+		if _, ok := t.Type.(*ast.Byte); ok {
+			return "bytes", nil
+		}
+		return g.getProtoRepeatedType(t.Type)
+	case *ast.Set:
+		return g.getProtoRepeatedType(t.Type)
+	case *ast.Map:
+		key, err := g.getProtoMapKeyType(t.Key)
+		if err != nil {
+			return "", err
+		}
+
+		value, err := g.getProtoFieldType(t.Value)
+		if err != nil {
+			return "", err
+		}
+
+		if strings.HasPrefix(value, "repeated ") || strings.HasPrefix(value, "map<") {
+			return "", fmt.Errorf("protobuf map's value can't be repeated or map")
+		}
+
+		return fmt.Sprintf("map<%s, %s>", key, value), nil
+	default:
+		return g.getProtoScalarType(typ)
+	}
+}
+
+func (g *protoGenerator) getProtoRepeatedType(typ ast.Type) (string, error) {
+	elem, err := g.getProtoFieldType(typ)
+	if err != nil {
+		return "", err
+	}
+
+	if strings.HasPrefix(elem, "repeated ") || strings.HasPrefix(elem, "map<") {
+		return "", fmt.Errorf("protobuf doesn't support nested repeated and map fields")
+	}
+
+	return "repeated " + elem, nil
+}
+
+// getProtoMapKeyType returns the key type of a map, protobuf doesn't allow
+// enums as keys, so they are replaced with their int32 value
+func (g *protoGenerator) getProtoMapKeyType(typ ast.Type) (string, error) {
+	switch t := typ.(type) {
+	case *ast.CustomType:
+		if g.isEnumType[t.Token.Value] {
+			return "int32", nil
+		}
+	case *ast.Bool, *ast.Int, *ast.Uint, *ast.Byte, *ast.String:
+		return g.getProtoScalarType(typ)
+	}
+
+	return "", fmt.Errorf("%s can't be used as protobuf map's key", formatExpr(typ))
+}
+
+func (g *protoGenerator) getProtoScalarType(typ ast.Type) (string, error) {
+	switch t := typ.(type) {
+	case *ast.Bool:
+		return "bool", nil
+	case *ast.Int:
+		if t.Size == 64 {
+			return "int64", nil
+		}
+		return "int32", nil
+	case *ast.Uint:
+		if t.Size == 64 {
+			return "uint64", nil
+		}
+		return "uint32", nil
+	case *ast.Byte:
+		return "uint32", nil
+	case *ast.Float:
+		if t.Size == 32 {
+			return "float", nil
+		}
+		return "double", nil
+	case *ast.String:
+		return "string", nil
+	case *ast.Timestamp:
+		g.hasTimestamp = true
+		return "google.protobuf.Timestamp", nil
+	case *ast.Any:
+		g.hasAny = true
+		return "google.protobuf.Value", nil
+	case *ast.CustomType:
+		return t.Token.Value, nil
+	default:
+		return "", fmt.Errorf("unknown type: %T", t)
+	}
+}
+
+// protoFieldNumbers assigns the field numbers of a message, the pinned numbers are
+// kept and the rest are assigned in order, skipping the pinned and reserved numbers
+type protoFieldNumbers struct {
+	next   int64
+	pinned map[any]int64
+	used   map[int64]bool
+}
+
+func newProtoFieldNumbers() *protoFieldNumbers {
+	return &protoFieldNumbers{
+		next:   1,
+		pinned: make(map[any]int64),
+		used:   make(map[int64]bool),
+	}
+}
+
+func (n *protoFieldNumbers) pin(key any, number int64) {
+	n.pinned[key] = number
+	n.used[number] = true
+}
+
+func (n *protoFieldNumbers) get(key any) int64 {
+	if number, ok := n.pinned[key]; ok {
+		return number
+	}
+
+	for n.used[n.next] || (n.next >= protoReservedStart && n.next <= protoReservedEnd) {
+		n.next++
+	}
+
+	n.used[n.next] = true
+	return n.next
+}
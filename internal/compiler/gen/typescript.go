@@ -3,7 +3,8 @@ package gen
 import (
 	"embed"
 	"fmt"
-	"os"
+	"io"
+	"slices"
 	"strconv"
 	"strings"
 	"text/template"
@@ -16,43 +17,103 @@ import (
 //go:embed typescript/*.ts.tmpl
 var typescriptTemplateFiles embed.FS
 
-func generateTypescript(pkg, output string, doc *ast.Document) error {
+// generateTypescript executes the named root template, "main" for typescript
+// types and services or "zod" for zod schemas of enums and models
+func generateTypescript(out io.Writer, pkg, name string, doc *ast.Document, opts *options) error {
 	// Note: Currently we only care about the http services
 	// in typescript, so we filter out the rpc services.
+	services := doc.Services
 	doc.Services = filterFunc(doc.Services, func(service *ast.Service) bool {
-		return service.Token.Type != token.Type(ast.ServiceRPC)
+		return service.Type != ast.ServiceRPC
 	})
 
+	// an rpc only schema silently generates no client, which is confusing
+	if len(services) > 0 && len(doc.Services) == 0 {
+		opts.warn("the schema has only rpc services, %s, typescript only generates the clients of http services", strings.Join(mapperFunc(services, func(service *ast.Service) string {
+			return service.Name.Token.Value
+		}), ", "))
+	}
+
+	// the zod schemas have no services, so the skipped methods are only reported once
+	if name == "main" {
+		for _, service := range getServicesByType(doc.Services, ast.ServiceHTTP) {
+			for _, method := range service.Methods {
+				if isBidirectionalStream(method) {
+					opts.warn("%s.%s is a bidirectional stream, it's skipped as typescript clients can't send the request's body while receiving the response", service.Name.Token.Value, method.Name.Token.Value)
+				}
+			}
+		}
+	}
+
 	// CONSTANTS
 
 	type TsConst struct {
-		Name  string
-		Value string
+		Name     string
+		Type     string // the type of object and list literals, empty for the other constants
+		Value    string
+		Comments []string
 	}
 
 	// ENUMS
 
 	type TsEnumKeyValue struct {
-		Name  string
-		Value string
+		Name     string
+		Value    string
+		Label    string // quoted
+		Comments []string
 	}
 
 	type TsEnum struct {
-		Name string
-		Keys []TsEnumKeyValue
+		Name       string
+		Keys       []TsEnumKeyValue
+		HasLabels  bool
+		JsonNumber bool // Value is the key's number instead of its snake case name
+		Comments   []string
 	}
 
 	// MODELS
 
 	type TsField struct {
-		Name       string
-		Type       string
-		IsOptional bool
+		Name     string
+		Type     string
+		Zod      string
+		ZodInfer string // the type which zod infers from Zod, see getZodFieldInferType
+		// zod infers an optional key if its type includes undefined, e.g. any
+		ZodOptional bool
+		IsOptional  bool
+		Comments    []string
+	}
+
+	type TsOneOfVariant struct {
+		Discriminator string
+		Type          string
+		Zod           string
+	}
+
+	type TsOneOf struct {
+		Name     string
+		Field    string
+		Variants []TsOneOfVariant
+		Comments []string
 	}
 
 	type TsModel struct {
-		Name   string
-		Fields []TsField
+		Name      string
+		Fields    []TsField
+		OneOfs    []TsOneOf
+		Recursive bool // refers to itself, so its zod type is written out instead of inferred
+		Comments  []string
+	}
+
+	// TsEnumPath is a json field of a model whose value contains enums
+	type TsEnumPath struct {
+		Field string // quoted json name
+		Path  string // enumPath literal, see getTypescriptEnumPath
+	}
+
+	type TsModelEnumPaths struct {
+		Name  string
+		Paths []TsEnumPath
 	}
 
 	// SERVICES
@@ -74,91 +135,159 @@ func generateTypescript(pkg, output string, doc *ast.Document) error {
 		ServiceName string
 		ReqType     string // json, fileupload
 		RespType    string // json, blob, sse
+		HttpMethod  string // GET or POST, based on HttpMethod option
 		Args        []TsArg
 		Returns     []TsReturn
+		Decode      string // enumPath literals of the returns, empty if they don't contain enums
+		Comments    []string
 	}
 
 	type TsService struct {
-		Name    string
-		Methods []TsMethod
+		Name     string
+		Methods  []TsMethod
+		Comments []string
 	}
 
 	// CUSTOM ERROR
 
 	type TsError struct {
-		Name string
-		Code int64
+		Name     string
+		Code     int64
+		Comments []string
 	}
 
 	// Data
 
 	type Data struct {
 		PackageName  string
+		ConstEnums   bool // enums are generated as const objects, based on WithTsConstEnums
 		Constants    []TsConst
 		Enums        []TsEnum
 		Models       []TsModel
+		EnumPaths    []TsModelEnumPaths
 		HttpServices []TsService
 		Errors       []TsError
+		HasSSE       bool // at least one method returns a subscription, so the manager is generated
+	}
+
+	isModelType := createIsModelTypeFunc(doc.Models)
+	isEnumType := createIsEnumTypeFunc(doc.Enums)
+	recursiveModels := getRecursiveModels(doc.Models)
+	modelEnumPaths := getTypescriptModelEnumPaths(doc.Models, isEnumType)
+	hasModelEnums := func(name string) bool {
+		_, ok := modelEnumPaths[name]
+		return ok
 	}
 
 	data := Data{
 		PackageName: pkg,
+		ConstEnums:  opts.tsConstEnums,
 		Constants: mapperFunc(doc.Consts, func(c *ast.Const) TsConst {
+			var typ string
+			if c.Type != nil {
+				typ = getTypescriptType(c.Type)
+			}
+
 			return TsConst{
-				Name:  c.Identifier.Token.Value,
-				Value: getGolangValue(c.Value),
+				Name:     c.Identifier.Token.Value,
+				Type:     typ,
+				Value:    getTypescriptValue(c.Value),
+				Comments: getCommentLines(c.Comments, ast.CommentTop),
 			}
 		}),
 		Enums: mapperFunc(doc.Enums, func(enum *ast.Enum) TsEnum {
+			jsonNumber := isEnumJsonNumber(enum)
 			return TsEnum{
 				Name: enum.Name.Token.Value,
 				Keys: mapperFunc(filterFunc(enum.Sets, func(set *ast.EnumSet) bool {
 					return set.Name.Token.Value != "_"
 				}), func(set *ast.EnumSet) TsEnumKeyValue {
+					value := strcase.ToSnake(set.Name.Token.Value)
+					if jsonNumber {
+						value = strconv.FormatInt(set.Value.Value, 10)
+					}
+
 					return TsEnumKeyValue{
-						Name:  set.Name.Token.Value,
-						Value: strcase.ToSnake(set.Name.Token.Value),
+						Name:     set.Name.Token.Value,
+						Value:    value,
+						Label:    strconv.Quote(getEnumSetLabel(set)),
+						Comments: getCommentLines(set.Comments, ast.CommentTop),
 					}
 				}),
+				HasLabels:  hasEnumLabels(enum),
+				JsonNumber: jsonNumber,
+				Comments:   getCommentLines(enum.Comments, ast.CommentTop),
 			}
 		}),
 		Models: mapperFunc(doc.Models, func(model *ast.Model) TsModel {
 			return TsModel{
 				Name: model.Name.Token.Value,
 				Fields: filterFunc(mapperFunc(model.Fields, func(field *ast.Field) TsField {
-					name := strcase.ToSnake(field.Name.Token.Value)
-					for _, opt := range field.Options.List {
-						if opt.Name.Token.Value == "Json" {
-							switch v := opt.Value.(type) {
-							case *ast.ValueString:
-								name = v.Value
-							case *ast.ValueBool:
-								if !v.Value {
-									name = ""
-								}
-							}
-							break
-						}
+					name := getTypescriptFieldName(field)
+
+					typ := getTypescriptType(field.Type)
+					// unix and unixmilli timestamps are encoded as numbers
+					if getFieldTimeFormat(field) != "rfc3339" {
+						typ = "number"
+					} else if isFieldJsonString(field) {
+						typ = "string"
 					}
 
+					_, isAny := field.Type.(*ast.Any)
+
 					return TsField{
-						Name:       name,
-						Type:       getTypescriptType(field.Type),
-						IsOptional: field.IsOptional,
+						Name:        name,
+						Type:        typ,
+						Zod:         getZodFieldType(field, isModelType),
+						ZodInfer:    getZodFieldInferType(field, isModelType),
+						ZodOptional: field.IsOptional || isAny,
+						IsOptional:  field.IsOptional,
+						Comments:    getTypescriptDeprecatedComments(getCommentLines(field.Comments, ast.CommentTop), field.Options),
 					}
 				}), func(field TsField) bool {
 					return field.Name != ""
 				}),
+				OneOfs: mapperFunc(model.OneOfs, func(oneOf *ast.OneOf) TsOneOf {
+					return TsOneOf{
+						Name:  model.Name.Token.Value + oneOf.Name.Token.Value,
+						Field: strcase.ToSnake(oneOf.Name.Token.Value),
+						Variants: mapperFunc(oneOf.Variants, func(variant *ast.OneOfVariant) TsOneOfVariant {
+							return TsOneOfVariant{
+								Discriminator: strcase.ToSnake(variant.Name.Token.Value),
+								Type:          getTypescriptType(variant.Type),
+								Zod:           getZodType(variant.Type, isModelType),
+							}
+						}),
+						Comments: getCommentLines(oneOf.Comments, ast.CommentTop),
+					}
+				}),
+				Recursive: recursiveModels[model.Name.Token.Value],
+				Comments:  getCommentLines(model.Comments, ast.CommentTop),
+			}
+		}),
+		EnumPaths: mapperFunc(filterFunc(doc.Models, func(model *ast.Model) bool {
+			return hasModelEnums(model.Name.Token.Value)
+		}), func(model *ast.Model) TsModelEnumPaths {
+			return TsModelEnumPaths{
+				Name: model.Name.Token.Value,
+				Paths: mapperFunc(modelEnumPaths[model.Name.Token.Value], func(path [2]string) TsEnumPath {
+					return TsEnumPath{Field: strconv.Quote(path[0]), Path: path[1]}
+				}),
 			}
 		}),
 		HttpServices: mapperFunc(getServicesByType(doc.Services, ast.ServiceHTTP), func(service *ast.Service) TsService {
 			return TsService{
 				Name: service.Name.Token.Value,
-				Methods: mapperFunc(service.Methods, func(method *ast.Method) TsMethod {
+				// bidirectional streams need to send the request's body while receiving
+				// the response, which fetch and XHR don't support, so they are skipped
+				Methods: mapperFunc(filterFunc(service.Methods, func(method *ast.Method) bool {
+					return !isBidirectionalStream(method)
+				}), func(method *ast.Method) TsMethod {
 					var tsMethod TsMethod
 
 					tsMethod.Name = method.Name.Token.Value
 					tsMethod.ServiceName = service.Name.Token.Value
+					tsMethod.Comments = getTypescriptDeprecatedComments(getCommentLines(method.Comments, ast.CommentTop), method.Options)
 					tsMethod.Args = mapperFunc(
 						method.Args,
 						func(arg *ast.Arg) TsArg {
@@ -177,6 +306,22 @@ func generateTypescript(pkg, output string, doc *ast.Document) error {
 						}
 					})
 
+					tsMethod.HttpMethod = getMethodHttpMethod(method)
+
+					// the results are decoded as a json array, one item per return
+					var hasEnums bool
+					paths := mapperFunc(method.Returns, func(ret *ast.Return) string {
+						path := getTypescriptEnumPath(ret.Type, isEnumType, hasModelEnums)
+						if path == "" {
+							return "null"
+						}
+						hasEnums = true
+						return path
+					})
+					if hasEnums {
+						tsMethod.Decode = "[" + strings.Join(paths, ", ") + "]"
+					}
+
 					tsMethod.ReqType = "JSON"
 
 					for _, arg := range tsMethod.Args {
@@ -202,16 +347,26 @@ func generateTypescript(pkg, output string, doc *ast.Document) error {
 
 					return tsMethod
 				}),
+				Comments: getCommentLines(service.Comments, ast.CommentTop),
 			}
 		}),
 		Errors: mapperFunc(doc.Errors, func(err *ast.CustomError) TsError {
 			return TsError{
-				Name: err.Name.Token.Value,
-				Code: err.Code,
+				Name:     err.Name.Token.Value,
+				Code:     err.Code,
+				Comments: getCommentLines(err.Comments, ast.CommentTop),
 			}
 		}),
 	}
 
+	for _, service := range data.HttpServices {
+		for _, method := range service.Methods {
+			if method.RespType == "SSE" {
+				data.HasSSE = true
+			}
+		}
+	}
+
 	tmpl, err := template.
 		New("GenerateTS").
 		Funcs(defaultFuncsMap).
@@ -240,6 +395,21 @@ func generateTypescript(pkg, output string, doc *ast.Document) error {
 
 				return sb.String()
 			},
+			// the arguments of url builders, GET methods don't have stream arguments
+			"ToUrlArgs": func(args []TsArg) string {
+				var sb strings.Builder
+
+				sb.WriteString("endpoint: string")
+				for _, arg := range args {
+					sb.WriteString(", ")
+					sb.WriteString(arg.Name)
+					sb.WriteString(": ")
+					sb.WriteString(arg.Type)
+				}
+				sb.WriteString(", routes: boolean = false")
+
+				return sb.String()
+			},
 			"ToParams": func(args []TsArg) string {
 				var sb strings.Builder
 
@@ -285,6 +455,7 @@ func generateTypescript(pkg, output string, doc *ast.Document) error {
 
 				return sb.String()
 			},
+			"ToJsDoc": getTypescriptJsDoc,
 			"ToFileUploadArgName": func(args []TsArg) string {
 				for _, arg := range args {
 					if arg.Stream && arg.Type == "byte[]" {
@@ -300,12 +471,60 @@ func generateTypescript(pkg, output string, doc *ast.Document) error {
 		return err
 	}
 
-	out, err := os.Create(output)
-	if err != nil {
-		return err
+	return tmpl.ExecuteTemplate(out, name, data)
+}
+
+// getTypescriptDeprecatedComments appends the @deprecated tag of the Deprecated
+// option, so the editors strike through the usages
+func getTypescriptDeprecatedComments(comments []string, options *ast.Options) []string {
+	message, ok := options.Deprecated()
+	if !ok {
+		return comments
+	}
+
+	if message == "" {
+		return append(comments, "@deprecated")
+	}
+
+	return append(comments, "@deprecated "+message)
+}
+
+// getTypescriptJsDoc writes the comments as a /** */ block, a single line one
+// if there is only one comment, followed by the indent, so it can be placed
+// right before the documented code
+func getTypescriptJsDoc(indent string, comments []string) string {
+	if len(comments) == 0 {
+		return ""
 	}
 
-	return tmpl.ExecuteTemplate(out, "main", data)
+	// */ inside a comment would close the block early
+	escape := func(comment string) string {
+		return strings.ReplaceAll(comment, "*/", "*\\/")
+	}
+
+	var sb strings.Builder
+	if len(comments) == 1 {
+		sb.WriteString("/** ")
+		sb.WriteString(escape(comments[0]))
+		sb.WriteString(" */\n")
+		sb.WriteString(indent)
+		return sb.String()
+	}
+
+	sb.WriteString("/**\n")
+	for _, comment := range comments {
+		sb.WriteString(indent)
+		sb.WriteString(" *")
+		if comment != "" {
+			sb.WriteString(" ")
+			sb.WriteString(escape(comment))
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString(indent)
+	sb.WriteString(" */\n")
+	sb.WriteString(indent)
+	return sb.String()
 }
 
 func getTypescriptValue(value ast.Value) string {
@@ -320,10 +539,22 @@ func getTypescriptValue(value ast.Value) string {
 		}
 	case *ast.ValueInt:
 		return strconv.FormatInt(v.Value, 10)
+	case *ast.ValueUint:
+		// numbers above Number.MAX_SAFE_INTEGER lose precision,
+		// so they are emitted as bigint literals
+		if v.Value > 1<<53-1 {
+			return strconv.FormatUint(v.Value, 10) + "n"
+		}
+		return strconv.FormatUint(v.Value, 10)
 	case *ast.ValueByteSize:
 		return fmt.Sprintf(`%d`, v.Value*int64(v.Scale))
 	case *ast.ValueDuration:
 		return fmt.Sprintf(`%d`, v.Value*int64(v.Scale))
+	case *ast.ValueObject:
+		return getTypescriptObjectValue(v)
+	case *ast.ValueList:
+		values := mapperFunc(v.Values, getTypescriptValue)
+		return "[" + strings.Join(values, ", ") + "]"
 	default:
 		var sb strings.Builder
 		value.Format(&sb)
@@ -331,6 +562,66 @@ func getTypescriptValue(value ast.Value) string {
 	}
 }
 
+// getTypescriptObjectValue returns the object literal with the same names as the model's
+// interface, the fields which are not encoded in json are not part of the interface
+func getTypescriptObjectValue(object *ast.ValueObject) string {
+	fieldsMap := make(map[string]*ast.Field, len(object.Model.Fields))
+	for _, field := range object.Model.Fields {
+		fieldsMap[field.Name.Token.Value] = field
+	}
+
+	var sb strings.Builder
+
+	sb.WriteString("{")
+	for _, field := range object.Fields {
+		name := getTypescriptFieldName(fieldsMap[field.Name.Token.Value])
+		if name == "" {
+			continue
+		}
+
+		if sb.Len() > 1 {
+			sb.WriteString(",")
+		}
+		sb.WriteString(" ")
+		sb.WriteString(strcase.ToCamel(name))
+		sb.WriteString(": ")
+
+		// uint64 values are numbers in the models, not bigints
+		if v, ok := field.Value.(*ast.ValueUint); ok {
+			sb.WriteString(strconv.FormatUint(v.Value, 10))
+		} else {
+			sb.WriteString(getTypescriptValue(field.Value))
+		}
+	}
+	if sb.Len() > 1 {
+		sb.WriteString(" ")
+	}
+	sb.WriteString("}")
+
+	return sb.String()
+}
+
+// getTypescriptFieldName returns the json name of the field, which is snake_case or
+// the Json option's value, and empty if the field is not encoded in json
+func getTypescriptFieldName(field *ast.Field) string {
+	name := strcase.ToSnake(field.Name.Token.Value)
+	for _, opt := range field.Options.List {
+		if opt.Name.Token.Value == "Json" {
+			switch v := opt.Value.(type) {
+			case *ast.ValueString:
+				name = v.Value
+			case *ast.ValueBool:
+				if !v.Value {
+					name = ""
+				}
+			}
+			break
+		}
+	}
+
+	return name
+}
+
 func getTypescriptType(typ ast.Type) string {
 	switch t := typ.(type) {
 	case *ast.Bool:
@@ -346,9 +637,17 @@ func getTypescriptType(typ ast.Type) string {
 	case *ast.Array:
 		typ := getTypescriptType(t.Type)
 		return typ + "[]"
+	case *ast.Set:
+		// sets are encoded as json arrays
+		typ := getTypescriptType(t.Type)
+		return typ + "[]"
 	case *ast.Map:
 		key := getTypescriptType(t.Key)
 		value := getTypescriptType(t.Value)
+		if _, ok := t.Key.(*ast.CustomType); ok {
+			// enums can't be used as index signature's type
+			return `Partial<Record<` + key + `, ` + value + `>>`
+		}
 		return `{ [key: ` + key + `]: ` + value + ` }`
 	case *ast.CustomType:
 		return t.Token.Value
@@ -358,3 +657,264 @@ func getTypescriptType(typ ast.Type) string {
 		panic(fmt.Errorf("unknown type: %T", t))
 	}
 }
+
+// getTypescriptModelEnumPaths returns the json fields, and their enumPath literals,
+// of the models which contain enums directly or through their nested models
+func getTypescriptModelEnumPaths(models []*ast.Model, isEnumType func(value string) bool) map[string][][2]string {
+	result := make(map[string][][2]string)
+	hasModelEnums := func(name string) bool {
+		_, ok := result[name]
+		return ok
+	}
+
+	// a model might reference the models declared after it or itself,
+	// so the paths are collected until none of the models changes
+	for changed := true; changed; {
+		changed = false
+
+		for _, model := range models {
+			var paths [][2]string
+
+			for _, field := range model.Fields {
+				name := getTypescriptFieldName(field)
+				if name == "" {
+					continue
+				}
+
+				if path := getTypescriptEnumPath(field.Type, isEnumType, hasModelEnums); path != "" {
+					paths = append(paths, [2]string{name, path})
+				}
+			}
+
+			for _, oneOf := range model.OneOfs {
+				var variants []string
+				for _, variant := range oneOf.Variants {
+					if path := getTypescriptEnumPath(variant.Type, isEnumType, hasModelEnums); path != "" {
+						variants = append(variants, strconv.Quote(strcase.ToSnake(variant.Name.Token.Value))+": "+path)
+					}
+				}
+
+				if len(variants) > 0 {
+					paths = append(paths, [2]string{strcase.ToSnake(oneOf.Name.Token.Value), "{ o: { " + strings.Join(variants, ", ") + " } }"})
+				}
+			}
+
+			if len(paths) == 0 {
+				continue
+			}
+
+			if !slices.Equal(paths, result[model.Name.Token.Value]) {
+				changed = true
+			}
+			result[model.Name.Token.Value] = paths
+		}
+	}
+
+	return result
+}
+
+// getTypescriptEnumPath returns the enumPath literal of the helper, which describes where
+// the enums are in the decoded value of typ, or empty if typ doesn't contain any enum
+func getTypescriptEnumPath(typ ast.Type, isEnumType, hasModelEnums func(value string) bool) string {
+	switch t := typ.(type) {
+	case *ast.Array:
+		if path := getTypescriptEnumPath(t.Type, isEnumType, hasModelEnums); path != "" {
+			return "{ a: " + path + " }"
+		}
+	case *ast.Set:
+		// sets are encoded as json arrays
+		if path := getTypescriptEnumPath(t.Type, isEnumType, hasModelEnums); path != "" {
+			return "{ a: " + path + " }"
+		}
+	case *ast.Map:
+		var parts []string
+		if key, ok := t.Key.(*ast.CustomType); ok && isEnumType(key.Token.Value) {
+			parts = append(parts, "k: "+strconv.Quote(key.Token.Value))
+		}
+		if path := getTypescriptEnumPath(t.Value, isEnumType, hasModelEnums); path != "" {
+			parts = append(parts, "r: "+path)
+		}
+		if len(parts) > 0 {
+			return "{ " + strings.Join(parts, ", ") + " }"
+		}
+	case *ast.CustomType:
+		if isEnumType(t.Token.Value) {
+			return "{ e: " + strconv.Quote(t.Token.Value) + " }"
+		}
+		if hasModelEnums(t.Token.Value) {
+			return "{ m: " + strconv.Quote(t.Token.Value) + " }"
+		}
+	}
+
+	return ""
+}
+
+// getZodFieldType returns the zod schema of the field. Optional fields are
+// omitted by the go server, and non optional arrays, sets, maps and models
+// are encoded as null when they are not set, so they are nullable
+func getZodFieldType(field *ast.Field, isModelType func(value string) bool) string {
+	typ := getZodType(field.Type, isModelType)
+	if getFieldTimeFormat(field) != "rfc3339" {
+		typ = `z.number().int()`
+	} else if isFieldJsonString(field) {
+		typ = `z.string()`
+	}
+
+	if field.IsOptional {
+		return typ + ".optional()"
+	}
+
+	switch t := field.Type.(type) {
+	case *ast.Array, *ast.Set, *ast.Map:
+		return typ + ".nullable()"
+	case *ast.CustomType:
+		if isModelType(t.Token.Value) {
+			return typ + ".nullable()"
+		}
+	}
+
+	return typ
+}
+
+func getZodType(typ ast.Type, isModelType func(value string) bool) string {
+	switch t := typ.(type) {
+	case *ast.Bool:
+		return `z.boolean()`
+	case *ast.Int, *ast.Float, *ast.Uint, *ast.Byte:
+		return `z.number()`
+	case *ast.String:
+		return `z.string()`
+	case *ast.Any:
+		return `z.any()`
+	case *ast.Timestamp:
+		return `z.string()`
+	case *ast.Array:
+		return `z.array(` + getZodType(t.Type, isModelType) + `)`
+	case *ast.Set:
+		// sets are encoded as json arrays
+		return `z.array(` + getZodType(t.Type, isModelType) + `)`
+	case *ast.Map:
+		// json object keys are always strings
+		return `z.record(z.string(), ` + getZodType(t.Value, isModelType) + `)`
+	case *ast.CustomType:
+		// models are referenced lazily, so the order of declaration is not an issue,
+		// the schemas of the recursive models are annotated by their written out type,
+		// as typescript can't infer a type which refers to itself
+		if isModelType(t.Token.Value) {
+			return `z.lazy(() => ` + t.Token.Value + `Schema)`
+		}
+		return t.Token.Value + `Schema`
+	default:
+		panic(fmt.Errorf("unknown type: %T", t))
+	}
+}
+
+// getZodInferType returns the typescript type which zod infers from the schema of
+// getZodType, it's written out for the recursive models, see getRecursiveModels
+func getZodInferType(typ ast.Type) string {
+	switch t := typ.(type) {
+	case *ast.Bool:
+		return `boolean`
+	case *ast.Int, *ast.Float, *ast.Uint, *ast.Byte:
+		return `number`
+	case *ast.String, *ast.Timestamp:
+		return `string`
+	case *ast.Any:
+		return `any`
+	case *ast.Array:
+		return `Array<` + getZodInferType(t.Type) + `>`
+	case *ast.Set:
+		return `Array<` + getZodInferType(t.Type) + `>`
+	case *ast.Map:
+		return `Record<string, ` + getZodInferType(t.Value) + `>`
+	case *ast.CustomType:
+		return t.Token.Value
+	default:
+		panic(fmt.Errorf("unknown type: %T", t))
+	}
+}
+
+// getZodFieldInferType returns the typescript type which zod infers from the
+// schema of getZodFieldType
+func getZodFieldInferType(field *ast.Field, isModelType func(value string) bool) string {
+	typ := getZodInferType(field.Type)
+	if getFieldTimeFormat(field) != "rfc3339" {
+		typ = `number`
+	} else if isFieldJsonString(field) {
+		typ = `string`
+	}
+
+	if field.IsOptional {
+		return typ + ` | undefined`
+	}
+
+	switch t := field.Type.(type) {
+	case *ast.Array, *ast.Set, *ast.Map:
+		return typ + ` | null`
+	case *ast.CustomType:
+		if isModelType(t.Token.Value) {
+			return typ + ` | null`
+		}
+	}
+
+	return typ
+}
+
+// getRecursiveModels returns the models which refer to themselves, directly or through
+// other models, by their fields or oneofs, e.g. model Node { Children: []Node }
+func getRecursiveModels(models []*ast.Model) map[string]bool {
+	refs := make(map[string][]string, len(models))
+	for _, model := range models {
+		var names []string
+		add := func(typ ast.Type) {
+			for t := typ; t != nil; {
+				switch v := t.(type) {
+				case *ast.Array:
+					t = v.Type
+				case *ast.Set:
+					t = v.Type
+				case *ast.Map:
+					t = v.Value
+				case *ast.CustomType:
+					names = append(names, v.Token.Value)
+					t = nil
+				default:
+					t = nil
+				}
+			}
+		}
+
+		for _, field := range model.Fields {
+			add(field.Type)
+		}
+		for _, oneOf := range model.OneOfs {
+			for _, variant := range oneOf.Variants {
+				add(variant.Type)
+			}
+		}
+
+		refs[model.Name.Token.Value] = names
+	}
+
+	recursive := make(map[string]bool)
+	for _, model := range models {
+		name := model.Name.Token.Value
+		visited := make(map[string]bool)
+		stack := slices.Clone(refs[name])
+		for len(stack) > 0 {
+			ref := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if ref == name {
+				recursive[name] = true
+				break
+			}
+			if visited[ref] {
+				continue
+			}
+			visited[ref] = true
+			stack = append(stack, refs[ref]...)
+		}
+	}
+
+	return recursive
+}
@@ -0,0 +1,607 @@
+package gen
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateTypescriptSet(t *testing.T) {
+	const input = `
+enum Role {
+	Admin
+	Member
+}
+
+model User {
+	Tags: set<string>
+	Roles?: set<Role>
+}
+`
+
+	output := generateOutput(t, ".ts", input)
+
+	assert.Contains(t, output, "tags: string[];")
+	assert.Contains(t, output, "roles?: Role[];")
+}
+
+func TestGenerateZod(t *testing.T) {
+	const input = `
+enum Role {
+	Admin
+	Member
+}
+
+model Address {
+	Street: string
+}
+
+model User {
+	Id: string
+	Role?: Role
+	Tags: []string
+	Home: Address
+}
+`
+
+	output := generateOutput(t, ".zod.ts", input)
+
+	assert.Contains(t, output, `import { z } from "zod";`)
+	assert.Contains(t, output, `export const RoleSchema = z.enum(["admin", "member"]);`)
+	assert.Contains(t, output, `export type Role = z.infer<typeof RoleSchema>;`)
+	assert.Contains(t, output, `export const UserSchema = z.object({`)
+	assert.Contains(t, output, `id: z.string(),`)
+	assert.Contains(t, output, `role: RoleSchema.optional(),`)
+	assert.Contains(t, output, `tags: z.array(z.string()).nullable(),`)
+	assert.Contains(t, output, `home: z.lazy(() => AddressSchema).nullable(),`)
+	assert.Contains(t, output, `export type User = z.infer<typeof UserSchema>;`)
+
+	// zod output only includes schemas
+	assert.NotContains(t, output, "export enum Role")
+	assert.NotContains(t, output, "export interface User")
+}
+
+func TestGenerateZodNestedModel(t *testing.T) {
+	const input = `
+enum Role {
+	Admin
+	Member
+}
+
+model Tag {
+	Name: string
+}
+
+model Team {
+	Name: string
+	Members: map<string, []Tag>
+	Roles?: map<string, Role>
+	Groups: [][]Tag
+}
+`
+
+	output := generateOutput(t, ".zod.ts", input)
+
+	assert.Contains(t, output, "export const TeamSchema = z.object({\n"+
+		"\tname: z.string(),\n"+
+		"\tmembers: z.record(z.string(), z.array(z.lazy(() => TagSchema))).nullable(),\n"+
+		"\troles: z.record(z.string(), RoleSchema).optional(),\n"+
+		"\tgroups: z.array(z.array(z.lazy(() => TagSchema))).nullable(),\n"+
+		"});")
+	assert.Contains(t, output, "export type Team = z.infer<typeof TeamSchema>;")
+}
+
+func TestGenerateZodRecursiveModel(t *testing.T) {
+	const input = `
+model Node {
+	Name: string
+	Meta: any
+	Parent?: Node
+	Children: []Node
+	Link?: Link
+}
+
+model Link {
+	Target?: Node
+}
+
+model Tag {
+	Node?: Node
+}
+`
+
+	output := generateOutput(t, ".zod.ts", input)
+
+	// typescript can't infer the type of a schema which refers to itself, so it's written out
+	assert.Contains(t, output, "export type Node = {\n"+
+		"\tname: string;\n"+
+		"\tmeta?: any;\n"+
+		"\tparent?: Node | undefined;\n"+
+		"\tchildren: Array<Node> | null;\n"+
+		"\tlink?: Link | undefined;\n"+
+		"};\n"+
+		"export const NodeSchema: z.ZodType<Node> = z.object({\n"+
+		"\tname: z.string(),\n"+
+		"\tmeta: z.any(),\n"+
+		"\tparent: z.lazy(() => NodeSchema).optional(),\n"+
+		"\tchildren: z.array(z.lazy(() => NodeSchema)).nullable(),\n"+
+		"\tlink: z.lazy(() => LinkSchema).optional(),\n"+
+		"});")
+	assert.NotContains(t, output, "export type Node = z.infer")
+
+	// the models in a cycle are recursive as well
+	assert.Contains(t, output, "export const LinkSchema: z.ZodType<Link> = z.object({")
+
+	// referring to a recursive model doesn't make a model recursive
+	assert.Contains(t, output, "export const TagSchema = z.object({")
+	assert.Contains(t, output, "export type Tag = z.infer<typeof TagSchema>;")
+}
+
+func TestGenerateTypescriptUintConst(t *testing.T) {
+	output := generateOutput(t, ".ts", `
+const Mask = 18446744073709551615
+const Small = 9007199254740991
+`)
+
+	assert.Contains(t, output, "export const Mask = 18446744073709551615n")
+	assert.Contains(t, output, "export const Small = 9007199254740991\n")
+}
+
+func TestGenerateTypescriptOneOf(t *testing.T) {
+	const input = `
+model User {
+	Id: string
+}
+
+model Result {
+	oneof Value {
+		User: User
+		Count: int64
+	}
+}
+`
+
+	output := generateOutput(t, ".ts", input)
+
+	assert.Contains(t, output, "value?: ResultValue;")
+	assert.Contains(t, output, `| { type: "user"; value: User }`)
+	assert.Contains(t, output, `| { type: "count"; value: number };`)
+
+	output = generateOutput(t, ".zod.ts", input)
+
+	assert.Contains(t, output, `export const ResultValueSchema = z.discriminatedUnion("type", [`)
+	assert.Contains(t, output, `z.object({ type: z.literal("user"), value: z.lazy(() => UserSchema) }),`)
+	assert.Contains(t, output, "value: ResultValueSchema.optional(),")
+}
+
+func TestGenerateTypescriptEnumLabel(t *testing.T) {
+	const input = `
+enum Status {
+	Active { Label = "Currently active" }
+	Pending
+}
+`
+
+	output := generateOutput(t, ".ts", input)
+
+	assert.Contains(t, output, "export const StatusLabels: Record<Status, string> = {")
+	assert.Contains(t, output, `[Status.Active]: "Currently active",`)
+	assert.Contains(t, output, `[Status.Pending]: "Pending",`)
+
+	output = generateOutput(t, ".zod.ts", input)
+
+	assert.Contains(t, output, `active: "Currently active",`)
+}
+
+func TestGenerateTypescriptEnumMapKey(t *testing.T) {
+	const input = `
+enum Role {
+	Admin
+}
+
+model User {
+	Counts: map<Role, int64>
+}
+`
+
+	output := generateOutput(t, ".ts", input)
+
+	assert.Contains(t, output, "counts: Partial<Record<Role, number>>;")
+}
+
+func TestGenerateTypescriptModelExtends(t *testing.T) {
+	const input = `
+model User {
+	Id: string
+}
+
+model Admin {
+	...User
+	Role: string
+}
+`
+
+	output := generateOutput(t, ".ts", input)
+
+	assert.Contains(t, output, "export interface Admin {\n\tid: string;\n\trole: string;\n}")
+}
+
+func TestGenerateTypescriptMapValues(t *testing.T) {
+	const input = `
+model Flags {
+	Enabled: map<string, bool>
+	UpdatedAt: map<string, timestamp>
+	Extra?: map<string, any>
+	Values: []bool
+}
+`
+
+	output := generateOutput(t, ".ts", input)
+
+	assert.Contains(t, output, "enabled: { [key: string]: boolean };")
+	assert.Contains(t, output, "updatedAt: { [key: string]: string };")
+	assert.Contains(t, output, "extra?: { [key: string]: any };")
+	assert.Contains(t, output, "values: boolean[];")
+
+	output = generateOutput(t, ".zod.ts", input)
+
+	assert.Contains(t, output, "enabled: z.record(z.string(), z.boolean())")
+	assert.Contains(t, output, "updatedAt: z.record(z.string(), z.string())")
+	assert.Contains(t, output, "values: z.array(z.boolean())")
+}
+
+func TestGenerateTypescriptUrlBuilder(t *testing.T) {
+	const input = `
+service HttpUserService {
+	GetById(id: string, tags: []string) => (name: string) {
+		HttpMethod = "GET"
+	}
+	Create(name: string) => (id: string)
+}
+`
+
+	output := generateOutput(t, ".ts", input)
+
+	assert.Contains(t, output, "  static getByIdUrl(endpoint: string, id: string, tags: string[], routes: boolean = false): string {\n"+
+		"    return buildUrl(endpoint, \"HttpUserService.GetById\", { id, tags }, routes);\n"+
+		"  }\n")
+	assert.NotContains(t, output, "createUrl")
+
+	// the query values are escaped and the params are encoded as json
+	assert.Contains(t, output, `query.push("method=" + encodeURIComponent(method));`)
+	assert.Contains(t, output, `query.push("params=" + encodeURIComponent(JSON.stringify(params)));`)
+	assert.Contains(t, output, `path = path.replace(/\/+$/, "") + "/" + method;`)
+}
+
+func TestGenerateTypescriptTimeFormat(t *testing.T) {
+	const input = `
+model Event {
+	At: timestamp { TimeFormat = "unixmilli" }
+	Seen?: timestamp { TimeFormat = "unix" }
+	Created: timestamp
+}
+`
+
+	output := generateOutput(t, ".ts", input)
+
+	assert.Contains(t, output, "at: number;")
+	assert.Contains(t, output, "seen?: number;")
+	assert.Contains(t, output, "created: string;")
+
+	output = generateOutput(t, ".zod.ts", input)
+
+	assert.Contains(t, output, "at: z.number().int(),")
+	assert.Contains(t, output, "seen: z.number().int().optional(),")
+	assert.Contains(t, output, "created: z.string(),")
+}
+
+func TestGenerateTypescriptJsonString(t *testing.T) {
+	const input = `
+model Account {
+	Id: int64 { JsonString }
+	Balance?: uint64 { JsonString }
+	Count: int64
+}
+`
+
+	output := generateOutput(t, ".ts", input)
+
+	assert.Contains(t, output, "id: string;")
+	assert.Contains(t, output, "balance?: string;")
+	assert.Contains(t, output, "count: number;")
+
+	output = generateOutput(t, ".zod.ts", input)
+
+	assert.Contains(t, output, "id: z.string(),")
+	assert.Contains(t, output, "balance: z.string().optional(),")
+	assert.Contains(t, output, "count: z.number(),")
+}
+
+func TestGenerateTypescriptEnumValues(t *testing.T) {
+	const input = `
+enum Emotion int8 {
+	_
+	Sad
+	Happy = 5
+	Excited
+} { JsonNumber = true }
+
+error ErrNotFound { Msg = "not found" }
+`
+
+	goValues := make(map[string]string)
+	for _, m := range regexp.MustCompile(`(?m)^\tEmotion_(\w+)\s+Emotion = (\d+)$`).FindAllStringSubmatch(generateOutput(t, ".go", input), -1) {
+		goValues[m[1]] = m[2]
+	}
+	assert.Equal(t, map[string]string{"Sad": "1", "Happy": "5", "Excited": "6"}, goValues)
+
+	enumPattern := regexp.MustCompile(`(?s)export enum Emotion \{\n(.*?)\n\}`)
+	constPattern := regexp.MustCompile(`(?s)export const Emotion = \{\n(.*?)\n\} as const;`)
+
+	tsValues := func(body string) map[string]string {
+		values := make(map[string]string)
+		for _, m := range regexp.MustCompile(`(\w+)(?: =|:) (\d+),`).FindAllStringSubmatch(body, -1) {
+			values[m[1]] = m[2]
+		}
+		return values
+	}
+
+	// the _ key is not generated and the values are the same as the go generator's
+	output := generateOutput(t, ".ts", input)
+	body := enumPattern.FindStringSubmatch(output)
+	if assert.NotNil(t, body) {
+		assert.Equal(t, goValues, tsValues(body[1]))
+	}
+	assert.Contains(t, output, "export enum ErrorCode {\n    ErrNotFound = 1,\n}")
+
+	output = generateOutput(t, ".ts", input, WithTsConstEnums())
+	body = constPattern.FindStringSubmatch(output)
+	if assert.NotNil(t, body) {
+		assert.Equal(t, goValues, tsValues(body[1]))
+	}
+	assert.Contains(t, output, "export type Emotion = (typeof Emotion)[keyof typeof Emotion];")
+	assert.Contains(t, output, "export const ErrorCode = {\n    ErrNotFound: 1,\n} as const;")
+	assert.NotContains(t, output, "enum ")
+}
+
+func TestGenerateTypescriptJsDoc(t *testing.T) {
+	const input = `
+# user's role
+enum Role {
+	# normal user
+	User
+	# administrator
+	# closes */ early
+	Admin
+}
+
+# a user
+#
+# with a role
+model User {
+	# user's id
+	Id: string
+}
+
+# user's service
+service HttpUserService {
+	# returns the user
+	GetById(id: string) => (user: User)
+}
+`
+
+	output := generateOutput(t, ".ts", input)
+
+	assert.Contains(t, output, "/** user's role */\nexport enum Role {")
+	assert.Contains(t, output, "    /** normal user */\n    User = \"user\",")
+	assert.Contains(t, output, "    /**\n     * administrator\n     * closes *\\/ early\n     */\n    Admin = \"admin\",")
+	assert.Contains(t, output, "/**\n * a user\n *\n * with a role\n */\nexport interface User {")
+	assert.Contains(t, output, "\t/** user's id */\n\tid: string;")
+	assert.Contains(t, output, "/** user's service */\nexport class HttpUserService {")
+	assert.Contains(t, output, "  /** returns the user */\n  getById(id: string, _opts?: reqOpts)")
+
+	output = generateOutput(t, ".ts", input, WithTsConstEnums())
+
+	assert.Contains(t, output, "/** user's role */\nexport const Role = {")
+	assert.Contains(t, output, "    /** normal user */\n    User: \"user\",")
+}
+
+func TestGenerateTypescriptDeprecated(t *testing.T) {
+	const input = `
+model User {
+	# user's name
+	Name: string { Deprecated = "use fullName instead" }
+	Nickname: string { Deprecated }
+	FullName: string
+}
+
+service HttpUserService {
+	GetByName(name: string) => (user: User) { Deprecated = "use getById instead" }
+}
+`
+
+	output := generateOutput(t, ".ts", input)
+
+	assert.Contains(t, output, "\t/**\n\t * user's name\n\t * @deprecated use fullName instead\n\t */\n\tname: string;")
+	assert.Contains(t, output, "\t/** @deprecated */\n\tnickname: string;")
+	assert.Contains(t, output, "  /** @deprecated use getById instead */\n  getByName(")
+}
+
+func TestGenerateTypescriptRpcOnlyWarning(t *testing.T) {
+	const input = `
+service RpcUserService {
+	GetById(id: string) => (name: string)
+}
+
+service RpcOrderService {
+	Cancel(id: string)
+}
+`
+
+	var warnings strings.Builder
+	output := generateOutput(t, ".ts", input, WithWarnings(&warnings))
+
+	assert.NotContains(t, output, "class RpcUserService")
+	assert.Equal(t, "Warning: the schema has only rpc services, RpcUserService, RpcOrderService, typescript only generates the clients of http services\n", warnings.String())
+
+	warnings.Reset()
+	generateOutput(t, ".ts", input+`
+service HttpUserService {
+	GetById(id: string) => (name: string)
+}
+`, WithWarnings(&warnings))
+
+	assert.Empty(t, warnings.String())
+}
+
+func TestGenerateTypescriptBidirectionalStreamWarning(t *testing.T) {
+	const input = `
+model Message {
+	Text: string
+}
+
+service HttpChatService {
+	Chat(room: string, msgs: stream Message) => (replies: stream Message)
+	Watch(room: string) => (replies: stream Message)
+}
+`
+
+	var warnings strings.Builder
+	output := generateOutput(t, ".ts", input, WithWarnings(&warnings))
+
+	assert.NotContains(t, output, "chat(")
+	assert.Contains(t, output, "watch(")
+	assert.Equal(t, "Warning: HttpChatService.Chat is a bidirectional stream, it's skipped as typescript clients can't send the request's body while receiving the response\n", warnings.String())
+
+	// the zod schemas have no clients, so nothing is reported
+	warnings.Reset()
+	generateOutput(t, ".zod.ts", input, WithWarnings(&warnings))
+
+	assert.Empty(t, warnings.String())
+}
+
+func TestGenerateTypescriptAbortSignal(t *testing.T) {
+	const input = `
+service HttpUserService {
+	Get(id: string) => (name: string)
+	Watch() => (names: stream string)
+}
+`
+
+	output := generateOutput(t, ".ts", input)
+
+	assert.Contains(t, output, "abort: _opts?.signal,")
+	assert.Contains(t, output, "abort?: AbortSignal;")
+	assert.Contains(t, output, "signal: meta.abort,")
+	assert.Contains(t, output, "return createSSE(url, body, headers, withCredentials, meta.abort, meta.decode?.[0], policy);")
+	assert.NotContains(t, output, "meta.abort?.signal")
+}
+
+func TestGenerateTypescriptSubscriptionManager(t *testing.T) {
+	const input = `
+service HttpUserService {
+	Get(id: string) => (name: string)
+	Watch() => (names: stream string)
+}
+`
+
+	output := generateOutput(t, ".ts", input)
+
+	assert.Contains(t, output, "export class SubscriptionManager {")
+	assert.Contains(t, output, "  add<T>(\n    open: () => Promise<subscription<T>>,\n    fn: (event: T) => void\n  ): () => void {")
+	assert.Contains(t, output, "  pause(): void {")
+	assert.Contains(t, output, "  resume(): void {")
+	assert.Contains(t, output, "  closeAll(): void {")
+	assert.Contains(t, output, "entry.timer = setTimeout(() => this.connect(entry), this.retryDelay);")
+
+	// the manager is only generated if there is a stream return
+	output = generateOutput(t, ".ts", `
+service HttpUserService {
+	Get(id: string) => (name: string)
+	Avatar(id: string) => (data: stream []byte)
+}
+`)
+	assert.NotContains(t, output, "SubscriptionManager")
+}
+
+func TestGenerateTypescriptConstLiteral(t *testing.T) {
+	const input = `
+model Address {
+	City: string
+}
+
+model User {
+	FirstName: string
+	Age: uint64
+	Secret: string { Json = false }
+	Address: Address
+	Tags: []string
+}
+
+const DefaultUser: User = {
+	FirstName = "anon"
+	Age = 18446744073709551615
+	Secret = "hidden"
+	Address = { City = "Toronto" }
+	Tags = []
+}
+`
+
+	output := generateOutput(t, ".ts", input)
+
+	assert.Contains(t, output, `export const DefaultUser: User = { firstName: "anon", age: 18446744073709551615, address: { city: "Toronto" }, tags: [] }`)
+}
+
+func TestGenerateTypescriptEnumDecode(t *testing.T) {
+	const input = `
+enum Role {
+	Admin
+	Member
+}
+
+enum Level int8 {
+	Low
+	High
+} { JsonNumber = true }
+
+model Tag {
+	Name: string
+}
+
+model User {
+	Role?: Role
+	Levels: map<Role, []Level>
+	Friends: []User
+	Tag: Tag
+}
+
+service HttpUserService {
+	Get(id: string) => (user: User, role: Role, count: int64)
+	Watch() => (users: stream User)
+	Count() => (count: int64)
+}
+`
+
+	output := generateOutput(t, ".ts", input)
+
+	// the known values reuse the enum objects
+	assert.Contains(t, output, "    Role: [Role.Admin, Role.Member],\n")
+	assert.Contains(t, output, "    Level: [Level.Low, Level.High],\n")
+
+	// models without enums, even nested ones, are not decoded
+	assert.Contains(t, output, `	User: { "role": { e: "Role" }, "levels": { k: "Role", r: { a: { e: "Level" } } }, "friends": { a: { m: "User" } } },`)
+	assert.NotContains(t, output, "\tTag: {")
+
+	assert.Contains(t, output, `        decode: [{ m: "User" }, { e: "Role" }, null],`)
+	assert.Contains(t, output, `        decode: [{ m: "User" }],`)
+	assert.Equal(t, 2, strings.Count(output, "        decode: "))
+
+	// an unknown value is kept, rejected or replaced based on the caller's policy
+	assert.Contains(t, output, "  policy: enumPolicy = enumPolicy.IGNORE\n): CallerFunc {")
+	assert.Contains(t, output, "    throw new EnumDecodeError(name, value);")
+	assert.Contains(t, output, "  return values[0];")
+	assert.Contains(t, output, "const result = decodeResults(JSON.parse(msg).result, meta.decode, policy);")
+	assert.Contains(t, output, "return createSSE(url, body, headers, withCredentials, meta.abort, meta.decode?.[0], policy);")
+}
@@ -47,6 +47,12 @@ func PrettyMessageWithFilename(filename string, start int, end int, msg string)
 }
 
 func PrettyMessage(filename string, src string, start int, end int, msg string) string {
+	return prettyMessage("Error", filename, src, start, end, msg)
+}
+
+// prettyMessage formats the msg with the given level, e.g. Error or Warning,
+// followed by the lines of src around the start position
+func prettyMessage(level string, filename string, src string, start int, end int, msg string) string {
 	lines := strings.Split(src, "\n")
 	lineStart, column := getLineAndColumn(src, start)
 
@@ -54,9 +60,9 @@ func PrettyMessage(filename string, src string, start int, end int, msg string)
 
 	// Print error message with line and column
 	if filename != "" {
-		fmt.Fprintf(&output, "Error: %s at (%s:%d:%d)\n\n", msg, filename, lineStart+1, column+1)
+		fmt.Fprintf(&output, "%s: %s at (%s:%d:%d)\n\n", level, msg, filename, lineStart+1, column+1)
 	} else {
-		fmt.Fprintf(&output, "Error: %s at line %d, column %d\n\n", msg, lineStart+1, column+1)
+		fmt.Fprintf(&output, "%s: %s at line %d, column %d\n\n", level, msg, lineStart+1, column+1)
 	}
 
 	// Show context (3 lines before and after)
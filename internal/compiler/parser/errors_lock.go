@@ -0,0 +1,96 @@
+package parser
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"sort"
+
+	"github.com/hexe-dev/hexe/internal/compiler/ast"
+)
+
+// ErrorsLock maps the custom errors' names to their codes, so once a code is
+// assigned, it stays the same when other errors are added or removed, which
+// otherwise shifts the codes, as they are assigned by the sorted names
+type ErrorsLock map[string]int64
+
+// ReadErrorsLock reads the lock file, a missing file is an empty lock
+func ReadErrorsLock(filename string) (ErrorsLock, error) {
+	lock := make(ErrorsLock)
+
+	b, err := os.ReadFile(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return lock, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err = json.Unmarshal(b, &lock); err != nil {
+		return nil, err
+	}
+
+	return lock, nil
+}
+
+// Write writes the lock file, the names are sorted so the file is stable
+func (l ErrorsLock) Write(filename string) error {
+	b, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filename, append(b, '\n'), os.ModePerm)
+}
+
+// Apply assigns the locked codes to the errors without an explicit code, allocates
+// new codes, after the largest locked one, to the new errors and records them in
+// the lock. The removed errors are kept, so their codes are not reused. It should
+// be called before Validate, as Validate assigns the codes which are not set
+func (l ErrorsLock) Apply(docs ...*ast.Document) error {
+	var customErrors []*ast.CustomError
+	for _, doc := range docs {
+		customErrors = append(customErrors, doc.Errors...)
+	}
+
+	// the same order as Validate, so the new errors get the same codes without the lock
+	sort.Slice(customErrors, func(i, j int) bool {
+		return customErrors[i].Name.Token.Value < customErrors[j].Name.Token.Value
+	})
+
+	owners := make(map[int64]string, len(l))
+	var maxCode int64
+	for name, code := range l {
+		owners[code] = name
+		maxCode = max(maxCode, code)
+	}
+
+	for _, e := range customErrors {
+		name := e.Name.Token.Value
+
+		if e.Code == 0 {
+			e.Code = l[name]
+			continue
+		}
+
+		if locked, ok := l[name]; ok && locked != e.Code {
+			return NewError(e.Name.Token, "code %d is different from the locked code %d, remove it from the errors lock file to change it", e.Code, locked)
+		}
+
+		if owner, ok := owners[e.Code]; ok && owner != name {
+			return NewError(e.Name.Token, "code %d is locked by %s in the errors lock file", e.Code, owner)
+		}
+
+		maxCode = max(maxCode, e.Code)
+	}
+
+	for _, e := range customErrors {
+		if e.Code == 0 {
+			maxCode++
+			e.Code = maxCode
+		}
+
+		l[e.Name.Token.Value] = e.Code
+	}
+
+	return nil
+}
@@ -0,0 +1,96 @@
+package parser
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/hexe-dev/hexe/internal/compiler/ast"
+	"github.com/stretchr/testify/assert"
+)
+
+func lockErrorCodes(t *testing.T, filename string, input string) (map[string]int64, error) {
+	t.Helper()
+
+	doc, err := ParseDocument(NewParser(input))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	lock, err := ReadErrorsLock(filename)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	if err := lock.Apply(doc); err != nil {
+		return nil, err
+	}
+
+	if err := Validate([]*ast.Document{doc}...); err != nil {
+		return nil, err
+	}
+
+	if !assert.NoError(t, lock.Write(filename)) {
+		t.FailNow()
+	}
+
+	codes := make(map[string]int64)
+	for _, e := range doc.Errors {
+		codes[e.Name.Token.Value] = e.Code
+	}
+
+	return codes, nil
+}
+
+func TestErrorsLock(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "hexe.errors.lock")
+
+	codes, err := lockErrorCodes(t, filename, `
+error ErrNotFound { Msg = "not found" }
+error ErrUnauthorized { Msg = "unauthorized" }
+`)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, map[string]int64{"ErrNotFound": 1, "ErrUnauthorized": 2}, codes)
+
+	// ErrBadRequest is sorted first, which shifts the codes without the lock
+	codes, err = lockErrorCodes(t, filename, `
+error ErrNotFound { Msg = "not found" }
+error ErrUnauthorized { Msg = "unauthorized" }
+error ErrBadRequest { Msg = "bad request" }
+`)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, map[string]int64{"ErrNotFound": 1, "ErrUnauthorized": 2, "ErrBadRequest": 3}, codes)
+
+	// the removed error's code is not reused
+	codes, err = lockErrorCodes(t, filename, `
+error ErrNotFound { Msg = "not found" }
+error ErrBadRequest { Msg = "bad request" }
+error ErrConflict { Msg = "conflict" }
+`)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, map[string]int64{"ErrNotFound": 1, "ErrBadRequest": 3, "ErrConflict": 4}, codes)
+
+	lock, err := ReadErrorsLock(filename)
+	if assert.NoError(t, err) {
+		assert.Equal(t, ErrorsLock{"ErrNotFound": 1, "ErrUnauthorized": 2, "ErrBadRequest": 3, "ErrConflict": 4}, lock)
+	}
+
+	_, err = lockErrorCodes(t, filename, `
+error ErrNotFound { Code = 10 Msg = "not found" }
+`)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.(*Error).Message, "code 10 is different from the locked code 1")
+	}
+
+	_, err = lockErrorCodes(t, filename, `
+error ErrGone { Code = 2 Msg = "gone" }
+`)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.(*Error).Message, "code 2 is locked by ErrUnauthorized")
+	}
+}
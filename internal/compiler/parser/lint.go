@@ -0,0 +1,104 @@
+package parser
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"slices"
+
+	"github.com/hexe-dev/hexe/internal/compiler/ast"
+	"github.com/hexe-dev/hexe/internal/compiler/token"
+)
+
+// Warning is an advisory finding of Lint, unlike Error,
+// it doesn't stop the schema from being generated
+type Warning struct {
+	Filename string
+	Start    int
+	End      int
+	Message  string
+}
+
+func (w *Warning) String() string {
+	b, err := os.ReadFile(w.Filename)
+	if err != nil {
+		return fmt.Sprintf("Warning: %s\n", w.Message)
+	}
+
+	return prettyMessage("Warning", w.Filename, string(b), w.Start, w.End, w.Message)
+}
+
+func NewWarning(tok *token.Token, format string, args ...any) *Warning {
+	return &Warning{
+		Filename: tok.Filename,
+		Start:    tok.Start,
+		End:      tok.End,
+		Message:  fmt.Sprintf(format, args...),
+	}
+}
+
+// Checks the following
+// [x] Enum's values should be contiguous, after auto-assignment, so a dense switch covers them
+
+// Lint returns the advisory warnings of the documents, which are expected to be validated
+func Lint(docs ...*ast.Document) []*Warning {
+	var warnings []*Warning
+
+	for _, doc := range docs {
+		for _, enum := range doc.Enums {
+			if warning := lintEnumGaps(enum); warning != nil {
+				warnings = append(warnings, warning)
+			}
+		}
+	}
+
+	return warnings
+}
+
+// lintEnumGaps reports the first missing value between the smallest and
+// the largest values of the enum, the reserved values are removed on
+// purpose, so they are not considered as a gap
+func lintEnumGaps(enum *ast.Enum) *Warning {
+	if len(enum.Sets) < 2 {
+		return nil
+	}
+
+	values := make([]int64, 0, len(enum.Sets))
+	for _, set := range enum.Sets {
+		values = append(values, set.Value.Value)
+	}
+	slices.Sort(values)
+
+	for i := 1; i < len(values); i++ {
+		if values[i] == values[i-1] || values[i] == values[i-1]+1 {
+			continue
+		}
+		missing := skipEnumReservedValues(enum, values[i-1]+1)
+		if missing < values[i] {
+			return NewWarning(enum.Name.Token, "enum %s's values are not contiguous, %d is missing between %d and %d", enum.Name.Token.Value, missing, values[i-1], values[i])
+		}
+	}
+
+	return nil
+}
+
+// skipEnumReservedValues returns the first value, starting from value,
+// which is not in any of the enum's reserved ranges
+func skipEnumReservedValues(enum *ast.Enum, value int64) int64 {
+	for skipped := true; skipped; {
+		skipped = false
+		for _, reserved := range enum.Reserved {
+			for _, r := range reserved.Ranges {
+				end := r.Start
+				if r.End != nil {
+					end = r.End
+				}
+				if r.Start.Value <= value && value <= end.Value && end.Value < math.MaxInt64 {
+					value = end.Value + 1
+					skipped = true
+				}
+			}
+		}
+	}
+	return value
+}
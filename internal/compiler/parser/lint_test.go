@@ -0,0 +1,84 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/hexe-dev/hexe/internal/compiler/ast"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLintEnumGaps(t *testing.T) {
+	testCases := []struct {
+		input   string
+		warning string
+	}{
+		{
+			input: `
+enum Status {
+    A = 0
+    C = 5
+}
+`,
+			warning: "enum Status's values are not contiguous, 1 is missing between 0 and 5",
+		},
+		{
+			input: `
+enum Status {
+    A
+    B
+    C
+}
+`,
+		},
+		{
+			input: `
+enum Status {
+    A = 1
+    B
+    C = 0
+}
+`,
+		},
+		{
+			input: `
+enum Status {
+    reserved 1..3
+    A = 0
+    C = 4
+}
+`,
+		},
+		{
+			input: `
+enum Status {
+    reserved 1, 3
+    A = 0
+    C = 4
+}
+`,
+			warning: "enum Status's values are not contiguous, 2 is missing between 0 and 4",
+		},
+	}
+
+	for _, tc := range testCases {
+		doc, err := ParseDocument(NewParser(tc.input))
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+
+		if !assert.NoError(t, Validate([]*ast.Document{doc}...)) {
+			continue
+		}
+
+		warnings := Lint(doc)
+		if tc.warning == "" {
+			assert.Empty(t, warnings)
+			continue
+		}
+
+		if assert.Len(t, warnings, 1) {
+			assert.Equal(t, tc.warning, warnings[0].Message)
+			assert.Equal(t, "Status", tc.input[warnings[0].Start:warnings[0].End])
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package parser
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hexe-dev/hexe/internal/compiler/ast"
+)
+
+// LoadDocuments parses the given files and all the files they import. Imports are
+// resolved relative to the importing file and each file is loaded only once, so
+// a file which is both matched by the search paths and imported is not duplicated
+func LoadDocuments(filenames ...string) ([]*ast.Document, error) {
+	l := newLoader()
+
+	for _, filename := range filenames {
+		if err := l.loadFile(filename); err != nil {
+			return nil, err
+		}
+	}
+
+	return l.docs, nil
+}
+
+// LoadDocumentImports loads all the files imported by the already parsed document,
+// filename is used to resolve the imports and if it is empty, the imports are
+// resolved relative to the current working directory
+func LoadDocumentImports(filename string, doc *ast.Document) ([]*ast.Document, error) {
+	l := newLoader()
+
+	if filename != "" {
+		key, err := filepath.Abs(filename)
+		if err != nil {
+			return nil, err
+		}
+		l.loaded[key] = struct{}{}
+		l.stack = append(l.stack, filename)
+	}
+
+	if err := l.loadImports(filename, doc); err != nil {
+		return nil, err
+	}
+
+	return append(l.docs, doc), nil
+}
+
+type loader struct {
+	docs   []*ast.Document
+	loaded map[string]struct{}
+	stack  []string // files which are currently being loaded, used for detecting cycles
+}
+
+func (l *loader) loadFile(filename string) error {
+	key, err := filepath.Abs(filename)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := l.loaded[key]; ok {
+		return nil
+	}
+	l.loaded[key] = struct{}{}
+
+	// all the parse errors of the file are reported at once
+	doc, errs := ParseDocumentAll(NewWithFilenames(filename))
+	if len(errs) == 1 {
+		return errs[0]
+	} else if len(errs) > 1 {
+		return errors.Join(errs...)
+	}
+
+	l.stack = append(l.stack, filename)
+
+	if err = l.loadImports(filename, doc); err != nil {
+		return err
+	}
+
+	l.stack = l.stack[:len(l.stack)-1]
+
+	// imported documents are added before the importer
+	l.docs = append(l.docs, doc)
+
+	return nil
+}
+
+func (l *loader) loadImports(filename string, doc *ast.Document) error {
+	for _, imp := range doc.Imports {
+		path := imp.Path.Value
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(filepath.Dir(filename), path)
+		}
+
+		for i, loading := range l.stack {
+			if isSameFile(loading, path) {
+				cycle := append(l.stack[i:len(l.stack):len(l.stack)], path)
+				return NewError(imp.Path.Token, "import cycle is detected: %s", strings.Join(cycle, " -> "))
+			}
+		}
+
+		if _, err := os.Stat(path); err != nil {
+			return NewError(imp.Path.Token, "imported file is not found: %s", path)
+		}
+
+		if err := l.loadFile(path); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func isSameFile(a, b string) bool {
+	a, errA := filepath.Abs(a)
+	b, errB := filepath.Abs(b)
+	return errA == nil && errB == nil && a == b
+}
+
+func newLoader() *loader {
+	return &loader{
+		loaded: make(map[string]struct{}),
+	}
+}
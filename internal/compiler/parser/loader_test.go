@@ -0,0 +1,104 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, content := range files {
+		filename := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(filename), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filename, []byte(content), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
+
+func TestLoadDocuments(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"common/common.hexe": `model Base { Id: string }`,
+		"common/role.hexe":   `enum Role { Admin }`,
+		"user.hexe": `
+import "common/common.hexe"
+import "./common/role.hexe"
+
+model User {
+	...Base
+	Role: Role
+}`,
+	})
+
+	// common.hexe is both imported and passed directly
+	docs, err := LoadDocuments(filepath.Join(dir, "user.hexe"), filepath.Join(dir, "common/common.hexe"))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	if !assert.Len(t, docs, 3) {
+		return
+	}
+
+	// imported documents come before the importer
+	assert.Equal(t, "Base", docs[0].Models[0].Name.Token.Value)
+	assert.Equal(t, "Role", docs[1].Enums[0].Name.Token.Value)
+	assert.Equal(t, "User", docs[2].Models[0].Name.Token.Value)
+
+	assert.NoError(t, Validate(docs...))
+}
+
+func TestLoadDocumentsErrors(t *testing.T) {
+	testCases := []struct {
+		files map[string]string
+		error string
+	}{
+		{
+			files: map[string]string{
+				"user.hexe": `import "a.hexe"`,
+				"a.hexe":    `import "b.hexe"`,
+				"b.hexe":    `import "a.hexe"`,
+			},
+			error: "import cycle is detected: ",
+		},
+		{
+			files: map[string]string{
+				"user.hexe": `import "user.hexe"`,
+			},
+			error: "import cycle is detected: ",
+		},
+		{
+			files: map[string]string{
+				"user.hexe": `import "missing.hexe"`,
+			},
+			error: "imported file is not found: ",
+		},
+		{
+			files: map[string]string{
+				"user.hexe": `
+model User { Id: string }
+import "common.hexe"`,
+				"common.hexe": ``,
+			},
+			error: "import must be at the top of the document",
+		},
+	}
+
+	for _, tc := range testCases {
+		dir := writeFiles(t, tc.files)
+
+		_, err := LoadDocuments(filepath.Join(dir, "user.hexe"))
+		if assert.Error(t, err) {
+			assert.Contains(t, err.(*Error).Message, tc.error)
+		}
+	}
+}
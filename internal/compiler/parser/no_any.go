@@ -0,0 +1,67 @@
+package parser
+
+import (
+	"github.com/hexe-dev/hexe/internal/compiler/ast"
+)
+
+// Checks the following
+// [x] any type is not used by the models' fields and oneof variants, and the services' arguments and returns
+
+// ValidateNoAny returns an error pointing at the first use of the any type, it's
+// an opt-in check on top of Validate for the schemas which should be fully typed
+func ValidateNoAny(docs ...*ast.Document) error {
+	for _, doc := range docs {
+		for _, model := range doc.Models {
+			for _, field := range model.Fields {
+				if err := checkNoAny(field.Type); err != nil {
+					return err
+				}
+			}
+
+			for _, oneOf := range model.OneOfs {
+				for _, variant := range oneOf.Variants {
+					if err := checkNoAny(variant.Type); err != nil {
+						return err
+					}
+				}
+			}
+		}
+
+		for _, service := range doc.Services {
+			for _, method := range service.Methods {
+				for _, arg := range method.Args {
+					if err := checkNoAny(arg.Type); err != nil {
+						return err
+					}
+				}
+
+				for _, ret := range method.Returns {
+					if err := checkNoAny(ret.Type); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkNoAny looks for the any type, including the elements of arrays, sets and maps
+func checkNoAny(t ast.Type) error {
+	switch v := t.(type) {
+	case *ast.Any:
+		return NewError(v.Token, "any type is not allowed, use a model or a concrete type instead")
+	case *ast.Array:
+		return checkNoAny(v.Type)
+	case *ast.Set:
+		return checkNoAny(v.Type)
+	case *ast.Map:
+		if err := checkNoAny(v.Key); err != nil {
+			return err
+		}
+		return checkNoAny(v.Value)
+	default:
+		return nil
+	}
+}
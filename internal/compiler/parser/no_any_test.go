@@ -0,0 +1,85 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateNoAny(t *testing.T) {
+	testCases := []struct {
+		input string
+		error string
+	}{
+		{
+			input: `
+model User {
+	Name: string
+	Tags: map<string, []string>
+}
+
+service HttpUserService {
+	Get(id: string) => (user: User)
+}`,
+		},
+		{
+			input: `
+model User {
+	Name: string
+	Meta: any
+}`,
+			error: "any type is not allowed",
+		},
+		{
+			input: `
+model User {
+	Meta: map<string, []any>
+}`,
+			error: "any type is not allowed",
+		},
+		{
+			input: `
+model User {
+	oneof Contact {
+		Email: string
+		Other: any
+	}
+}`,
+			error: "any type is not allowed",
+		},
+		{
+			input: `
+service HttpUserService {
+	Update(id: string, patch: any)
+}`,
+			error: "any type is not allowed",
+		},
+		{
+			input: `
+service HttpUserService {
+	Get(id: string) => (values: []any)
+}`,
+			error: "any type is not allowed",
+		},
+	}
+
+	for _, tc := range testCases {
+		doc, err := ParseDocument(NewParser(tc.input))
+		if !assert.NoError(t, err) {
+			continue
+		}
+
+		// any is still a valid type without the check
+		if !assert.NoError(t, Validate(doc)) {
+			continue
+		}
+
+		err = ValidateNoAny(doc)
+		if tc.error == "" {
+			assert.NoError(t, err)
+		} else if assert.Error(t, err) {
+			assert.Contains(t, err.(*Error).Message, tc.error)
+			assert.Equal(t, "any", tc.input[err.(*Error).Start:err.(*Error).End])
+		}
+	}
+}
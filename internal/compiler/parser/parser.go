@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"errors"
 	"math"
 	"strconv"
 	"strings"
@@ -16,6 +17,8 @@ type Parser struct {
 	nextTok  *token.Token
 	currTok  *token.Token
 	comments []*ast.Comment
+	// last is the last top-level statement, which a comment on its line trails
+	last ast.Expr
 }
 
 func (p *Parser) Current() *token.Token {
@@ -64,7 +67,57 @@ func ParseComment(p *Parser) (*ast.Comment, error) {
 		return nil, NewError(p.Peek(), "expected comment but got %s", p.Peek().Type)
 	}
 
-	return &ast.Comment{Token: p.Next()}, nil
+	prev := p.Current()
+	comment := &ast.Comment{Token: p.Next()}
+
+	// a comment on the same line as the previous token trails the element which
+	// ends there, except after '{' where it leads the first element of the block
+	if prev != nil && prev.Type != token.OpenCurly && prev.Filename == comment.Token.Filename && prev.Line == comment.Token.Line {
+		comment.Position = ast.CommentTrailing
+	}
+
+	return comment, nil
+}
+
+// addComment adds a trailing comment to last, the element it trails, the
+// other comments are kept in p.comments for the next element
+func addComment(p *Parser, comment *ast.Comment, last ast.Expr) {
+	if comment.Position == ast.CommentTrailing && last != nil {
+		last.AddComments(comment)
+		return
+	}
+
+	comment.Position = ast.CommentTop
+	p.comments = append(p.comments, comment)
+}
+
+// Parse Import
+
+func ParseImport(p *Parser) (*ast.Import, error) {
+	if p.Peek().Type != token.Import {
+		return nil, NewError(p.Peek(), "expected 'import' keyword")
+	}
+
+	imp := &ast.Import{Token: p.Next()}
+
+	switch p.Peek().Type {
+	case token.ConstStringSingleQuote, token.ConstStringDoubleQuote, token.ConstStringBacktickQoute:
+	default:
+		return nil, NewError(p.Peek(), "expected string for the import path")
+	}
+
+	pathTok := p.Next()
+
+	if pathTok.Value == "" {
+		return nil, NewError(pathTok, "import path should not be empty")
+	}
+
+	imp.Path = &ast.ValueString{
+		Token: pathTok,
+		Value: pathTok.Value,
+	}
+
+	return imp, nil
 }
 
 // Parse Contsnant
@@ -82,6 +135,18 @@ func ParseConst(p *Parser) (*ast.Const, error) {
 
 	constant.Identifier = &ast.Identifier{Token: p.Next()}
 
+	// optional type of an object literal, e.g. const DefaultUser: User = { ... }
+	if p.Peek().Type == token.Colon {
+		p.Next() // skip ':'
+
+		typ, err := ParseType(p)
+		if err != nil {
+			return nil, err
+		}
+
+		constant.Type = typ
+	}
+
 	if p.Peek().Type != token.Assign {
 		return nil, NewError(p.Peek(), "expected = after identifier, got %s", p.Peek().Type)
 	}
@@ -119,12 +184,31 @@ func ParseEnum(p *Parser) (enum *ast.Enum, err error) {
 
 	enum.Name = &ast.Identifier{Token: nameTok}
 
+	// optional explicit base type, e.g. enum Color uint8 { ... }
+	switch p.Peek().Type {
+	case token.Int8, token.Int16, token.Int32, token.Int64,
+		token.Uint8, token.Uint16, token.Uint32, token.Uint64:
+		enum.Type, err = ParseType(p)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	if p.Peek().Type != token.OpenCurly {
 		return nil, NewError(p.Peek(), "expected '{' after enum declaration")
 	}
 
 	p.Next() // skip '{'
 
+	// comments before the enum keyword belong to the enum, not its first key
+	if len(p.comments) > 0 {
+		enum.AddComments(p.comments...)
+		p.comments = p.comments[:0]
+	}
+
+	// last is the element which a comment on its line trails
+	var last ast.Expr
+
 	for {
 		peek := p.Peek()
 
@@ -132,16 +216,25 @@ func ParseEnum(p *Parser) (enum *ast.Enum, err error) {
 			break
 		}
 
+		if peek.Type == token.Reserved {
+			reserved, err := ParseEnumReserved(p)
+			if err != nil {
+				return nil, err
+			}
+
+			enum.Reserved = append(enum.Reserved, reserved)
+			last = reserved
+			continue
+		}
+
 		if peek.Type == token.Identifier {
 			set, err := EnumSet(p)
 			if err != nil {
 				return nil, err
 			}
 
-			set.AddComments(p.comments...)
-			p.comments = p.comments[:0]
-
 			enum.Sets = append(enum.Sets, set)
+			last = set
 			continue
 		}
 
@@ -151,10 +244,11 @@ func ParseEnum(p *Parser) (enum *ast.Enum, err error) {
 				return nil, err
 			}
 
-			comment.Position = ast.CommentBottom
-			p.comments = append(p.comments, comment)
+			addComment(p, comment, last)
 			continue
 		}
+
+		return nil, NewError(peek, "expected identifier for defining an enum constant, 'reserved' or '}'")
 	}
 
 	p.Next() // skip '}'
@@ -166,39 +260,63 @@ func ParseEnum(p *Parser) (enum *ast.Enum, err error) {
 	var maxV int64
 
 	for _, set := range enum.Sets {
-		if set.Defined {
-			next = set.Value.Value + 1
-			continue
-		}
-
-		set.Value = &ast.ValueInt{
-			Token:   nil,
-			Value:   next,
-			Defined: false,
+		if !set.Defined {
+			set.Value = &ast.ValueInt{
+				Token:   nil,
+				Value:   next,
+				Defined: false,
+			}
 		}
 
-		minV = min(minV, next)
-		maxV = max(maxV, next)
+		minV = min(minV, set.Value.Value)
+		maxV = max(maxV, set.Value.Value)
 
-		next++
+		next = set.Value.Value + 1
 	}
 
-	enum.Size = getIntSize(minV, maxV)
+	switch typ := enum.Type.(type) {
+	case *ast.Int:
+		enum.Size = typ.Size
+		for _, set := range enum.Sets {
+			if getIntSize(set.Value.Value, set.Value.Value) > typ.Size {
+				return nil, NewError(set.Name.Token, "enum value %d does not fit in %s", set.Value.Value, typ.Token.Value)
+			}
+		}
+	case *ast.Uint:
+		enum.Size = typ.Size
+		for _, set := range enum.Sets {
+			if set.Value.Value < 0 || (typ.Size < 64 && set.Value.Value >= 1<<typ.Size) {
+				return nil, NewError(set.Name.Token, "enum value %d does not fit in %s", set.Value.Value, typ.Token.Value)
+			}
+		}
+	default:
+		enum.Size = getIntSize(minV, maxV)
+	}
 
 	for _, set := range enum.Sets {
 		set.Value.Size = enum.Size
 	}
 
+	// the remaining comments are at the bottom of the enum
 	for _, comment := range p.comments {
+		comment.Position = ast.CommentBottom
 		enum.AddComments(comment)
 	}
 
 	p.comments = p.comments[:0]
 
+	// optional enum's options after the body, e.g. { JsonNumber = true }
+	if p.Peek().Type == token.OpenCurly {
+		enum.Options, err = ParseOptions(p)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return enum, nil
 }
 
-func EnumSet(p *Parser) (*ast.EnumSet, error) {
+func EnumSet(p *Parser) (set *ast.EnumSet, err error) {
 	if p.Peek().Type != token.Identifier {
 		return nil, NewError(p.Peek(), "expected identifier for defining an enum constant")
 	}
@@ -209,34 +327,109 @@ func EnumSet(p *Parser) (*ast.EnumSet, error) {
 		return nil, NewError(nameTok, "enum's set name must be in Pascal Case format")
 	}
 
-	if p.Peek().Type != token.Assign {
-		return &ast.EnumSet{
-			Name: &ast.Identifier{Token: nameTok},
-			Value: &ast.ValueInt{
-				Value: 0,
-			},
-		}, nil
+	set = &ast.EnumSet{
+		Name: &ast.Identifier{Token: nameTok},
+		Value: &ast.ValueInt{
+			Value: 0,
+		},
+		Options: &ast.Options{List: make([]*ast.Option, 0)},
 	}
 
-	p.Next() // skip '='
+	if p.Peek().Type == token.Assign {
+		p.Next() // skip '='
+
+		if p.Peek().Type != token.ConstInt {
+			return nil, NewError(p.Peek(), "expected constant integer value for defining an enum set value")
+		}
+
+		valueTok := p.Next()
+		value, err := strconv.ParseInt(strings.ReplaceAll(valueTok.Value, "_", ""), 10, 64)
+		if err != nil {
+			return nil, NewError(valueTok, "invalid integer value for defining an enum constant value: %s", err)
+		}
+
+		set.Value = &ast.ValueInt{
+			Token:   valueTok,
+			Value:   value,
+			Defined: true,
+		}
+		set.Defined = true
+	}
+
+	// comments above the set belong to the set, and must be
+	// attached before parsing the options, e.g. { Label = "..." }
+	set.AddComments(p.comments...)
+	p.comments = p.comments[:0]
+
+	if p.Peek().Type != token.OpenCurly {
+		return set, nil
+	}
+
+	set.Options, err = ParseOptions(p)
+	if err != nil {
+		return nil, err
+	}
+
+	return set, nil
+}
+
+func ParseEnumReserved(p *Parser) (*ast.EnumReserved, error) {
+	if p.Peek().Type != token.Reserved {
+		return nil, NewError(p.Peek(), "expected 'reserved' keyword")
+	}
+
+	reserved := &ast.EnumReserved{Token: p.Next()}
+
+	reserved.AddComments(p.comments...)
+	p.comments = p.comments[:0]
+
+	for {
+		start, err := parseEnumReservedValue(p)
+		if err != nil {
+			return nil, err
+		}
+
+		r := &ast.EnumReservedRange{Start: start}
+
+		if p.Peek().Type == token.Range {
+			p.Next() // skip '..'
+
+			r.End, err = parseEnumReservedValue(p)
+			if err != nil {
+				return nil, err
+			}
+
+			if r.End.Value < r.Start.Value {
+				return nil, NewErrorWithEndToken(r.Start.Token, r.End.Token, "reserved range's end should be greater than its start")
+			}
+		}
+
+		reserved.Ranges = append(reserved.Ranges, r)
+
+		if p.Peek().Type != token.Comma {
+			break
+		}
+
+		p.Next() // skip ','
+	}
+
+	return reserved, nil
+}
 
+func parseEnumReservedValue(p *Parser) (*ast.ValueInt, error) {
 	if p.Peek().Type != token.ConstInt {
-		return nil, NewError(p.Peek(), "expected constant integer value for defining an enum set value")
+		return nil, NewError(p.Peek(), "expected constant integer value for defining a reserved enum value")
 	}
 
 	valueTok := p.Next()
 	value, err := strconv.ParseInt(strings.ReplaceAll(valueTok.Value, "_", ""), 10, 64)
 	if err != nil {
-		return nil, NewError(valueTok, "invalid integer value for defining an enum constant value: %s", err)
+		return nil, NewError(valueTok, "invalid integer value for defining a reserved enum value: %s", err)
 	}
 
-	return &ast.EnumSet{
-		Name: &ast.Identifier{Token: nameTok},
-		Value: &ast.ValueInt{
-			Token:   valueTok,
-			Value:   value,
-			Defined: true,
-		},
+	return &ast.ValueInt{
+		Token:   valueTok,
+		Value:   value,
 		Defined: true,
 	}, nil
 }
@@ -282,6 +475,10 @@ func ParseOptions(p *Parser) (*ast.Options, error) {
 
 	p.Next() // skip '{'
 
+	// the comments inside the block are collected separately from p.comments,
+	// so the pending comments of the caller never end up in the block
+	var comments []*ast.Comment
+
 	for {
 		peek := p.Peek()
 
@@ -295,7 +492,14 @@ func ParseOptions(p *Parser) (*ast.Options, error) {
 				return nil, err
 			}
 
-			p.comments = append(p.comments, comment)
+			// a trailing comment belongs to the option before it
+			if comment.Position == ast.CommentTrailing && len(options.List) > 0 {
+				options.List[len(options.List)-1].AddComments(comment)
+				continue
+			}
+
+			comment.Position = ast.CommentTop
+			comments = append(comments, comment)
 			continue
 		}
 
@@ -304,9 +508,10 @@ func ParseOptions(p *Parser) (*ast.Options, error) {
 			return nil, err
 		}
 
-		if len(p.comments) > 0 {
-			option.AddComments(p.comments...)
-			p.comments = p.comments[:0]
+		// comments before and between the options belong to the next option
+		if len(comments) > 0 {
+			option.AddComments(comments...)
+			comments = nil
 		}
 
 		options.List = append(options.List, option)
@@ -314,14 +519,11 @@ func ParseOptions(p *Parser) (*ast.Options, error) {
 
 	p.Next() // skip '}'
 
-	if len(p.comments) > 0 {
-		for _, comment := range p.comments {
-			comment.Position = ast.CommentBottom
-		}
-
-		options.AddComments(p.comments...)
-		p.comments = p.comments[:0]
+	// comments after the last option are at the bottom of the block
+	for _, comment := range comments {
+		comment.Position = ast.CommentBottom
 	}
+	options.AddComments(comments...)
 
 	return options, nil
 }
@@ -358,6 +560,8 @@ func ParseModel(p *Parser) (*ast.Model, error) {
 		p.comments = p.comments[:0]
 	}
 
+	var last ast.Expr
+
 	for {
 		peek := p.Peek()
 
@@ -371,7 +575,18 @@ func ParseModel(p *Parser) (*ast.Model, error) {
 				return nil, err
 			}
 
-			p.comments = append(p.comments, comment)
+			addComment(p, comment, last)
+			continue
+		}
+
+		if peek.Type == token.Reserved {
+			reserved, err := ParseModelReserved(p)
+			if err != nil {
+				return nil, err
+			}
+
+			model.Reserved = append(model.Reserved, reserved)
+			last = reserved
 			continue
 		}
 
@@ -387,6 +602,34 @@ func ParseModel(p *Parser) (*ast.Model, error) {
 			}
 
 			model.Extends = append(model.Extends, extend)
+			last = extend
+			continue
+		}
+
+		if peek.Type == token.OneOf {
+			oneOf, err := ParseOneOf(p)
+			if err != nil {
+				return nil, err
+			}
+
+			model.OneOfs = append(model.OneOfs, oneOf)
+			last = oneOf
+			continue
+		}
+
+		if peek.Type == token.Requires {
+			requires, err := ParseRequires(p)
+			if err != nil {
+				return nil, err
+			}
+
+			if len(p.comments) > 0 {
+				requires.AddComments(p.comments...)
+				p.comments = p.comments[:0]
+			}
+
+			model.Requires = append(model.Requires, requires)
+			last = requires
 			continue
 		}
 
@@ -396,6 +639,7 @@ func ParseModel(p *Parser) (*ast.Model, error) {
 		}
 
 		model.Fields = append(model.Fields, field)
+		last = field
 	}
 
 	p.Next() // skip '}'
@@ -435,6 +679,212 @@ func ParseExtend(p *Parser) (*ast.Extend, error) {
 	}, nil
 }
 
+// ParseRequires parses requires(B, C, when: A)
+func ParseModelReserved(p *Parser) (*ast.ModelReserved, error) {
+	if p.Peek().Type != token.Reserved {
+		return nil, NewError(p.Peek(), "expected 'reserved' keyword")
+	}
+
+	reserved := &ast.ModelReserved{Token: p.Next()}
+
+	reserved.AddComments(p.comments...)
+	p.comments = p.comments[:0]
+
+	for {
+		peek := p.Peek()
+		if peek.Type != token.ConstStringSingleQuote && peek.Type != token.ConstStringDoubleQuote {
+			return nil, NewError(peek, "expected a quoted field name for defining a reserved model field")
+		}
+
+		nameTok := p.Next()
+		if !strcase.IsPascal(nameTok.Value) {
+			return nil, NewError(nameTok, "reserved field name must be in PascalCase format")
+		}
+
+		reserved.Names = append(reserved.Names, &ast.ValueString{Token: nameTok, Value: nameTok.Value})
+
+		if p.Peek().Type != token.Comma {
+			break
+		}
+
+		p.Next() // skip ','
+	}
+
+	return reserved, nil
+}
+
+func ParseRequires(p *Parser) (*ast.Requires, error) {
+	if p.Peek().Type != token.Requires {
+		return nil, NewError(p.Peek(), "expected 'requires' keyword")
+	}
+
+	requires := &ast.Requires{Token: p.Next()}
+
+	if p.Peek().Type != token.OpenParen {
+		return nil, NewError(p.Peek(), "expected '(' after requires")
+	}
+
+	p.Next() // skip '('
+
+	for {
+		if p.Peek().Type != token.Identifier {
+			return nil, NewError(p.Peek(), "expected field name or 'when' in requires")
+		}
+
+		nameTok := p.Next()
+
+		if nameTok.Value == "when" {
+			if len(requires.Fields) == 0 {
+				return nil, NewError(nameTok, "requires must have at least one field before 'when'")
+			}
+
+			if p.Peek().Type != token.Colon {
+				return nil, NewError(p.Peek(), "expected ':' after 'when'")
+			}
+
+			p.Next() // skip ':'
+
+			if p.Peek().Type != token.Identifier {
+				return nil, NewError(p.Peek(), "expected field name after 'when:'")
+			}
+
+			requires.When = &ast.Identifier{Token: p.Next()}
+			break
+		}
+
+		if !strcase.IsPascal(nameTok.Value) {
+			return nil, NewError(nameTok, "field name must be in PascalCase format")
+		}
+
+		requires.Fields = append(requires.Fields, &ast.Identifier{Token: nameTok})
+
+		if p.Peek().Type != token.Comma {
+			return nil, NewError(p.Peek(), "expected ',' after field name in requires")
+		}
+
+		p.Next() // skip ','
+	}
+
+	if p.Peek().Type != token.CloseParen {
+		return nil, NewError(p.Peek(), "expected ')' at the end of requires")
+	}
+
+	p.Next() // skip ')'
+
+	return requires, nil
+}
+
+func ParseOneOf(p *Parser) (*ast.OneOf, error) {
+	if p.Peek().Type != token.OneOf {
+		return nil, NewError(p.Peek(), "expected 'oneof' keyword")
+	}
+
+	oneOf := &ast.OneOf{Token: p.Next()}
+
+	if p.Peek().Type != token.Identifier {
+		return nil, NewError(p.Peek(), "expected identifier for defining a oneof")
+	}
+
+	nameTok := p.Next()
+
+	if !strcase.IsPascal(nameTok.Value) {
+		return nil, NewError(nameTok, "oneof name must be in PascalCase format")
+	}
+
+	oneOf.Name = &ast.Identifier{Token: nameTok}
+
+	if p.Peek().Type != token.OpenCurly {
+		return nil, NewError(p.Peek(), "expected '{' after oneof declaration")
+	}
+
+	p.Next() // skip '{'
+
+	if len(p.comments) > 0 {
+		oneOf.AddComments(p.comments...)
+		p.comments = p.comments[:0]
+	}
+
+	var last ast.Expr
+
+	for {
+		peek := p.Peek()
+
+		if peek.Type == token.CloseCurly {
+			break
+		}
+
+		if peek.Type == token.Comment {
+			comment, err := ParseComment(p)
+			if err != nil {
+				return nil, err
+			}
+
+			addComment(p, comment, last)
+			continue
+		}
+
+		variant, err := ParseOneOfVariant(p)
+		if err != nil {
+			return nil, err
+		}
+
+		oneOf.Variants = append(oneOf.Variants, variant)
+		last = variant
+	}
+
+	p.Next() // skip '}'
+
+	if len(oneOf.Variants) == 0 {
+		return nil, NewError(nameTok, "oneof must have at least one variant")
+	}
+
+	if len(p.comments) > 0 {
+		for _, comment := range p.comments {
+			comment.Position = ast.CommentBottom
+		}
+
+		oneOf.AddComments(p.comments...)
+		p.comments = p.comments[:0]
+	}
+
+	return oneOf, nil
+}
+
+func ParseOneOfVariant(p *Parser) (variant *ast.OneOfVariant, err error) {
+	if p.Peek().Type != token.Identifier {
+		return nil, NewError(p.Peek(), "expected identifier for defining a oneof variant")
+	}
+
+	nameTok := p.Next()
+
+	if !strcase.IsPascal(nameTok.Value) {
+		return nil, NewError(nameTok, "oneof variant name must be in PascalCase format")
+	}
+
+	variant = &ast.OneOfVariant{
+		Name:     &ast.Identifier{Token: nameTok},
+		Comments: make([]*ast.Comment, 0),
+	}
+
+	if p.Peek().Type != token.Colon {
+		return nil, NewError(p.Peek(), "expected ':' after oneof variant name")
+	}
+
+	p.Next() // skip ':'
+
+	variant.Type, err = ParseType(p)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(p.comments) > 0 {
+		variant.AddComments(p.comments...)
+		p.comments = p.comments[:0]
+	}
+
+	return variant, nil
+}
+
 func ParseModelField(p *Parser) (field *ast.Field, err error) {
 	if p.Peek().Type != token.Identifier {
 		return nil, NewError(p.Peek(), "expected identifier for defining a message field")
@@ -501,6 +951,8 @@ func ParseType(p *Parser) (ast.Type, error) {
 	switch peek.Type {
 	case token.Map:
 		return ParseMapType(p)
+	case token.Set:
+		return ParseSetType(p)
 	case token.Array:
 		return ParseArrayType(p)
 	case token.Bool:
@@ -539,6 +991,8 @@ func ParseType(p *Parser) (ast.Type, error) {
 		}
 
 		return &ast.CustomType{Token: nameTok}, nil
+	case token.ConstNull:
+		return nil, NewError(peek, "null is a value and can't be used as a type, use an optional field instead")
 	default:
 		return nil, NewError(peek, "expected type")
 	}
@@ -557,7 +1011,9 @@ func ParseMapType(p *Parser) (*ast.Map, error) {
 
 	p.Next() // skip '<'
 
-	keyType, err := ParseMapKeyType(p)
+	// key type is checked to be comparable during validation,
+	// as enums can only be resolved once all the documents are parsed
+	keyType, err := ParseType(p)
 	if err != nil {
 		return nil, err
 	}
@@ -586,19 +1042,36 @@ func ParseMapType(p *Parser) (*ast.Map, error) {
 	}, nil
 }
 
-func ParseMapKeyType(p *Parser) (ast.Type, error) {
-	switch p.Peek().Type {
-	case token.Int8, token.Int16, token.Int32, token.Int64:
-		return ParseType(p)
-	case token.Uint8, token.Uint16, token.Uint32, token.Uint64:
-		return ParseType(p)
-	case token.String:
-		return ParseType(p)
-	case token.Byte:
-		return ParseType(p)
-	default:
-		return nil, NewError(p.Peek(), "expected map key type to be comparable")
+func ParseSetType(p *Parser) (*ast.Set, error) {
+	if p.Peek().Type != token.Set {
+		return nil, NewError(p.Peek(), "expected 'set' keyword")
 	}
+
+	setTok := p.Next()
+
+	if p.Peek().Type != token.OpenAngle {
+		return nil, NewError(p.Peek(), "expected '<' after 'set' keyword")
+	}
+
+	p.Next() // skip '<'
+
+	// element type is checked to be comparable during validation,
+	// as enums can only be resolved once all the documents are parsed
+	elemType, err := ParseType(p)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.Peek().Type != token.CloseAngle {
+		return nil, NewError(p.Peek(), "expected '>' after set element type")
+	}
+
+	p.Next() // skip '>'
+
+	return &ast.Set{
+		Token: setTok,
+		Type:  elemType,
+	}, nil
 }
 
 func ParseArrayType(p *Parser) (*ast.Array, error) {
@@ -667,6 +1140,8 @@ func ParseService(p *Parser) (service *ast.Service, err error) {
 
 	p.Next() // skip '{'
 
+	var last ast.Expr
+
 	for {
 		peek := p.Peek()
 
@@ -680,7 +1155,7 @@ func ParseService(p *Parser) (service *ast.Service, err error) {
 				return nil, err
 			}
 
-			p.comments = append(p.comments, comment)
+			addComment(p, comment, last)
 			continue
 		}
 
@@ -690,10 +1165,20 @@ func ParseService(p *Parser) (service *ast.Service, err error) {
 		}
 
 		service.Methods = append(service.Methods, method)
+		last = method
 	}
 
 	p.Next() // skip '}'
 
+	if len(p.comments) > 0 {
+		for _, comment := range p.comments {
+			comment.Position = ast.CommentBottom
+		}
+
+		service.AddComments(p.comments...)
+		p.comments = p.comments[:0]
+	}
+
 	return service, nil
 }
 
@@ -887,7 +1372,7 @@ func ParseCustomError(p *Parser) (customError *ast.CustomError, err error) {
 				return nil, err
 			}
 
-			p.comments = append(p.comments, comment)
+			addComment(p, comment, nil)
 			continue
 		}
 
@@ -919,6 +1404,8 @@ func parseCustomErrorValues(p *Parser, customError *ast.CustomError) (err error)
 	switch p.Peek().Value {
 	case "Code":
 		return parseCustomErrorCode(p, customError)
+	case "HttpStatus":
+		return parseCustomErrorHttpStatus(p, customError)
 	case "Msg":
 		return parseCustomErrorMsg(p, customError)
 	}
@@ -948,7 +1435,40 @@ func parseCustomErrorCode(p *Parser, customError *ast.CustomError) (err error) {
 		return err
 	}
 
-	customError.Code = codeValue.(*ast.ValueInt).Value
+	intValue, ok := codeValue.(*ast.ValueInt)
+	if !ok {
+		return NewError(codeValue.(*ast.ValueUint).Token, "code value is out of int64 range")
+	}
+
+	customError.Code = intValue.Value
+
+	return nil
+}
+
+func parseCustomErrorHttpStatus(p *Parser, customError *ast.CustomError) (err error) {
+	if customError.HttpStatus != nil {
+		return NewError(p.Peek(), "HttpStatus is already defined in custom error")
+	}
+
+	p.Next() // skip 'HttpStatus'
+
+	if p.Peek().Type != token.Assign {
+		return NewError(p.Peek(), "expected '=' after 'HttpStatus'")
+	}
+
+	p.Next() // skip '='
+
+	// the status name and code's range are checked during validation
+	switch p.Peek().Type {
+	case token.ConstInt, token.Identifier:
+	default:
+		return NewError(p.Peek(), "expected integer value or status name, e.g. NotFound, for 'HttpStatus'")
+	}
+
+	customError.HttpStatus, err = ParseValue(p)
+	if err != nil {
+		return err
+	}
 
 	return nil
 }
@@ -984,74 +1504,158 @@ func parseCustomErrorMsg(p *Parser, customError *ast.CustomError) (err error) {
 // Parse Document
 
 func ParseDocument(p *Parser) (*ast.Document, error) {
+	doc, errs := parseDocument(p, false)
+	if len(errs) > 0 {
+		return nil, errs[0]
+	}
+
+	return doc, nil
+}
+
+// ParseDocumentAll is the same as ParseDocument, but it recovers from the errors at the
+// next top-level statement, i.e. import, const, enum, model, service or error, and returns
+// all the errors. The document only contains the statements which are parsed successfully
+func ParseDocumentAll(p *Parser) (*ast.Document, []error) {
+	return parseDocument(p, true)
+}
+
+func parseDocument(p *Parser, all bool) (*ast.Document, []error) {
 	doc := &ast.Document{}
 
+	var errs []error
 	for p.Peek().Type != token.EOF {
+		curr := p.Current()
+		err := parseStatement(p, doc)
+		if err == nil {
+			continue
+		}
+
+		errs = append(errs, err)
+		if !all {
+			return nil, errs
+		}
+
+		// the scanner stops at its first error, so no token comes after it
+		if (p.Current() != nil && p.Current().Type == token.Error) || p.Peek().Type == token.Error {
+			break
+		}
+
+		p.comments = p.comments[:0]
+		recoverStatement(p, curr)
+
+		if p.Peek().Type == token.Error {
+			errs = append(errs, NewError(p.Peek(), "%s", p.Peek().Value))
+			break
+		}
+	}
+
+	if len(p.comments) > 0 {
+		doc.AddComments(p.comments...)
+		p.comments = nil
+	}
+
+	return doc, errs
+}
+
+// recoverStatement skips the tokens until the next top-level statement, at least one
+// token is skipped if the failed statement didn't move past curr, so it's not parsed again
+func recoverStatement(p *Parser, curr *token.Token) {
+	if p.Current() == curr {
+		p.Next()
+	}
+
+	for {
 		switch p.Peek().Type {
-		case token.Comment:
-			comment, err := ParseComment(p)
-			if err != nil {
-				return nil, err
-			}
+		case token.EOF, token.Error, token.Import, token.Const, token.Enum, token.Model, token.Service, token.CustomError:
+			return
+		}
+		p.Next()
+	}
+}
 
-			p.comments = append(p.comments, comment)
+func parseStatement(p *Parser, doc *ast.Document) error {
+	switch p.Peek().Type {
+	case token.Comment:
+		comment, err := ParseComment(p)
+		if err != nil {
+			return err
+		}
 
-		case token.Const:
-			constant, err := ParseConst(p)
-			if err != nil {
-				return nil, err
-			}
+		addComment(p, comment, p.last)
 
-			doc.Consts = append(doc.Consts, constant)
+	case token.Import:
+		if len(doc.Consts) > 0 || len(doc.Enums) > 0 || len(doc.Models) > 0 || len(doc.Services) > 0 || len(doc.Errors) > 0 {
+			return NewError(p.Peek(), "import must be at the top of the document")
+		}
 
-			if len(p.comments) > 0 {
-				constant.AddComments(p.comments...)
-				p.comments = p.comments[:0]
-			}
+		imp, err := ParseImport(p)
+		if err != nil {
+			return err
+		}
 
-		case token.Enum:
-			enum, err := ParseEnum(p)
-			if err != nil {
-				return nil, err
-			}
+		doc.Imports = append(doc.Imports, imp)
+		p.last = imp
 
-			doc.Enums = append(doc.Enums, enum)
+		if len(p.comments) > 0 {
+			imp.AddComments(p.comments...)
+			p.comments = p.comments[:0]
+		}
 
-		case token.Model:
-			model, err := ParseModel(p)
-			if err != nil {
-				return nil, err
-			}
+	case token.Const:
+		constant, err := ParseConst(p)
+		if err != nil {
+			return err
+		}
 
-			doc.Models = append(doc.Models, model)
+		doc.Consts = append(doc.Consts, constant)
+		p.last = constant
 
-		case token.Service:
-			service, err := ParseService(p)
-			if err != nil {
-				return nil, err
-			}
+		if len(p.comments) > 0 {
+			constant.AddComments(p.comments...)
+			p.comments = p.comments[:0]
+		}
 
-			doc.Services = append(doc.Services, service)
+	case token.Enum:
+		enum, err := ParseEnum(p)
+		if err != nil {
+			return err
+		}
 
-		case token.CustomError:
-			customError, err := ParseCustomError(p)
-			if err != nil {
-				return nil, err
-			}
+		doc.Enums = append(doc.Enums, enum)
+		p.last = enum
+
+	case token.Model:
+		model, err := ParseModel(p)
+		if err != nil {
+			return err
+		}
 
-			doc.Errors = append(doc.Errors, customError)
+		doc.Models = append(doc.Models, model)
+		p.last = model
 
-		default:
-			return nil, NewError(p.Peek(), "unexpected token")
+	case token.Service:
+		service, err := ParseService(p)
+		if err != nil {
+			return err
 		}
-	}
 
-	if len(p.comments) > 0 {
-		doc.AddComments(p.comments...)
-		p.comments = nil
+		doc.Services = append(doc.Services, service)
+		p.last = service
+
+	case token.CustomError:
+		customError, err := ParseCustomError(p)
+		if err != nil {
+			return err
+		}
+
+		doc.Errors = append(doc.Errors, customError)
+		p.last = customError
+
+	default:
+		return NewError(p.Peek(), "unexpected token")
 	}
 
-	return doc, nil
+	return nil
 }
 
 // Parse Value
@@ -1138,8 +1742,22 @@ func ParseValue(p *Parser) (value ast.Value, err error) {
 			Size:  getFloatSize(float),
 		}
 	case token.ConstInt:
-		integer, err := strconv.ParseInt(strings.ReplaceAll(peekTok.Value, "_", ""), 10, 64)
-		if err != nil {
+		literal := strings.ReplaceAll(peekTok.Value, "_", "")
+		integer, err := strconv.ParseInt(literal, 10, 64)
+		if errors.Is(err, strconv.ErrRange) {
+			// literals which exceed int64 range, but fit in uint64
+			// such as 18446744073709551615 are unsigned values
+			unsigned, uerr := strconv.ParseUint(literal, 10, 64)
+			if uerr != nil {
+				return nil, NewError(peekTok, "failed to parse int value: %s", uerr)
+			}
+			value = &ast.ValueUint{
+				Token: peekTok,
+				Value: unsigned,
+				Size:  64,
+			}
+			break
+		} else if err != nil {
 			return nil, NewError(peekTok, "failed to parse int value: %s", err)
 		}
 		value = &ast.ValueInt{
@@ -1171,8 +1789,17 @@ func ParseValue(p *Parser) (value ast.Value, err error) {
 		value = &ast.ValueVariable{
 			Token: peekTok,
 		}
+	case token.OpenCurly:
+		return parseValueObject(p)
+	case token.OpenBracket:
+		return parseValueList(p)
+	case token.Array:
+		// [] is scanned as the array type, which is an empty list literal here
+		value = &ast.ValueList{
+			Token: peekTok,
+		}
 	default:
-		return nil, NewError(peekTok, "expected one of the following, 'int', 'float', 'bool', 'null', 'string' values or identifier, got %s", peekTok.Type)
+		return nil, NewError(peekTok, "expected one of the following, 'int', 'float', 'bool', 'null', 'string', object and list values or identifier, got %s", peekTok.Type)
 	}
 
 	p.Next() // skip value if no error
@@ -1180,6 +1807,54 @@ func ParseValue(p *Parser) (value ast.Value, err error) {
 	return value, nil
 }
 
+// parseValueObject parses the fields of an object literal, e.g. { Name = "anon" Age = 0 },
+// the same as options, so a field without a value is true
+func parseValueObject(p *Parser) (*ast.ValueObject, error) {
+	object := &ast.ValueObject{Token: p.Next()} // skip '{'
+
+	for p.Peek().Type != token.CloseCurly {
+		if p.Peek().Type == token.Comment {
+			return nil, NewError(p.Peek(), "comments are not allowed in object literals")
+		}
+
+		field, err := ParseOption(p)
+		if err != nil {
+			return nil, err
+		}
+
+		object.Fields = append(object.Fields, field)
+	}
+
+	p.Next() // skip '}'
+
+	return object, nil
+}
+
+// parseValueList parses the values of a list literal, e.g. [1, 2, 3], which
+// are separated by commas, a trailing comma is allowed
+func parseValueList(p *Parser) (*ast.ValueList, error) {
+	list := &ast.ValueList{Token: p.Next()} // skip '['
+
+	for p.Peek().Type != token.CloseBracket {
+		value, err := ParseValue(p)
+		if err != nil {
+			return nil, err
+		}
+
+		list.Values = append(list.Values, value)
+
+		if p.Peek().Type == token.Comma {
+			p.Next() // skip ','
+		} else if p.Peek().Type != token.CloseBracket {
+			return nil, NewError(p.Peek(), "expected ',' or ']' in list literal, got %s", p.Peek().Type)
+		}
+	}
+
+	p.Next() // skip ']'
+
+	return list, nil
+}
+
 // find out about the min size for integer based on min and max values
 // 8, –128, 127
 // 16, –32768, 32767
@@ -1197,7 +1872,9 @@ func getIntSize(min, max int64) int {
 	}
 }
 
+// getFloatSize returns the size by the magnitude of value, so -1.5 is float32 as 1.5 is
 func getFloatSize(value float64) int {
+	value = math.Abs(value)
 	if value >= math.SmallestNonzeroFloat32 && value <= math.MaxFloat32 {
 		return 32
 	}
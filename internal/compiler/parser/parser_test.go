@@ -4,6 +4,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/hexe-dev/hexe/internal/compiler/ast"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -92,6 +93,30 @@ func TestParserValue(t *testing.T) {
 			input:  `1eb`,
 			output: `1eb`,
 		},
+		{
+			input:  `18446744073709551615`,
+			output: `18446744073709551615`,
+		},
+		{
+			input:  `{ Name = "anon" Age = 0 Active }`,
+			output: `{ Name = "anon" Age = 0 Active = true }`,
+		},
+		{
+			input:  `{}`,
+			output: `{}`,
+		},
+		{
+			input:  `[1, 2, 3,]`,
+			output: `[1, 2, 3]`,
+		},
+		{
+			input:  `[]`,
+			output: `[]`,
+		},
+		{
+			input:  `{ Tags = ["a", "b"] Address = { City = 'Toronto' } }`,
+			output: `{ Tags = ["a", "b"] Address = { City = 'Toronto' } }`,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -108,6 +133,60 @@ func TestParserValue(t *testing.T) {
 	}
 }
 
+func TestParserValueUint(t *testing.T) {
+	testCases := []struct {
+		input string
+		value ast.Value
+		error bool
+	}{
+		{
+			input: `9223372036854775807`,
+			value: &ast.ValueInt{Value: 9223372036854775807, Size: 64, Defined: true},
+		},
+		{
+			input: `9223372036854775808`,
+			value: &ast.ValueUint{Value: 9223372036854775808, Size: 64},
+		},
+		{
+			input: `18446744073709551615`,
+			value: &ast.ValueUint{Value: 18446744073709551615, Size: 64},
+		},
+		{
+			input: `18_446_744_073_709_551_615`,
+			value: &ast.ValueUint{Value: 18446744073709551615, Size: 64},
+		},
+		{
+			input: `18446744073709551616`,
+			error: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		result, err := ParseValue(NewParser(tc.input))
+		if tc.error {
+			assert.Error(t, err)
+			continue
+		}
+
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		switch expected := tc.value.(type) {
+		case *ast.ValueInt:
+			if assert.IsType(t, expected, result) {
+				assert.Equal(t, expected.Value, result.(*ast.ValueInt).Value)
+				assert.Equal(t, expected.Size, result.(*ast.ValueInt).Size)
+			}
+		case *ast.ValueUint:
+			if assert.IsType(t, expected, result) {
+				assert.Equal(t, expected.Value, result.(*ast.ValueUint).Value)
+				assert.Equal(t, expected.Size, result.(*ast.ValueUint).Size)
+			}
+		}
+	}
+}
+
 func TestParserConst(t *testing.T) {
 	testCases := []struct {
 		input  string
@@ -201,6 +280,14 @@ func TestParserConst(t *testing.T) {
 			input:  `const V = 1eb`,
 			output: `const V = 1eb`,
 		},
+		{
+			input:  `const DefaultUser: User = { Name = "anon" Age = 0 }`,
+			output: `const DefaultUser: User = { Name = "anon" Age = 0 }`,
+		},
+		{
+			input:  `const Admins: []User = [{ Name = "root" }]`,
+			output: `const Admins: []User = [{ Name = "root" }]`,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -252,6 +339,383 @@ model User {
 	}
 }
 
+func TestParseOptionsComments(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input string
+	}{
+		{
+			name: "field",
+			input: `model User {
+    # doc of id
+    Id: string {
+        # before json
+        Json = "id"
+        # between options
+        Required
+        # after the last option
+    }
+    Name: string {
+        # the only comment
+    }
+    # bottom of model
+}`,
+		},
+		{
+			name: "enum",
+			input: `enum Level {
+    # doc of low
+    Low {
+        # before label
+        Label = "low"
+        # after label
+    }
+    High
+    # bottom of enum
+} {
+    # before json number
+    JsonNumber = true
+    # bottom of options
+}`,
+		},
+		{
+			name: "method",
+			input: `service HttpUserService {
+    # doc of get
+    GetById (id: string) => (name: string) {
+        # before http method
+        HttpMethod = "GET"
+        # between options
+        Timeout = 10s
+        # after the last option
+    }
+    Create (name: string)
+}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			doc, err := ParseDocument(NewParser(tc.input))
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			var sb strings.Builder
+			doc.Format(&sb)
+			assert.Equal(t, tc.input, sb.String())
+
+			// formatting is stable
+			doc, err = ParseDocument(NewParser(sb.String()))
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			var again strings.Builder
+			doc.Format(&again)
+			assert.Equal(t, sb.String(), again.String())
+		})
+	}
+}
+
+func TestParseDocComments(t *testing.T) {
+	const input = `# Role of a user
+enum Role {
+    # can do anything
+    Admin
+    Member
+    # more roles later
+}
+
+# manages users
+service HttpUserService {
+    # returns a user
+    Get (id: string) => (name: string)
+    # more methods later
+}`
+
+	doc, err := ParseDocument(NewParser(input))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	enum := doc.Enums[0]
+	if assert.Len(t, enum.Comments, 2) {
+		assert.Equal(t, ast.CommentTop, enum.Comments[0].Position)
+		assert.Equal(t, ast.CommentBottom, enum.Comments[1].Position)
+	}
+	if assert.Len(t, enum.Sets[0].Comments, 1) {
+		assert.Equal(t, ast.CommentTop, enum.Sets[0].Comments[0].Position)
+	}
+
+	service := doc.Services[0]
+	if assert.Len(t, service.Comments, 2) {
+		assert.Equal(t, ast.CommentTop, service.Comments[0].Position)
+		assert.Equal(t, ast.CommentBottom, service.Comments[1].Position)
+	}
+
+	var sb strings.Builder
+	doc.Format(&sb)
+	assert.Equal(t, input, sb.String())
+
+	// the service's bottom comments should not be attached to the next error
+	doc, err = ParseDocument(NewParser(input + "\n\n# not found\nerror ErrNotFound { Msg = \"not found\" }"))
+	if assert.NoError(t, err) {
+		assert.Len(t, doc.Services[0].Comments, 2)
+		assert.Len(t, doc.Errors[0].Comments, 1)
+	}
+}
+
+func TestParseTrailingComments(t *testing.T) {
+	const input = `const Version = "1.0.0" # the api version
+
+# Role of a user
+enum Role {
+    Admin # can do anything
+    Member
+} {
+    JsonNumber = true # as a number
+} # after role
+
+model User {
+    reserved "Email" # moved to Contact
+    Id: string {
+        Required # always set
+    } # the user's id
+    Age?: int32 # the user's age
+    oneof Contact {
+        Phone: string # with the country code
+        Email: string
+    } # after contact
+    # bottom of user
+}
+
+service HttpUserService {
+    # returns a user
+    Get (id: string) => (user: User) # by id
+}`
+
+	doc, err := ParseDocument(NewParser(input))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// the trailing comment of the const is not the top comment of the enum
+	if assert.Len(t, doc.Consts[0].Comments, 1) {
+		assert.Equal(t, ast.CommentTrailing, doc.Consts[0].Comments[0].Position)
+	}
+	assert.Len(t, doc.Enums[0].Comments, 2)
+
+	model := doc.Models[0]
+	if assert.Len(t, model.Fields[1].Comments, 1) {
+		assert.Equal(t, ast.CommentTrailing, model.Fields[1].Comments[0].Position)
+		assert.Equal(t, " the user's age", model.Fields[1].Comments[0].Token.Value)
+	}
+	assert.Len(t, model.Fields[0].Options.List[0].Comments, 1)
+	if assert.Len(t, model.Comments, 1) {
+		assert.Equal(t, ast.CommentBottom, model.Comments[0].Position)
+	}
+
+	if assert.Len(t, doc.Services[0].Methods[0].Comments, 2) {
+		assert.Equal(t, ast.CommentTop, doc.Services[0].Methods[0].Comments[0].Position)
+		assert.Equal(t, ast.CommentTrailing, doc.Services[0].Methods[0].Comments[1].Position)
+	}
+
+	var sb strings.Builder
+	doc.Format(&sb)
+	assert.Equal(t, input, sb.String())
+}
+
+func TestParseImport(t *testing.T) {
+	testCases := []struct {
+		input  string
+		output string
+		error  string
+	}{
+		{
+			input: `
+# shared models
+import "common.hexe"
+import './other/role.hexe'
+
+model User {
+	Id: string
+}
+			`,
+			output: `
+# shared models
+import "common.hexe"
+import './other/role.hexe'
+
+model User {
+    Id: string
+}`,
+		},
+		{
+			input: `import common`,
+			error: "expected string for the import path",
+		},
+		{
+			input: `import ""`,
+			error: "import path should not be empty",
+		},
+	}
+
+	for _, tc := range testCases {
+		var sb strings.Builder
+		parser := NewParser(tc.input)
+
+		result, err := ParseDocument(parser)
+		if tc.error != "" {
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tc.error)
+			}
+			continue
+		}
+
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		result.Format(&sb)
+		assert.Equal(t, strings.TrimSpace(tc.output), sb.String())
+	}
+}
+
+func TestParseOneOf(t *testing.T) {
+	testCases := []struct {
+		input  string
+		output string
+		error  string
+	}{
+		{
+			input: `
+model Result {
+	Id: string
+	# the outcome
+	oneof Value {
+		# found user
+		User: User
+		Count: int64
+	}
+}
+			`,
+			output: `
+model Result {
+    Id: string
+    # the outcome
+    oneof Value {
+        # found user
+        User: User
+        Count: int64
+    }
+}`,
+		},
+		{
+			input: `model Result { oneof value { User: User } }`,
+			error: "oneof name must be in PascalCase format",
+		},
+		{
+			input: `model Result { oneof Value { user: User } }`,
+			error: "oneof variant name must be in PascalCase format",
+		},
+		{
+			input: `model Result { oneof Value {} }`,
+			error: "oneof must have at least one variant",
+		},
+	}
+
+	for _, tc := range testCases {
+		var sb strings.Builder
+		parser := NewParser(tc.input)
+
+		result, err := ParseDocument(parser)
+		if tc.error != "" {
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tc.error)
+			}
+			continue
+		}
+
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		result.Format(&sb)
+		assert.Equal(t, strings.TrimSpace(tc.output), sb.String())
+	}
+}
+
+func TestParseRequires(t *testing.T) {
+	testCases := []struct {
+		input  string
+		output string
+		error  string
+	}{
+		{
+			input: `
+model Payment {
+	Method: string
+	Card?: string
+	Cvv?: string
+	# bank details
+	requires(Card, Cvv, when: Method)
+	requires(Cvv,when:Card)
+}
+			`,
+			output: `
+model Payment {
+    Method: string
+    Card?: string
+    Cvv?: string
+    # bank details
+    requires(Card, Cvv, when: Method)
+    requires(Cvv, when: Card)
+}`,
+		},
+		{
+			input: `model Payment { requires(when: Method) }`,
+			error: "requires must have at least one field before 'when'",
+		},
+		{
+			input: `model Payment { requires(Card) }`,
+			error: "expected ',' after field name in requires",
+		},
+		{
+			input: `model Payment { requires(Card, Method) }`,
+			error: "expected ',' after field name in requires",
+		},
+		{
+			input: `model Payment { requires(card, when: Method) }`,
+			error: "field name must be in PascalCase format",
+		},
+		{
+			input: `model Payment { requires(Card, when: Method, Cvv) }`,
+			error: "expected ')' at the end of requires",
+		},
+	}
+
+	for _, tc := range testCases {
+		var sb strings.Builder
+		parser := NewParser(tc.input)
+
+		result, err := ParseDocument(parser)
+		if tc.error != "" {
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tc.error)
+			}
+			continue
+		}
+
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		result.Format(&sb)
+		assert.Equal(t, strings.TrimSpace(tc.output), sb.String())
+	}
+}
+
 func TestParsComplex(t *testing.T) {
 	testCases := []struct {
 		input  string
@@ -295,3 +759,472 @@ service HttpUserService {
 		assert.Equal(t, strings.TrimSpace(tc.output), sb.String())
 	}
 }
+
+func TestParseSetType(t *testing.T) {
+	testCases := []struct {
+		input  string
+		output string
+		error  bool
+	}{
+		{
+			input:  `set<string>`,
+			output: `set<string>`,
+		},
+		{
+			input:  `set<Role>`,
+			output: `set<Role>`,
+		},
+		{
+			input:  `map<string, set<int64>>`,
+			output: `map<string, set<int64>>`,
+		},
+		{
+			input: `set string`,
+			error: true,
+		},
+		{
+			input: `set<string`,
+			error: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		var sb strings.Builder
+		parser := NewParser(tc.input)
+
+		result, err := ParseType(parser)
+		if tc.error {
+			assert.Error(t, err)
+			continue
+		}
+
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		result.Format(&sb)
+		assert.Equal(t, tc.output, sb.String())
+	}
+}
+
+func TestParseMapValueType(t *testing.T) {
+	testCases := []struct {
+		input  string
+		output string
+		error  string
+	}{
+		{
+			input:  `map<string, bool>`,
+			output: `map<string, bool>`,
+		},
+		{
+			input:  `map<string, timestamp>`,
+			output: `map<string, timestamp>`,
+		},
+		{
+			input:  `map<string, any>`,
+			output: `map<string, any>`,
+		},
+		{
+			input:  `map<string, []bool>`,
+			output: `map<string, []bool>`,
+		},
+		{
+			input: `map<string, null>`,
+			error: "null is a value and can't be used as a type",
+		},
+		{
+			input: `[]null`,
+			error: "null is a value and can't be used as a type",
+		},
+	}
+
+	for _, tc := range testCases {
+		var sb strings.Builder
+
+		result, err := ParseType(NewParser(tc.input))
+		if tc.error != "" {
+			if assert.Error(t, err) {
+				assert.Contains(t, err.(*Error).Message, tc.error)
+			}
+			continue
+		}
+
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		result.Format(&sb)
+		assert.Equal(t, tc.output, sb.String())
+	}
+}
+
+func TestParseEnumType(t *testing.T) {
+	testCases := []struct {
+		input  string
+		output string
+		size   int
+		error  string
+	}{
+		{
+			input: `enum Color uint8 {
+    Red
+    Green
+    Blue
+}`,
+			size: 8,
+		},
+		{
+			input: `enum Color int64 {
+    Red
+}`,
+			size: 64,
+		},
+		{
+			input: `enum Color int16 {
+    Red = 1000
+}`,
+			size: 16,
+		},
+		{
+			input: `enum Color {
+    Red
+    Green = 1000
+}`,
+			size: 16,
+		},
+		{
+			input: `enum Color {
+    Red = -128
+    Green
+    Blue = 127
+}`,
+			size: 8,
+		},
+		{
+			input: `enum Color {
+    Red = -200
+    Green
+}`,
+			size: 16,
+		},
+		{
+			input: `enum Color uint8 {
+    Red = 255
+    Green
+}`,
+			error: "enum value 256 does not fit in uint8",
+		},
+		{
+			input: `enum Color int8 {
+    Red = -129
+}`,
+			error: "enum value -129 does not fit in int8",
+		},
+		{
+			input: `enum Color int8 {
+    Red = 128
+}`,
+			error: "enum value 128 does not fit in int8",
+		},
+		{
+			input: `enum Color float32 {
+    Red
+}`,
+			error: "expected '{' after enum declaration",
+		},
+	}
+
+	for _, tc := range testCases {
+		enum, err := ParseEnum(NewParser(tc.input))
+		if tc.error != "" {
+			if assert.Error(t, err) {
+				assert.Contains(t, err.(*Error).Message, tc.error)
+			}
+			continue
+		}
+
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		assert.Equal(t, tc.size, enum.Size)
+
+		var sb strings.Builder
+		enum.Format(&sb)
+		assert.Equal(t, tc.input, sb.String())
+	}
+}
+
+func TestParseNegativeValue(t *testing.T) {
+	value, err := ParseValue(NewParser(`-128`))
+	if assert.NoError(t, err) {
+		assert.Equal(t, int64(-128), value.(*ast.ValueInt).Value)
+		assert.Equal(t, 8, value.(*ast.ValueInt).Size)
+	}
+
+	value, err = ParseValue(NewParser(`-200`))
+	if assert.NoError(t, err) {
+		assert.Equal(t, 16, value.(*ast.ValueInt).Size)
+	}
+
+	// the sign doesn't change the size of a float
+	value, err = ParseValue(NewParser(`-1.5`))
+	if assert.NoError(t, err) {
+		assert.Equal(t, -1.5, value.(*ast.ValueFloat).Value)
+		assert.Equal(t, 32, value.(*ast.ValueFloat).Size)
+	}
+
+	doc, err := ParseDocument(NewParser(`const Offset = -5`))
+	if assert.NoError(t, err) && assert.Len(t, doc.Consts, 1) {
+		assert.Equal(t, int64(-5), doc.Consts[0].Value.(*ast.ValueInt).Value)
+	}
+}
+
+func TestParseEnumLabel(t *testing.T) {
+	testCases := []struct {
+		input string
+		error string
+	}{
+		{
+			input: `enum Status {
+    # the active one
+    Active = 1 {
+        # shown in dropdowns
+        Label = "Currently active"
+    }
+    Inactive {
+        Label = InactiveLabel
+    }
+    Pending
+}`,
+		},
+		{
+			input: `enum Status {
+    Active = 1 {
+        Label = "Currently active"
+}`,
+			error: "expected identifier for defining an enum constant",
+		},
+	}
+
+	for _, tc := range testCases {
+		enum, err := ParseEnum(NewParser(tc.input))
+		if tc.error != "" {
+			if assert.Error(t, err) {
+				assert.Contains(t, err.(*Error).Message, tc.error)
+			}
+			continue
+		}
+
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		assert.Equal(t, "the active one", strings.TrimSpace(strings.TrimPrefix(enum.Sets[0].Comments[0].Token.Value, "#")))
+
+		var sb strings.Builder
+		enum.Format(&sb)
+		assert.Equal(t, tc.input, sb.String())
+	}
+}
+
+func TestParseEnumReserved(t *testing.T) {
+	testCases := []struct {
+		input string
+		error string
+	}{
+		{
+			input: `enum Status {
+    # used by the billing system
+    reserved 10, 20..30
+    reserved 100
+    Active
+    Inactive = 40
+}`,
+		},
+		{
+			input: `enum Status {
+    reserved 30..20
+    Active
+}`,
+			error: "reserved range's end should be greater than its start",
+		},
+		{
+			input: `enum Status {
+    reserved Active
+}`,
+			error: "expected constant integer value for defining a reserved enum value",
+		},
+	}
+
+	for _, tc := range testCases {
+		enum, err := ParseEnum(NewParser(tc.input))
+		if tc.error != "" {
+			if assert.Error(t, err) {
+				assert.Contains(t, err.(*Error).Message, tc.error)
+			}
+			continue
+		}
+
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		var sb strings.Builder
+		enum.Format(&sb)
+		assert.Equal(t, tc.input, sb.String())
+	}
+}
+
+func TestParseModelReserved(t *testing.T) {
+	testCases := []struct {
+		input string
+		error string
+	}{
+		{
+			input: `model User {
+    # removed in v2
+    reserved "Nickname", 'Avatar'
+    reserved "Age"
+    Id: string
+}`,
+		},
+		{
+			input: `model User {
+    reserved Nickname
+}`,
+			error: "expected a quoted field name for defining a reserved model field",
+		},
+		{
+			input: `model User {
+    reserved "nickname"
+}`,
+			error: "reserved field name must be in PascalCase format",
+		},
+	}
+
+	for _, tc := range testCases {
+		model, err := ParseModel(NewParser(tc.input))
+		if tc.error != "" {
+			if assert.Error(t, err) {
+				assert.Contains(t, err.(*Error).Message, tc.error)
+			}
+			continue
+		}
+
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		var sb strings.Builder
+		model.Format(&sb)
+		assert.Equal(t, tc.input, sb.String())
+	}
+}
+
+func TestParseEnumOptions(t *testing.T) {
+	testCases := []struct {
+		input string
+		error string
+	}{
+		{
+			input: `enum Status {
+    Active
+    Inactive
+} {
+    JsonNumber = true
+}`,
+		},
+		{
+			input: `enum Status {
+    Active
+    # the last one
+} {
+    # keeps the numbers
+    JsonNumber = true
+}`,
+		},
+		{
+			input: `enum Status {
+    Active
+} {
+    JsonNumber
+}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		enum, err := ParseEnum(NewParser(tc.input))
+		if tc.error != "" {
+			if assert.Error(t, err) {
+				assert.Contains(t, err.(*Error).Message, tc.error)
+			}
+			continue
+		}
+
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		var sb strings.Builder
+		enum.Format(&sb)
+		assert.Equal(t, tc.input, sb.String())
+	}
+}
+
+func TestParseDocumentAll(t *testing.T) {
+	const input = `
+const A =
+
+model User {
+	Id string
+}
+
+enum Role {
+	Admin
+}
+
+service HttpUserService {
+	Get(id: string) =>
+}
+
+model Account {
+	Id: string
+}
+
+const B = "unclosed`
+
+	doc, errs := ParseDocumentAll(NewParser(input))
+	if assert.Len(t, errs, 4) {
+		assert.Contains(t, errs[0].(*Error).Message, "expected one of the following")
+		assert.Contains(t, errs[1].(*Error).Message, "expected ':'")
+		assert.Contains(t, errs[2].(*Error).Message, "expected '('")
+		assert.Contains(t, errs[3].(*Error).Message, "got Error")
+	}
+
+	// the statements without errors are still parsed
+	assert.Len(t, doc.Enums, 1)
+	assert.Len(t, doc.Models, 1)
+
+	doc, errs = ParseDocumentAll(NewParser(`model User { Id: string }`))
+	assert.Empty(t, errs)
+	assert.Len(t, doc.Models, 1)
+
+	_, err := ParseDocument(NewParser(input))
+	if assert.Error(t, err) {
+		assert.Contains(t, err.(*Error).Message, "expected one of the following")
+	}
+}
+
+func TestParseDocumentBOM(t *testing.T) {
+	// a model saved on Windows, with a byte order mark and CRLF line endings
+	const input = "\uFEFFmodel User {\r\n\tId: string\r\n\tName?: string\n}\r\n"
+
+	doc, err := ParseDocument(NewParser(input))
+	if assert.NoError(t, err) && assert.Len(t, doc.Models, 1) {
+		assert.Equal(t, "User", doc.Models[0].Name.Token.Value)
+		assert.Len(t, doc.Models[0].Fields, 2)
+	}
+	assert.NoError(t, Validate(doc))
+}
@@ -1,7 +1,11 @@
 package parser
 
 import (
+	"math"
+	"regexp"
 	"sort"
+	"strings"
+	"unicode"
 
 	"github.com/hexe-dev/hexe/internal/compiler/ast"
 	"github.com/hexe-dev/hexe/internal/compiler/token"
@@ -13,427 +17,1743 @@ import (
 // [x] All the names should be unique (const, model, enum and services)
 // [x] All the same service's method names should be unique
 // [x] All the same enum's keys should be unique
+// [x] Enum key's Label option should be a string
+// [x] Enum's JsonNumber option should be a bool
+// [x] Enum key's value should not be one of the enum's reserved values
+// [x] Model field's name should not be one of the model's reserved names
+// [x] All the same oneof's variant names should be unique and not clash with the model's fields
 // [x] Constant assignment should be valid and the name of the constant should be available
-// [x] Check if Custom Types (Model and Enum names) are defined in Model's fields and Service's arguments and return types
+// [x] Constant's object and list literals should match their model or array type
+// [x] Model's extends should refer to other models without any cycle
+// [x] Extended model's fields and oneofs are inlined and should not clash with the model's own
+// [x] Model's requires should refer to the model's fields
+// [x] Check if Custom Types (Model and Enum names) are defined in Model's fields, OneOf's variants and Service's arguments and return types
 // [x] All the arg's and return's names should be unique per method
 // [x] There should be only one method's argument with type of stream []byte
 // [x] There should be only one stream return type
-// [ ] The key type of map should be comparable type
+// [x] Stream argument of messages, other than []byte, should be used with a stream return of messages, i.e. bidirectional stream
+// [x] The key type of map should be comparable type
+// [x] The element type of set should be comparable type
 // [x] Array byte should be used with stream for argument and return types
-// [ ] Validate if Custom Error Code and HttpStatus are valid
+// [x] Validate if Custom Error Code and HttpStatus are valid
+// [x] Custom Error Msg should not contain control characters
 // [x] RpcService should not have any stream type in arguments and return types
 // [x] make sure `err` is not part of any argument or return names
+// [x] Names should not collide with the identifiers of the generated code
+// [x] HttpMethod option should be GET or POST and Cache option can only be used by GET methods
+// [x] Method's Timeout option should be a positive duration
+// [x] Method's MaxSize option should be a positive byte size and only used in http services
+// [x] Method's MsgPack option should be a bool and only used by http POST methods without streams
+// [x] Method's RateLimit option should be a count per window, e.g. "100/s", and only used in http services
+// [x] Method's Disposition option should be "attachment" or "inline" and only used by http methods with a stream []byte return
+// [x] Field's Proto option should be a valid protobuf field number and unique per model
+// [x] Field's Required, Pattern, Min and Max options should match the field's type
+// [x] Field's TimeFormat option should be "rfc3339", "unix" or "unixmilli" and only used by timestamp fields
+// [x] Field's JsonString option should be a bool and only used by integer and float fields
+// [x] Field's and method's Deprecated option should be a bool or a message
+// [x] Field's and method's Since and Until options should be semantic versions, Since before Until
 
 func Validate(docs ...*ast.Document) error {
-	consts := make([]*ast.Const, 0)
-	enums := make([]*ast.Enum, 0)
-	models := make([]*ast.Model, 0)
-	services := make([]*ast.Service, 0)
-	customErrors := make([]*ast.CustomError, 0)
+	if errs := validate(false, docs...); len(errs) > 0 {
+		return errs[0]
+	}
+
+	return nil
+}
+
+// ValidateAll is the same as Validate, but it continues past the failed checks and
+// returns all the errors, the checks of the later stages are skipped if any fails
+func ValidateAll(docs ...*ast.Document) []error {
+	return validate(true, docs...)
+}
+
+// validate returns the errors of the checks, stops at the first one unless all is set
+func validate(all bool, docs ...*ast.Document) []error {
+	// the slices are allocated once with the total size, as the number
+	// of declarations can be large when many documents are validated together
+	var constsSize, enumsSize, modelsSize, servicesSize, customErrorsSize int
+	for _, doc := range docs {
+		constsSize += len(doc.Consts)
+		enumsSize += len(doc.Enums)
+		modelsSize += len(doc.Models)
+		servicesSize += len(doc.Services)
+		customErrorsSize += len(doc.Errors)
+	}
+
+	consts := make([]*ast.Const, 0, constsSize)
+	enums := make([]*ast.Enum, 0, enumsSize)
+	models := make([]*ast.Model, 0, modelsSize)
+	services := make([]*ast.Service, 0, servicesSize)
+	customErrors := make([]*ast.CustomError, 0, customErrorsSize)
+
+	// Since all the hexe's documents are compiled into a single file,
+	// First we need to sort all the consts, enums, models and services
+
+	for _, doc := range docs {
+		consts = append(consts, doc.Consts...)
+		enums = append(enums, doc.Enums...)
+		models = append(models, doc.Models...)
+		services = append(services, doc.Services...)
+		customErrors = append(customErrors, doc.Errors...)
+	}
+
+	// the checks are grouped in stages, the checks of a stage assume the previous
+	// stages have passed, e.g. the extends are inlined only when there is no cycle,
+	// and the later checks only see the flattened models
+	stages := [][]func() error{
+		{
+			func() error {
+				// check for CamelCase names
+				for _, c := range consts {
+					if !strcase.IsPascal(c.Identifier.Token.Value) {
+						return NewError(c.Identifier.Token, "name should be PascalCase")
+					}
+				}
+
+				for _, e := range enums {
+					if !strcase.IsPascal(e.Name.Token.Value) {
+						return NewError(e.Name.Token, "name should be PascalCase")
+					}
+
+					for _, k := range e.Sets {
+						if k.Name.Token.Value == "_" {
+							continue
+						}
+
+						if !strcase.IsPascal(k.Name.Token.Value) {
+							return NewError(k.Name.Token, "name should be PascalCase")
+						}
+					}
+				}
+
+				for _, m := range models {
+					if !strcase.IsPascal(m.Name.Token.Value) {
+						return NewError(m.Name.Token, "name should be PascalCase")
+					}
+
+					for _, f := range m.Fields {
+						if !strcase.IsPascal(f.Name.Token.Value) {
+							return NewError(f.Name.Token, "name should be PascalCase")
+						}
+
+						for _, o := range f.Options.List {
+							if !strcase.IsPascal(o.Name.Token.Value) {
+								return NewError(o.Name.Token, "name should be PascalCase")
+							}
+						}
+					}
+				}
+
+				for _, s := range services {
+					if !strcase.IsPascal(s.Name.Token.Value) {
+						return NewError(s.Name.Token, "name should be PascalCase")
+					}
+
+					for _, m := range s.Methods {
+						if !strcase.IsPascal(m.Name.Token.Value) {
+							return NewError(m.Name.Token, "name should be PascalCase")
+						}
+
+						for _, a := range m.Args {
+							if !strcase.IsCamel(a.Name.Token.Value) {
+								return NewError(a.Name.Token, "name should be camelCase")
+							}
+						}
+
+						for _, r := range m.Returns {
+							if !strcase.IsCamel(r.Name.Token.Value) {
+								return NewError(r.Name.Token, "name should be camelCase")
+							}
+						}
+
+						for _, o := range m.Options.List {
+							if !strcase.IsPascal(o.Name.Token.Value) {
+								return NewError(o.Name.Token, "name should be PascalCase")
+							}
+						}
+					}
+				}
+
+				return nil
+			},
+			func() error {
+				// check for duplicate names
+
+				duplicateNames := make(map[string]struct{})
+				for _, c := range consts {
+					if _, ok := duplicateNames[c.Identifier.Token.Value]; ok {
+						return NewError(c.Identifier.Token, "name is already used")
+					}
+					duplicateNames[c.Identifier.Token.Value] = struct{}{}
+				}
+
+				for _, e := range enums {
+					if _, ok := duplicateNames[e.Name.Token.Value]; ok {
+						return NewError(e.Name.Token, "name is already used")
+					}
+					duplicateNames[e.Name.Token.Value] = struct{}{}
+
+					enumDuplicateKeys := make(map[string]struct{})
+					for _, k := range e.Sets {
+						if k.Name.Token.Value == "_" {
+							continue
+						}
+
+						if _, ok := enumDuplicateKeys[k.Name.Token.Value]; ok {
+							return NewError(k.Name.Token, "key is already used in the same enum")
+						}
+						enumDuplicateKeys[k.Name.Token.Value] = struct{}{}
+
+						for _, reserved := range e.Reserved {
+							for _, r := range reserved.Ranges {
+								if r.End == nil && k.Value.Value == r.Start.Value ||
+									r.End != nil && r.Start.Value <= k.Value.Value && k.Value.Value <= r.End.Value {
+									return NewError(k.Name.Token, "enum value %d is reserved", k.Value.Value)
+								}
+							}
+						}
+
+						if k.Options == nil {
+							continue
+						}
+
+						enumOptionDuplicateNames := make(map[string]struct{})
+						for _, o := range k.Options.List {
+							if _, ok := enumOptionDuplicateNames[o.Name.Token.Value]; ok {
+								return NewError(o.Name.Token, "option name is already used in the same enum key")
+							}
+							enumOptionDuplicateNames[o.Name.Token.Value] = struct{}{}
+						}
+					}
+
+					if e.Options == nil {
+						continue
+					}
+
+					enumOptionDuplicateNames := make(map[string]struct{})
+					for _, o := range e.Options.List {
+						if _, ok := enumOptionDuplicateNames[o.Name.Token.Value]; ok {
+							return NewError(o.Name.Token, "option name is already used in the same enum")
+						}
+						enumOptionDuplicateNames[o.Name.Token.Value] = struct{}{}
+					}
+				}
+
+				for _, m := range models {
+					if _, ok := duplicateNames[m.Name.Token.Value]; ok {
+						return NewError(m.Name.Token, "name is already used")
+					}
+					duplicateNames[m.Name.Token.Value] = struct{}{}
+
+					modelReservedNames := make(map[string]struct{})
+					for _, reserved := range m.Reserved {
+						for _, name := range reserved.Names {
+							modelReservedNames[name.Value] = struct{}{}
+						}
+					}
+
+					modelDuplicateFields := make(map[string]struct{})
+					for _, f := range m.Fields {
+						if _, ok := modelDuplicateFields[f.Name.Token.Value]; ok {
+							return NewError(f.Name.Token, "field name is already used in the same model")
+						}
+						modelDuplicateFields[f.Name.Token.Value] = struct{}{}
+
+						if _, ok := modelReservedNames[f.Name.Token.Value]; ok {
+							return NewError(f.Name.Token, "field name %s is reserved", f.Name.Token.Value)
+						}
+
+						modelOptionDuplicateNames := make(map[string]struct{})
+						for _, o := range f.Options.List {
+							if _, ok := modelOptionDuplicateNames[o.Name.Token.Value]; ok {
+								return NewError(o.Name.Token, "option name is already used in the same field")
+							}
+							modelOptionDuplicateNames[o.Name.Token.Value] = struct{}{}
+						}
+					}
+
+					for _, o := range m.OneOfs {
+						if _, ok := modelDuplicateFields[o.Name.Token.Value]; ok {
+							return NewError(o.Name.Token, "oneof name is already used in the same model")
+						}
+						modelDuplicateFields[o.Name.Token.Value] = struct{}{}
+
+						oneOfDuplicateVariants := make(map[string]struct{})
+						for _, v := range o.Variants {
+							if _, ok := oneOfDuplicateVariants[v.Name.Token.Value]; ok {
+								return NewError(v.Name.Token, "variant name is already used in the same oneof")
+							}
+							oneOfDuplicateVariants[v.Name.Token.Value] = struct{}{}
+						}
+					}
+				}
+
+				for _, s := range services {
+					if _, ok := duplicateNames[s.Name.Token.Value]; ok {
+						return NewError(s.Name.Token, "name is already used")
+					}
+					duplicateNames[s.Name.Token.Value] = struct{}{}
+
+					serviceDuplicateMethods := make(map[string]struct{})
+					for _, m := range s.Methods {
+						if _, ok := serviceDuplicateMethods[m.Name.Token.Value]; ok {
+							return NewError(m.Name.Token, "method name is already used in the same service")
+						}
+						serviceDuplicateMethods[m.Name.Token.Value] = struct{}{}
+
+						serviceMethodDuplicateArguments := make(map[string]struct{})
+						for _, a := range m.Args {
+							if _, ok := serviceMethodDuplicateArguments[a.Name.Token.Value]; ok {
+								return NewError(a.Name.Token, "argument name is already used in the same method")
+							}
+
+							if a.Name.Token.Value == "err" {
+								return NewError(a.Name.Token, "err is a reserved name")
+							}
+
+							serviceMethodDuplicateArguments[a.Name.Token.Value] = struct{}{}
+						}
+
+						serviceMethodDuplicateReturns := make(map[string]struct{})
+
+						for _, r := range m.Returns {
+							if _, ok := serviceMethodDuplicateReturns[r.Name.Token.Value]; ok {
+								return NewError(r.Name.Token, "return name is already used in the same method")
+							}
+
+							if r.Name.Token.Value == "err" {
+								return NewError(r.Name.Token, "err is a reserved name")
+							}
+
+							serviceMethodDuplicateReturns[r.Name.Token.Value] = struct{}{}
+
+							if _, ok := serviceMethodDuplicateArguments[r.Name.Token.Value]; ok {
+								return NewError(r.Name.Token, "return name is already used in the same method as argument")
+							}
+						}
+
+						serviceMethodDuplicateOptions := make(map[string]struct{})
+						for _, o := range m.Options.List {
+							if _, ok := serviceMethodDuplicateOptions[o.Name.Token.Value]; ok {
+								return NewError(o.Name.Token, "option name is already used in the same method")
+							}
+							serviceMethodDuplicateOptions[o.Name.Token.Value] = struct{}{}
+						}
+					}
+				}
+
+				{
+					constMap := make(map[string]*ast.Const)
+
+					for _, c := range consts {
+						constMap[c.Identifier.Token.Value] = c
+					}
+
+					var findConst func(name string) *ast.Const
+					findConst = func(name string) *ast.Const {
+						c, ok := constMap[name]
+						if !ok {
+							return nil
+						}
+
+						if v, ok := c.Value.(*ast.ValueVariable); ok {
+							return findConst(v.Token.Value)
+						}
+
+						return c
+					}
+
+					findConstValue := func(name string) ast.Value {
+						if c := findConst(name); c != nil {
+							return c.Value
+						}
+						return nil
+					}
+
+					// the fields of object literals and the elements of list literals can refer to constants as well
+					var resolveLiteral func(value ast.Value) (ast.Value, error)
+					resolveLiteral = func(value ast.Value) (ast.Value, error) {
+						switch v := value.(type) {
+						case *ast.ValueVariable:
+							value := findConstValue(v.Token.Value)
+							if value == nil {
+								return nil, NewError(v.Token, "unknown constant is not defined")
+							}
+							return value, nil
+						case *ast.ValueObject:
+							for _, o := range v.Fields {
+								value, err := resolveLiteral(o.Value)
+								if err != nil {
+									return nil, err
+								}
+								o.Value = value
+							}
+						case *ast.ValueList:
+							for i := range v.Values {
+								value, err := resolveLiteral(v.Values[i])
+								if err != nil {
+									return nil, err
+								}
+								v.Values[i] = value
+							}
+						}
+
+						return value, nil
+					}
+
+					for _, c := range consts {
+						if _, ok := c.Value.(*ast.ValueVariable); !ok {
+							if _, err := resolveLiteral(c.Value); err != nil {
+								return err
+							}
+						}
+					}
+
+					for _, c := range consts {
+						if variable, ok := c.Value.(*ast.ValueVariable); ok {
+							target := findConst(variable.Token.Value)
+							if target == nil {
+								return NewError(variable.Token, "unknown constant is not defined")
+							}
+							c.Value = target.Value
+
+							// the literal is shared, so it should be the same type
+							if c.Type == nil {
+								c.Type = target.Type
+							} else if target.Type != nil && formatNode(c.Type) != formatNode(target.Type) {
+								return NewError(variable.Token, "constant's type %s is different from %s", formatNode(c.Type), formatNode(target.Type))
+							}
+						}
+					}
+
+					for _, e := range enums {
+						for _, k := range e.Sets {
+							if k.Options == nil {
+								continue
+							}
+
+							for _, o := range k.Options.List {
+								if variable, ok := o.Value.(*ast.ValueVariable); ok {
+									value := findConstValue(variable.Token.Value)
+									if value == nil {
+										return NewError(variable.Token, "unknown constant is not defined")
+									}
+									o.Value = value
+								}
+
+								if err := checkOptionValue(o); err != nil {
+									return err
+								}
+
+								if o.Name.Token.Value != "Label" {
+									continue
+								}
+
+								if _, ok := o.Value.(*ast.ValueString); !ok {
+									return NewError(o.Name.Token, "enum key's Label should be a string")
+								}
+							}
+						}
+
+						if e.Options == nil {
+							continue
+						}
+
+						for _, o := range e.Options.List {
+							if variable, ok := o.Value.(*ast.ValueVariable); ok {
+								value := findConstValue(variable.Token.Value)
+								if value == nil {
+									return NewError(variable.Token, "unknown constant is not defined")
+								}
+								o.Value = value
+							}
+
+							if err := checkOptionValue(o); err != nil {
+								return err
+							}
+
+							if o.Name.Token.Value != "JsonNumber" {
+								continue
+							}
+
+							if _, ok := o.Value.(*ast.ValueBool); !ok {
+								return NewError(o.Name.Token, "enum's JsonNumber should be a bool")
+							}
+						}
+					}
+
+					for _, m := range models {
+						for _, f := range m.Fields {
+							for _, o := range f.Options.List {
+								if variable, ok := o.Value.(*ast.ValueVariable); ok {
+									value := findConstValue(variable.Token.Value)
+									if value == nil {
+										return NewError(variable.Token, "unknown constant is not defined")
+									}
+									o.Value = value
+								}
+
+								if err := checkOptionValue(o); err != nil {
+									return err
+								}
+							}
+						}
+					}
+
+					for _, s := range services {
+						for _, m := range s.Methods {
+							for _, o := range m.Options.List {
+								if variable, ok := o.Value.(*ast.ValueVariable); ok {
+									value := findConstValue(variable.Token.Value)
+									if value == nil {
+										return NewError(variable.Token, "unknown constant is not defined")
+									}
+									o.Value = value
+								}
+
+								if err := checkOptionValue(o); err != nil {
+									return err
+								}
+							}
+						}
+					}
+				}
+
+				return nil
+			},
+			func() error {
+				// check for custom types name exist
+				typesMap := make(map[string]struct{})
+
+				for _, m := range models {
+					typesMap[m.Name.Token.Value] = struct{}{}
+				}
+
+				for _, e := range enums {
+					typesMap[e.Name.Token.Value] = struct{}{}
+				}
+
+				// check for custom types name exist in models
+				for _, m := range models {
+					for _, f := range m.Fields {
+						if err := checkTypeExists(typesMap, f.Type); err != nil {
+							return err
+						}
+					}
+
+					for _, o := range m.OneOfs {
+						for _, v := range o.Variants {
+							if err := checkTypeExists(typesMap, v.Type); err != nil {
+								return err
+							}
+						}
+					}
+				}
+
+				// check for custom types name exist in services
+				for _, s := range services {
+					for _, m := range s.Methods {
+						for _, a := range m.Args {
+							if err := checkTypeExists(typesMap, a.Type); err != nil {
+								return err
+							}
+						}
+
+						for _, r := range m.Returns {
+							if err := checkTypeExists(typesMap, r.Type); err != nil {
+								return err
+							}
+						}
+					}
+				}
+
+				return nil
+			},
+		},
+		{
+			func() error {
+				// check for map's key type and set's element type to be comparable
+				enumsMap := make(map[string]struct{})
+
+				for _, e := range enums {
+					enumsMap[e.Name.Token.Value] = struct{}{}
+				}
+
+				for _, m := range models {
+					for _, f := range m.Fields {
+						if err := checkSetTypeComparable(enumsMap, f.Type); err != nil {
+							return err
+						}
+
+						if err := checkMapKeyComparable(enumsMap, f.Type); err != nil {
+							return err
+						}
+					}
+
+					for _, o := range m.OneOfs {
+						for _, v := range o.Variants {
+							if err := checkSetTypeComparable(enumsMap, v.Type); err != nil {
+								return err
+							}
+
+							if err := checkMapKeyComparable(enumsMap, v.Type); err != nil {
+								return err
+							}
+						}
+					}
+				}
+
+				for _, s := range services {
+					for _, m := range s.Methods {
+						for _, a := range m.Args {
+							if err := checkSetTypeComparable(enumsMap, a.Type); err != nil {
+								return err
+							}
+
+							if err := checkMapKeyComparable(enumsMap, a.Type); err != nil {
+								return err
+							}
+						}
+
+						for _, r := range m.Returns {
+							if err := checkSetTypeComparable(enumsMap, r.Type); err != nil {
+								return err
+							}
+
+							if err := checkMapKeyComparable(enumsMap, r.Type); err != nil {
+								return err
+							}
+						}
+					}
+				}
+
+				return nil
+			},
+			func() error {
+				// check for custom errors
+				sort.Slice(customErrors, func(i, j int) bool {
+					return customErrors[i].Name.Token.Value < customErrors[j].Name.Token.Value
+				})
+
+				for _, e := range customErrors {
+					if strings.IndexFunc(e.Msg.Value, unicode.IsControl) != -1 {
+						return NewError(e.Msg.Token, "error message should not contain control characters such as tab or newline")
+					}
+				}
+
+				for _, e := range customErrors {
+					switch v := e.HttpStatus.(type) {
+					case nil:
+					case *ast.ValueVariable:
+						code, ok := httpStatusCodes[v.Token.Value]
+						if !ok {
+							return NewError(v.Token, "unknown http status, it should be one of net/http's status names without the Status prefix, e.g. NotFound")
+						}
+						// keeping the token, so the name is preserved in the generated code
+						e.HttpStatus = &ast.ValueInt{Token: v.Token, Value: code, Defined: true}
+					case *ast.ValueInt:
+						if v.Value < 400 || v.Value > 599 {
+							return NewError(v.Token, "http status code should be between 400 and 599")
+						}
+					case *ast.ValueUint:
+						return NewError(v.Token, "http status code should be between 400 and 599")
+					}
+				}
+
+				var maxCode int64 = 0
+				reservedCodes := make(map[int64]struct{})
+				for _, e := range customErrors {
+					if _, ok := reservedCodes[e.Code]; ok {
+						return NewError(e.Token, "code is already used")
+					}
+					if e.Code != 0 {
+						reservedCodes[e.Code] = struct{}{}
+						maxCode = max(maxCode, e.Code)
+					}
+				}
+
+				for _, e := range customErrors {
+					if e.Code == 0 {
+						maxCode++
+						e.Code = maxCode
+					}
+				}
+
+				return nil
+			},
+			func() error {
+				// check if stream exists in rpc service
+				for _, s := range services {
+					if s.Type == ast.ServiceRPC {
+						for _, m := range s.Methods {
+							for _, a := range m.Args {
+								if a.Stream {
+									return NewError(a.Name.Token, "stream is not allowed in rpc service")
+								}
+							}
+
+							for _, r := range m.Returns {
+								if r.Stream {
+									return NewError(r.Name.Token, "stream is not allowed in rpc service")
+								}
+							}
+						}
+					}
+				}
+
+				return nil
+			},
+			func() error {
+				// check if any of the model's field type is []byte
+				for _, m := range models {
+					for _, f := range m.Fields {
+						if a, ok := f.Type.(*ast.Array); ok {
+							if t := isTypeArrayBytes(a); t != nil {
+								return NewErrorWithEndToken(a.Token, t, "byte array is not allowed in model fields")
+							}
+						}
+					}
+				}
+
+				return nil
+			},
+			func() error {
+				// check stream should be the last argument of Http Method, and should be the only one in method return
+				for _, s := range services {
+					if s.Type != ast.ServiceHTTP {
+						continue
+					}
+
+					for _, m := range s.Methods {
+						hasStream := false
+						for i, a := range m.Args {
+							if a.Stream {
+								if hasStream {
+									return NewError(a.Name.Token, "stream should be the last argument")
+								}
+								hasStream = true
+							} else if hasStream {
+								return NewError(m.Args[i-1].Name.Token, "stream should be the last argument")
+							}
+						}
+
+						hasStream = false
+						for i, r := range m.Returns {
+							if r.Stream {
+								if hasStream {
+									return NewError(r.Name.Token, "stream should be the only return type")
+								}
+								hasStream = true
+							} else if hasStream {
+								return NewError(m.Returns[i-1].Name.Token, "stream should be the only return type")
+							}
+						}
+
+						// a stream argument of messages, other than []byte, is sent while the
+						// stream return is received on the same call, i.e. bidirectional stream
+						for _, a := range m.Args {
+							if !a.Stream || isTypeArrayBytes(a.Type) != nil {
+								continue
+							}
+
+							if len(m.Returns) == 0 || !m.Returns[len(m.Returns)-1].Stream || isTypeArrayBytes(m.Returns[len(m.Returns)-1].Type) != nil {
+								return NewError(a.Name.Token, "stream argument of messages requires a stream return of messages, e.g. (msgs: stream Msg) => (replies: stream Msg)")
+							}
+						}
+					}
+				}
+
+				return nil
+			},
+			func() error {
+				// check model's extends refer to other models and there is no cycle
+				modelsMap := make(map[string]*ast.Model)
+				for _, m := range models {
+					modelsMap[m.Name.Token.Value] = m
+				}
+
+				enumsMap := make(map[string]struct{})
+				for _, e := range enums {
+					enumsMap[e.Name.Token.Value] = struct{}{}
+				}
+
+				for _, m := range models {
+					modelDuplicateExtends := make(map[string]struct{})
+					for _, e := range m.Extends {
+						name := e.Name.Token.Value
+
+						if name == m.Name.Token.Value {
+							return NewError(e.Name.Token, "model can't extend itself")
+						}
+
+						if _, ok := modelsMap[name]; !ok {
+							if _, ok := enumsMap[name]; ok {
+								return NewError(e.Name.Token, "only models can be extended")
+							}
+							return NewError(e.Name.Token, "extended model is not defined")
+						}
+
+						if _, ok := modelDuplicateExtends[name]; ok {
+							return NewError(e.Name.Token, "model is already extended in the same model")
+						}
+						modelDuplicateExtends[name] = struct{}{}
+					}
+				}
+
+				const (
+					visiting = 1
+					visited  = 2
+				)
+
+				states := make(map[string]int)
+
+				var visit func(m *ast.Model, path []string) error
+				visit = func(m *ast.Model, path []string) error {
+					states[m.Name.Token.Value] = visiting
+					path = append(path, m.Name.Token.Value)
+
+					for _, e := range m.Extends {
+						name := e.Name.Token.Value
+						switch states[name] {
+						case visiting:
+							return NewError(e.Name.Token, "cyclic extend is detected: %s", strings.Join(append(path, name), " -> "))
+						case visited:
+							continue
+						}
+
+						if err := visit(modelsMap[name], path); err != nil {
+							return err
+						}
+					}
+
+					states[m.Name.Token.Value] = visited
+					return nil
+				}
+
+				for _, m := range models {
+					if states[m.Name.Token.Value] == visited {
+						continue
+					}
+
+					if err := visit(m, nil); err != nil {
+						return err
+					}
+				}
+
+				return nil
+			},
+		},
+		{
+			func() error {
+				// inline the extended models' fields and oneofs, in the order of extends,
+				// so the generators only see flattened models. Once inlined, the extends
+				// are removed, as they are not needed anymore
+				modelsMap := make(map[string]*ast.Model, len(models))
+				for _, m := range models {
+					modelsMap[m.Name.Token.Value] = m
+				}
+
+				// origin keeps where an inlined name comes from, the same field or oneof can be
+				// extended more than once through different models, e.g. diamond extends
+				type origin struct {
+					expr  ast.Expr
+					model string
+				}
+
+				resolved := make(map[string]struct{}, len(models))
+
+				var resolve func(m *ast.Model) error
+				resolve = func(m *ast.Model) error {
+					if _, ok := resolved[m.Name.Token.Value]; ok {
+						return nil
+					}
+					resolved[m.Name.Token.Value] = struct{}{}
+
+					if len(m.Extends) == 0 {
+						return nil
+					}
+
+					names := make(map[string]origin)
+					fields := make([]*ast.Field, 0, len(m.Fields))
+					oneOfs := make([]*ast.OneOf, 0, len(m.OneOfs))
+
+					for _, e := range m.Extends {
+						base := modelsMap[e.Name.Token.Value]
+						if err := resolve(base); err != nil {
+							return err
+						}
+
+						for _, f := range base.Fields {
+							if o, ok := names[f.Name.Token.Value]; ok {
+								if o.expr == f {
+									continue
+								}
+								return NewError(e.Name.Token, "field %s is defined in both %s and %s", f.Name.Token.Value, o.model, base.Name.Token.Value)
+							}
+							names[f.Name.Token.Value] = origin{expr: f, model: base.Name.Token.Value}
+							fields = append(fields, f)
+						}
+
+						for _, o := range base.OneOfs {
+							if prev, ok := names[o.Name.Token.Value]; ok {
+								if prev.expr == o {
+									continue
+								}
+								return NewError(e.Name.Token, "oneof %s is defined in both %s and %s", o.Name.Token.Value, prev.model, base.Name.Token.Value)
+							}
+							names[o.Name.Token.Value] = origin{expr: o, model: base.Name.Token.Value}
+							oneOfs = append(oneOfs, o)
+						}
+					}
+
+					for _, f := range m.Fields {
+						if o, ok := names[f.Name.Token.Value]; ok {
+							return NewError(f.Name.Token, "field name is already defined in extended model %s", o.model)
+						}
+					}
+
+					for _, o := range m.OneOfs {
+						if prev, ok := names[o.Name.Token.Value]; ok {
+							return NewError(o.Name.Token, "oneof name is already defined in extended model %s", prev.model)
+						}
+					}
+
+					// requires are inherited as well
+					requires := make([]*ast.Requires, 0)
+					inherited := make(map[*ast.Requires]struct{})
+					for _, e := range m.Extends {
+						for _, r := range modelsMap[e.Name.Token.Value].Requires {
+							if _, ok := inherited[r]; ok {
+								continue
+							}
+							inherited[r] = struct{}{}
+							requires = append(requires, r)
+						}
+					}
+
+					m.Fields = append(fields, m.Fields...)
+					m.OneOfs = append(oneOfs, m.OneOfs...)
+					m.Requires = append(requires, m.Requires...)
+					m.Extends = nil
+
+					return nil
+				}
+
+				for _, m := range models {
+					if err := resolve(m); err != nil {
+						return err
+					}
+				}
+
+				return nil
+			},
+		},
+		{
+			func() error {
+				// check the constants' object and list literals against their types, after
+				// the extends are inlined, so the extended models' fields can be set as well
+				modelsMap := make(map[string]*ast.Model, len(models))
+				for _, m := range models {
+					modelsMap[m.Name.Token.Value] = m
+				}
+
+				for _, c := range consts {
+					switch c.Value.(type) {
+					case *ast.ValueObject, *ast.ValueList:
+					default:
+						if c.Type != nil {
+							return NewError(c.Identifier.Token, "only object and list literals can have a type")
+						}
+						continue
+					}
+
+					if c.Type == nil {
+						return NewError(c.Identifier.Token, "object and list literals require a type, e.g. const DefaultUser: User = { ... }")
+					}
+
+					switch t := c.Type.(type) {
+					case *ast.Array:
+					case *ast.CustomType:
+						if _, ok := modelsMap[t.Token.Value]; !ok {
+							return NewError(t.Token, "constant's type should be a model or an array")
+						}
+					default:
+						return NewError(c.Identifier.Token, "constant's type should be a model or an array")
+					}
+
+					if err := validateLiteral(modelsMap, c.Identifier.Token, c.Value, c.Type); err != nil {
+						return err
+					}
+				}
+
+				return nil
+			},
+			func() error {
+				// check model's requires refer to the model's own or inlined fields
+				for _, m := range models {
+					if len(m.Requires) == 0 {
+						continue
+					}
+
+					fieldsMap := make(map[string]struct{}, len(m.Fields))
+					for _, f := range m.Fields {
+						fieldsMap[f.Name.Token.Value] = struct{}{}
+					}
+
+					for _, r := range m.Requires {
+						if _, ok := fieldsMap[r.When.Token.Value]; !ok {
+							return NewError(r.When.Token, "field is not defined in the model")
+						}
+
+						requiresDuplicateFields := make(map[string]struct{})
+						for _, f := range r.Fields {
+							if _, ok := fieldsMap[f.Token.Value]; !ok {
+								return NewError(f.Token, "field is not defined in the model")
+							}
+
+							if f.Token.Value == r.When.Token.Value {
+								return NewError(f.Token, "field can't be required by itself")
+							}
+
+							if _, ok := requiresDuplicateFields[f.Token.Value]; ok {
+								return NewError(f.Token, "field is already used in the same requires")
+							}
+							requiresDuplicateFields[f.Token.Value] = struct{}{}
+						}
+					}
+				}
+
+				return nil
+			},
+			func() error {
+				// check names don't collide with the generated code's identifiers,
+				// which otherwise ends up with confusing compile errors in the generated code
+				generatedNames := make(map[string]string)
+
+				for _, s := range services {
+					name := s.Name.Token.Value
+					generatedNames["Create"+name+"Client"] = name
+					generatedNames["Register"+name+"Server"] = name
+
+					for _, m := range s.Methods {
+						if _, ok := reservedMethodNames[strcase.ToCamel(m.Name.Token.Value)]; ok {
+							return NewError(m.Name.Token, "method name is reserved by the generated code")
+						}
+					}
+				}
+
+				for _, m := range models {
+					name := m.Name.Token.Value
+					generatedNames[name+"Schema"] = name
+
+					for _, o := range m.OneOfs {
+						generatedNames[name+o.Name.Token.Value] = name
+						for _, v := range o.Variants {
+							generatedNames[name+o.Name.Token.Value+v.Name.Token.Value] = name
+						}
+					}
+				}
+
+				for _, e := range enums {
+					generatedNames[e.Name.Token.Value+"Schema"] = e.Name.Token.Value
+					generatedNames["Parse"+e.Name.Token.Value] = e.Name.Token.Value
+					generatedNames[e.Name.Token.Value+"Labels"] = e.Name.Token.Value
+				}
+
+				checkName := func(tok *token.Token) error {
+					if _, ok := reservedNames[tok.Value]; ok {
+						return NewError(tok, "name is reserved by the generated code")
+					}
+
+					if owner, ok := generatedNames[tok.Value]; ok {
+						return NewError(tok, "name is already used by the generated code of %s", owner)
+					}
+
+					return nil
+				}
+
+				for _, c := range consts {
+					if err := checkName(c.Identifier.Token); err != nil {
+						return err
+					}
+				}
+
+				for _, e := range enums {
+					if err := checkName(e.Name.Token); err != nil {
+						return err
+					}
+				}
+
+				for _, m := range models {
+					if err := checkName(m.Name.Token); err != nil {
+						return err
+					}
+
+					for _, f := range m.Fields {
+						if _, ok := reservedFieldNames[f.Name.Token.Value]; ok {
+							return NewError(f.Name.Token, "field name is reserved by the generated code")
+						}
+					}
+				}
+
+				for _, s := range services {
+					if err := checkName(s.Name.Token); err != nil {
+						return err
+					}
+				}
+
+				for _, e := range customErrors {
+					if err := checkName(e.Name.Token); err != nil {
+						return err
+					}
+				}
+
+				return nil
+			},
+			func() error {
+				// check HttpMethod, Cache, Timeout, MaxSize, MsgPack, RateLimit and Disposition options of service methods
+				for _, s := range services {
+					for _, m := range s.Methods {
+						var httpMethod, cache, timeout, maxSize, msgPack, rateLimit, disposition *ast.Option
+						for _, o := range m.Options.List {
+							switch o.Name.Token.Value {
+							case "HttpMethod":
+								httpMethod = o
+							case "Cache":
+								cache = o
+							case "Timeout":
+								timeout = o
+							case "MaxSize":
+								maxSize = o
+							case "MsgPack":
+								msgPack = o
+							case "RateLimit":
+								rateLimit = o
+							case "Disposition":
+								disposition = o
+							}
+						}
+
+						if disposition != nil {
+							v, ok := disposition.Value.(*ast.ValueString)
+							if !ok || (v.Value != "attachment" && v.Value != "inline") {
+								return NewError(disposition.Name.Token, "Disposition should be either \"attachment\" or \"inline\"")
+							}
+
+							// rpc services can't have streams, so it's only used by http services
+							if len(m.Returns) == 0 || !m.Returns[len(m.Returns)-1].Stream || isTypeArrayBytes(m.Returns[len(m.Returns)-1].Type) == nil {
+								return NewError(disposition.Name.Token, "Disposition can only be used by methods with a stream []byte return")
+							}
+						}
+
+						if rateLimit != nil {
+							v, ok := rateLimit.Value.(*ast.ValueString)
+							if !ok {
+								return NewError(rateLimit.Name.Token, "RateLimit should be a string, e.g. \"100/s\"")
+							}
+
+							if _, _, err := ast.ParseRateLimit(v.Value); err != nil {
+								return NewError(rateLimit.Name.Token, "RateLimit should be a count per window, e.g. \"100/s\" or \"10/30s\": %s", err)
+							}
 
-	// Since all the hexe's documents are compiled into a single file,
-	// First we need to sort all the consts, enums, models and services
+							if s.Type != ast.ServiceHTTP {
+								return NewError(rateLimit.Name.Token, "RateLimit is only allowed in http service")
+							}
+						}
 
-	for _, doc := range docs {
-		for _, c := range doc.Consts {
-			consts = append(consts, c)
-		}
+						if maxSize != nil {
+							v, ok := maxSize.Value.(*ast.ValueByteSize)
+							if !ok {
+								return NewError(maxSize.Name.Token, "MaxSize should be a byte size, e.g. 5mb")
+							}
 
-		for _, e := range doc.Enums {
-			enums = append(enums, e)
-		}
+							if v.Value <= 0 {
+								return NewError(maxSize.Name.Token, "MaxSize should be greater than 0")
+							}
 
-		for _, m := range doc.Models {
-			models = append(models, m)
-		}
+							if s.Type != ast.ServiceHTTP {
+								return NewError(maxSize.Name.Token, "MaxSize is only allowed in http service")
+							}
+						}
 
-		for _, s := range doc.Services {
-			services = append(services, s)
-		}
+						if timeout != nil {
+							v, ok := timeout.Value.(*ast.ValueDuration)
+							if !ok {
+								return NewError(timeout.Name.Token, "Timeout should be a duration, e.g. 30s")
+							}
 
-		for _, e := range doc.Errors {
-			customErrors = append(customErrors, e)
-		}
-	}
+							if v.Value <= 0 {
+								return NewError(timeout.Name.Token, "Timeout should be greater than 0")
+							}
+						}
 
-	{
-		// check for CamelCase names
-		for _, c := range consts {
-			if !strcase.IsPascal(c.Identifier.Token.Value) {
-				return NewError(c.Identifier.Token, "name should be PascalCase")
-			}
-		}
+						isGet := false
 
-		for _, e := range enums {
-			if !strcase.IsPascal(e.Name.Token.Value) {
-				return NewError(e.Name.Token, "name should be PascalCase")
-			}
+						if httpMethod != nil {
+							v, ok := httpMethod.Value.(*ast.ValueString)
+							if !ok || (v.Value != "GET" && v.Value != "POST") {
+								return NewError(httpMethod.Name.Token, "HttpMethod should be either \"GET\" or \"POST\"")
+							}
 
-			for _, k := range e.Sets {
-				if k.Name.Token.Value == "_" {
-					continue
-				}
+							if s.Type != ast.ServiceHTTP {
+								return NewError(httpMethod.Name.Token, "HttpMethod is only allowed in http service")
+							}
 
-				if !strcase.IsPascal(k.Name.Token.Value) {
-					return NewError(k.Name.Token, "name should be PascalCase")
-				}
-			}
-		}
+							isGet = v.Value == "GET"
+						}
 
-		for _, m := range models {
-			if !strcase.IsPascal(m.Name.Token.Value) {
-				return NewError(m.Name.Token, "name should be PascalCase")
-			}
+						// GET method's arguments are json encoded into the query, a stream argument
+						// can only be sent in the request's body
+						if isGet {
+							for _, a := range m.Args {
+								if a.Stream {
+									return NewError(a.Name.Token, "stream is not allowed in GET method, as it requires a request body, use HttpMethod = \"POST\" instead")
+								}
+							}
 
-			for _, f := range m.Fields {
-				if !strcase.IsPascal(f.Name.Token.Value) {
-					return NewError(f.Name.Token, "name should be PascalCase")
-				}
+							for _, r := range m.Returns {
+								if r.Stream {
+									return NewError(r.Name.Token, "stream is not allowed in GET method")
+								}
+							}
+						}
 
-				for _, o := range f.Options.List {
-					if !strcase.IsPascal(o.Name.Token.Value) {
-						return NewError(o.Name.Token, "name should be PascalCase")
-					}
-				}
-			}
-		}
+						if cache != nil {
+							v, ok := cache.Value.(*ast.ValueBool)
+							if !ok {
+								return NewError(cache.Name.Token, "Cache should be a bool")
+							}
 
-		for _, s := range services {
-			if !strcase.IsPascal(s.Name.Token.Value) {
-				return NewError(s.Name.Token, "name should be PascalCase")
-			}
+							if v.Value && !isGet {
+								return NewError(cache.Name.Token, "Cache can only be used by GET methods")
+							}
+						}
 
-			for _, m := range s.Methods {
-				if !strcase.IsPascal(m.Name.Token.Value) {
-					return NewError(m.Name.Token, "name should be PascalCase")
-				}
+						if msgPack != nil {
+							v, ok := msgPack.Value.(*ast.ValueBool)
+							if !ok {
+								return NewError(msgPack.Name.Token, "MsgPack should be a bool")
+							}
 
-				for _, a := range m.Args {
-					if !strcase.IsCamel(a.Name.Token.Value) {
-						return NewError(a.Name.Token, "name should be camelCase")
+							if v.Value {
+								if s.Type != ast.ServiceHTTP {
+									return NewError(msgPack.Name.Token, "MsgPack is only allowed in http service")
+								}
+
+								if isGet {
+									return NewError(msgPack.Name.Token, "MsgPack can't be used by GET methods")
+								}
+
+								for _, a := range m.Args {
+									if a.Stream {
+										return NewError(msgPack.Name.Token, "MsgPack can't be used by methods with stream arguments")
+									}
+								}
+
+								for _, r := range m.Returns {
+									if r.Stream {
+										return NewError(msgPack.Name.Token, "MsgPack can't be used by methods with stream returns")
+									}
+								}
+							}
+						}
 					}
 				}
 
-				for _, r := range m.Returns {
-					if !strcase.IsCamel(r.Name.Token.Value) {
-						return NewError(r.Name.Token, "name should be camelCase")
+				return nil
+			},
+			func() error {
+				// check Proto option of model's fields, the inlined fields are included
+				// as they share the same protobuf message
+				for _, m := range models {
+					protoNumbers := make(map[int64]struct{})
+					for _, f := range m.Fields {
+						for _, o := range f.Options.List {
+							if o.Name.Token.Value != "Proto" {
+								continue
+							}
+
+							v, ok := o.Value.(*ast.ValueInt)
+							if !ok {
+								return NewError(o.Name.Token, "Proto should be an int field number")
+							}
+
+							if v.Value < 1 || v.Value > maxProtoFieldNumber {
+								return NewError(o.Name.Token, "Proto should be between 1 and %d", maxProtoFieldNumber)
+							}
+
+							if v.Value >= 19000 && v.Value <= 19999 {
+								return NewError(o.Name.Token, "Proto field numbers 19000 to 19999 are reserved by protobuf")
+							}
+
+							if _, ok := protoNumbers[v.Value]; ok {
+								return NewError(o.Name.Token, "Proto field number is already used in the same model")
+							}
+							protoNumbers[v.Value] = struct{}{}
+						}
 					}
 				}
 
-				for _, o := range m.Options.List {
-					if !strcase.IsPascal(o.Name.Token.Value) {
-						return NewError(o.Name.Token, "name should be PascalCase")
+				return nil
+			},
+			func() error {
+				// check Required, Pattern, Min and Max options of model's fields, they
+				// are checked by the generated Validate method of the model
+				for _, m := range models {
+					for _, f := range m.Fields {
+						if err := validateFieldConstraints(f); err != nil {
+							return err
+						}
 					}
 				}
-			}
-		}
-	}
-
-	{
-		// check for duplicate names
-
-		duplicateNames := make(map[string]struct{})
-		for _, c := range consts {
-			if _, ok := duplicateNames[c.Identifier.Token.Value]; ok {
-				return NewError(c.Identifier.Token, "name is already used")
-			}
-			duplicateNames[c.Identifier.Token.Value] = struct{}{}
-		}
 
-		for _, e := range enums {
-			if _, ok := duplicateNames[e.Name.Token.Value]; ok {
-				return NewError(e.Name.Token, "name is already used")
-			}
-			duplicateNames[e.Name.Token.Value] = struct{}{}
+				return nil
+			},
+			func() error {
+				// check TimeFormat option of model's fields
+				for _, m := range models {
+					for _, f := range m.Fields {
+						for _, o := range f.Options.List {
+							if o.Name.Token.Value != "TimeFormat" {
+								continue
+							}
 
-			enumDuplicateKeys := make(map[string]struct{})
-			for _, k := range e.Sets {
-				if k.Name.Token.Value == "_" {
-					continue
-				}
+							v, ok := o.Value.(*ast.ValueString)
+							if !ok || (v.Value != "rfc3339" && v.Value != "unix" && v.Value != "unixmilli") {
+								return NewError(o.Name.Token, "TimeFormat should be either \"rfc3339\", \"unix\" or \"unixmilli\"")
+							}
 
-				if _, ok := enumDuplicateKeys[k.Name.Token.Value]; ok {
-					return NewError(k.Name.Token, "key is already used in the same enum")
+							if _, ok := f.Type.(*ast.Timestamp); !ok {
+								return NewError(o.Name.Token, "TimeFormat is only allowed for timestamp fields")
+							}
+						}
+					}
 				}
-				enumDuplicateKeys[k.Name.Token.Value] = struct{}{}
-			}
-		}
 
-		for _, m := range models {
-			if _, ok := duplicateNames[m.Name.Token.Value]; ok {
-				return NewError(m.Name.Token, "name is already used")
-			}
-			duplicateNames[m.Name.Token.Value] = struct{}{}
+				return nil
+			},
+			func() error {
+				// check JsonString option of model's fields
+				for _, m := range models {
+					for _, f := range m.Fields {
+						for _, o := range f.Options.List {
+							if o.Name.Token.Value != "JsonString" {
+								continue
+							}
 
-			modelDuplicateFields := make(map[string]struct{})
-			for _, f := range m.Fields {
-				if _, ok := modelDuplicateFields[f.Name.Token.Value]; ok {
-					return NewError(f.Name.Token, "field name is already used in the same model")
-				}
-				modelDuplicateFields[f.Name.Token.Value] = struct{}{}
+							if _, ok := o.Value.(*ast.ValueBool); !ok {
+								return NewError(o.Name.Token, "JsonString should be a bool")
+							}
 
-				modelOptionDuplicateNames := make(map[string]struct{})
-				for _, o := range f.Options.List {
-					if _, ok := modelOptionDuplicateNames[o.Name.Token.Value]; ok {
-						return NewError(o.Name.Token, "option name is already used in the same field")
+							switch f.Type.(type) {
+							case *ast.Int, *ast.Uint, *ast.Float:
+							default:
+								return NewError(o.Name.Token, "JsonString is only allowed for integer and float fields")
+							}
+						}
 					}
-					modelOptionDuplicateNames[o.Name.Token.Value] = struct{}{}
 				}
-			}
-		}
 
-		for _, s := range services {
-			if _, ok := duplicateNames[s.Name.Token.Value]; ok {
-				return NewError(s.Name.Token, "name is already used")
-			}
-			duplicateNames[s.Name.Token.Value] = struct{}{}
+				return nil
+			},
+			func() error {
+				// check Deprecated option of model's fields and service methods
+				checkDeprecated := func(options *ast.Options) error {
+					for _, o := range options.List {
+						if o.Name.Token.Value != "Deprecated" {
+							continue
+						}
 
-			serviceDuplicateMethods := make(map[string]struct{})
-			for _, m := range s.Methods {
-				if _, ok := serviceDuplicateMethods[m.Name.Token.Value]; ok {
-					return NewError(m.Name.Token, "method name is already used in the same service")
+						switch o.Value.(type) {
+						case *ast.ValueBool, *ast.ValueString:
+						default:
+							return NewError(o.Name.Token, "Deprecated should be a bool or a message string")
+						}
+					}
+					return nil
 				}
-				serviceDuplicateMethods[m.Name.Token.Value] = struct{}{}
 
-				serviceMethodDuplicateArguments := make(map[string]struct{})
-				for _, a := range m.Args {
-					if _, ok := serviceMethodDuplicateArguments[a.Name.Token.Value]; ok {
-						return NewError(a.Name.Token, "argument name is already used in the same method")
+				for _, m := range models {
+					for _, f := range m.Fields {
+						if err := checkDeprecated(f.Options); err != nil {
+							return err
+						}
 					}
+				}
 
-					if a.Name.Token.Value == "err" {
-						return NewError(a.Name.Token, "err is a reserved name")
+				for _, s := range services {
+					for _, m := range s.Methods {
+						if err := checkDeprecated(m.Options); err != nil {
+							return err
+						}
 					}
-
-					serviceMethodDuplicateArguments[a.Name.Token.Value] = struct{}{}
 				}
 
-				serviceMethodDuplicateReturns := make(map[string]struct{})
+				return nil
+			},
+			func() error {
+				// check Since and Until options of model's fields and service methods
+				checkLifecycle := func(options *ast.Options) error {
+					var since, until *ast.Option
+					for _, o := range options.List {
+						switch o.Name.Token.Value {
+						case "Since":
+							since = o
+						case "Until":
+							until = o
+						default:
+							continue
+						}
+
+						v, ok := o.Value.(*ast.ValueString)
+						if !ok {
+							return NewError(o.Name.Token, "%s should be a semantic version string, e.g. \"1.2.0\"", o.Name.Token.Value)
+						}
+
+						if _, err := ast.ParseVersion(v.Value); err != nil {
+							return NewError(o.Name.Token, "%s should be a semantic version, e.g. \"1.2.0\": %s", o.Name.Token.Value, err)
+						}
+					}
 
-				for _, r := range m.Returns {
-					if _, ok := serviceMethodDuplicateReturns[r.Name.Token.Value]; ok {
-						return NewError(r.Name.Token, "return name is already used in the same method")
+					if since == nil || until == nil {
+						return nil
 					}
 
-					if r.Name.Token.Value == "err" {
-						return NewError(r.Name.Token, "err is a reserved name")
+					sinceVersion, untilVersion := options.Lifecycle()
+					if untilVersion.Compare(*sinceVersion) <= 0 {
+						return NewError(until.Name.Token, "Until should be greater than Since, %s is not after %s", untilVersion, sinceVersion)
 					}
 
-					serviceMethodDuplicateReturns[r.Name.Token.Value] = struct{}{}
+					return nil
+				}
 
-					if _, ok := serviceMethodDuplicateArguments[r.Name.Token.Value]; ok {
-						return NewError(r.Name.Token, "return name is already used in the same method as argument")
+				for _, m := range models {
+					for _, f := range m.Fields {
+						if err := checkLifecycle(f.Options); err != nil {
+							return err
+						}
 					}
 				}
 
-				serviceMethodDuplicateOptions := make(map[string]struct{})
-				for _, o := range m.Options.List {
-					if _, ok := serviceMethodDuplicateOptions[o.Name.Token.Value]; ok {
-						return NewError(o.Name.Token, "option name is already used in the same method")
+				for _, s := range services {
+					for _, m := range s.Methods {
+						if err := checkLifecycle(m.Options); err != nil {
+							return err
+						}
 					}
-					serviceMethodDuplicateOptions[o.Name.Token.Value] = struct{}{}
+				}
+
+				return nil
+			},
+		},
+	}
+
+	var errs []error
+	for _, checks := range stages {
+		for _, check := range checks {
+			if err := check(); err != nil {
+				errs = append(errs, err)
+				if !all {
+					return errs
 				}
 			}
 		}
 
-		{
-			constMap := make(map[string]*ast.Const)
+		if len(errs) > 0 {
+			return errs
+		}
+	}
 
-			for _, c := range consts {
-				constMap[c.Identifier.Token.Value] = c
-			}
+	return nil
+}
 
-			var findConstValue func(name string) ast.Value
-			findConstValue = func(name string) ast.Value {
-				c, ok := constMap[name]
-				if !ok {
-					return nil
-				}
+const maxProtoFieldNumber = 1<<29 - 1
 
-				if v, ok := c.Value.(*ast.ValueVariable); ok {
-					return findConstValue(v.Token.Value)
-				}
+// validateLiteral checks the value matches the type, the models' fields of object literals
+// and the elements of list literals are checked recursively. tok is used for the errors of
+// the values without a token, e.g. the fields without a value, { Active }
+func validateLiteral(modelsMap map[string]*ast.Model, tok *token.Token, value ast.Value, typ ast.Type) error {
+	if valueTok := getValueToken(value); valueTok != nil {
+		tok = valueTok
+	}
 
-				return c.Value
-			}
+	if _, ok := value.(*ast.ValueNull); ok {
+		return NewError(tok, "null is not allowed in literals, the field should be omitted instead")
+	}
 
-			for _, c := range consts {
-				if variable, ok := c.Value.(*ast.ValueVariable); ok {
-					value := findConstValue(variable.Token.Value)
-					if value == nil {
-						return NewError(variable.Token, "unknown constant is not defined")
-					}
-					c.Value = value
-				}
+	switch t := typ.(type) {
+	case *ast.CustomType:
+		model, ok := modelsMap[t.Token.Value]
+		if !ok {
+			return NewError(tok, "%s is not a model, only models are supported as custom types in literals", t.Token.Value)
+		}
+
+		object, ok := value.(*ast.ValueObject)
+		if !ok {
+			return NewError(tok, "expected an object literal of %s", t.Token.Value)
+		}
+
+		fieldsMap := make(map[string]*ast.Field, len(model.Fields))
+		for _, f := range model.Fields {
+			fieldsMap[f.Name.Token.Value] = f
+		}
+
+		duplicates := make(map[string]struct{}, len(object.Fields))
+		for _, o := range object.Fields {
+			name := o.Name.Token.Value
+
+			field, ok := fieldsMap[name]
+			if !ok {
+				return NewError(o.Name.Token, "%s is not a field of %s", name, t.Token.Value)
 			}
 
-			for _, m := range models {
-				for _, f := range m.Fields {
-					for _, o := range f.Options.List {
-						if variable, ok := o.Value.(*ast.ValueVariable); ok {
-							value := findConstValue(variable.Token.Value)
-							if value == nil {
-								return NewError(variable.Token, "unknown constant is not defined")
-							}
-							o.Value = value
-						}
-					}
-				}
+			if _, ok := duplicates[name]; ok {
+				return NewError(o.Name.Token, "field is already set in the same literal")
 			}
+			duplicates[name] = struct{}{}
 
-			for _, s := range services {
-				for _, m := range s.Methods {
-					for _, o := range m.Options.List {
-						if variable, ok := o.Value.(*ast.ValueVariable); ok {
-							value := findConstValue(variable.Token.Value)
-							if value == nil {
-								return NewError(variable.Token, "unknown constant is not defined")
-							}
-							o.Value = value
-						}
-					}
-				}
+			if err := validateLiteral(modelsMap, o.Name.Token, o.Value, field.Type); err != nil {
+				return err
 			}
 		}
-	}
 
-	{
-		// check for custom types name exist
-		typesMap := make(map[string]struct{})
+		// the typescript's interfaces require the fields which are not optional
+		for _, f := range model.Fields {
+			if _, ok := duplicates[f.Name.Token.Value]; !ok && !f.IsOptional {
+				return NewError(tok, "field %s of %s is not set, only the optional fields can be omitted", f.Name.Token.Value, t.Token.Value)
+			}
+		}
 
-		for _, m := range models {
-			typesMap[m.Name.Token.Value] = struct{}{}
+		object.Model = model
+	case *ast.Array, *ast.Set:
+		list, ok := value.(*ast.ValueList)
+		if !ok {
+			return NewError(tok, "expected a list literal of %s", formatNode(typ))
 		}
 
-		for _, e := range enums {
-			typesMap[e.Name.Token.Value] = struct{}{}
+		var elemType ast.Type
+		if array, ok := t.(*ast.Array); ok {
+			elemType = array.Type
+		} else {
+			elemType = t.(*ast.Set).Type
 		}
 
-		// check for custom types name exist in models
-		for _, m := range models {
-			for _, f := range m.Fields {
-				if err := checkTypeExists(typesMap, f.Type); err != nil {
-					return err
-				}
+		// duplicate keys of go's set literals don't compile
+		duplicates := make(map[string]struct{}, len(list.Values))
+		for _, v := range list.Values {
+			if err := validateLiteral(modelsMap, list.Token, v, elemType); err != nil {
+				return err
 			}
-		}
 
-		// check for custom types name exist in services
-		for _, s := range services {
-			for _, m := range s.Methods {
-				for _, a := range m.Args {
-					if err := checkTypeExists(typesMap, a.Type); err != nil {
-						return err
-					}
-				}
+			if _, ok := t.(*ast.Set); !ok {
+				continue
+			}
 
-				for _, r := range m.Returns {
-					if err := checkTypeExists(typesMap, r.Type); err != nil {
-						return err
-					}
-				}
+			key := formatNode(v)
+			if v, ok := v.(*ast.ValueString); ok {
+				key = v.Value
+			}
+
+			if _, ok := duplicates[key]; ok {
+				return NewError(getValueToken(v), "value is already in the set")
 			}
+			duplicates[key] = struct{}{}
+		}
+
+		list.Type = typ
+	case *ast.Bool:
+		if _, ok := value.(*ast.ValueBool); !ok {
+			return NewError(tok, "expected a bool value")
+		}
+	case *ast.String:
+		if _, ok := value.(*ast.ValueString); !ok {
+			return NewError(tok, "expected a string value")
+		}
+	case *ast.Float:
+		switch value.(type) {
+		case *ast.ValueInt, *ast.ValueUint, *ast.ValueFloat:
+		default:
+			return NewError(tok, "expected a number value")
+		}
+	case *ast.Int, *ast.Uint, *ast.Byte:
+		var lower, upper float64
+		switch t := t.(type) {
+		case *ast.Int:
+			lower, upper = -math.Pow(2, float64(t.Size-1)), math.Pow(2, float64(t.Size-1))-1
+		case *ast.Uint:
+			lower, upper = 0, math.Pow(2, float64(t.Size))-1
+		default:
+			lower, upper = 0, math.MaxUint8
+		}
+
+		var number float64
+		switch v := value.(type) {
+		case *ast.ValueInt, *ast.ValueUint:
+			number = getNumberValue(v)
+		case *ast.ValueByteSize:
+			number = float64(v.Value * int64(v.Scale))
+		case *ast.ValueDuration:
+			number = float64(v.Value * int64(v.Scale))
+		default:
+			return NewError(tok, "expected an integer value")
 		}
+
+		if number < lower || number > upper {
+			return NewError(tok, "value is out of range of %s", formatNode(typ))
+		}
+	default:
+		return NewError(tok, "%s is not supported in literals", formatNode(typ))
+	}
+
+	return nil
+}
+
+// checkOptionValue makes sure the object and list literals, which are only supported by the
+// constants, are not used as the options' values, either directly or through a constant
+func checkOptionValue(o *ast.Option) error {
+	switch o.Value.(type) {
+	case *ast.ValueObject, *ast.ValueList:
+		return NewError(o.Name.Token, "object and list literals can't be used as an option's value")
+	default:
+		return nil
+	}
+}
+
+// getValueToken returns the token of the value, which is nil for the options without a value
+func getValueToken(value ast.Value) *token.Token {
+	switch v := value.(type) {
+	case *ast.ValueBool:
+		return v.Token
+	case *ast.ValueString:
+		return v.Token
+	case *ast.ValueFloat:
+		return v.Token
+	case *ast.ValueUint:
+		return v.Token
+	case *ast.ValueInt:
+		return v.Token
+	case *ast.ValueDuration:
+		return v.Token
+	case *ast.ValueByteSize:
+		return v.Token
+	case *ast.ValueNull:
+		return v.Token
+	case *ast.ValueVariable:
+		return v.Token
+	case *ast.ValueObject:
+		return v.Token
+	case *ast.ValueList:
+		return v.Token
+	default:
+		return nil
 	}
+}
 
-	{
-		// check for custom errors
-		sort.Slice(customErrors, func(i, j int) bool {
-			return customErrors[i].Name.Token.Value < customErrors[j].Name.Token.Value
-		})
-
-		var maxCode int64 = 0
-		reservedCodes := make(map[int64]struct{})
-		for _, e := range customErrors {
-			if _, ok := reservedCodes[e.Code]; ok {
-				return NewError(e.Token, "code is already used")
+// formatNode returns the node as it's written, e.g. the types in the error messages
+func formatNode(node ast.Node) string {
+	var sb strings.Builder
+	node.Format(&sb)
+	return sb.String()
+}
+
+func validateFieldConstraints(f *ast.Field) error {
+	var min, max *ast.Option
+
+	for _, o := range f.Options.List {
+		switch o.Name.Token.Value {
+		case "Required":
+			if _, ok := o.Value.(*ast.ValueBool); !ok {
+				return NewError(o.Name.Token, "Required should be a bool")
+			}
+
+			if f.IsOptional {
+				return NewError(o.Name.Token, "Required can't be used by optional fields")
 			}
-			if e.Code != 0 {
-				reservedCodes[e.Code] = struct{}{}
-				maxCode = max(maxCode, e.Code)
+		case "Pattern":
+			v, ok := o.Value.(*ast.ValueString)
+			if !ok {
+				return NewError(o.Name.Token, "Pattern should be a string")
 			}
-		}
 
-		for _, e := range customErrors {
-			if e.Code == 0 {
-				maxCode++
-				e.Code = maxCode
+			if _, ok := f.Type.(*ast.String); !ok {
+				return NewError(o.Name.Token, "Pattern is only allowed for string fields")
 			}
-		}
-	}
 
-	{
-		// check if stream exists in rpc service
-		for _, s := range services {
-			if s.Type == ast.ServiceRPC {
-				for _, m := range s.Methods {
-					for _, a := range m.Args {
-						if a.Stream {
-							return NewError(a.Name.Token, "stream is not allowed in rpc service")
-						}
-					}
+			if _, err := regexp.Compile(v.Value); err != nil {
+				return NewError(o.Name.Token, "Pattern is not a valid regular expression: %s", err)
+			}
+		case "Min", "Max":
+			if err := validateFieldBound(f, o); err != nil {
+				return err
+			}
 
-					for _, r := range m.Returns {
-						if r.Stream {
-							return NewError(r.Name.Token, "stream is not allowed in rpc service")
-						}
-					}
-				}
+			if o.Name.Token.Value == "Min" {
+				min = o
+			} else {
+				max = o
 			}
 		}
 	}
 
-	{
-		// check if any of the model's field type is []byte
-		for _, m := range models {
-			for _, f := range m.Fields {
-				if a, ok := f.Type.(*ast.Array); ok {
-					if t := isTypeArrayBytes(a); t != nil {
-						return NewErrorWithEndToken(a.Token, t, "byte array is not allowed in model fields")
-					}
-				}
-			}
-		}
+	if min != nil && max != nil && getNumberValue(min.Value) > getNumberValue(max.Value) {
+		return NewError(min.Name.Token, "Min should be less than or equal to Max")
 	}
 
-	{
-		// check stream should be the last argument of Http Method, and should be the only one in method return
-		for _, s := range services {
-			if s.Type != ast.ServiceHTTP {
-				continue
-			}
+	return nil
+}
 
-			for _, m := range s.Methods {
-				hasStream := false
-				for i, a := range m.Args {
-					if a.Stream {
-						if hasStream {
-							return NewError(a.Name.Token, "stream should be the last argument")
-						}
-						hasStream = true
-					} else if hasStream {
-						return NewError(m.Args[i-1].Name.Token, "stream should be the last argument")
-					}
-				}
+// validateFieldBound checks the Min or Max option is a number which fits in the field's type
+func validateFieldBound(f *ast.Field, o *ast.Option) error {
+	name := o.Name.Token.Value
 
-				hasStream = false
-				for i, r := range m.Returns {
-					if r.Stream {
-						if hasStream {
-							return NewError(r.Name.Token, "stream should be the only return type")
-						}
-						hasStream = true
-					} else if hasStream {
-						return NewError(m.Returns[i-1].Name.Token, "stream should be the only return type")
-					}
-				}
-			}
+	var lower, upper float64
+
+	switch t := f.Type.(type) {
+	case *ast.Float:
+		switch o.Value.(type) {
+		case *ast.ValueInt, *ast.ValueUint, *ast.ValueFloat:
+			return nil
+		default:
+			return NewError(o.Name.Token, "%s should be a number", name)
 		}
+	case *ast.Int:
+		lower, upper = -math.Pow(2, float64(t.Size-1)), math.Pow(2, float64(t.Size-1))-1
+	case *ast.Uint:
+		lower, upper = 0, math.Pow(2, float64(t.Size))-1
+	case *ast.Byte:
+		lower, upper = 0, math.MaxUint8
+	default:
+		return NewError(o.Name.Token, "%s is only allowed for number fields", name)
+	}
+
+	switch o.Value.(type) {
+	case *ast.ValueInt, *ast.ValueUint:
+	case *ast.ValueFloat:
+		return NewError(o.Name.Token, "%s should be an integer for integer fields", name)
+	default:
+		return NewError(o.Name.Token, "%s should be a number", name)
+	}
+
+	if v := getNumberValue(o.Value); v < lower || v > upper {
+		return NewError(o.Name.Token, "%s is out of range of the field's type", name)
 	}
 
 	return nil
 }
 
+func getNumberValue(v ast.Value) float64 {
+	switch v := v.(type) {
+	case *ast.ValueInt:
+		return float64(v.Value)
+	case *ast.ValueUint:
+		return float64(v.Value)
+	case *ast.ValueFloat:
+		return v.Value
+	default:
+		return 0
+	}
+}
+
 func isTypeArrayBytes(t ast.Type) *token.Token {
 	if a, ok := t.(*ast.Array); ok {
 		if v, ok := a.Type.(*ast.Byte); ok {
@@ -451,6 +1771,8 @@ func checkTypeExists(typesMap map[string]struct{}, t ast.Type) error {
 		return checkTypeExists(typesMap, v.Value)
 	case *ast.Array:
 		return checkTypeExists(typesMap, v.Type)
+	case *ast.Set:
+		return checkTypeExists(typesMap, v.Type)
 	case *ast.CustomType:
 		if _, ok := typesMap[v.Token.Value]; !ok {
 			return NewError(v.Token, "type is not defined")
@@ -461,3 +1783,206 @@ func checkTypeExists(typesMap map[string]struct{}, t ast.Type) error {
 		return nil
 	}
 }
+
+func checkSetTypeComparable(enumsMap map[string]struct{}, t ast.Type) error {
+	switch v := t.(type) {
+	case *ast.Map:
+		return checkSetTypeComparable(enumsMap, v.Value)
+	case *ast.Array:
+		return checkSetTypeComparable(enumsMap, v.Type)
+	case *ast.Set:
+		switch e := v.Type.(type) {
+		case *ast.Int, *ast.Uint, *ast.String, *ast.Byte:
+			return nil
+		case *ast.CustomType:
+			if _, ok := enumsMap[e.Token.Value]; ok {
+				return nil
+			}
+			return NewError(e.Token, "set element type should be comparable, only enums are allowed as custom type")
+		default:
+			return NewError(v.Token, "set element type should be comparable")
+		}
+	default:
+		return nil
+	}
+}
+
+// checkMapKeyComparable makes sure the map keys can be json object keys, the error names
+// the key's type, e.g. timestamp cannot be used as a map key. Enums are the only custom types
+// which are allowed, they are checked against enumsMap as they are resolved after parsing
+func checkMapKeyComparable(enumsMap map[string]struct{}, t ast.Type) error {
+	switch v := t.(type) {
+	case *ast.Array:
+		return checkMapKeyComparable(enumsMap, v.Type)
+	case *ast.Set:
+		return checkMapKeyComparable(enumsMap, v.Type)
+	case *ast.Map:
+		switch k := v.Key.(type) {
+		case *ast.Int, *ast.Uint, *ast.String, *ast.Byte:
+		case *ast.CustomType:
+			if _, ok := enumsMap[k.Token.Value]; !ok {
+				return NewError(k.Token, "%s cannot be used as a map key, only enums are allowed as custom type", k.Token.Value)
+			}
+		case *ast.Bool:
+			return NewError(k.Token, "bool cannot be used as a map key, it can't be a json object key")
+		case *ast.Map:
+			return NewError(getTypeToken(k), "map cannot be used as a map key")
+		case *ast.Array:
+			return NewError(getTypeToken(k), "array cannot be used as a map key")
+		case *ast.Set:
+			return NewError(getTypeToken(k), "set cannot be used as a map key")
+		default:
+			return NewError(getTypeToken(k), "%s cannot be used as a map key", formatNode(k))
+		}
+
+		return checkMapKeyComparable(enumsMap, v.Value)
+	default:
+		return nil
+	}
+}
+
+// getTypeToken returns the first token of the type, used for pointing the errors to the type
+func getTypeToken(t ast.Type) *token.Token {
+	switch v := t.(type) {
+	case *ast.CustomType:
+		return v.Token
+	case *ast.Any:
+		return v.Token
+	case *ast.Int:
+		return v.Token
+	case *ast.Uint:
+		return v.Token
+	case *ast.Byte:
+		return v.Token
+	case *ast.Float:
+		return v.Token
+	case *ast.String:
+		return v.Token
+	case *ast.Bool:
+		return v.Token
+	case *ast.Timestamp:
+		return v.Token
+	case *ast.Map:
+		return v.Token
+	case *ast.Array:
+		return v.Token
+	case *ast.Set:
+		return v.Token
+	default:
+		return nil
+	}
+}
+
+// reservedNames are the exported identifiers of the generated Go and Typescript helpers
+var reservedNames = map[string]struct{}{
+	// Go
+	"ByteSize":                {},
+	"CacheEntry":              {},
+	"CacheStore":              {},
+	"Caller":                  {},
+	"CallerFunc":              {},
+	"CallerInterceptor":       {},
+	"CircuitBreakerConfig":    {},
+	"Codec":                   {},
+	"Config":                  {},
+	"Duration":                {},
+	"ErrCircuitOpen":          {},
+	"ErrRequestTooLarge":      {},
+	"ErrTooManyRequests":      {},
+	"ErrValidation":           {},
+	"Error":                   {},
+	"GetHttpContext":          {},
+	"GetRequestId":            {},
+	"HandleRegistry":          {},
+	"Handler":                 {},
+	"HandlerFunc":             {},
+	"HttpClientOpt":           {},
+	"HttpHandlerOpt":          {},
+	"InterceptCaller":         {},
+	"LoadConfig":              {},
+	"MemoryHandleRegistry":    {},
+	"NewHttpClient":           {},
+	"NewHttpHandler":          {},
+	"NewMemoryCacheStore":     {},
+	"NewMemoryHandleRegistry": {},
+	"NewMemoryRateLimiter":    {},
+	"NewSet":                  {},
+	"RateLimit":               {},
+	"RateLimiter":             {},
+	"RegisterCodec":           {},
+	"Request":                 {},
+	"Route":                   {},
+	"Set":                     {},
+	"WithCache":               {},
+	"WithCircuitBreaker":      {},
+	"WithInterceptor":         {},
+	"WithMiddleware":          {},
+	"WithRateLimiter":         {},
+	"WithRoutes":              {},
+	// Typescript
+	"Cache":               {},
+	"EnumDecodeError":     {},
+	"ErrorCode":           {},
+	"ErrorCode2Name":      {},
+	"ResponseError":       {},
+	"SubscriptionManager": {},
+}
+
+// reservedMethodNames are the members of the generated Typescript service
+// classes, methods are generated in camelCase
+var reservedMethodNames = map[string]struct{}{
+	"caller":      {},
+	"constructor": {},
+}
+
+// reservedFieldNames are the methods of the generated Go models, a field with
+// the same name doesn't compile
+var reservedFieldNames = map[string]struct{}{
+	"Validate": {},
+}
+
+// httpStatusCodes maps net/http's client and server error status names,
+// without the Status prefix, to their codes
+var httpStatusCodes = map[string]int64{
+	"BadRequest":                   400,
+	"Unauthorized":                 401,
+	"PaymentRequired":              402,
+	"Forbidden":                    403,
+	"NotFound":                     404,
+	"MethodNotAllowed":             405,
+	"NotAcceptable":                406,
+	"ProxyAuthRequired":            407,
+	"RequestTimeout":               408,
+	"Conflict":                     409,
+	"Gone":                         410,
+	"LengthRequired":               411,
+	"PreconditionFailed":           412,
+	"RequestEntityTooLarge":        413,
+	"RequestURITooLong":            414,
+	"UnsupportedMediaType":         415,
+	"RequestedRangeNotSatisfiable": 416,
+	"ExpectationFailed":            417,
+	"Teapot":                       418,
+	"MisdirectedRequest":           421,
+	"UnprocessableEntity":          422,
+	"Locked":                       423,
+	"FailedDependency":             424,
+	"TooEarly":                     425,
+	"UpgradeRequired":              426,
+	"PreconditionRequired":         428,
+	"TooManyRequests":              429,
+	"RequestHeaderFieldsTooLarge":  431,
+	"UnavailableForLegalReasons":   451,
+
+	"InternalServerError":           500,
+	"NotImplemented":                501,
+	"BadGateway":                    502,
+	"ServiceUnavailable":            503,
+	"GatewayTimeout":                504,
+	"HTTPVersionNotSupported":       505,
+	"VariantAlsoNegotiates":         506,
+	"InsufficientStorage":           507,
+	"LoopDetected":                  508,
+	"NotExtended":                   510,
+	"NetworkAuthenticationRequired": 511,
+}
@@ -0,0 +1,2033 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hexe-dev/hexe/internal/compiler/ast"
+	"github.com/stretchr/testify/assert"
+)
+
+func validateInput(t *testing.T, input string) error {
+	t.Helper()
+
+	doc, err := ParseDocument(NewParser(input))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	return Validate([]*ast.Document{doc}...)
+}
+
+func TestValidateSetElementType(t *testing.T) {
+	testCases := []struct {
+		input string
+		error string
+	}{
+		{
+			input: `
+enum Role {
+	Admin
+	Member
+}
+
+model User {
+	Tags: set<string>
+	Roles: set<Role>
+	Groups: map<string, set<int64>>
+}`,
+		},
+		{
+			input: `
+model User {
+	Scores: set<float64>
+}`,
+			error: "set element type should be comparable",
+		},
+		{
+			input: `
+model Tag {
+	Name: string
+}
+
+model User {
+	Tags: set<Tag>
+}`,
+			error: "only enums are allowed as custom type",
+		},
+		{
+			input: `
+service HttpUserService {
+	Get(ids: []set<bool>)
+}`,
+			error: "set element type should be comparable",
+		},
+	}
+
+	for _, tc := range testCases {
+		err := validateInput(t, tc.input)
+		if tc.error == "" {
+			assert.NoError(t, err)
+		} else if assert.Error(t, err) {
+			assert.Contains(t, err.(*Error).Message, tc.error)
+		}
+	}
+}
+
+func TestValidateCustomErrorMsg(t *testing.T) {
+	testCases := []struct {
+		input string
+		error string
+	}{
+		{
+			input: `error ErrNotFound { Msg = "not found" }`,
+		},
+		{
+			input: "error ErrNotFound { Msg = \"not\tfound\" }",
+			error: "error message should not contain control characters",
+		},
+		{
+			input: "error ErrNotFound { Msg = `not\nfound` }",
+			error: "error message should not contain control characters",
+		},
+	}
+
+	for _, tc := range testCases {
+		err := validateInput(t, tc.input)
+		if tc.error == "" {
+			assert.NoError(t, err)
+		} else if assert.Error(t, err) {
+			assert.Contains(t, err.(*Error).Message, tc.error)
+		}
+	}
+}
+
+func TestFormatCustomErrorMsgTrimmed(t *testing.T) {
+	doc, err := ParseDocument(NewParser(`error ErrNotFound { Code = 1 Msg = "  not found " }`))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var sb strings.Builder
+	doc.Format(&sb)
+
+	assert.Contains(t, sb.String(), `error ErrNotFound { Code = 1 Msg = "not found" }`)
+}
+
+func TestValidateCustomErrorHttpStatus(t *testing.T) {
+	testCases := []struct {
+		input  string
+		status int64
+		error  string
+	}{
+		{
+			input:  `error ErrNotFound { Code = 1 HttpStatus = NotFound Msg = "not found" }`,
+			status: 404,
+		},
+		{
+			input:  `error ErrConflict { Code = 1 HttpStatus = 409 Msg = "conflict" }`,
+			status: 409,
+		},
+		{
+			input: `error ErrNotFound { HttpStatus = Missing Msg = "not found" }`,
+			error: "unknown http status",
+		},
+		{
+			input: `error ErrNotFound { HttpStatus = OK Msg = "not found" }`,
+			error: "unknown http status",
+		},
+		{
+			input: `error ErrNotFound { HttpStatus = 200 Msg = "not found" }`,
+			error: "http status code should be between 400 and 599",
+		},
+		{
+			input: `error ErrNotFound { HttpStatus = 600 Msg = "not found" }`,
+			error: "http status code should be between 400 and 599",
+		},
+	}
+
+	for _, tc := range testCases {
+		doc, err := ParseDocument(NewParser(tc.input))
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		err = Validate(doc)
+		if tc.error != "" {
+			if assert.Error(t, err) {
+				assert.Contains(t, err.(*Error).Message, tc.error)
+			}
+			continue
+		}
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, tc.status, doc.Errors[0].HttpStatus.(*ast.ValueInt).Value)
+		}
+
+		// the status is formatted as it was defined
+		var sb strings.Builder
+		doc.Format(&sb)
+		assert.Equal(t, tc.input, sb.String())
+	}
+}
+
+func TestValidateMapKeyType(t *testing.T) {
+	testCases := []struct {
+		input string
+		error string
+	}{
+		{
+			input: `
+enum Role {
+	Admin
+	Member
+}
+
+model User {
+	Scores: map<string, int64>
+	Counts: map<Role, int64>
+	Groups: []map<uint8, map<Role, string>>
+}`,
+		},
+		{
+			input: `
+model User {
+	Scores: map<float64, string>
+}`,
+			error: "float64 cannot be used as a map key",
+		},
+		{
+			input: `
+model User {
+	Id: string
+}
+
+model Group {
+	Users: map<User, int32>
+}`,
+			error: "User cannot be used as a map key, only enums are allowed as custom type",
+		},
+		{
+			input: `
+model User {
+	Flags: map<bool, string>
+}`,
+			error: "bool cannot be used as a map key",
+		},
+		{
+			input: `
+model User {
+	Seen: map<timestamp, string>
+}`,
+			error: "timestamp cannot be used as a map key",
+		},
+		{
+			input: `
+model User {
+	Extra: map<any, string>
+}`,
+			error: "any cannot be used as a map key",
+		},
+		{
+			input: `
+model User {
+	Nested: map<map<string, int32>, string>
+}`,
+			error: "map cannot be used as a map key",
+		},
+		{
+			input: `
+model User {
+	Tags: map<[]string, string>
+}`,
+			error: "array cannot be used as a map key",
+		},
+		{
+			input: `
+service HttpUserService {
+	Get(ids: []map<timestamp, string>) => (result: map<string, map<any, string>>)
+}`,
+			error: "timestamp cannot be used as a map key",
+		},
+	}
+
+	for _, tc := range testCases {
+		err := validateInput(t, tc.input)
+		if tc.error == "" {
+			assert.NoError(t, err)
+		} else if assert.Error(t, err) {
+			assert.Contains(t, err.(*Error).Message, tc.error)
+		}
+	}
+}
+
+func TestValidateMethodCache(t *testing.T) {
+	testCases := []struct {
+		input string
+		error string
+	}{
+		{
+			input: `
+service HttpUserService {
+	Get(id: string) => (name: string) {
+		HttpMethod = "GET"
+		Cache = true
+	}
+	Create(name: string) => (id: string) {
+		HttpMethod = "POST"
+	}
+}`,
+		},
+		{
+			input: `
+service HttpUserService {
+	Get(id: string) => (name: string) {
+		HttpMethod = "PUT"
+	}
+}`,
+			error: "HttpMethod should be either \"GET\" or \"POST\"",
+		},
+		{
+			input: `
+service RpcUserService {
+	Get(id: string) => (name: string) {
+		HttpMethod = "GET"
+	}
+}`,
+			error: "HttpMethod is only allowed in http service",
+		},
+		{
+			input: `
+service HttpUserService {
+	Download(id: string) => (data: stream []byte) {
+		HttpMethod = "GET"
+	}
+}`,
+			error: "stream is not allowed in GET method",
+		},
+		{
+			input: `
+service HttpUserService {
+	Upload(name: string, files: stream []byte) => (count: int64) {
+		HttpMethod = "GET"
+	}
+}`,
+			error: "stream is not allowed in GET method, as it requires a request body, use HttpMethod = \"POST\" instead",
+		},
+		{
+			input: `
+model Filter {
+	Names: []string
+	Ages: map<string, int32>
+}
+
+service HttpUserService {
+	Search(filter: Filter, limit: int32) => (names: []string) {
+		HttpMethod = "GET"
+	}
+}`,
+		},
+		{
+			input: `
+service HttpUserService {
+	Get(id: string) => (name: string) {
+		Cache = true
+	}
+}`,
+			error: "Cache can only be used by GET methods",
+		},
+		{
+			input: `
+service HttpUserService {
+	Get(id: string) => (name: string) {
+		HttpMethod = "GET"
+		Cache = 1
+	}
+}`,
+			error: "Cache should be a bool",
+		},
+	}
+
+	for _, tc := range testCases {
+		err := validateInput(t, tc.input)
+		if tc.error == "" {
+			assert.NoError(t, err)
+		} else if assert.Error(t, err) {
+			assert.Contains(t, err.(*Error).Message, tc.error)
+		}
+	}
+}
+
+func TestValidateBidirectionalStream(t *testing.T) {
+	testCases := []struct {
+		input string
+		error string
+	}{
+		{
+			input: `
+model Message {
+	Text: string
+}
+
+service HttpChatService {
+	Chat(room: string, msgs: stream Message) => (replies: stream Message)
+	Upload(files: stream []byte) => (count: int64)
+}`,
+		},
+		{
+			input: `
+service HttpChatService {
+	Send(msgs: stream string) => (count: int64)
+}`,
+			error: "stream argument of messages requires a stream return of messages",
+		},
+		{
+			input: `
+service HttpChatService {
+	Send(msgs: stream string)
+}`,
+			error: "stream argument of messages requires a stream return of messages",
+		},
+		{
+			input: `
+service HttpChatService {
+	Send(msgs: stream string) => (data: stream []byte)
+}`,
+			error: "stream argument of messages requires a stream return of messages",
+		},
+		{
+			input: `
+service RpcChatService {
+	Chat(msgs: stream string) => (replies: stream string)
+}`,
+			error: "stream is not allowed in rpc service",
+		},
+	}
+
+	for _, tc := range testCases {
+		err := validateInput(t, tc.input)
+		if tc.error == "" {
+			assert.NoError(t, err)
+		} else if assert.Error(t, err) {
+			assert.Contains(t, err.(*Error).Message, tc.error)
+		}
+	}
+}
+
+func TestValidateMethodTimeout(t *testing.T) {
+	testCases := []struct {
+		input string
+		error string
+	}{
+		{
+			input: `
+const DefaultTimeout = 2s
+
+service HttpUserService {
+	Get(id: string) => (name: string) {
+		Timeout = 500ms
+	}
+	Watch() => (names: stream string) {
+		Timeout = DefaultTimeout
+	}
+}`,
+		},
+		{
+			input: `
+service HttpUserService {
+	Get(id: string) => (name: string) {
+		Timeout = 500
+	}
+}`,
+			error: "Timeout should be a duration",
+		},
+		{
+			input: `
+service HttpUserService {
+	Get(id: string) => (name: string) {
+		Timeout = "1s"
+	}
+}`,
+			error: "Timeout should be a duration",
+		},
+		{
+			input: `
+service HttpUserService {
+	Get(id: string) => (name: string) {
+		Timeout = 0s
+	}
+}`,
+			error: "Timeout should be greater than 0",
+		},
+	}
+
+	for _, tc := range testCases {
+		err := validateInput(t, tc.input)
+		if tc.error == "" {
+			assert.NoError(t, err)
+		} else if assert.Error(t, err) {
+			assert.Contains(t, err.(*Error).Message, tc.error)
+		}
+	}
+}
+
+func TestValidateMethodMaxSize(t *testing.T) {
+	testCases := []struct {
+		input string
+		error string
+	}{
+		{
+			input: `
+const UploadSize = 10mb
+
+service HttpStorageService {
+	Upload(files: stream []byte) => (count: int64) {
+		MaxSize = 5mb
+	}
+	UploadMany(files: stream []byte) => (count: int64) {
+		MaxSize = UploadSize
+	}
+}`,
+		},
+		{
+			input: `
+service HttpStorageService {
+	Upload(files: stream []byte) => (count: int64) {
+		MaxSize = 1024
+	}
+}`,
+			error: "MaxSize should be a byte size",
+		},
+		{
+			input: `
+service HttpStorageService {
+	Upload(files: stream []byte) => (count: int64) {
+		MaxSize = 0kb
+	}
+}`,
+			error: "MaxSize should be greater than 0",
+		},
+		{
+			input: `
+service RpcStorageService {
+	Save(data: string) {
+		MaxSize = 1mb
+	}
+}`,
+			error: "MaxSize is only allowed in http service",
+		},
+	}
+
+	for _, tc := range testCases {
+		err := validateInput(t, tc.input)
+		if tc.error == "" {
+			assert.NoError(t, err)
+		} else if assert.Error(t, err) {
+			assert.Contains(t, err.(*Error).Message, tc.error)
+		}
+	}
+}
+
+func TestValidateMethodRateLimit(t *testing.T) {
+	testCases := []struct {
+		input string
+		error string
+	}{
+		{
+			input: `
+const PingLimit = "10/30s"
+
+service HttpPingService {
+	Ping() {
+		RateLimit = "100/s"
+	}
+	PingHourly() {
+		RateLimit = "5000/h"
+	}
+	PingOften() {
+		RateLimit = PingLimit
+	}
+}`,
+		},
+		{
+			input: `
+service HttpPingService {
+	Ping() {
+		RateLimit = 100
+	}
+}`,
+			error: "RateLimit should be a string",
+		},
+		{
+			input: `
+service HttpPingService {
+	Ping() {
+		RateLimit = "100"
+	}
+}`,
+			error: "RateLimit should be a count per window",
+		},
+		{
+			input: `
+service HttpPingService {
+	Ping() {
+		RateLimit = "0/s"
+	}
+}`,
+			error: "count should be a positive integer",
+		},
+		{
+			input: `
+service HttpPingService {
+	Ping() {
+		RateLimit = "100/day"
+	}
+}`,
+			error: "window should be a positive duration",
+		},
+		{
+			input: `
+service RpcPingService {
+	Ping() {
+		RateLimit = "100/s"
+	}
+}`,
+			error: "RateLimit is only allowed in http service",
+		},
+	}
+
+	for _, tc := range testCases {
+		err := validateInput(t, tc.input)
+		if tc.error == "" {
+			assert.NoError(t, err)
+		} else if assert.Error(t, err) {
+			assert.Contains(t, err.(*Error).Message, tc.error)
+		}
+	}
+}
+
+func TestValidateMethodDisposition(t *testing.T) {
+	testCases := []struct {
+		input string
+		error string
+	}{
+		{
+			input: `
+service HttpAssetService {
+	Download() => (asset: stream []byte) {
+		Disposition = "attachment"
+	}
+	View() => (asset: stream []byte) {
+		Disposition = "inline"
+	}
+}`,
+		},
+		{
+			input: `
+service HttpAssetService {
+	Download() => (asset: stream []byte) {
+		Disposition = "preview"
+	}
+}`,
+			error: "Disposition should be either \"attachment\" or \"inline\"",
+		},
+		{
+			input: `
+service HttpAssetService {
+	Download() => (asset: stream []byte) {
+		Disposition = true
+	}
+}`,
+			error: "Disposition should be either \"attachment\" or \"inline\"",
+		},
+		{
+			input: `
+service HttpAssetService {
+	Get() => (name: string) {
+		Disposition = "inline"
+	}
+}`,
+			error: "Disposition can only be used by methods with a stream []byte return",
+		},
+	}
+
+	for _, tc := range testCases {
+		err := validateInput(t, tc.input)
+		if tc.error == "" {
+			assert.NoError(t, err)
+		} else if assert.Error(t, err) {
+			assert.Contains(t, err.(*Error).Message, tc.error)
+		}
+	}
+}
+
+func TestValidateMethodMsgPack(t *testing.T) {
+	testCases := []struct {
+		input string
+		error string
+	}{
+		{
+			input: `
+service HttpUserService {
+	Get(id: string) => (name: string) {
+		MsgPack = true
+	}
+	List() => (names: []string) {
+		HttpMethod = "GET"
+		MsgPack = false
+	}
+}`,
+		},
+		{
+			input: `
+service HttpUserService {
+	Get(id: string) => (name: string) {
+		MsgPack = "yes"
+	}
+}`,
+			error: "MsgPack should be a bool",
+		},
+		{
+			input: `
+service RpcUserService {
+	Get(id: string) => (name: string) {
+		MsgPack = true
+	}
+}`,
+			error: "MsgPack is only allowed in http service",
+		},
+		{
+			input: `
+service HttpUserService {
+	Get(id: string) => (name: string) {
+		HttpMethod = "GET"
+		MsgPack = true
+	}
+}`,
+			error: "MsgPack can't be used by GET methods",
+		},
+		{
+			input: `
+service HttpUserService {
+	Upload(files: stream []byte) => (count: int64) {
+		MsgPack = true
+	}
+}`,
+			error: "MsgPack can't be used by methods with stream arguments",
+		},
+		{
+			input: `
+service HttpUserService {
+	Watch() => (names: stream string) {
+		MsgPack = true
+	}
+}`,
+			error: "MsgPack can't be used by methods with stream returns",
+		},
+	}
+
+	for _, tc := range testCases {
+		err := validateInput(t, tc.input)
+		if tc.error == "" {
+			assert.NoError(t, err)
+		} else if assert.Error(t, err) {
+			assert.Contains(t, err.(*Error).Message, tc.error)
+		}
+	}
+}
+
+func TestValidateFieldProto(t *testing.T) {
+	testCases := []struct {
+		input string
+		error string
+	}{
+		{
+			input: `
+const NameField = 5
+
+model Base {
+	Id: string { Proto = 1 }
+}
+
+model User {
+	...Base
+	Name: string { Proto = NameField }
+	Age: int32
+}`,
+		},
+		{
+			input: `
+model User {
+	Name: string { Proto = "1" }
+}`,
+			error: "Proto should be an int field number",
+		},
+		{
+			input: `
+model User {
+	Name: string { Proto = 0 }
+}`,
+			error: "Proto should be between 1 and 536870911",
+		},
+		{
+			input: `
+model User {
+	Name: string { Proto = 19500 }
+}`,
+			error: "Proto field numbers 19000 to 19999 are reserved by protobuf",
+		},
+		{
+			input: `
+model Base {
+	Id: string { Proto = 2 }
+}
+
+model User {
+	...Base
+	Name: string { Proto = 2 }
+}`,
+			error: "Proto field number is already used in the same model",
+		},
+	}
+
+	for _, tc := range testCases {
+		err := validateInput(t, tc.input)
+		if tc.error == "" {
+			assert.NoError(t, err)
+		} else if assert.Error(t, err) {
+			assert.Contains(t, err.(*Error).Message, tc.error)
+		}
+	}
+}
+
+func TestValidateFieldConstraints(t *testing.T) {
+	testCases := []struct {
+		input string
+		error string
+	}{
+		{
+			input: `
+const MaxAge = 150
+
+model User {
+	Name: string { Required = true Pattern = "^[a-zA-Z]+$" }
+	Age: uint8 { Min = 1 Max = MaxAge }
+	Score?: float32 { Min = 0 Max = 1.5 }
+}`,
+		},
+		{
+			input: `
+model User {
+	Age: int32 { Pattern = "^[0-9]+$" }
+}`,
+			error: "Pattern is only allowed for string fields",
+		},
+		{
+			input: `
+model User {
+	Name: string { Pattern = "[a-z" }
+}`,
+			error: "Pattern is not a valid regular expression",
+		},
+		{
+			input: `
+model User {
+	Name?: string { Required = true }
+}`,
+			error: "Required can't be used by optional fields",
+		},
+		{
+			input: `
+model User {
+	Name: string { Required = "yes" }
+}`,
+			error: "Required should be a bool",
+		},
+		{
+			input: `
+model User {
+	Name: string { Min = 1 }
+}`,
+			error: "Min is only allowed for number fields",
+		},
+		{
+			input: `
+model User {
+	Age: int8 { Max = 200 }
+}`,
+			error: "Max is out of range of the field's type",
+		},
+		{
+			input: `
+model User {
+	Age: int32 { Min = 1.5 }
+}`,
+			error: "Min should be an integer for integer fields",
+		},
+		{
+			input: `
+model User {
+	Age: int32 { Min = 10 Max = 5 }
+}`,
+			error: "Min should be less than or equal to Max",
+		},
+	}
+
+	for _, tc := range testCases {
+		err := validateInput(t, tc.input)
+		if tc.error == "" {
+			assert.NoError(t, err)
+		} else if assert.Error(t, err) {
+			assert.Contains(t, err.(*Error).Message, tc.error)
+		}
+	}
+}
+
+func TestValidateFieldTimeFormat(t *testing.T) {
+	testCases := []struct {
+		input string
+		error string
+	}{
+		{
+			input: `
+model Event {
+	At: timestamp { TimeFormat = "unixmilli" }
+	Seen?: timestamp { TimeFormat = "unix" }
+	Created: timestamp { TimeFormat = "rfc3339" }
+}`,
+		},
+		{
+			input: `
+model Event {
+	At: timestamp { TimeFormat = "iso8601" }
+}`,
+			error: "TimeFormat should be either \"rfc3339\", \"unix\" or \"unixmilli\"",
+		},
+		{
+			input: `
+model Event {
+	At: timestamp { TimeFormat = 1 }
+}`,
+			error: "TimeFormat should be either",
+		},
+		{
+			input: `
+model Event {
+	At: int64 { TimeFormat = "unix" }
+}`,
+			error: "TimeFormat is only allowed for timestamp fields",
+		},
+	}
+
+	for _, tc := range testCases {
+		err := validateInput(t, tc.input)
+		if tc.error == "" {
+			assert.NoError(t, err)
+		} else if assert.Error(t, err) {
+			assert.Contains(t, err.(*Error).Message, tc.error)
+		}
+	}
+}
+
+func TestValidateFieldJsonString(t *testing.T) {
+	testCases := []struct {
+		input string
+		error string
+	}{
+		{
+			input: `
+model User {
+	Id: int64 { JsonString }
+	Balance?: uint64 { JsonString = true }
+	Score: float64 { JsonString = false }
+}`,
+		},
+		{
+			input: `
+model User {
+	Id: int64 { JsonString = "yes" }
+}`,
+			error: "JsonString should be a bool",
+		},
+		{
+			input: `
+model User {
+	Name: string { JsonString }
+}`,
+			error: "JsonString is only allowed for integer and float fields",
+		},
+		{
+			input: `
+model User {
+	Ids: []int64 { JsonString }
+}`,
+			error: "JsonString is only allowed for integer and float fields",
+		},
+	}
+
+	for _, tc := range testCases {
+		err := validateInput(t, tc.input)
+		if tc.error == "" {
+			assert.NoError(t, err)
+		} else if assert.Error(t, err) {
+			assert.Contains(t, err.(*Error).Message, tc.error)
+		}
+	}
+}
+
+func TestValidateModelExtends(t *testing.T) {
+	testCases := []struct {
+		input string
+		error string
+	}{
+		{
+			// diamond
+			input: `
+model Base {
+	Id: string
+}
+
+model Left {
+	...Base
+	Name: string
+}
+
+model Right {
+	...Base
+	Age: int8
+}
+
+model Bottom {
+	...Left
+	...Right
+}`,
+		},
+		{
+			input: `
+model A {
+	...B
+}
+
+model B {
+	...A
+}`,
+			error: "cyclic extend is detected: A -> B -> A",
+		},
+		{
+			input: `
+model A {
+	...B
+}
+
+model B {
+	...C
+}
+
+model C {
+	...B
+}`,
+			error: "cyclic extend is detected: A -> B -> C -> B",
+		},
+		{
+			input: `
+model A {
+	...A
+}`,
+			error: "model can't extend itself",
+		},
+		{
+			input: `
+enum Role {
+	Admin
+}
+
+model A {
+	...Role
+}`,
+			error: "only models can be extended",
+		},
+		{
+			input: `
+model A {
+	...Missing
+}`,
+			error: "extended model is not defined",
+		},
+		{
+			input: `
+model A {
+	Id: string
+}
+
+model B {
+	...A
+	...A
+}`,
+			error: "model is already extended in the same model",
+		},
+	}
+
+	for _, tc := range testCases {
+		err := validateInput(t, tc.input)
+		if tc.error == "" {
+			assert.NoError(t, err)
+		} else if assert.Error(t, err) {
+			assert.Contains(t, err.(*Error).Message, tc.error)
+		}
+	}
+}
+
+func TestValidateModelExtendsInline(t *testing.T) {
+	testCases := []struct {
+		input  string
+		model  string
+		fields []string
+		error  string
+	}{
+		{
+			input: `
+model User {
+	Id: string
+	Name: string
+}
+
+model Admin {
+	...User
+	Role: string
+}`,
+			model:  "Admin",
+			fields: []string{"Id", "Name", "Role"},
+		},
+		{
+			// extends are resolved regardless of the models' order
+			input: `
+model Admin {
+	...User
+	...Audit
+	Role: string
+}
+
+model User {
+	...Entity
+	Name: string
+}
+
+model Entity {
+	Id: string
+}
+
+model Audit {
+	CreatedAt: timestamp
+}`,
+			model:  "Admin",
+			fields: []string{"Id", "Name", "CreatedAt", "Role"},
+		},
+		{
+			// diamond
+			input: `
+model Base {
+	Id: string
+}
+
+model Left {
+	...Base
+	Name: string
+}
+
+model Right {
+	...Base
+	Age: int8
+}
+
+model Bottom {
+	...Left
+	...Right
+}`,
+			model:  "Bottom",
+			fields: []string{"Id", "Name", "Age"},
+		},
+		{
+			input: `
+model User {
+	Id: string
+}
+
+model Admin {
+	...User
+	Id: int64
+}`,
+			error: "field name is already defined in extended model User",
+		},
+		{
+			input: `
+model User {
+	Id: string
+}
+
+model Group {
+	Id: string
+}
+
+model Admin {
+	...User
+	...Group
+}`,
+			error: "field Id is defined in both User and Group",
+		},
+		{
+			input: `
+model User {
+	Id: string
+	oneof Value {
+		Name: string
+	}
+}
+
+model Admin {
+	...User
+	Value: string
+}`,
+			error: "field name is already defined in extended model User",
+		},
+	}
+
+	for _, tc := range testCases {
+		doc, err := ParseDocument(NewParser(tc.input))
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		err = Validate(doc)
+		if tc.error != "" {
+			if assert.Error(t, err) {
+				assert.Contains(t, err.(*Error).Message, tc.error)
+			}
+			continue
+		}
+
+		if !assert.NoError(t, err) {
+			continue
+		}
+
+		for _, m := range doc.Models {
+			if m.Name.Token.Value != tc.model {
+				continue
+			}
+
+			fields := make([]string, 0, len(m.Fields))
+			for _, f := range m.Fields {
+				fields = append(fields, f.Name.Token.Value)
+			}
+			assert.Equal(t, tc.fields, fields)
+			assert.Empty(t, m.Extends)
+		}
+
+		// validating the inlined models again should not fail
+		assert.NoError(t, Validate(doc))
+	}
+}
+
+func TestValidateReservedNames(t *testing.T) {
+	testCases := []struct {
+		input string
+		error string
+	}{
+		{
+			input: `
+model Requests {
+	Id: string
+}
+
+service HttpUserService {
+	Handle(id: string) => (name: string)
+	CallerId() => (id: string)
+}`,
+		},
+		{
+			input: `
+model Request {
+	Id: string
+}`,
+			error: "name is reserved by the generated code",
+		},
+		{
+			input: `error ErrCircuitOpen { Msg = "open" }`,
+			error: "name is reserved by the generated code",
+		},
+		{
+			input: `const Cache = 1`,
+			error: "name is reserved by the generated code",
+		},
+		{
+			input: `
+model RateLimiter {
+	Id: string
+}`,
+			error: "name is reserved by the generated code",
+		},
+		{
+			input: `error ErrTooManyRequests { Msg = "too many" }`,
+			error: "name is reserved by the generated code",
+		},
+		{
+			input: `
+enum Duration {
+	Short
+}`,
+			error: "name is reserved by the generated code",
+		},
+		{
+			input: `const WithMiddleware = 1`,
+			error: "name is reserved by the generated code",
+		},
+		{
+			input: `
+model CallerInterceptor {
+	Id: string
+}`,
+			error: "name is reserved by the generated code",
+		},
+		{
+			input: `const GetRequestId = "id"`,
+			error: "name is reserved by the generated code",
+		},
+		{
+			input: `
+model Config {
+	Port: int32
+}`,
+			error: "name is reserved by the generated code",
+		},
+		{
+			input: `
+model EnumDecodeError {
+	Id: string
+}`,
+			error: "name is reserved by the generated code",
+		},
+		{
+			input: `
+model SubscriptionManager {
+	Id: string
+}`,
+			error: "name is reserved by the generated code",
+		},
+		{
+			input: `
+service HttpUserService {
+	Caller() => (id: string)
+}`,
+			error: "method name is reserved by the generated code",
+		},
+		{
+			input: `
+service HttpUserService {
+	Constructor() => (id: string)
+}`,
+			error: "method name is reserved by the generated code",
+		},
+		{
+			input: `
+service HttpUserService {
+	Get(id: string) => (name: string)
+}
+
+model CreateHttpUserServiceClient {
+	Id: string
+}`,
+			error: "name is already used by the generated code of HttpUserService",
+		},
+		{
+			input: `
+model User {
+	Id: string
+}
+
+enum UserSchema {
+	A
+}`,
+			error: "name is already used by the generated code of User",
+		},
+		{
+			input: `
+model Result {
+	oneof Value {
+		User: string
+	}
+}
+
+model ResultValueUser {
+	Id: string
+}`,
+			error: "name is already used by the generated code of Result",
+		},
+		{
+			input: `
+enum Status {
+	Active
+}
+
+model ParseStatus {
+	Id: string
+}`,
+			error: "name is already used by the generated code of Status",
+		},
+		{
+			input: `
+enum Status {
+	Active { Label = "Currently active" }
+}
+
+const StatusLabels = "labels"`,
+			error: "name is already used by the generated code of Status",
+		},
+		{
+			input: `
+model Signup {
+	Username: string { Required = true }
+	Validate: bool
+}`,
+			error: "field name is reserved by the generated code",
+		},
+	}
+
+	for _, tc := range testCases {
+		err := validateInput(t, tc.input)
+		if tc.error == "" {
+			assert.NoError(t, err)
+		} else if assert.Error(t, err) {
+			assert.Contains(t, err.(*Error).Message, tc.error)
+		}
+	}
+}
+
+func TestValidateModelRequires(t *testing.T) {
+	testCases := []struct {
+		input string
+		error string
+	}{
+		{
+			input: `
+model Payment {
+	Method: string
+	Card?: string
+	requires(Card, when: Method)
+}`,
+		},
+		{
+			// fields of extended models can be used
+			input: `
+model Base {
+	Method: string
+}
+
+model Payment {
+	...Base
+	Card?: string
+	requires(Card, when: Method)
+}`,
+		},
+		{
+			input: `
+model Payment {
+	Card?: string
+	requires(Card, when: Method)
+}`,
+			error: "field is not defined in the model",
+		},
+		{
+			input: `
+model Payment {
+	Method: string
+	requires(Card, when: Method)
+}`,
+			error: "field is not defined in the model",
+		},
+		{
+			input: `
+model Payment {
+	Method: string
+	requires(Method, when: Method)
+}`,
+			error: "field can't be required by itself",
+		},
+		{
+			input: `
+model Payment {
+	Method: string
+	Card?: string
+	requires(Card, Card, when: Method)
+}`,
+			error: "field is already used in the same requires",
+		},
+	}
+
+	for _, tc := range testCases {
+		err := validateInput(t, tc.input)
+		if tc.error == "" {
+			assert.NoError(t, err)
+		} else if assert.Error(t, err) {
+			assert.Contains(t, err.(*Error).Message, tc.error)
+		}
+	}
+}
+
+func TestValidateOneOf(t *testing.T) {
+	testCases := []struct {
+		input string
+		error string
+	}{
+		{
+			input: `
+model User {
+	Id: string
+}
+
+model Result {
+	Id: string
+	oneof Value {
+		User: User
+		Count: int64
+	}
+}`,
+		},
+		{
+			input: `
+model Result {
+	oneof Value {
+		Count: int64
+		Count: int32
+	}
+}`,
+			error: "variant name is already used in the same oneof",
+		},
+		{
+			input: `
+model Result {
+	Value: string
+	oneof Value {
+		Count: int64
+	}
+}`,
+			error: "oneof name is already used in the same model",
+		},
+		{
+			input: `
+model Result {
+	oneof Value {
+		User: User
+	}
+}`,
+			error: "type is not defined",
+		},
+	}
+
+	for _, tc := range testCases {
+		err := validateInput(t, tc.input)
+		if tc.error == "" {
+			assert.NoError(t, err)
+		} else if assert.Error(t, err) {
+			assert.Contains(t, err.(*Error).Message, tc.error)
+		}
+	}
+}
+
+func TestValidateEnumLabel(t *testing.T) {
+	testCases := []struct {
+		input string
+		error string
+	}{
+		{
+			input: `
+const ActiveLabel = "Currently active"
+
+enum Status {
+	Active { Label = ActiveLabel }
+	Inactive { Label = "Not active" }
+}`,
+		},
+		{
+			input: `
+enum Status {
+	Active { Label = 1 }
+}`,
+			error: "enum key's Label should be a string",
+		},
+		{
+			input: `
+enum Status {
+	Active { Label = Missing }
+}`,
+			error: "unknown constant is not defined",
+		},
+		{
+			input: `
+enum Status {
+	Active {
+		Label = "a"
+		Label = "b"
+	}
+}`,
+			error: "option name is already used in the same enum key",
+		},
+	}
+
+	for _, tc := range testCases {
+		err := validateInput(t, tc.input)
+		if tc.error == "" {
+			assert.NoError(t, err)
+		} else if assert.Error(t, err) {
+			assert.Contains(t, err.(*Error).Message, tc.error)
+		}
+	}
+}
+
+func TestValidateEnumJsonNumber(t *testing.T) {
+	testCases := []struct {
+		input string
+		error string
+	}{
+		{
+			input: `
+const UseNumber = true
+
+enum Status {
+	Active
+} { JsonNumber = UseNumber }`,
+		},
+		{
+			input: `
+enum Status {
+	Active
+} { JsonNumber = "yes" }`,
+			error: "enum's JsonNumber should be a bool",
+		},
+		{
+			input: `
+enum Status {
+	Active
+} {
+	JsonNumber = true
+	JsonNumber = false
+}`,
+			error: "option name is already used in the same enum",
+		},
+	}
+
+	for _, tc := range testCases {
+		err := validateInput(t, tc.input)
+		if tc.error == "" {
+			assert.NoError(t, err)
+		} else if assert.Error(t, err) {
+			assert.Contains(t, err.(*Error).Message, tc.error)
+		}
+	}
+}
+
+func TestValidateEnumReserved(t *testing.T) {
+	testCases := []struct {
+		input string
+		error string
+	}{
+		{
+			input: `
+enum Status {
+	reserved 1, 5..9
+	_
+	Active = 2
+	Inactive = 10
+}`,
+		},
+		{
+			input: `
+enum Status {
+	reserved 1
+	Active
+	Inactive
+}`,
+			error: "enum value 1 is reserved",
+		},
+		{
+			input: `
+enum Status {
+	reserved 20..30
+	Active = 25
+}`,
+			error: "enum value 25 is reserved",
+		},
+	}
+
+	for _, tc := range testCases {
+		err := validateInput(t, tc.input)
+		if tc.error == "" {
+			assert.NoError(t, err)
+		} else if assert.Error(t, err) {
+			assert.Contains(t, err.(*Error).Message, tc.error)
+		}
+	}
+}
+
+func TestValidateModelReserved(t *testing.T) {
+	testCases := []struct {
+		input string
+		error string
+	}{
+		{
+			input: `
+model User {
+	reserved "Nickname", "Avatar"
+	Id: string
+	Name: string
+}`,
+		},
+		{
+			input: `
+model User {
+	reserved "Nickname"
+	reserved "Avatar"
+	Id: string
+	Avatar: string
+}`,
+			error: "field name Avatar is reserved",
+		},
+	}
+
+	for _, tc := range testCases {
+		err := validateInput(t, tc.input)
+		if tc.error == "" {
+			assert.NoError(t, err)
+		} else if assert.Error(t, err) {
+			assert.Contains(t, err.(*Error).Message, tc.error)
+		}
+	}
+}
+
+func TestValidateDeprecated(t *testing.T) {
+	testCases := []struct {
+		input string
+		error string
+	}{
+		{
+			input: `
+model User {
+	Name: string { Deprecated }
+	Nickname: string { Deprecated = "use Name instead" }
+}
+
+service HttpUserService {
+	GetByName(name: string) => (user: User) { Deprecated = false }
+}`,
+		},
+		{
+			input: `
+model User {
+	Name: string { Deprecated = 1 }
+}`,
+			error: "Deprecated should be a bool or a message string",
+		},
+		{
+			input: `
+service RpcUserService {
+	Delete(id: string) { Deprecated = 10s }
+}`,
+			error: "Deprecated should be a bool or a message string",
+		},
+	}
+
+	for _, tc := range testCases {
+		err := validateInput(t, tc.input)
+		if tc.error == "" {
+			assert.NoError(t, err)
+		} else if assert.Error(t, err) {
+			assert.Contains(t, err.(*Error).Message, tc.error)
+		}
+	}
+}
+
+func TestValidateLifecycle(t *testing.T) {
+	testCases := []struct {
+		input string
+		error string
+	}{
+		{
+			input: `
+model User {
+	Name: string { Since = "1.0.0" Until = "v2.0.0" }
+}
+
+service HttpUserService {
+	GetByName(name: string) => (user: User) { Until = "1.5.0" }
+}`,
+		},
+		{
+			input: `
+model User {
+	Name: string { Since = "1.0" }
+}`,
+			error: "Since should be a semantic version, e.g. \"1.2.0\": expected major.minor.patch",
+		},
+		{
+			input: `
+model User {
+	Name: string { Until = 2 }
+}`,
+			error: "Until should be a semantic version string, e.g. \"1.2.0\"",
+		},
+		{
+			input: `
+service RpcUserService {
+	Delete(id: string) { Since = "2.0.0" Until = "1.0.0" }
+}`,
+			error: "Until should be greater than Since, 1.0.0 is not after 2.0.0",
+		},
+	}
+
+	for _, tc := range testCases {
+		err := validateInput(t, tc.input)
+		if tc.error == "" {
+			assert.NoError(t, err)
+		} else if assert.Error(t, err) {
+			assert.Contains(t, err.(*Error).Message, tc.error)
+		}
+	}
+}
+
+// largeDocument synthesizes a document with size models, which extend and refer to
+// each other, and enums, consts and services in proportion to stress the validation
+func largeDocument(size int) string {
+	var sb strings.Builder
+
+	for i := range size / 10 {
+		fmt.Fprintf(&sb, "const Value%d = %d\n", i, i)
+		fmt.Fprintf(&sb, "enum Status%d {\n\tActive\n\tInactive { Label = \"inactive\" }\n}\n", i)
+		fmt.Fprintf(&sb, "error ErrNotFound%d { Code = %d HttpStatus = NotFound Msg = \"not found\" }\n", i, i+1)
+	}
+
+	for i := range size {
+		fmt.Fprintf(&sb, "model Model%d {\n", i)
+		// extends are chained in groups of 10, as the inlined fields grow quadratically with the chain
+		if i%10 != 0 {
+			fmt.Fprintf(&sb, "\t...Model%d\n", i-1)
+		}
+		fmt.Fprintf(&sb, "\tId%d: string { Json = \"id%d\" }\n", i, i)
+		fmt.Fprintf(&sb, "\tStatus%d: Status%d\n", i, i/10)
+		fmt.Fprintf(&sb, "\tItems%d?: map<string, []Model%d>\n", i, max(i-1, 0))
+		sb.WriteString("}\n")
+	}
+
+	for i := range size / 10 {
+		fmt.Fprintf(&sb, "service HttpService%d {\n", i)
+		for j := range 10 {
+			fmt.Fprintf(&sb, "\tGet%d(id: string, value: int64) => (result: Model%d)\n", j, i*10+j)
+		}
+		sb.WriteString("}\n")
+	}
+
+	return sb.String()
+}
+
+func BenchmarkParseValidateLargeDocument(b *testing.B) {
+	input := largeDocument(5000)
+
+	b.ReportAllocs()
+
+	for b.Loop() {
+		doc, err := ParseDocument(NewParser(input))
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		if err := Validate(doc); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestValidateConstLiteral(t *testing.T) {
+	const models = `
+model Address {
+	City: string
+}
+
+model Base {
+	Id: int64
+}
+
+model User {
+	...Base
+	Name: string
+	Age: uint8
+	Score: float64
+	Active: bool
+	Timeout: int64
+	Tags: []string
+	Roles: set<string>
+	Address: Address
+	Addresses: []Address
+	CreatedAt?: timestamp
+}
+`
+
+	testCases := []struct {
+		input string
+		error string
+	}{
+		{
+			input: `
+const Anonymous = "anon"
+
+const DefaultUser: User = {
+	Id = 1
+	Name = Anonymous
+	Age = 0
+	Score = 1
+	Active
+	Timeout = 30s
+	Tags = ["a", "b"]
+	Roles = ["admin"]
+	Address = { City = "Toronto" }
+	Addresses = [{ City = "Paris" }]
+}
+
+const Admin = DefaultUser
+const Guest: User = DefaultUser
+const Cities: []Address = [{ City = "Tokyo" }]`,
+		},
+		{
+			input: `const DefaultUser = { Name = "anon" }`,
+			error: "object and list literals require a type",
+		},
+		{
+			input: `const Name: User = "anon"`,
+			error: "only object and list literals can have a type",
+		},
+		{
+			input: `const Names: map<string, string> = {}`,
+			error: "constant's type should be a model or an array",
+		},
+		{
+			input: `
+enum Status { Active }
+const DefaultStatus: Status = {}`,
+			error: "constant's type should be a model or an array",
+		},
+		{
+			input: `const DefaultAddress: Address = {}`,
+			error: "field City of Address is not set, only the optional fields can be omitted",
+		},
+		{
+			input: `const DefaultUser: User = { Email = "a@b.c" }`,
+			error: "Email is not a field of User",
+		},
+		{
+			input: `const DefaultUser: User = { Name = "a" Name = "b" }`,
+			error: "field is already set in the same literal",
+		},
+		{
+			input: `const DefaultUser: User = { Name = 1 }`,
+			error: "expected a string value",
+		},
+		{
+			input: `const DefaultUser: User = { Age = 256 }`,
+			error: "value is out of range of uint8",
+		},
+		{
+			input: `const DefaultUser: User = { Age = 1.5 }`,
+			error: "expected an integer value",
+		},
+		{
+			input: `const DefaultUser: User = { Name }`,
+			error: "expected a string value",
+		},
+		{
+			input: `const DefaultUser: User = { Address = null }`,
+			error: "null is not allowed in literals",
+		},
+		{
+			input: `const DefaultUser: User = { Tags = "a" }`,
+			error: "expected a list literal of []string",
+		},
+		{
+			input: `const DefaultUser: User = { Roles = ["a", "a"] }`,
+			error: "value is already in the set",
+		},
+		{
+			input: `const DefaultUser: User = { Address = { Street = "Main" } }`,
+			error: "Street is not a field of Address",
+		},
+		{
+			input: `const DefaultUser: User = { CreatedAt = "2024-01-01" }`,
+			error: "timestamp is not supported in literals",
+		},
+		{
+			input: `const DefaultUser: User = { Name = Unknown }`,
+			error: "unknown constant is not defined",
+		},
+		{
+			input: `
+const DefaultAddress: Address = { City = "Toronto" }
+const DefaultUser: User = DefaultAddress`,
+			error: "constant's type User is different from Address",
+		},
+		{
+			input: `
+const DefaultAddress: Address = { City = "Toronto" }
+
+model Account {
+	Name: string { Default = DefaultAddress }
+}`,
+			error: "object and list literals can't be used as an option's value",
+		},
+	}
+
+	for _, tc := range testCases {
+		err := validateInput(t, models+tc.input)
+		if tc.error == "" {
+			assert.NoError(t, err)
+		} else if assert.Error(t, err) {
+			assert.Contains(t, err.(*Error).Message, tc.error)
+		}
+	}
+}
+
+func TestValidateAll(t *testing.T) {
+	const input = `
+const maxSize = 10
+
+model User {
+	Role: Role
+}
+
+model Account {
+	Id: string
+}
+
+model Account {
+	Name: string
+}
+
+service RpcUserService {
+	Upload(data: stream []byte) => ()
+}
+`
+
+	doc, err := ParseDocument(NewParser(input))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// the stream of the rpc service is checked in a later stage, so it's not reported
+	errs := ValidateAll(doc)
+	if assert.Len(t, errs, 3) {
+		assert.Contains(t, errs[0].(*Error).Message, "name should be PascalCase")
+		assert.Contains(t, errs[1].(*Error).Message, "name is already used")
+		assert.Contains(t, errs[2].(*Error).Message, "type is not defined")
+	}
+
+	// Validate stops at the first error
+	err = Validate(doc)
+	if assert.Error(t, err) {
+		assert.Equal(t, errs[0].(*Error).Message, err.(*Error).Message)
+	}
+}
@@ -15,6 +15,8 @@ type Lexer struct {
 	start   int
 	pos     int
 	width   int
+	line    int // 0-based line of start
+	lineAt  int // byte offset of the line's first character
 }
 
 func (l *Lexer) Current() string {
@@ -22,14 +24,26 @@ func (l *Lexer) Current() string {
 }
 
 func (l *Lexer) Emit(typ token.Type) {
+	l.emit(typ, l.input[l.start:l.pos])
+}
+
+// EmitMultiline emits the current input with its CRLF line endings replaced by LF,
+// so a multi line value is the same regardless of the line endings of the file
+func (l *Lexer) EmitMultiline(typ token.Type) {
+	l.emit(typ, strings.ReplaceAll(l.input[l.start:l.pos], "\r\n", "\n"))
+}
+
+func (l *Lexer) emit(typ token.Type, value string) {
 	token := &token.Token{
-		Type:  typ,
-		Value: l.input[l.start:l.pos],
-		Start: l.start,
-		End:   l.pos,
+		Type:   typ,
+		Value:  value,
+		Start:  l.start,
+		End:    l.pos,
+		Line:   l.line + 1,
+		Column: l.start - l.lineAt + 1,
 	}
 	l.emitter.EmitToken(token)
-	l.start = l.pos
+	l.Ignore()
 }
 
 func (l *Lexer) Next() rune {
@@ -73,7 +87,15 @@ func (l *Lexer) Backup() {
 	l.width = 0
 }
 
+// Ignore skips the current input, the line of the next token is
+// updated by the skipped new lines
 func (l *Lexer) Ignore() {
+	for i := l.start; i < l.pos; i++ {
+		if l.input[i] == '\n' {
+			l.line++
+			l.lineAt = i + 1
+		}
+	}
 	l.start = l.pos
 }
 
@@ -106,10 +128,12 @@ func (l *Lexer) AcceptRunUntil(invalid string) {
 
 func (l *Lexer) Errorf(format string, args ...interface{}) {
 	l.emitter.EmitToken(&token.Token{
-		Type:  token.Error,
-		Value: fmt.Sprintf(format, args...),
-		Start: l.start,
-		End:   l.pos,
+		Type:   token.Error,
+		Value:  fmt.Sprintf(format, args...),
+		Start:  l.start,
+		End:    l.pos,
+		Line:   l.line + 1,
+		Column: l.start - l.lineAt + 1,
 	})
 }
 
@@ -121,11 +145,21 @@ func (l *Lexer) Run(state State) {
 
 type State func(*Lexer) State
 
+// bom is the byte order mark which some editors, e.g. on Windows, write at the start of utf-8 files
+const bom = "\uFEFF"
+
 func Start(emitter token.Emitter, inital State, input string) {
 	lexer := &Lexer{
 		emitter: emitter,
 		input:   input,
 	}
+
+	// the mark is skipped rather than trimmed, so the tokens' offsets are still the file's offsets
+	if strings.HasPrefix(input, bom) {
+		lexer.start = len(bom)
+		lexer.pos = len(bom)
+		lexer.lineAt = len(bom)
+	}
 	for state := inital; state != nil; {
 		state = state(lexer)
 	}
@@ -40,10 +40,12 @@ func Lex(l *Lexer) State {
 			l.Errorf("extend requires 3 consecutive dots")
 			return nil
 		}
-		if l.Next() != '.' {
-			l.Errorf("extend requires 3 consecutive dots")
-			return nil
+		// 2 consecutive dots is a range, e.g. 20..30
+		if l.Peek() != '.' {
+			l.Emit(token.Range)
+			return Lex
 		}
+		l.Next()
 		l.Emit(token.Extend)
 		return Lex
 	case '{':
@@ -72,13 +74,18 @@ func Lex(l *Lexer) State {
 		return Lex
 	case '[':
 		l.Next()
+		// [ which is not followed by ] opens a list literal, e.g. [1, 2]
 		if l.Peek() != ']' {
-			l.Errorf("expect ] to close array")
-			return nil
+			l.Emit(token.OpenBracket)
+			return Lex
 		}
 		l.Next()
 		l.Emit(token.Array)
 		return Lex
+	case ']':
+		l.Next()
+		l.Emit(token.CloseBracket)
+		return Lex
 	case '#':
 		l.Next()
 		l.Ignore()
@@ -114,7 +121,7 @@ func Lex(l *Lexer) State {
 			l.Errorf("expect ` to close back multi line quote")
 			return nil
 		}
-		l.Emit(token.ConstStringBacktickQoute)
+		l.EmitMultiline(token.ConstStringBacktickQoute)
 		l.Next()
 		l.Ignore()
 	default:
@@ -161,7 +168,8 @@ func parseNumber(l *Lexer) (ok bool, found bool) {
 		return true, false // not founding number but no error
 	}
 
-	if l.Accept(".") {
+	// the dot is not a decimal point if it's the start of a range, e.g. 20..30
+	if l.PeekN(2) != ".." && l.Accept(".") {
 		isFloat = true
 		if !l.AcceptRun(digits) {
 			l.Errorf("expected digit after decimal point")
@@ -189,7 +197,7 @@ func parseNumber(l *Lexer) (ok bool, found bool) {
 
 	peek := l.Peek()
 
-	if peek == 0 || peek == ' ' || peek == '\t' || peek == '\n' || peek == '\r' || peek == '#' {
+	if peek == 0 || peek == ' ' || peek == '\t' || peek == '\n' || peek == '\r' || peek == '#' || peek == ',' || l.PeekN(2) == ".." {
 		if strings.Contains(l.Current(), "__") {
 			l.Errorf("expected digit after each underscore")
 			return false, false // not founding number and with error
@@ -312,6 +320,9 @@ func reservedKeywrod(l *Lexer) bool {
 	case "map":
 		l.Emit(token.Map)
 		return true
+	case "set":
+		l.Emit(token.Set)
+		return true
 	case "any":
 		l.Emit(token.Any)
 		return true
@@ -327,6 +338,18 @@ func reservedKeywrod(l *Lexer) bool {
 	case "error":
 		l.Emit(token.CustomError)
 		return true
+	case "oneof":
+		l.Emit(token.OneOf)
+		return true
+	case "import":
+		l.Emit(token.Import)
+		return true
+	case "reserved":
+		l.Emit(token.Reserved)
+		return true
+	case "requires":
+		l.Emit(token.Requires)
+		return true
 	default:
 		return false
 	}
@@ -4,8 +4,10 @@ type Token struct {
 	Filename string
 	Value    string
 	Type     Type
-	Start    int
-	End      int
+	Start    int // byte offset of the first character
+	End      int // byte offset after the last character
+	Line     int // 1-based line of Start, zero if the token isn't from the input
+	Column   int // 1-based byte column of Start in its line
 }
 
 type Emitter interface {
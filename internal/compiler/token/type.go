@@ -25,6 +25,7 @@ const (
 	Timestamp                            // timestamp
 	String                               // string
 	Map                                  // map
+	Set                                  // set
 	Array                                // array []
 	Any                                  // any
 	Stream                               // stream
@@ -51,6 +52,13 @@ const (
 	CloseAngle                           // >
 	Comment                              // # comment
 	CustomError                          // error
+	OneOf                                // oneof
+	Import                               // import
+	Reserved                             // reserved
+	Range                                // ..
+	Requires                             // requires
+	OpenBracket                          // [
+	CloseBracket                         // ]
 )
 
 func (tt Type) String() string {
@@ -99,6 +107,8 @@ func (tt Type) String() string {
 		return "String"
 	case Map:
 		return "Map"
+	case Set:
+		return "Set"
 	case Array:
 		return "Array"
 	case Any:
@@ -151,6 +161,20 @@ func (tt Type) String() string {
 		return "Comment"
 	case CustomError:
 		return "CustomError"
+	case OneOf:
+		return "OneOf"
+	case Import:
+		return "Import"
+	case Reserved:
+		return "Reserved"
+	case Range:
+		return "Range"
+	case Requires:
+		return "Requires"
+	case OpenBracket:
+		return "OpenBracket"
+	case CloseBracket:
+		return "CloseBracket"
 	default:
 		return "Unknown"
 	}
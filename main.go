@@ -1,9 +1,15 @@
 package main
 
 import (
+	"bufio"
+	"cmp"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/hexe-dev/hexe/internal/compiler/ast"
@@ -26,18 +32,110 @@ Usage: hexe [command]
 
 Commands:
   - fmt Format one or many files in place using glob pattern
-        hexe fmt <glob path>
+        hexe fmt [--check | -l] <glob paths...>
+
+        --check, or -l, prints the files which are not formatted without
+        writing them, and exits with non-zero status if there is any
+
+        - as the path formats stdin into stdout, e.g. cat x.hexe | hexe fmt -
 
   - gen Generate code from a folder to a file and currently
-        supports .go and .ts extensions
-        hexe gen <pkg> <output path to file> <search glob paths...>
+        supports .go, .cli.go (a main package with a command line
+        client of http services), .ts, .zod.ts (zod schemas), .openapi.json
+        (OpenAPI 3.0 of http services), .schema.json (JSON Schema
+        of models and enums), .ast.json (the validated schema as
+        JSON) and .proto (protobuf of models and rpc services)
+        extensions,
+        .json and .env only contain the constants
+        hexe gen [--profile[=<dir>]] [--ts-enums=<enum|const>] [--go-typed-units]
+                 [--go-config] [--go-presence] [--go-version=<1.x>] [--errors-lock[=<path>]]
+                 [--no-any] [--plugin=<path>] [--api-version=<x.y.z>]
+                 <pkg> <output path to file> <search glob paths...>
+
+        --profile prints per phase timings and memory stats to stderr,
+        and if a dir is provided, writes cpu.pprof and heap.pprof into it
+
+        --ts-enums=const generates the Typescript enums as const objects
+        for the environments which don't allow enums, default is enum
+
+        --go-typed-units generates the byte size and duration constants
+        as ByteSize and Duration types with a String method, e.g. 10mb
+
+        --go-config generates a Config struct of the constants and a LoadConfig
+        function which overrides them by the environment variables, e.g. MAX_UPLOAD
+
+        --go-presence tracks the optional fields of the models by a bitset with
+        HasX and SetX methods, so a zero value which is set is still encoded,
+        and the models are values instead of pointers, except the recursive ones
+
+        --go-version targets an older Go version, e.g. 1.22, so the newer
+        features such as omitzero tag are not used, at least 1.21 is required
+
+        --errors-lock keeps the codes of the custom errors in a lock file,
+        default is hexe.errors.lock, so the codes don't shift once errors
+        are added or removed, the file is created if it doesn't exist
+
+        --no-any fails if the any type is used by the models or services
+
+        --api-version generates the api as it is at the version, e.g. 1.2.0,
+        the fields and methods whose Since option is after it, or whose
+        Until option is at or before it, are excluded
+
+        --plugin generates the code by an executable, e.g. ./hexe-gen-kotlin,
+        which reads the schema as JSON from stdin and writes the generated
+        files as JSON into stdout, the output is the directory of the files
+
+        if the output is -, the generated Go code is written to stdout,
+        the other targets are selected by their extension, e.g. -.ts,
+        and if no search path is given, the schema is read from stdin
+        hexe gen <pkg> <- | -.ts | -.zod.ts> [search glob paths...]
+
+  - explain Print the resolved description of a constant, enum, model,
+        service or error, including its Go and Typescript types and
+        the models and services which use it
+        hexe explain <name> <search glob paths...>
+
+  - diff Compare two versions of a schema and print the breaking
+        changes, e.g. removed fields, changed types and renumbered
+        enum values, and the compatible additions
+        hexe diff [--strict] <old search glob path> <new search glob path>
+
+        --strict exits with non-zero status if there is any breaking change
+
+  - lint Print the advisory warnings of the schema, e.g. the enums
+        whose values are not contiguous
+        hexe lint [--strict] <search glob paths...>
+
+        --strict exits with non-zero status if there is any warning
+
+  - list Print the counts and names of the constants, enums, models,
+        services, with their methods count, and errors of the schema
+        hexe list [--json] <search glob paths...>
+
+        --json prints the inventory as JSON
 
   - ver Print the version of hexe
 
 example:
   hexe fmt "./path/to/*.hexe"
+  hexe fmt --check "./path/to/*.hexe"
+  hexe gen rpc ./path/to/output.go "./path/to/**/*.hexe"
   hexe gen rpc ./path/to/output.go "./path/to/*.hexe"
   hexe gen rpc ./path/to/output.ts "./path/to/*.hexe" "./path/to/other/*.hexe"
+  hexe gen rpc ./path/to/output.zod.ts "./path/to/*.hexe"
+  hexe gen rpc ./path/to/consts.env "./path/to/*.hexe"
+  hexe gen rpc ./path/to/api.openapi.json "./path/to/*.hexe"
+  hexe gen rpc ./path/to/api.proto "./path/to/*.hexe"
+  hexe gen rpc ./path/to/api.schema.json "./path/to/*.hexe"
+  hexe gen rpc ./path/to/api.ast.json "./path/to/*.hexe"
+  hexe gen main ./cmd/apictl/main.cli.go "./path/to/*.hexe"
+  hexe gen --profile=./profile rpc ./path/to/output.go "./path/to/*.hexe"
+  hexe gen --plugin=./hexe-gen-kotlin rpc ./path/to/out "./path/to/*.hexe"
+  cat ./path/to/*.hexe | hexe gen rpc - > ./path/to/output.go
+  hexe explain User "./path/to/*.hexe"
+  hexe diff --strict ./path/to/old.hexe ./path/to/new.hexe
+  hexe lint "./path/to/*.hexe"
+  hexe list --json "./path/to/*.hexe"
 `
 
 func main() {
@@ -50,17 +148,113 @@ func main() {
 
 	switch os.Args[1] {
 	case "fmt":
-		if len(os.Args) < 3 {
+		args := os.Args[2:]
+		check := len(args) > 0 && (args[0] == "--check" || args[0] == "-l")
+		if check {
+			args = args[1:]
+		}
+		if len(args) == 0 {
 			fmt.Print(usage)
 			os.Exit(0)
 		}
-		err = formatCmd(os.Args[2])
+		err = formatCmd(os.Stdin, os.Stdout, check, args...)
 	case "gen":
-		if len(os.Args) < 5 {
+		var prof *profiler
+		var checks validateChecks
+		opts := []gen.Option{gen.WithWarnings(os.Stderr)}
+		args := os.Args[2:]
+		for len(args) > 0 && strings.HasPrefix(args[0], "--") {
+			flag, value, _ := strings.Cut(args[0], "=")
+			switch flag {
+			case "--profile":
+				prof, err = newProfiler(os.Stderr, value)
+			case "--ts-enums":
+				opts, err = appendTsEnumsOption(opts, value)
+			case "--go-typed-units":
+				opts = append(opts, gen.WithGoTypedUnits())
+			case "--go-config":
+				opts = append(opts, gen.WithGoConfig())
+			case "--go-presence":
+				opts = append(opts, gen.WithGoPresence())
+			case "--go-version":
+				opts, err = appendGoVersionOption(opts, value)
+			case "--errors-lock":
+				checks.errorsLock = cmp.Or(value, defaultErrorsLock)
+			case "--no-any":
+				checks.noAny = true
+			case "--plugin":
+				opts, err = appendPluginOption(opts, value)
+			case "--api-version":
+				opts, err = appendApiVersionOption(opts, value)
+			default:
+				err = fmt.Errorf("unknown flag: %s", flag)
+			}
+			if err != nil {
+				break
+			}
+			args = args[1:]
+		}
+		if err != nil {
+			break
+		}
+		if len(args) == 2 && isStdioOutput(args[1]) {
+			stdout := bufio.NewWriter(os.Stdout)
+			err = genStdioCmd(prof, opts, checks, args[0], args[1], os.Stdin, stdout)
+			if flushErr := stdout.Flush(); err == nil {
+				err = flushErr
+			}
+		} else if len(args) < 3 {
+			fmt.Print(usage)
+			os.Exit(0)
+		} else {
+			stdout := bufio.NewWriter(os.Stdout)
+			err = genCmd(stdout, prof, opts, checks, args[0], args[1], args[2:]...)
+			if flushErr := stdout.Flush(); err == nil {
+				err = flushErr
+			}
+		}
+		if stopErr := prof.Stop(); err == nil {
+			err = stopErr
+		}
+	case "explain":
+		if len(os.Args) < 4 {
+			fmt.Print(usage)
+			os.Exit(0)
+		}
+		err = explainCmd(os.Stdout, os.Args[2], os.Args[3:]...)
+	case "diff":
+		args := os.Args[2:]
+		strict := len(args) > 0 && args[0] == "--strict"
+		if strict {
+			args = args[1:]
+		}
+		if len(args) != 2 {
+			fmt.Print(usage)
+			os.Exit(0)
+		}
+		err = diffCmd(os.Stdout, strict, args[0], args[1])
+	case "lint":
+		args := os.Args[2:]
+		strict := len(args) > 0 && args[0] == "--strict"
+		if strict {
+			args = args[1:]
+		}
+		if len(args) == 0 {
+			fmt.Print(usage)
+			os.Exit(0)
+		}
+		err = lintCmd(os.Stdout, strict, args...)
+	case "list":
+		args := os.Args[2:]
+		asJSON := len(args) > 0 && args[0] == "--json"
+		if asJSON {
+			args = args[1:]
+		}
+		if len(args) == 0 {
 			fmt.Print(usage)
 			os.Exit(0)
 		}
-		err = genCmd(os.Args[2], os.Args[3], os.Args[4:]...)
+		err = listCmd(os.Stdout, asJSON, args...)
 	case "ver":
 		fmt.Println(Version)
 	default:
@@ -74,60 +268,471 @@ func main() {
 	}
 }
 
-func formatCmd(searchPaths ...string) error {
+// formatCmd formats the files matched by the search paths in place, if check is set,
+// the files are not written, the unformatted ones are written into w instead and
+// an error is returned when there is any. The stdioPath search path formats r into w
+func formatCmd(r io.Reader, w io.Writer, check bool, searchPaths ...string) error {
+	unformatted := 0
+	globs := make([]string, 0, len(searchPaths))
+
 	for _, searchPath := range searchPaths {
-		filenames, err := filesFromGlob(searchPath)
+		if searchPath != stdioPath {
+			globs = append(globs, searchPath)
+			continue
+		}
+
+		formatted, err := formatStdio(r, w, check)
 		if err != nil {
 			return err
 		}
+		if !formatted {
+			unformatted++
+		}
+	}
 
-		for _, filename := range filenames {
-			doc, err := parser.ParseDocument(parser.NewWithFilenames(filename))
-			if err != nil {
-				return err
-			}
+	filenames, err := filesFromGlobs(globs...)
+	if err != nil {
+		return err
+	}
 
-			var sb strings.Builder
-			doc.Format(&sb)
+	for _, filename := range filenames {
+		doc, err := parser.ParseDocument(parser.NewWithFilenames(filename))
+		if err != nil {
+			return err
+		}
 
-			err = os.WriteFile(filename, []byte(sb.String()), os.ModePerm)
+		var sb strings.Builder
+		doc.Format(&sb)
+
+		if check {
+			content, err := os.ReadFile(filename)
 			if err != nil {
 				return err
 			}
+
+			if string(content) != sb.String() {
+				unformatted++
+				if _, err := fmt.Fprintln(w, filename); err != nil {
+					return err
+				}
+			}
+			continue
 		}
+
+		err = os.WriteFile(filename, []byte(sb.String()), os.ModePerm)
+		if err != nil {
+			return err
+		}
+	}
+
+	if unformatted > 0 {
+		return fmt.Errorf("found %d unformatted files", unformatted)
 	}
 
 	return nil
 }
 
-func genCmd(pkg, out string, searchPaths ...string) (err error) {
+// stdioPath is the path of stdin and stdout, e.g. hexe fmt -, or hexe gen rpc - for
+// the Go code, the other targets are selected by an extension, e.g. hexe gen rpc -.ts
+const stdioPath = "-"
+
+// formatStdio writes the formatted schema read from r into w, if check is set,
+// stdioPath is written instead if the schema is not formatted
+func formatStdio(r io.Reader, w io.Writer, check bool) (formatted bool, err error) {
+	input, err := io.ReadAll(r)
+	if err != nil {
+		return false, err
+	}
+
+	// there is no filename for stdin, so the errors only include their message
+	doc, err := parser.ParseDocument(parser.NewParser(string(input)))
+	if err != nil {
+		return false, err
+	}
+
+	var sb strings.Builder
+	doc.Format(&sb)
+
+	formatted = string(input) == sb.String()
+
+	if check {
+		if !formatted {
+			_, err = fmt.Fprintln(w, stdioPath)
+		}
+		return formatted, err
+	}
+
+	// the formatted schema is always written, so it can be used in pipes
+	_, err = io.WriteString(w, sb.String())
+	return true, err
+}
+
+// appendTsEnumsOption appends the option of --ts-enums flag, which is either enum or const
+func appendTsEnumsOption(opts []gen.Option, value string) ([]gen.Option, error) {
+	switch value {
+	case "enum":
+		return opts, nil
+	case "const":
+		return append(opts, gen.WithTsConstEnums()), nil
+	default:
+		return nil, fmt.Errorf("--ts-enums should be either enum or const, got %q", value)
+	}
+}
+
+// minGoVersion is the minor version of the oldest go1.x which the generated code supports,
+// as it uses generics and the standard library's features, e.g. cmp and slices packages
+const minGoVersion = 21
+
+// appendPluginOption appends the option of --plugin flag, the path of the plugin's executable
+func appendPluginOption(opts []gen.Option, value string) ([]gen.Option, error) {
+	if value == "" {
+		return nil, fmt.Errorf("--plugin requires the path of the plugin, e.g. --plugin=./hexe-gen-kotlin")
+	}
+
+	return append(opts, gen.WithPlugin(value)), nil
+}
+
+// appendApiVersionOption appends the option of --api-version flag, e.g. 1.2.0
+func appendApiVersionOption(opts []gen.Option, value string) ([]gen.Option, error) {
+	version, err := ast.ParseVersion(value)
+	if err != nil {
+		return nil, fmt.Errorf("--api-version should be a semantic version, e.g. 1.2.0, got %q", value)
+	}
+
+	return append(opts, gen.WithApiVersion(version)), nil
+}
+
+// appendGoVersionOption appends the option of --go-version flag, e.g. 1.22 or go1.22
+func appendGoVersionOption(opts []gen.Option, value string) ([]gen.Option, error) {
+	version, ok := strings.CutPrefix(strings.TrimPrefix(value, "go"), "1.")
+	if ok {
+		// the patch version doesn't matter, e.g. 1.22.3
+		version, _, _ = strings.Cut(version, ".")
+	}
+
+	minor, err := strconv.Atoi(version)
+	if !ok || err != nil {
+		return nil, fmt.Errorf("--go-version should be a go version, e.g. 1.22, got %q", value)
+	}
+
+	if minor < minGoVersion {
+		return nil, fmt.Errorf("--go-version should be at least 1.%d, got %q", minGoVersion, value)
+	}
+
+	return append(opts, gen.WithGoVersion(minor)), nil
+}
+
+// genCmd generates the output file from all the files matched by the search paths,
+// prof is optional and if provided, it records the duration of each phase, and
+// checks are the optional validations. If the output is stdioPath,
+// optionally followed by the target's extension, the code is written into stdout
+func genCmd(stdout io.Writer, prof *profiler, opts []gen.Option, checks validateChecks, pkg, out string, searchPaths ...string) (err error) {
 	var docs []*ast.Document
 
-	for _, searchPath := range searchPaths {
-		filenames, err := filesFromGlob(searchPath)
+	filenames, err := filesFromGlobs(searchPaths...)
+	if err != nil {
+		return err
+	}
+
+	prof.Phase("glob")
+
+	// the imported files are loaded as well, even if they are not matched by the search paths
+	docs, err = parser.LoadDocuments(filenames...)
+	if err != nil {
+		return err
+	}
+
+	prof.Phase("parse")
+
+	if err = validateWithChecks(checks, docs); err != nil {
+		return err
+	}
+
+	prof.Phase("validate")
+
+	if isStdioOutput(out) {
+		target, err := stdioTarget(out)
 		if err != nil {
 			return err
 		}
 
-		for _, filename := range filenames {
-			doc, err := parser.ParseDocument(parser.NewWithFilenames(filename))
-			if err != nil {
-				return err
-			}
+		err = gen.GenerateTo(stdout, target, pkg, docs, opts...)
+	} else {
+		err = gen.Generate(pkg, out, docs, opts...)
+	}
+	if err != nil {
+		return err
+	}
+
+	prof.Phase("generate")
 
-			docs = append(docs, doc)
+	return nil
+}
+
+// explainCmd writes the resolved description of the name, defined in
+// the files matched by the search paths or their imports, into w
+func explainCmd(w io.Writer, name string, searchPaths ...string) error {
+	filenames, err := filesFromGlobs(searchPaths...)
+	if err != nil {
+		return err
+	}
+
+	docs, err := parser.LoadDocuments(filenames...)
+	if err != nil {
+		return err
+	}
+
+	if err = validateAll(docs); err != nil {
+		return err
+	}
+
+	return gen.Explain(w, name, docs)
+}
+
+// diffCmd writes the changes between the schemas matched by the old and new search paths
+// into w, if strict is set, it returns an error when there is any breaking change
+func diffCmd(w io.Writer, strict bool, oldSearchPath, newSearchPath string) error {
+	oldDocs, err := loadValidatedDocuments(oldSearchPath)
+	if err != nil {
+		return err
+	}
+
+	newDocs, err := loadValidatedDocuments(newSearchPath)
+	if err != nil {
+		return err
+	}
+
+	breaking := 0
+	for _, change := range gen.Diff(oldDocs, newDocs) {
+		if change.Breaking {
+			breaking++
+		}
+		if _, err := fmt.Fprintln(w, change); err != nil {
+			return err
+		}
+	}
+
+	if strict && breaking > 0 {
+		return fmt.Errorf("found %d breaking changes", breaking)
+	}
+
+	return nil
+}
+
+// lintCmd writes the warnings of the schemas matched by the search paths into w,
+// if strict is set, it returns an error when there is any warning
+func lintCmd(w io.Writer, strict bool, searchPaths ...string) error {
+	filenames, err := filesFromGlobs(searchPaths...)
+	if err != nil {
+		return err
+	}
+
+	if len(filenames) == 0 {
+		return fmt.Errorf("no files found for %s", strings.Join(searchPaths, ", "))
+	}
+
+	docs, err := parser.LoadDocuments(filenames...)
+	if err != nil {
+		return err
+	}
+
+	if err = validateAll(docs); err != nil {
+		return err
+	}
+
+	warnings := parser.Lint(docs...)
+	for _, warning := range warnings {
+		if _, err := fmt.Fprintln(w, warning); err != nil {
+			return err
+		}
+	}
+
+	if strict && len(warnings) > 0 {
+		return fmt.Errorf("found %d warnings", len(warnings))
+	}
+
+	return nil
+}
+
+// listCmd writes the inventory of the declarations of the schemas matched by the
+// search paths into w, so it's easy to check what the search paths matched
+func listCmd(w io.Writer, asJSON bool, searchPaths ...string) error {
+	filenames, err := filesFromGlobs(searchPaths...)
+	if err != nil {
+		return err
+	}
+
+	if len(filenames) == 0 {
+		return fmt.Errorf("no files found for %s", strings.Join(searchPaths, ", "))
+	}
+
+	docs, err := parser.LoadDocuments(filenames...)
+	if err != nil {
+		return err
+	}
+
+	if err = validateAll(docs); err != nil {
+		return err
+	}
+
+	return gen.List(w, docs, asJSON)
+}
+
+// loadValidatedDocuments loads and validates the files matched by the search path and their imports
+func loadValidatedDocuments(searchPath string) ([]*ast.Document, error) {
+	filenames, err := filesFromGlob(searchPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(filenames) == 0 {
+		return nil, fmt.Errorf("no files found for %s", searchPath)
+	}
+
+	docs, err := parser.LoadDocuments(filenames...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = validateAll(docs); err != nil {
+		return nil, err
+	}
+
+	return docs, nil
+}
+
+// validateAll validates the docs and returns all the errors joined, so they are printed at once
+func validateAll(docs []*ast.Document) error {
+	return errors.Join(parser.ValidateAll(docs...)...)
+}
+
+// defaultErrorsLock is the path of the errors lock file if --errors-lock has no value
+const defaultErrorsLock = "hexe.errors.lock"
+
+// validateChecks are the optional validations of gen's flags
+type validateChecks struct {
+	errorsLock string // path of the errors lock file, based on --errors-lock
+	noAny      bool   // any type is not allowed, based on --no-any
+}
+
+// validateWithChecks validates the docs, if errorsLock is set, the custom errors' codes
+// are assigned from the lock file and the new ones are written back to it
+func validateWithChecks(checks validateChecks, docs []*ast.Document) error {
+	var lock parser.ErrorsLock
+	if checks.errorsLock != "" {
+		var err error
+		if lock, err = parser.ReadErrorsLock(checks.errorsLock); err != nil {
+			return err
+		}
+
+		if err = lock.Apply(docs...); err != nil {
+			return err
+		}
+	}
+
+	if err := validateAll(docs); err != nil {
+		return err
+	}
+
+	if checks.noAny {
+		if err := parser.ValidateNoAny(docs...); err != nil {
+			return err
 		}
 	}
 
-	if err = parser.Validate(docs...); err != nil {
+	if checks.errorsLock == "" {
+		return nil
+	}
+
+	return lock.Write(checks.errorsLock)
+}
+
+// isStdioOutput reports whether gen's output is stdioPath, optionally
+// followed by the target's extension, e.g. - or -.ts
+func isStdioOutput(out string) bool {
+	ext, ok := strings.CutPrefix(out, stdioPath)
+	return ok && (ext == "" || strings.HasPrefix(ext, "."))
+}
+
+// stdioTarget returns the target of a stdio output, which is Go without an extension
+func stdioTarget(out string) (gen.Target, error) {
+	return gen.TargetFromFilename(cmp.Or(strings.TrimPrefix(out, stdioPath), ".go"))
+}
+
+// genStdioCmd generates the code for the schema read from r into w, it's used when
+// gen has a stdio output and no search paths, e.g. cat *.hexe | hexe gen rpc -.ts
+func genStdioCmd(prof *profiler, opts []gen.Option, checks validateChecks, pkg, out string, r io.Reader, w io.Writer) error {
+	target, err := stdioTarget(out)
+	if err != nil {
+		return err
+	}
+
+	input, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	prof.Phase("read")
+
+	doc, errs := parser.ParseDocumentAll(parser.NewParser(string(input)))
+	if err = errors.Join(errs...); err != nil {
+		return err
+	}
+
+	// there is no file for stdin, so the imports are resolved from the working directory
+	docs, err := parser.LoadDocumentImports("", doc)
+	if err != nil {
 		return err
 	}
 
-	return gen.Generate(pkg, out, docs)
+	prof.Phase("parse")
+
+	if err = validateWithChecks(checks, docs); err != nil {
+		return err
+	}
+
+	prof.Phase("validate")
+
+	if err = gen.GenerateTo(w, target, pkg, docs, opts...); err != nil {
+		return err
+	}
+
+	prof.Phase("generate")
+
+	return nil
 }
 
+// filesFromGlobs returns the files matched by any of the search paths,
+// a file matched by more than one of them is only returned once
+func filesFromGlobs(searchPaths ...string) ([]string, error) {
+	var filenames []string
+	seen := make(map[string]struct{})
+
+	for _, searchPath := range searchPaths {
+		matches, err := filesFromGlob(searchPath)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, match := range matches {
+			if _, ok := seen[match]; ok {
+				continue
+			}
+			seen[match] = struct{}{}
+			filenames = append(filenames, match)
+		}
+	}
+
+	return filenames, nil
+}
+
+// recursiveGlob as the last dir of the search path, e.g. ./schema/**/*.hexe,
+// matches the pattern in the dir and all of its sub dirs
+const recursiveGlob = "**"
+
 // make sure only pattern is used at the end of the search path
-// and only one level of search path is allowed
+// and only one level of search path is allowed, unless the last
+// dir is recursiveGlob
 func filesFromGlob(searchPath string) ([]string, error) {
 	filenames := []string{}
 
@@ -136,6 +741,10 @@ func filesFromGlob(searchPath string) ([]string, error) {
 		dir = "."
 	}
 
+	if base, ok := strings.CutSuffix(filepath.Clean(dir), recursiveGlob); ok && (base == "" || os.IsPathSeparator(base[len(base)-1])) {
+		return filesFromRecursiveGlob(cmp.Or(filepath.Clean(base), "."), pattern, searchPath)
+	}
+
 	if strings.Contains(dir, "*") {
 		return nil, fmt.Errorf("glob pattern should not be used in dir level: %s", searchPath)
 	}
@@ -160,3 +769,36 @@ func filesFromGlob(searchPath string) ([]string, error) {
 
 	return filenames, nil
 }
+
+// filesFromRecursiveGlob returns the files matched by the pattern in the dir and its sub dirs
+func filesFromRecursiveGlob(dir, pattern, searchPath string) ([]string, error) {
+	if strings.Contains(dir, "*") {
+		return nil, fmt.Errorf("glob pattern should not be used in dir level: %s", searchPath)
+	}
+
+	// the pattern is checked before walking, so a bad pattern fails even without any file
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+
+	filenames := []string{}
+
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+
+		if match, _ := filepath.Match(pattern, entry.Name()); match {
+			filenames = append(filenames, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return filenames, nil
+}
@@ -0,0 +1,536 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hexe-dev/hexe/internal/compiler/gen"
+	"github.com/stretchr/testify/assert"
+)
+
+const profileSchema = `
+model User {
+	Id: string
+	Name: string
+}
+
+service HttpUserService {
+	GetById(id: string) => (user: User)
+}
+`
+
+func TestGenCmdProfile(t *testing.T) {
+	dir := t.TempDir()
+
+	err := os.WriteFile(filepath.Join(dir, "schema.hexe"), []byte(profileSchema), os.ModePerm)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	search := filepath.Join(dir, "*.hexe")
+	plainOut := filepath.Join(dir, "plain.go")
+	profiledOut := filepath.Join(dir, "profiled.go")
+	profileDir := filepath.Join(dir, "profile")
+
+	err = genCmd(nil, nil, nil, validateChecks{}, "test", plainOut, search)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var report bytes.Buffer
+	prof, err := newProfiler(&report, profileDir)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	err = genCmd(nil, prof, nil, validateChecks{}, "test", profiledOut, search)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	if !assert.NoError(t, prof.Stop()) {
+		return
+	}
+
+	for _, phase := range []string{"glob", "parse", "validate", "generate", "total", "heap alloc"} {
+		assert.Contains(t, report.String(), phase)
+	}
+
+	assert.FileExists(t, filepath.Join(profileDir, "cpu.pprof"))
+	assert.FileExists(t, filepath.Join(profileDir, "heap.pprof"))
+
+	plain, err := os.ReadFile(plainOut)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	profiled, err := os.ReadFile(profiledOut)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, string(plain), string(profiled))
+}
+
+func TestGenStdioCmd(t *testing.T) {
+	dir := t.TempDir()
+
+	err := os.WriteFile(filepath.Join(dir, "schema.hexe"), []byte(profileSchema), os.ModePerm)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	for _, ext := range []string{".go", ".ts", ".zod.ts"} {
+		fileOut := filepath.Join(dir, "output"+ext)
+
+		err = genCmd(nil, nil, nil, validateChecks{}, "test", fileOut, filepath.Join(dir, "*.hexe"))
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		expected, err := os.ReadFile(fileOut)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		var stdout bytes.Buffer
+		err = genStdioCmd(nil, nil, validateChecks{}, "test", "-"+ext, strings.NewReader(profileSchema), &stdout)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		assert.Equal(t, string(expected), stdout.String())
+	}
+
+	var stdout bytes.Buffer
+	assert.Error(t, genStdioCmd(nil, nil, validateChecks{}, "test", "-.rs", strings.NewReader(profileSchema), &stdout))
+	assert.Error(t, genStdioCmd(nil, nil, validateChecks{}, "test", stdioPath, strings.NewReader("model {"), &stdout))
+}
+
+func TestGenCmdErrorsLock(t *testing.T) {
+	dir := t.TempDir()
+	schema := filepath.Join(dir, "schema.hexe")
+	lock := filepath.Join(dir, defaultErrorsLock)
+	out := filepath.Join(dir, "output.go")
+
+	err := os.WriteFile(schema, []byte("error ErrNotFound { Msg = \"not found\" }\n"), os.ModePerm)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	if !assert.NoError(t, genCmd(nil, nil, nil, validateChecks{errorsLock: lock}, "test", out, schema)) {
+		return
+	}
+
+	// ErrBadRequest is sorted first, but ErrNotFound keeps its code
+	err = os.WriteFile(schema, []byte("error ErrNotFound { Msg = \"not found\" }\nerror ErrBadRequest { Msg = \"bad request\" }\n"), os.ModePerm)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	if !assert.NoError(t, genCmd(nil, nil, nil, validateChecks{errorsLock: lock}, "test", out, schema)) {
+		return
+	}
+
+	output, err := os.ReadFile(out)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Contains(t, string(output), "var ErrNotFound = newError(1,")
+	assert.Contains(t, string(output), "var ErrBadRequest = newError(2,")
+
+	content, err := os.ReadFile(lock)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "{\n  \"ErrBadRequest\": 2,\n  \"ErrNotFound\": 1\n}\n", string(content))
+	}
+}
+
+func TestGenCmdTsEnums(t *testing.T) {
+	dir := t.TempDir()
+
+	err := os.WriteFile(filepath.Join(dir, "schema.hexe"), []byte("enum Level {\n    _\n    Low\n    High\n}\n"), os.ModePerm)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	opts, err := appendTsEnumsOption(nil, "const")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	out := filepath.Join(dir, "output.ts")
+	if !assert.NoError(t, genCmd(nil, nil, opts, validateChecks{}, "test", out, filepath.Join(dir, "*.hexe"))) {
+		return
+	}
+
+	output, err := os.ReadFile(out)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Contains(t, string(output), "export const Level = {\n    Low: \"low\",\n    High: \"high\",\n} as const;")
+
+	opts, err = appendTsEnumsOption(nil, "enum")
+	assert.NoError(t, err)
+	assert.Empty(t, opts)
+
+	_, err = appendTsEnumsOption(nil, "union")
+	assert.EqualError(t, err, `--ts-enums should be either enum or const, got "union"`)
+}
+
+func TestExplainCmd(t *testing.T) {
+	dir := t.TempDir()
+
+	err := os.WriteFile(filepath.Join(dir, "schema.hexe"), []byte(profileSchema), os.ModePerm)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var out bytes.Buffer
+	err = explainCmd(&out, "User", filepath.Join(dir, "*.hexe"))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Contains(t, out.String(), "model User\n")
+	assert.Contains(t, out.String(), "Name:  string  go: string  ts: string\n")
+	assert.Contains(t, out.String(), "service HttpUserService method GetById return user\n")
+
+	assert.Error(t, explainCmd(&out, "Unknown", filepath.Join(dir, "*.hexe")))
+}
+
+func TestDiffCmd(t *testing.T) {
+	dir := t.TempDir()
+
+	oldPath := filepath.Join(dir, "old.hexe")
+	newPath := filepath.Join(dir, "new.hexe")
+
+	err := os.WriteFile(oldPath, []byte(profileSchema), os.ModePerm)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	err = os.WriteFile(newPath, []byte(strings.Replace(profileSchema, "Name: string", "Email?: string", 1)), os.ModePerm)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var out bytes.Buffer
+	if !assert.NoError(t, diffCmd(&out, false, oldPath, newPath)) {
+		return
+	}
+
+	assert.Equal(t, "breaking: model User field Name is removed\ncompatible: model User field Email is added\n", out.String())
+
+	out.Reset()
+	assert.EqualError(t, diffCmd(&out, true, oldPath, newPath), "found 1 breaking changes")
+
+	out.Reset()
+	assert.NoError(t, diffCmd(&out, true, oldPath, oldPath))
+	assert.Empty(t, out.String())
+}
+
+func TestLintCmd(t *testing.T) {
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "status.hexe")
+	err := os.WriteFile(path, []byte("enum Status {\n    A = 0\n    C = 5\n}\n"), os.ModePerm)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var out bytes.Buffer
+	if !assert.NoError(t, lintCmd(&out, false, path)) {
+		return
+	}
+
+	assert.Contains(t, out.String(), "Warning: enum Status's values are not contiguous, 1 is missing between 0 and 5")
+
+	out.Reset()
+	assert.EqualError(t, lintCmd(&out, true, path), "found 1 warnings")
+
+	err = os.WriteFile(path, []byte("enum Status {\n    A\n    B\n}\n"), os.ModePerm)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	out.Reset()
+	assert.NoError(t, lintCmd(&out, true, filepath.Join(dir, "*.hexe")))
+	assert.Empty(t, out.String())
+}
+
+func TestGenCmdGoVersion(t *testing.T) {
+	dir := t.TempDir()
+
+	err := os.WriteFile(filepath.Join(dir, "schema.hexe"), []byte("model User {\n    Name?: string\n}\n"), os.ModePerm)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	opts, err := appendGoVersionOption(nil, "1.22")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	out := filepath.Join(dir, "output.go")
+	if !assert.NoError(t, genCmd(nil, nil, opts, validateChecks{}, "test", out, filepath.Join(dir, "*.hexe"))) {
+		return
+	}
+
+	output, err := os.ReadFile(out)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Contains(t, string(output), "`json:\"name,omitempty\"`")
+
+	for _, version := range []string{"go1.24", "1.25.1"} {
+		_, err = appendGoVersionOption(nil, version)
+		assert.NoError(t, err)
+	}
+
+	_, err = appendGoVersionOption(nil, "1.20")
+	assert.EqualError(t, err, `--go-version should be at least 1.21, got "1.20"`)
+
+	_, err = appendGoVersionOption(nil, "2")
+	assert.EqualError(t, err, `--go-version should be a go version, e.g. 1.22, got "2"`)
+}
+
+func TestGenCmdApiVersion(t *testing.T) {
+	dir := t.TempDir()
+
+	err := os.WriteFile(filepath.Join(dir, "schema.hexe"), []byte("model User {\n    Id: string\n    Name: string { Until = \"2.0.0\" }\n}\n"), os.ModePerm)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	opts, err := appendApiVersionOption(nil, "2.1.0")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	out := filepath.Join(dir, "output.ts")
+	if !assert.NoError(t, genCmd(nil, nil, opts, validateChecks{}, "test", out, filepath.Join(dir, "*.hexe"))) {
+		return
+	}
+
+	output, err := os.ReadFile(out)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Contains(t, string(output), "\tid: string;")
+	assert.NotContains(t, string(output), "\tname: string;")
+
+	_, err = appendApiVersionOption(nil, "2.1")
+	assert.EqualError(t, err, `--api-version should be a semantic version, e.g. 1.2.0, got "2.1"`)
+}
+
+func TestFormatCmdCheck(t *testing.T) {
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "status.hexe")
+	unformatted := "enum Status {\nA\n    B\n}\n"
+	err := os.WriteFile(path, []byte(unformatted), os.ModePerm)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var out bytes.Buffer
+	assert.EqualError(t, formatCmd(nil, &out, true, path), "found 1 unformatted files")
+	assert.Equal(t, path+"\n", out.String())
+
+	// the file is not written in check mode
+	content, err := os.ReadFile(path)
+	if assert.NoError(t, err) {
+		assert.Equal(t, unformatted, string(content))
+	}
+
+	out.Reset()
+	if !assert.NoError(t, formatCmd(nil, &out, false, path)) {
+		return
+	}
+	assert.Empty(t, out.String())
+
+	out.Reset()
+	assert.NoError(t, formatCmd(nil, &out, true, filepath.Join(dir, "*.hexe")))
+	assert.Empty(t, out.String())
+}
+
+func TestFormatCmdStdio(t *testing.T) {
+	const unformatted = "enum Status {\nA\n    B\n}\n"
+
+	var out bytes.Buffer
+	if !assert.NoError(t, formatCmd(strings.NewReader(unformatted), &out, false, "-")) {
+		return
+	}
+
+	formatted := out.String()
+	assert.NotEqual(t, unformatted, formatted)
+	assert.Contains(t, formatted, "enum Status {")
+
+	out.Reset()
+	assert.EqualError(t, formatCmd(strings.NewReader(unformatted), &out, true, "-"), "found 1 unformatted files")
+	assert.Equal(t, "-\n", out.String())
+
+	out.Reset()
+	assert.NoError(t, formatCmd(strings.NewReader(formatted), &out, true, "-"))
+	assert.Empty(t, out.String())
+
+	out.Reset()
+	assert.Error(t, formatCmd(strings.NewReader("enum Status {"), &out, false, "-"))
+	assert.Empty(t, out.String())
+}
+
+func TestGenCmdStdout(t *testing.T) {
+	dir := t.TempDir()
+
+	err := os.WriteFile(filepath.Join(dir, "schema.hexe"), []byte("model User {\n    Name: string\n}\n"), os.ModePerm)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var out bytes.Buffer
+	if !assert.NoError(t, genCmd(&out, nil, nil, validateChecks{}, "test", "-", filepath.Join(dir, "*.hexe"))) {
+		return
+	}
+	assert.Contains(t, out.String(), "package test")
+	assert.Contains(t, out.String(), "type User struct {")
+
+	out.Reset()
+	if !assert.NoError(t, genCmd(&out, nil, nil, validateChecks{}, "test", "-.ts", filepath.Join(dir, "*.hexe"))) {
+		return
+	}
+	assert.Contains(t, out.String(), "export interface User {")
+
+	out.Reset()
+	assert.EqualError(t, genCmd(&out, nil, nil, validateChecks{}, "test", "-.txt", filepath.Join(dir, "*.hexe")), "unknown output file type: .txt")
+	assert.Empty(t, out.String())
+
+	// no file is created for the stdout
+	_, err = os.Stat("-")
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestFilesFromGlobRecursive(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, name := range []string{
+		"root.hexe",
+		"readme.md",
+		"users/user.hexe",
+		"users/admin/admin.hexe",
+		"orders/order.hexe",
+		"orders/order.json",
+	} {
+		path := filepath.Join(dir, name)
+		if !assert.NoError(t, os.MkdirAll(filepath.Dir(path), os.ModePerm)) {
+			return
+		}
+		if !assert.NoError(t, os.WriteFile(path, []byte("const A = 1\n"), os.ModePerm)) {
+			return
+		}
+	}
+
+	filenames, err := filesFromGlob(filepath.Join(dir, "**", "*.hexe"))
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{
+			filepath.Join(dir, "orders", "order.hexe"),
+			filepath.Join(dir, "root.hexe"),
+			filepath.Join(dir, "users", "admin", "admin.hexe"),
+			filepath.Join(dir, "users", "user.hexe"),
+		}, filenames)
+	}
+
+	filenames, err = filesFromGlob(filepath.Join(dir, "users", "**", "*.hexe"))
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{
+			filepath.Join(dir, "users", "admin", "admin.hexe"),
+			filepath.Join(dir, "users", "user.hexe"),
+		}, filenames)
+	}
+
+	// without ** only one level is matched
+	filenames, err = filesFromGlob(filepath.Join(dir, "users", "*.hexe"))
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{filepath.Join(dir, "users", "user.hexe")}, filenames)
+	}
+
+	// the files matched by both patterns are returned once
+	filenames, err = filesFromGlobs(filepath.Join(dir, "users", "*.hexe"), filepath.Join(dir, "users", "**", "*.hexe"))
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{
+			filepath.Join(dir, "users", "user.hexe"),
+			filepath.Join(dir, "users", "admin", "admin.hexe"),
+		}, filenames)
+	}
+
+	_, err = filesFromGlob(filepath.Join(dir, "*", "*.hexe"))
+	assert.ErrorContains(t, err, "glob pattern should not be used in dir level")
+
+	_, err = filesFromGlob(filepath.Join(dir, "**", "admin", "*.hexe"))
+	assert.ErrorContains(t, err, "glob pattern should not be used in dir level")
+
+	_, err = filesFromGlob(filepath.Join(dir, "**", "[.hexe"))
+	assert.ErrorIs(t, err, filepath.ErrBadPattern)
+}
+
+func TestGenCmdNoAny(t *testing.T) {
+	dir := t.TempDir()
+
+	err := os.WriteFile(filepath.Join(dir, "schema.hexe"), []byte("model User {\n    Meta: any\n}\n"), os.ModePerm)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	out := filepath.Join(dir, "schema.gen.go")
+	assert.NoError(t, genCmd(nil, nil, nil, validateChecks{}, "test", out, filepath.Join(dir, "*.hexe")))
+
+	err = genCmd(nil, nil, nil, validateChecks{noAny: true}, "test", out, filepath.Join(dir, "*.hexe"))
+	assert.ErrorContains(t, err, "any type is not allowed")
+}
+
+func TestListCmd(t *testing.T) {
+	dir := t.TempDir()
+
+	files := map[string]string{
+		"user.hexe":  "const MaxUsers = 10\n\nenum Role {\n    Admin\n    Member\n}\n\nmodel User {\n    Role: Role\n}\n",
+		"order.hexe": "model Order {\n    Id: string\n}\n\nservice HttpOrderService {\n    Get(id: string) => (order: Order)\n    List() => (orders: []Order)\n}\n\nerror ErrOrderNotFound { Msg = \"order not found\" }\n",
+	}
+	for name, content := range files {
+		if !assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), os.ModePerm)) {
+			return
+		}
+	}
+
+	var out bytes.Buffer
+	if !assert.NoError(t, listCmd(&out, false, filepath.Join(dir, "*.hexe"))) {
+		return
+	}
+
+	assert.Equal(t, ""+
+		"consts    1  MaxUsers\n"+
+		"enums     1  Role\n"+
+		"models    2  Order, User\n"+
+		"services  1  HttpOrderService (http, 2 methods)\n"+
+		"errors    1  ErrOrderNotFound\n", out.String())
+
+	out.Reset()
+	if !assert.NoError(t, listCmd(&out, true, filepath.Join(dir, "*.hexe"))) {
+		return
+	}
+
+	var inv gen.Inventory
+	if assert.NoError(t, json.Unmarshal(out.Bytes(), &inv)) {
+		assert.Equal(t, []string{"MaxUsers"}, inv.Consts)
+		assert.Equal(t, []string{"Order", "User"}, inv.Models)
+		assert.Equal(t, []gen.InventoryService{{Name: "HttpOrderService", Type: "http", Methods: 2}}, inv.Services)
+	}
+
+	assert.EqualError(t, listCmd(&out, false, filepath.Join(dir, "*.missing")), "no files found for "+filepath.Join(dir, "*.missing"))
+}
@@ -0,0 +1,383 @@
+// Package msgpack converts json documents to MessagePack and back. The generated
+// code keeps using encoding/json for the models, so the custom json encodings, e.g.
+// enums and sets, stay the same while the payload on the wire is MessagePack.
+package msgpack
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+)
+
+// ContentType is the http content type of MessagePack payloads
+const ContentType = "application/msgpack"
+
+// Codec converts the json messages of the generated code to MessagePack
+type Codec struct{}
+
+func (Codec) ContentType() string {
+	return ContentType
+}
+
+func (Codec) FromJSON(data []byte) ([]byte, error) {
+	return FromJSON(data)
+}
+
+func (Codec) ToJSON(data []byte) ([]byte, error) {
+	return ToJSON(data)
+}
+
+// FromJSON converts a json document to MessagePack, objects keep their keys' order
+// and numbers are encoded as integers if they fit, otherwise as float64
+func FromJSON(data []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	out, err := encodeValue(dec, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return nil, errors.New("msgpack: unexpected data after json value")
+	}
+
+	return out, nil
+}
+
+func encodeValue(dec *json.Decoder, out []byte) ([]byte, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch v := tok.(type) {
+	case nil:
+		return append(out, 0xc0), nil
+	case bool:
+		if v {
+			return append(out, 0xc3), nil
+		}
+		return append(out, 0xc2), nil
+	case json.Number:
+		return encodeNumber(out, v)
+	case string:
+		return encodeString(out, v), nil
+	case json.Delim:
+		var items []byte
+		count := 0
+
+		for dec.More() {
+			if v == '{' {
+				key, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				items = encodeString(items, key.(string))
+			}
+
+			items, err = encodeValue(dec, items)
+			if err != nil {
+				return nil, err
+			}
+			count++
+		}
+
+		// consume the closing delimiter
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+
+		if v == '{' {
+			out = appendHeader(out, count, 0x80, 0xde, 0xdf)
+		} else {
+			out = appendHeader(out, count, 0x90, 0xdc, 0xdd)
+		}
+
+		return append(out, items...), nil
+	default:
+		return nil, fmt.Errorf("msgpack: unexpected json token %v", tok)
+	}
+}
+
+func encodeNumber(out []byte, n json.Number) ([]byte, error) {
+	if i, err := strconv.ParseInt(n.String(), 10, 64); err == nil {
+		return encodeInt(out, i), nil
+	}
+
+	if u, err := strconv.ParseUint(n.String(), 10, 64); err == nil {
+		return binary.BigEndian.AppendUint64(append(out, 0xcf), u), nil
+	}
+
+	f, err := n.Float64()
+	if err != nil {
+		return nil, err
+	}
+
+	return binary.BigEndian.AppendUint64(append(out, 0xcb), math.Float64bits(f)), nil
+}
+
+func encodeInt(out []byte, i int64) []byte {
+	switch {
+	case i >= 0 && i <= math.MaxInt8:
+		return append(out, byte(i))
+	case i < 0 && i >= -32:
+		return append(out, byte(int8(i)))
+	case i >= 0 && i <= math.MaxUint8:
+		return append(out, 0xcc, byte(i))
+	case i >= 0 && i <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(out, 0xcd), uint16(i))
+	case i >= 0 && i <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(out, 0xce), uint32(i))
+	case i >= 0:
+		return binary.BigEndian.AppendUint64(append(out, 0xcf), uint64(i))
+	case i >= math.MinInt8:
+		return append(out, 0xd0, byte(int8(i)))
+	case i >= math.MinInt16:
+		return binary.BigEndian.AppendUint16(append(out, 0xd1), uint16(int16(i)))
+	case i >= math.MinInt32:
+		return binary.BigEndian.AppendUint32(append(out, 0xd2), uint32(int32(i)))
+	default:
+		return binary.BigEndian.AppendUint64(append(out, 0xd3), uint64(i))
+	}
+}
+
+func encodeString(out []byte, s string) []byte {
+	switch n := len(s); {
+	case n < 32:
+		out = append(out, 0xa0|byte(n))
+	case n <= math.MaxUint8:
+		out = append(out, 0xd9, byte(n))
+	case n <= math.MaxUint16:
+		out = binary.BigEndian.AppendUint16(append(out, 0xda), uint16(n))
+	default:
+		out = binary.BigEndian.AppendUint32(append(out, 0xdb), uint32(n))
+	}
+	return append(out, s...)
+}
+
+// appendHeader writes the header of an array or a map, based on the given fix, 16 and 32 formats
+func appendHeader(out []byte, n int, fix, format16, format32 byte) []byte {
+	switch {
+	case n < 16:
+		return append(out, fix|byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(out, format16), uint16(n))
+	default:
+		return binary.BigEndian.AppendUint32(append(out, format32), uint32(n))
+	}
+}
+
+// ToJSON converts a MessagePack document to json, binary values are encoded
+// as base64 strings, the same as encoding/json does for []byte
+func ToJSON(data []byte) ([]byte, error) {
+	d := &decoder{data: data}
+
+	var out bytes.Buffer
+
+	if err := d.decodeValue(&out); err != nil {
+		return nil, err
+	}
+
+	if d.pos != len(d.data) {
+		return nil, errors.New("msgpack: unexpected data after value")
+	}
+
+	return out.Bytes(), nil
+}
+
+type decoder struct {
+	data []byte
+	pos  int
+}
+
+func (d *decoder) read(n int) ([]byte, error) {
+	if n < 0 || len(d.data)-d.pos < n {
+		return nil, io.ErrUnexpectedEOF
+	}
+	b := d.data[d.pos : d.pos+n]
+	d.pos += n
+	return b, nil
+}
+
+// readUint reads a big endian unsigned integer of the given size in bytes
+func (d *decoder) readUint(size int) (uint64, error) {
+	b, err := d.read(size)
+	if err != nil {
+		return 0, err
+	}
+
+	switch size {
+	case 1:
+		return uint64(b[0]), nil
+	case 2:
+		return uint64(binary.BigEndian.Uint16(b)), nil
+	case 4:
+		return uint64(binary.BigEndian.Uint32(b)), nil
+	default:
+		return binary.BigEndian.Uint64(b), nil
+	}
+}
+
+func (d *decoder) decodeValue(out *bytes.Buffer) error {
+	b, err := d.read(1)
+	if err != nil {
+		return err
+	}
+
+	switch c := b[0]; {
+	case c <= 0x7f:
+		out.WriteString(strconv.FormatUint(uint64(c), 10))
+	case c >= 0xe0:
+		out.WriteString(strconv.FormatInt(int64(int8(c)), 10))
+	case c >= 0x80 && c <= 0x8f:
+		return d.decodeMap(out, int(c&0x0f))
+	case c >= 0x90 && c <= 0x9f:
+		return d.decodeArray(out, int(c&0x0f))
+	case c >= 0xa0 && c <= 0xbf:
+		return d.decodeString(out, int(c&0x1f))
+	case c == 0xc0:
+		out.WriteString("null")
+	case c == 0xc2:
+		out.WriteString("false")
+	case c == 0xc3:
+		out.WriteString("true")
+	case c >= 0xc4 && c <= 0xc6:
+		n, err := d.readUint(1 << (c - 0xc4))
+		if err != nil {
+			return err
+		}
+		data, err := d.read(int(n))
+		if err != nil {
+			return err
+		}
+		out.WriteByte('"')
+		out.WriteString(base64.StdEncoding.EncodeToString(data))
+		out.WriteByte('"')
+	case c == 0xca:
+		v, err := d.readUint(4)
+		if err != nil {
+			return err
+		}
+		return writeFloat(out, float64(math.Float32frombits(uint32(v))))
+	case c == 0xcb:
+		v, err := d.readUint(8)
+		if err != nil {
+			return err
+		}
+		return writeFloat(out, math.Float64frombits(v))
+	case c >= 0xcc && c <= 0xcf:
+		v, err := d.readUint(1 << (c - 0xcc))
+		if err != nil {
+			return err
+		}
+		out.WriteString(strconv.FormatUint(v, 10))
+	case c >= 0xd0 && c <= 0xd3:
+		size := 1 << (c - 0xd0)
+		v, err := d.readUint(size)
+		if err != nil {
+			return err
+		}
+		// sign extend the value based on its size
+		shift := 64 - 8*size
+		out.WriteString(strconv.FormatInt(int64(v<<shift)>>shift, 10))
+	case c >= 0xd9 && c <= 0xdb:
+		n, err := d.readUint(1 << (c - 0xd9))
+		if err != nil {
+			return err
+		}
+		return d.decodeString(out, int(n))
+	case c == 0xdc || c == 0xdd:
+		n, err := d.readUint(2 << (c - 0xdc))
+		if err != nil {
+			return err
+		}
+		return d.decodeArray(out, int(n))
+	case c == 0xde || c == 0xdf:
+		n, err := d.readUint(2 << (c - 0xde))
+		if err != nil {
+			return err
+		}
+		return d.decodeMap(out, int(n))
+	default:
+		return fmt.Errorf("msgpack: unsupported format 0x%x", c)
+	}
+
+	return nil
+}
+
+func (d *decoder) decodeString(out *bytes.Buffer, n int) error {
+	s, err := d.read(n)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(string(s))
+	if err != nil {
+		return err
+	}
+
+	out.Write(data)
+	return nil
+}
+
+func (d *decoder) decodeArray(out *bytes.Buffer, n int) error {
+	out.WriteByte('[')
+	for i := range n {
+		if i > 0 {
+			out.WriteByte(',')
+		}
+		if err := d.decodeValue(out); err != nil {
+			return err
+		}
+	}
+	out.WriteByte(']')
+	return nil
+}
+
+// decodeMap writes the map as a json object, json only allows string keys,
+// so the other keys, e.g. integers, are converted to strings
+func (d *decoder) decodeMap(out *bytes.Buffer, n int) error {
+	out.WriteByte('{')
+	for i := range n {
+		if i > 0 {
+			out.WriteByte(',')
+		}
+
+		var key bytes.Buffer
+		if err := d.decodeValue(&key); err != nil {
+			return err
+		}
+
+		if key.Len() > 0 && key.Bytes()[0] == '"' {
+			out.Write(key.Bytes())
+		} else {
+			data, err := json.Marshal(key.String())
+			if err != nil {
+				return err
+			}
+			out.Write(data)
+		}
+
+		out.WriteByte(':')
+		if err := d.decodeValue(out); err != nil {
+			return err
+		}
+	}
+	out.WriteByte('}')
+	return nil
+}
+
+func writeFloat(out *bytes.Buffer, f float64) error {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return fmt.Errorf("msgpack: unsupported float value %v", f)
+	}
+	out.WriteString(strconv.FormatFloat(f, 'g', -1, 64))
+	return nil
+}
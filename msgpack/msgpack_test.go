@@ -0,0 +1,118 @@
+package msgpack_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/hexe-dev/hexe/msgpack"
+)
+
+func TestFromJSON(t *testing.T) {
+	testCases := []struct {
+		json     string
+		expected string // hex encoded MessagePack
+	}{
+		{json: `null`, expected: "c0"},
+		{json: `true`, expected: "c3"},
+		{json: `false`, expected: "c2"},
+		{json: `1`, expected: "01"},
+		{json: `-1`, expected: "ff"},
+		{json: `200`, expected: "ccc8"},
+		{json: `-200`, expected: "d1ff38"},
+		{json: `70000`, expected: "ce00011170"},
+		{json: `18446744073709551615`, expected: "cfffffffffffffffff"},
+		{json: `1.5`, expected: "cb3ff8000000000000"},
+		{json: `"a"`, expected: "a161"},
+		{json: `[1,"a"]`, expected: "9201a161"},
+		{json: `{"b":1,"a":[]}`, expected: "82a16201a16190"},
+	}
+
+	for _, tc := range testCases {
+		data, err := msgpack.FromJSON([]byte(tc.json))
+		if err != nil {
+			t.Fatalf("%s: %v", tc.json, err)
+		}
+
+		if got := hex.EncodeToString(data); got != tc.expected {
+			t.Errorf("%s: expected %s, got %s", tc.json, tc.expected, got)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	long := strings.Repeat("x", 70000)
+
+	testCases := []string{
+		`{"name":"hexe","age":30,"score":-2.25,"tags":["a","b"],"meta":null,"ok":true}`,
+		`[` + strings.Repeat(`1,`, 20) + `2]`,
+		`"` + long + `"`,
+		`-9223372036854775808`,
+		`{"nested":{"deep":{"value":[{},[]]}}}`,
+	}
+
+	for _, tc := range testCases {
+		data, err := msgpack.FromJSON([]byte(tc))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		result, err := msgpack.ToJSON(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(result, []byte(tc)) {
+			t.Errorf("expected %.100s, got %.100s", tc, result)
+		}
+	}
+}
+
+func TestToJSON(t *testing.T) {
+	testCases := []struct {
+		msgpack  string // hex encoded MessagePack
+		expected string
+	}{
+		// bin8 is encoded as a base64 string
+		{msgpack: "c403010203", expected: `"AQID"`},
+		// float32
+		{msgpack: "ca3fc00000", expected: `1.5`},
+		// int8
+		{msgpack: "d080", expected: `-128`},
+		// map with integer key
+		{msgpack: "8101a161", expected: `{"1":"a"}`},
+	}
+
+	for _, tc := range testCases {
+		data, _ := hex.DecodeString(tc.msgpack)
+
+		result, err := msgpack.ToJSON(data)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.msgpack, err)
+		}
+
+		if string(result) != tc.expected {
+			t.Errorf("%s: expected %s, got %s", tc.msgpack, tc.expected, result)
+		}
+	}
+}
+
+func TestToJSONErrors(t *testing.T) {
+	testCases := []string{
+		"",       // empty
+		"92",     // array without items
+		"a261",   // short string
+		"c1",     // never used
+		"c0c0",   // trailing data
+		"d40100", // ext types are not supported
+	}
+
+	for _, tc := range testCases {
+		data, _ := hex.DecodeString(tc)
+
+		if _, err := msgpack.ToJSON(data); err == nil {
+			t.Errorf("%s: expected an error", tc)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"text/tabwriter"
+	"time"
+)
+
+// profiler records the duration of each phase of the gen command and
+// optionally writes cpu and heap pprof profiles into a directory.
+// A nil profiler is valid and does nothing, so the gen command can call
+// it unconditionally.
+type profiler struct {
+	w      io.Writer
+	dir    string
+	cpu    *os.File
+	start  time.Time
+	last   time.Time
+	phases []profilePhase
+}
+
+type profilePhase struct {
+	name     string
+	duration time.Duration
+}
+
+// newProfiler creates a profiler which writes the report to w. If dir is not
+// empty, cpu.pprof and heap.pprof are written into it as well
+func newProfiler(w io.Writer, dir string) (*profiler, error) {
+	p := &profiler{
+		w:   w,
+		dir: dir,
+	}
+
+	if dir != "" {
+		if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+			return nil, err
+		}
+
+		cpu, err := os.Create(filepath.Join(dir, "cpu.pprof"))
+		if err != nil {
+			return nil, err
+		}
+
+		if err = pprof.StartCPUProfile(cpu); err != nil {
+			cpu.Close()
+			return nil, err
+		}
+
+		p.cpu = cpu
+	}
+
+	p.start = time.Now()
+	p.last = p.start
+
+	return p, nil
+}
+
+// Phase marks the end of the named phase, which started at the end of the
+// previous phase
+func (p *profiler) Phase(name string) {
+	if p == nil {
+		return
+	}
+
+	now := time.Now()
+	p.phases = append(p.phases, profilePhase{name: name, duration: now.Sub(p.last)})
+	p.last = now
+}
+
+// Stop stops the cpu profile, writes the heap profile and prints the
+// timings and memory stats report
+func (p *profiler) Stop() error {
+	if p == nil {
+		return nil
+	}
+
+	total := time.Since(p.start)
+
+	if p.cpu != nil {
+		pprof.StopCPUProfile()
+		if err := p.cpu.Close(); err != nil {
+			return err
+		}
+	}
+
+	if p.dir != "" {
+		heap, err := os.Create(filepath.Join(p.dir, "heap.pprof"))
+		if err != nil {
+			return err
+		}
+		defer heap.Close()
+
+		runtime.GC()
+		if err = pprof.WriteHeapProfile(heap); err != nil {
+			return err
+		}
+	}
+
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "phase\tduration")
+	for _, phase := range p.phases {
+		fmt.Fprintf(tw, "%s\t%s\n", phase.name, phase.duration)
+	}
+	fmt.Fprintf(tw, "total\t%s\n", total)
+	fmt.Fprintln(tw)
+	fmt.Fprintf(tw, "heap alloc\t%d bytes\n", mem.HeapAlloc)
+	fmt.Fprintf(tw, "total alloc\t%d bytes\n", mem.TotalAlloc)
+	fmt.Fprintf(tw, "sys\t%d bytes\n", mem.Sys)
+	fmt.Fprintf(tw, "num gc\t%d\n", mem.NumGC)
+	if p.dir != "" {
+		fmt.Fprintf(tw, "profiles\t%s\n", p.dir)
+	}
+
+	return tw.Flush()
+}
@@ -0,0 +1,35 @@
+package sse
+
+import (
+	"io"
+	"sync/atomic"
+)
+
+// Metrics is notified about the lifecycle of the streams, e.g. to record them as
+// OpenTelemetry metrics. The methods are called synchronously by the pusher and
+// the receiver, so they should not block
+type Metrics interface {
+	// Connect is called once a pusher is created or a receiver is connected
+	Connect()
+	// Disconnect is called once a pusher is closed or a receiver's connection is lost
+	Disconnect()
+	// Push is called after msg, including the pings, is written with n bytes
+	Push(msg *Message, n int64)
+	// Receive is called after msg is received, n is the bytes read since the previous one
+	Receive(msg *Message, n int64)
+	// Reconnect is called before a receiver connects again, attempt starts from 1
+	Reconnect(attempt int)
+}
+
+// countingReader counts the bytes read from r, which is read
+// by the parser's goroutine while the receiver reports them
+type countingReader struct {
+	r io.Reader
+	n atomic.Int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n.Add(int64(n))
+	return n, err
+}
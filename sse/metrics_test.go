@@ -0,0 +1,128 @@
+package sse
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recordedMetrics struct {
+	mu         sync.Mutex
+	connects   int
+	disconnect int
+	pushes     []string
+	receives   []string
+	bytes      int64
+	reconnects []int
+}
+
+func (m *recordedMetrics) Connect() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.connects++
+}
+
+func (m *recordedMetrics) Disconnect() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.disconnect++
+}
+
+func (m *recordedMetrics) Push(msg *Message, n int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pushes = append(m.pushes, msg.Data)
+	m.bytes += n
+}
+
+func (m *recordedMetrics) Receive(msg *Message, n int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.receives = append(m.receives, msg.Data)
+	m.bytes += n
+}
+
+func (m *recordedMetrics) Reconnect(attempt int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.reconnects = append(m.reconnects, attempt)
+}
+
+func TestMetrics(t *testing.T) {
+	var pushed recordedMetrics
+	var requests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pusher, err := NewHttpPusher(w, 0, WithPushMetrics(&pushed))
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer pusher.Close()
+
+		// the first connection ends after two messages, so the receiver reconnects
+		request := int(requests.Add(1))
+		for i := 1; i <= 3-request; i++ {
+			id := strconv.Itoa(request*10 + i)
+			if err := pusher.Push(NewMessage(id, "data", "msg "+id)); err != nil {
+				return
+			}
+		}
+	}))
+	defer server.Close()
+
+	var received recordedMetrics
+
+	receiver, err := NewHttpReceiver(server.URL, WithReceiveMetrics(&received), WithConnectionInitialDelay(time.Millisecond))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	for range 3 {
+		_, err := receiver.Receive(ctx)
+		if !assert.NoError(t, err) {
+			return
+		}
+	}
+
+	// the second pusher may be closed after its message is received
+	assert.Eventually(t, func() bool {
+		pushed.mu.Lock()
+		defer pushed.mu.Unlock()
+		return pushed.disconnect == 2
+	}, time.Second, time.Millisecond)
+
+	pushed.mu.Lock()
+	assert.Equal(t, 2, pushed.connects)
+	assert.Equal(t, []string{"msg 11", "msg 12", "msg 21"}, pushed.pushes)
+	assert.Equal(t, int64(len("id: 11\nevent: data\ndata: msg 11\n\n")*3), pushed.bytes)
+	pushed.mu.Unlock()
+
+	received.mu.Lock()
+	assert.Equal(t, 2, received.connects)
+	assert.Equal(t, 1, received.disconnect)
+	assert.Equal(t, []string{"msg 11", "msg 12", "msg 21"}, received.receives)
+	assert.Equal(t, []int{1}, received.reconnects)
+	assert.Positive(t, received.bytes)
+	received.mu.Unlock()
+}
+
+func TestMetricsNil(t *testing.T) {
+	pusher, err := NewPusher(io.Discard, 0, WithPushMetrics(nil))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.NoError(t, pusher.Push(NewMessage("1", "data", "hello")))
+	assert.NoError(t, pusher.Close())
+}
@@ -1,8 +1,12 @@
 package sse
 
 import (
+	"encoding/json"
+	"errors"
 	"io"
+	"strconv"
 	"sync"
+	"time"
 )
 
 // Pool for reusing byte slices to reduce memory allocations
@@ -13,6 +17,9 @@ var bufferPool = sync.Pool{
 	},
 }
 
+// ErrEmptyData is returned by Message.JSON when the message has no data to unmarshal
+var ErrEmptyData = errors.New("sse: message has no data")
+
 // Message pool to reuse Message objects and reduce GC pressure
 var messagePool = sync.Pool{
 	New: func() interface{} {
@@ -49,6 +56,12 @@ type Message struct {
 	Id    string
 	Event string
 	Data  string
+	// Retry is the reconnection time advised by the server using the retry field,
+	// zero means the server did not send one
+	Retry time.Duration
+	// Err is only set on the last message sent by ParseWithOptions
+	// when parsing fails, the other fields are empty in that case
+	Err error
 
 	// private for keep track of Reader state
 	readerRemaining int
@@ -64,6 +77,8 @@ func (m *Message) Reset() {
 	m.Id = ""
 	m.Event = ""
 	m.Data = ""
+	m.Retry = 0
+	m.Err = nil
 	m.readerRemaining = 0
 	if m.buffer != nil {
 		putBuffer(m.buffer[:0])
@@ -226,6 +241,27 @@ func (m *Message) Write(b []byte) (int, error) {
 	return len(b), nil
 }
 
+// parseRetry parses the value of retry field which should only contain
+// ascii digits representing milliseconds, any other value is ignored per spec
+func parseRetry(value []byte) (time.Duration, bool) {
+	if len(value) == 0 {
+		return 0, false
+	}
+
+	for _, b := range value {
+		if b < '0' || b > '9' {
+			return 0, false
+		}
+	}
+
+	ms, err := strconv.ParseInt(string(value), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return time.Duration(ms) * time.Millisecond, true
+}
+
 func NewMessage(id, event, data string) *Message {
 	msg := GetMessage()
 	msg.Id = id
@@ -234,6 +270,27 @@ func NewMessage(id, event, data string) *Message {
 	return msg
 }
 
+// NewJSONMessage gets a message from the pool with v marshaled as its data,
+// json.Marshal never emits new lines, so the data fits in a single data field
+func NewJSONMessage(id, event string, v any) (*Message, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewMessage(id, event, string(data)), nil
+}
+
+// JSON unmarshals the data of the message into v, ErrEmptyData is returned
+// if the message has no data, e.g. a ping
+func (m *Message) JSON(v any) error {
+	if m.Data == "" {
+		return ErrEmptyData
+	}
+
+	return json.Unmarshal([]byte(m.Data), v)
+}
+
 func NewPingEvent() *Message {
 	return GetMessage() // Already reset by GetMessage
 }
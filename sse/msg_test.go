@@ -2,9 +2,12 @@ package sse_test
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"io"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/hexe-dev/hexe/sse"
 )
@@ -159,3 +162,70 @@ func BenchmarkMsgConcurrent(b *testing.B) {
 		}
 	})
 }
+
+func TestJSONMessage(t *testing.T) {
+	type payload struct {
+		Name  string   `json:"name"`
+		Count int      `json:"count"`
+		Tags  []string `json:"tags"`
+	}
+
+	sent := payload{Name: "multi\nline", Count: 3, Tags: []string{"a", "b"}}
+
+	msg, err := sse.NewJSONMessage("1", "update", sent)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buffer bytes.Buffer
+
+	pusher, err := sse.NewPusher(&buffer, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := pusher.Push(msg); err != nil {
+		t.Fatal(err)
+	}
+	pusher.Close()
+	sse.PutMessage(msg)
+
+	receiver := sse.NewReceiver(&buffer)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	recv, err := receiver.Receive(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sse.PutMessage(recv)
+
+	if recv.Id != "1" || recv.Event != "update" {
+		t.Fatalf("unexpected message: %s", recv)
+	}
+
+	var got payload
+	if err := recv.JSON(&got); err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Name != sent.Name || got.Count != sent.Count || strings.Join(got.Tags, ",") != "a,b" {
+		t.Fatalf("expected %+v, got %+v", sent, got)
+	}
+}
+
+func TestJSONMessageErrors(t *testing.T) {
+	if _, err := sse.NewJSONMessage("1", "update", make(chan int)); err == nil {
+		t.Fatal("expected an error marshaling a channel")
+	}
+
+	var v map[string]any
+	if err := sse.NewPingEvent().JSON(&v); !errors.Is(err, sse.ErrEmptyData) {
+		t.Fatalf("expected sse.ErrEmptyData, got %v", err)
+	}
+
+	if err := sse.NewMessage("1", "update", "not json").JSON(&v); err == nil {
+		t.Fatal("expected an error unmarshaling invalid data")
+	}
+}
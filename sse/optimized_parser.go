@@ -26,11 +26,15 @@ func FastParse(r io.Reader) <-chan *Message {
 			msg := GetMessage()
 			hasContent := false
 
+			// the last message might not end with an empty line
+			eof := true
+
 			for scanner.Scan() {
 				line := scanner.Bytes()
 
 				// Empty line indicates end of message
 				if len(line) == 0 {
+					eof = false
 					break
 				}
 
@@ -79,12 +83,18 @@ func FastParse(r io.Reader) <-chan *Message {
 
 			if !hasContent {
 				PutMessage(msg)
-				return // EOF or no more messages
+				if eof {
+					return // no more messages
+				}
+				continue // comments or extra empty lines
 			}
 
+			// msg is owned by the consumer once sent
+			done := msg.Event == "done"
+
 			ch <- msg
 
-			if msg.Event == "done" {
+			if done {
 				return
 			}
 		}
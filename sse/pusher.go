@@ -1,6 +1,7 @@
 package sse
 
 import (
+	"context"
 	"io"
 	"net/http"
 	"sync"
@@ -13,15 +14,55 @@ import (
 //
 
 type rawPusher struct {
-	w       io.Writer
-	mtx     sync.RWMutex // Use RWMutex for better read performance
-	timeout time.Duration
-	timer   *time.Timer
-	closed  int32 // Use atomic for lock-free reads
-	done    chan struct{}
+	w io.Writer
+	// flush is called after each message is written, it's nil if w is not buffered,
+	// its error is returned by the push, e.g. when the write deadline is hit
+	flush func() error
+	// setWriteDeadline unblocks the writes to w when the context of PushContext
+	// is canceled, it's nil if w doesn't support deadlines
+	setWriteDeadline func(time.Time) error
+	mtx              sync.RWMutex // Use RWMutex for better read performance
+	timeout          time.Duration
+	timer            *time.Timer
+	closed           int32 // Use atomic for lock-free reads
+	done             chan struct{}
+	// metrics is optional, see WithPushMetrics
+	metrics Metrics
+}
+
+type pusherOpt func(*rawPusher)
+
+// WithPushMetrics reports the pusher's connection, pushes and bytes to m, nil disables it
+func WithPushMetrics(m Metrics) pusherOpt {
+	return func(p *rawPusher) {
+		p.metrics = m
+	}
+}
+
+// newRawPusher applies the options and reports the connection of the new pusher
+func newRawPusher(raw *rawPusher, opts []pusherOpt) *rawPusher {
+	for _, opt := range opts {
+		opt(raw)
+	}
+
+	if raw.metrics != nil {
+		raw.metrics.Connect()
+	}
+
+	return raw
 }
 
 func (p *rawPusher) Push(msg *Message) error {
+	return p.PushContext(context.Background(), msg)
+}
+
+// PushContext writes msg unless ctx is canceled before the write is done, a canceled
+// push leaves a partial message on the stream, so the pusher is closed afterward
+func (p *rawPusher) PushContext(ctx context.Context, msg *Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	// Fast path: check if closed without lock
 	if atomic.LoadInt32(&p.closed) == 1 {
 		return io.ErrClosedPipe
@@ -45,8 +86,77 @@ func (p *rawPusher) Push(msg *Message) error {
 		}
 	}
 
-	_, err := io.Copy(p.w, msg)
-	return err
+	// the context can never be canceled, e.g. context.Background()
+	if ctx.Done() == nil {
+		return p.write(msg)
+	}
+
+	if p.setWriteDeadline != nil {
+		// the deadline makes the blocked write to return, so there is no need for a goroutine
+		aborted := make(chan struct{})
+		stop := context.AfterFunc(ctx, func() {
+			p.setWriteDeadline(time.Now())
+			close(aborted)
+		})
+
+		err := p.write(msg)
+		if stop() {
+			return err
+		}
+
+		// w is not touched after PushContext returns
+		<-aborted
+	} else {
+		// the write can't be interrupted by a deadline, once ctx is canceled the pusher
+		// is closed right away, and w too if it's a closer, e.g. io.PipeWriter, to unblock
+		// the write, but PushContext still waits for it, so w is not touched afterward
+		result := make(chan error, 1)
+		go func() {
+			result <- p.write(msg)
+		}()
+
+		select {
+		case err := <-result:
+			return err
+		case <-ctx.Done():
+		}
+
+		if atomic.CompareAndSwapInt32(&p.closed, 0, 1) {
+			p.stop()
+		}
+
+		if closer, ok := p.w.(io.Closer); ok {
+			closer.Close()
+		}
+
+		<-result
+		return ctx.Err()
+	}
+
+	if atomic.CompareAndSwapInt32(&p.closed, 0, 1) {
+		p.stop()
+	}
+
+	return ctx.Err()
+}
+
+func (p *rawPusher) write(msg *Message) error {
+	n, err := io.Copy(p.w, msg)
+	if err != nil {
+		return err
+	}
+
+	if p.flush != nil {
+		if err := p.flush(); err != nil {
+			return err
+		}
+	}
+
+	if p.metrics != nil {
+		p.metrics.Push(msg, n)
+	}
+
+	return nil
 }
 
 func (p *rawPusher) Close() error {
@@ -56,13 +166,22 @@ func (p *rawPusher) Close() error {
 	}
 
 	p.mtx.Lock()
+	p.stop()
+	p.mtx.Unlock()
+
+	return nil
+}
+
+// stop stops the ping timer and its goroutine, p.mtx should be held by the caller
+func (p *rawPusher) stop() {
 	if p.timer != nil {
 		p.timer.Stop()
 	}
 	close(p.done) // Signal goroutine to stop
-	p.mtx.Unlock()
 
-	return nil
+	if p.metrics != nil {
+		p.metrics.Disconnect()
+	}
 }
 
 // timerHandler manages the ping timer in a single goroutine
@@ -99,12 +218,17 @@ func (p *rawPusher) sendPing() {
 	}
 }
 
-func NewPusher(w io.Writer, timeout time.Duration) (Pusher, error) {
+func NewPusher(w io.Writer, timeout time.Duration, opts ...pusherOpt) (Pusher, error) {
 	switch v := w.(type) {
 	case http.ResponseWriter:
-		return NewHttpPusher(v, timeout)
+		return NewHttpPusher(v, timeout, opts...)
 	default:
-		return &rawPusher{w: w, timeout: timeout, done: make(chan struct{})}, nil
+		raw := &rawPusher{w: w, timeout: timeout, done: make(chan struct{})}
+		// e.g. net.Conn
+		if conn, ok := w.(interface{ SetWriteDeadline(time.Time) error }); ok {
+			raw.setWriteDeadline = conn.SetWriteDeadline
+		}
+		return newRawPusher(raw, opts), nil
 	}
 }
 
@@ -112,7 +236,7 @@ func NewPusher(w io.Writer, timeout time.Duration) (Pusher, error) {
 // Http Pusher
 //
 
-func NewHttpPusher(w http.ResponseWriter, timeout time.Duration) (Pusher, error) {
+func NewHttpPusher(w http.ResponseWriter, timeout time.Duration, opts ...pusherOpt) (Pusher, error) {
 	out, ok := w.(http.Flusher)
 	if !ok {
 		return nil, http.ErrNotSupported
@@ -126,16 +250,15 @@ func NewHttpPusher(w http.ResponseWriter, timeout time.Duration) (Pusher, error)
 
 	out.Flush() // Flush the headers
 
-	raw := &rawPusher{w: w, timeout: timeout, done: make(chan struct{})}
+	// the controller's Flush reports the errors, so a flush blocked by a stuck client
+	// and aborted by PushContext is not mistaken for a successful push
+	rc := http.NewResponseController(w)
+	raw := &rawPusher{w: w, flush: rc.Flush, timeout: timeout, done: make(chan struct{})}
 
-	return NewPushCloser(
-		func(msg *Message) error {
-			if err := raw.Push(msg); err != nil {
-				return err
-			}
-			out.Flush()
-			return nil
-		},
-		raw.Close,
-	), nil
+	// a zero deadline is a no-op, it only reports if the deadlines are supported by w
+	if rc.SetWriteDeadline(time.Time{}) == nil {
+		raw.setWriteDeadline = rc.SetWriteDeadline
+	}
+
+	return newRawPusher(raw, opts), nil
 }
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"iter"
 	"math"
 	"net/http"
 	"sync"
@@ -26,23 +27,146 @@ func (r *receiver) Receive(ctx context.Context) (*Message, error) {
 		if !ok {
 			return nil, io.EOF
 		}
+		if err := msg.Err; err != nil {
+			PutMessage(msg)
+			return nil, err
+		}
 		return msg, nil
 	}
 }
 
+// NewReceiver returns a receiver of the messages of rc, Receive returns io.EOF once
+// rc ends, or the parsing error if reading rc fails, e.g. a line is too long
 func NewReceiver(rc io.Reader) Receiver {
 	return &receiver{
-		ch: Parse(rc),
+		ch: ParseWithOptions(rc),
+	}
+}
+
+// Listen receives messages from r and dispatches each one to the handler registered
+// for its Event, the "" key acts as a catch-all for events without a handler.
+// Messages are returned to the pool once the handler returns, so handlers should not
+// keep a reference to them. Listen returns nil once the stream ends with io.EOF,
+// ctx.Err() if the context is cancelled or the error returned by Receive
+func Listen(ctx context.Context, r Receiver, handlers map[string]func(*Message)) error {
+	for {
+		msg, err := r.Receive(ctx)
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return ctxErr
+			}
+			return err
+		}
+
+		handler, ok := handlers[msg.Event]
+		if !ok {
+			handler, ok = handlers[""]
+		}
+
+		if ok {
+			handler(msg)
+		}
+
+		PutMessage(msg)
+	}
+}
+
+// Iter returns a sequence of messages received from r, it can be used as
+//
+//	for msg, err := range sse.Iter(r, ctx) {
+//		...
+//	}
+//
+// The sequence ends once the stream ends with io.EOF, or after yielding the
+// error returned by Receive, including the context's error if it is cancelled.
+// The caller owns each yielded message, it is never returned to the pool by Iter,
+// and the caller may call PutMessage once it is done with it
+func Iter(r Receiver, ctx context.Context) iter.Seq2[*Message, error] {
+	return func(yield func(*Message, error) bool) {
+		for {
+			msg, err := r.Receive(ctx)
+			if errors.Is(err, io.EOF) {
+				return
+			} else if err != nil {
+				yield(nil, err)
+				return
+			}
+
+			if !yield(msg, nil) {
+				return
+			}
+		}
+	}
+}
+
+const (
+	defaultChannelBuffer = 16
+	defaultMaxTokenSize  = 65536
+)
+
+type parseConfig struct {
+	channelBuffer int
+	maxTokenSize  int
+	// reportErr sends a final message carrying the scanner's error
+	reportErr bool
+}
+
+type parseOpt func(*parseConfig)
+
+// WithChannelBuffer sets the capacity of the channel returned by ParseWithOptions,
+// non-positive values keep the default of 16 messages
+func WithChannelBuffer(n int) parseOpt {
+	return func(c *parseConfig) {
+		if n > 0 {
+			c.channelBuffer = n
+		}
+	}
+}
+
+// WithMaxTokenSize sets the maximum size of a single line in bytes,
+// non-positive values keep the default of 64KB
+func WithMaxTokenSize(bytes int) parseOpt {
+	return func(c *parseConfig) {
+		if bytes > 0 {
+			c.maxTokenSize = bytes
+		}
 	}
 }
 
 func Parse(r io.Reader) <-chan *Message {
-	ch := make(chan *Message, 16) // Buffered channel for better throughput
+	return parse(r, parseConfig{
+		channelBuffer: defaultChannelBuffer,
+		maxTokenSize:  defaultMaxTokenSize,
+	})
+}
+
+// ParseWithOptions is the same as Parse, but the channel buffer and the max token size
+// can be tuned. If parsing fails, e.g. reading r fails or a line exceeds the max token size,
+// a final message with Err set is sent before the channel is closed, so unlike Parse, the
+// consumer can tell the end of the stream from a failure
+func ParseWithOptions(r io.Reader, opts ...parseOpt) <-chan *Message {
+	cfg := parseConfig{
+		channelBuffer: defaultChannelBuffer,
+		maxTokenSize:  defaultMaxTokenSize,
+		reportErr:     true,
+	}
+
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	return parse(r, cfg)
+}
+
+func parse(r io.Reader, cfg parseConfig) <-chan *Message {
+	ch := make(chan *Message, cfg.channelBuffer) // Buffered channel for better throughput
 	scanner := bufio.NewScanner(r)
 
 	// Use a larger buffer to reduce system calls
-	buf := make([]byte, 0, 4096)
-	scanner.Buffer(buf, 65536) // 64KB max token size
+	buf := make([]byte, 0, min(4096, cfg.maxTokenSize))
+	scanner.Buffer(buf, cfg.maxTokenSize)
 
 	go func() {
 		defer close(ch)
@@ -50,21 +174,31 @@ func Parse(r io.Reader) <-chan *Message {
 		for {
 			msg, err := parseMessageOptimized(scanner)
 			if err != nil {
-				if !errors.Is(err, io.EOF) {
-					// Log error if needed
+				if !errors.Is(err, io.EOF) && cfg.reportErr {
+					if errors.Is(err, bufio.ErrTooLong) {
+						err = fmt.Errorf("sse: line exceeds max token size of %d bytes: %w", cfg.maxTokenSize, err)
+					}
+					errMsg := GetMessage()
+					errMsg.Err = err
+					ch <- errMsg
 				}
 				return
 			}
 
-			// Skip empty messages
-			if msg.Id == "" && msg.Event == "" && msg.Data == "" {
+			// Skip empty messages, a message with only retry field is still
+			// delivered so the receiver can adjust its reconnection delay
+			if msg.Id == "" && msg.Event == "" && msg.Data == "" && msg.Retry == 0 {
 				PutMessage(msg) // Return unused message to pool
 				continue
 			}
 
+			// msg is owned by the consumer once sent and might be returned
+			// to the pool, so it should not be accessed after sending
+			done := msg.Event == "done"
+
 			ch <- msg
 
-			if msg.Event == "done" {
+			if done {
 				return
 			}
 		}
@@ -79,6 +213,10 @@ func parseMessageOptimized(scanner *bufio.Scanner) (*Message, error) {
 
 	isComment := false
 
+	// the last message of the stream might not end with an empty line,
+	// so it is still returned once the scanner reaches the end
+	eof := true
+
 	for scanner.Scan() {
 		line := scanner.Bytes() // Use Bytes() instead of Text() to avoid string allocation
 
@@ -88,6 +226,7 @@ func parseMessageOptimized(scanner *bufio.Scanner) (*Message, error) {
 				isComment = false
 				continue
 			}
+			eof = false
 			break
 		}
 
@@ -120,6 +259,13 @@ func parseMessageOptimized(scanner *bufio.Scanner) (*Message, error) {
 			} else if len(field) == 4 &&
 				field[0] == 'd' && field[1] == 'a' && field[2] == 't' && field[3] == 'a' {
 				msg.Data = string(value)
+			} else if len(field) == 5 &&
+				field[0] == 'r' && field[1] == 'e' && field[2] == 't' &&
+				field[3] == 'r' && field[4] == 'y' {
+				// Malformed retry values are ignored
+				if retry, ok := parseRetry(value); ok {
+					msg.Retry = retry
+				}
 			}
 		}
 	}
@@ -129,8 +275,9 @@ func parseMessageOptimized(scanner *bufio.Scanner) (*Message, error) {
 		return nil, err
 	}
 
-	// If we got here without any fields, check if scanner is done
-	if msg.Id == "" && msg.Event == "" && msg.Data == "" {
+	// An empty message in the middle of the stream, e.g. extra empty lines,
+	// is returned and skipped by the caller, only the end of stream stops parsing
+	if eof && msg.Id == "" && msg.Event == "" && msg.Data == "" && msg.Retry == 0 {
 		PutMessage(msg) // Return to pool
 		return nil, io.EOF
 	}
@@ -151,7 +298,23 @@ type httpReceiver struct {
 	maxConnectionRetries int
 	initialRetryDelay    time.Duration
 	maxRetryDelay        time.Duration
-	// Mutex to protect concurrent access to receiver and connected fields
+	// jitter is shared with the retry transport, see WithJitter
+	jitter float64
+	// Id of the last delivered message, sent as Last-Event-ID header on reconnect
+	lastEventID string
+	// metrics is optional, see WithReceiveMetrics
+	metrics Metrics
+	// onReconnect is optional, see WithOnReconnect
+	onReconnect func(attempt int)
+	// body of the current connection, its read bytes are reported by metrics
+	body *countingReader
+	// bytes of body which are already reported
+	reported int64
+	// connected at least once, so the next connections are reconnects
+	everConnected bool
+	// the number of reconnects since the last received message
+	reconnects int
+	// Mutex to protect concurrent access to receiver, connected, lastEventID, initialRetryDelay and metrics' fields
 	mu sync.RWMutex
 }
 
@@ -168,6 +331,23 @@ func (hr *httpReceiver) Receive(ctx context.Context) (*Message, error) {
 
 		// If not connected or receiver is nil, establish connection
 		if !connected || receiver == nil {
+			hr.mu.Lock()
+			reconnects := 0
+			if hr.everConnected || attempt > 0 {
+				hr.reconnects++
+				reconnects = hr.reconnects
+			}
+			hr.mu.Unlock()
+
+			// the hooks are called without holding mu, so they can call back into the receiver
+			if hr.metrics != nil && reconnects > 0 {
+				hr.metrics.Reconnect(reconnects)
+			}
+
+			if hr.onReconnect != nil && reconnects > 0 {
+				hr.onReconnect(reconnects)
+			}
+
 			if err := hr.connect(ctx); err != nil {
 				// If this is the last attempt, return the error
 				if attempt == hr.maxConnectionRetries {
@@ -200,6 +380,10 @@ func (hr *httpReceiver) Receive(ctx context.Context) (*Message, error) {
 			hr.receiver = nil
 			hr.mu.Unlock()
 
+			if hr.metrics != nil {
+				hr.metrics.Disconnect()
+			}
+
 			// If this is the last attempt, return the error
 			if attempt == hr.maxConnectionRetries {
 				return nil, fmt.Errorf("failed to receive message after %d connection attempts: %w", hr.maxConnectionRetries+1, err)
@@ -209,6 +393,28 @@ func (hr *httpReceiver) Receive(ctx context.Context) (*Message, error) {
 			continue
 		}
 
+		// Track the last delivered id so a reconnect can resume from it
+		// and the server advised retry delay for the next reconnection
+		hr.mu.Lock()
+		if msg.Id != "" {
+			hr.lastEventID = msg.Id
+		}
+		if msg.Retry > 0 {
+			hr.initialRetryDelay = msg.Retry
+		}
+		hr.reconnects = 0
+
+		var n int64
+		if hr.body != nil {
+			read := hr.body.n.Load()
+			n, hr.reported = read-hr.reported, read
+		}
+		hr.mu.Unlock()
+
+		if hr.metrics != nil {
+			hr.metrics.Receive(msg, n)
+		}
+
 		return msg, nil
 	}
 
@@ -226,6 +432,14 @@ func (hr *httpReceiver) connect(ctx context.Context) error {
 	req.Header.Set("Accept", "text/event-stream")
 	req.Header.Set("Cache-Control", "no-cache")
 
+	hr.mu.RLock()
+	lastEventID := hr.lastEventID
+	hr.mu.RUnlock()
+
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+
 	resp, err := hr.client.Do(req)
 	if err != nil {
 		return err
@@ -238,21 +452,34 @@ func (hr *httpReceiver) connect(ctx context.Context) error {
 	}
 
 	// Create receiver from response body and update state with write lock
+	body := &countingReader{r: resp.Body}
+
 	hr.mu.Lock()
-	hr.receiver = NewReceiver(resp.Body)
+	hr.receiver = NewReceiver(body)
 	hr.connected = true
+	hr.everConnected = true
+	hr.body = body
+	hr.reported = 0
 	hr.mu.Unlock()
 
+	if hr.metrics != nil {
+		hr.metrics.Connect()
+	}
+
 	return nil
 }
 
 // calculateConnectionBackoff calculates exponential backoff with max delay for connection retries
 func (hr *httpReceiver) calculateConnectionBackoff(attempt int) time.Duration {
-	delay := time.Duration(float64(hr.initialRetryDelay) * math.Pow(2, float64(attempt)))
+	hr.mu.RLock()
+	initialRetryDelay := hr.initialRetryDelay
+	hr.mu.RUnlock()
+
+	delay := time.Duration(float64(initialRetryDelay) * math.Pow(2, float64(attempt)))
 	if delay > hr.maxRetryDelay {
 		delay = hr.maxRetryDelay
 	}
-	return delay
+	return applyJitter(delay, hr.jitter)
 }
 
 // Connection retry options for httpReceiver
@@ -288,6 +515,36 @@ func WithConnectionMaxDelay(delay time.Duration) httpReceiverOpt {
 	}
 }
 
+// WithLastEventID seeds the Last-Event-ID header sent on the first connection,
+// which is useful to resume a stream from a previous session
+func WithLastEventID(id string) httpReceiverOpt {
+	return func(hr *httpReceiver) error {
+		hr.lastEventID = id
+		return nil
+	}
+}
+
+// WithReceiveMetrics reports the receiver's connections, reconnects,
+// received messages and bytes to m, nil disables it
+func WithReceiveMetrics(m Metrics) httpReceiverOpt {
+	return func(hr *httpReceiver) error {
+		hr.metrics = m
+		return nil
+	}
+}
+
+// WithOnReconnect calls fn before each reconnection with the number of reconnects
+// since the last received message, starting from 1
+func WithOnReconnect(fn func(attempt int)) httpReceiverOpt {
+	return func(hr *httpReceiver) error {
+		if fn == nil {
+			return fmt.Errorf("on reconnect hook cannot be nil")
+		}
+		hr.onReconnect = fn
+		return nil
+	}
+}
+
 func NewHttpReceiver(url string, opts ...interface{}) (*httpReceiver, error) {
 	// Separate retry transport options from connection retry options
 	var retryTransportOpts []retryTransportOpt
@@ -312,6 +569,7 @@ func NewHttpReceiver(url string, opts ...interface{}) (*httpReceiver, error) {
 	hr := &httpReceiver{
 		url:    url,
 		client: client,
+		jitter: client.Transport.(*retryTransport).Jitter,
 		// Default connection retry configuration
 		maxConnectionRetries: 3,
 		initialRetryDelay:    500 * time.Millisecond,
@@ -358,6 +358,48 @@ func TestHttpReceiver_Reconnection(t *testing.T) {
 	}
 }
 
+func TestHttpReceiver_OnReconnect(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		// Send one message then close connection to force a reconnect on each receive
+		fmt.Fprint(w, "event: test\ndata: message\n\n")
+	}))
+	defer server.Close()
+
+	var receiver *httpReceiver
+	var attempts []int
+
+	receiver, err := NewHttpReceiver(server.URL,
+		WithConnectionInitialDelay(10*time.Millisecond),
+		WithOnReconnect(func(attempt int) {
+			// the hook is called without holding the receiver's lock, so it doesn't deadlock
+			receiver.calculateConnectionBackoff(attempt)
+			attempts = append(attempts, attempt)
+		}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create httpReceiver: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	for range 3 {
+		if _, err := receiver.Receive(ctx); err != nil {
+			t.Fatalf("Failed to receive message: %v", err)
+		}
+	}
+
+	// the first connection is not a reconnect, and each received message resets the count
+	if fmt.Sprint(attempts) != "[1 1]" {
+		t.Errorf("Expected the hook to be called for attempts [1 1], got %v", attempts)
+	}
+
+	if _, err := NewHttpReceiver(server.URL, WithOnReconnect(nil)); err == nil {
+		t.Error("Expected error for nil on reconnect hook")
+	}
+}
+
 func TestHttpReceiver_WithRetryOptions(t *testing.T) {
 	attempts := 0
 	var mu sync.Mutex
@@ -664,6 +706,90 @@ func TestHttpReceiver_ConnectionLossAndRetry(t *testing.T) {
 	}
 }
 
+func TestHttpReceiver_LastEventID(t *testing.T) {
+	connectionCount := 0
+	var mu sync.Mutex
+
+	// Create a server that echoes the Last-Event-ID header back as data
+	// and closes the connection after each message
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		connectionCount++
+		currentConnection := connectionCount
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+
+		fmt.Fprintf(w, "id: conn%d\nevent: test\ndata: last=%s\n\n", currentConnection, r.Header.Get("Last-Event-ID"))
+	}))
+	defer server.Close()
+
+	receiver, err := NewHttpReceiver(
+		server.URL,
+		WithConnectionMaxRetries(2),
+		WithConnectionInitialDelay(10*time.Millisecond),
+		WithLastEventID("seed"),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create httpReceiver: %v", err)
+	}
+
+	ctx := context.Background()
+
+	// First connection should send the seeded id
+	msg1, err := receiver.Receive(ctx)
+	if err != nil {
+		t.Fatalf("Failed to receive first message: %v", err)
+	}
+	if msg1.Data != "last=seed" {
+		t.Errorf("Expected seeded Last-Event-ID, got: %s", msg1.Data)
+	}
+
+	// Reconnect should send the id of the last delivered message
+	msg2, err := receiver.Receive(ctx)
+	if err != nil {
+		t.Fatalf("Failed to receive message after reconnect: %v", err)
+	}
+	if msg2.Data != "last=conn1" {
+		t.Errorf("Expected Last-Event-ID of previous message, got: %s", msg2.Data)
+	}
+
+	msg3, err := receiver.Receive(ctx)
+	if err != nil {
+		t.Fatalf("Failed to receive message after second reconnect: %v", err)
+	}
+	if msg3.Data != "last=conn2" {
+		t.Errorf("Expected Last-Event-ID of previous message, got: %s", msg3.Data)
+	}
+}
+
+func TestHttpReceiver_NoLastEventID(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := r.Header["Last-Event-Id"]; ok {
+			t.Errorf("Expected no Last-Event-ID header, got %s", r.Header.Get("Last-Event-ID"))
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "event: test\ndata: hello\n\n")
+	}))
+	defer server.Close()
+
+	receiver, err := NewHttpReceiver(server.URL)
+	if err != nil {
+		t.Fatalf("Failed to create httpReceiver: %v", err)
+	}
+
+	msg, err := receiver.Receive(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to receive message: %v", err)
+	}
+	if msg.Data != "hello" {
+		t.Errorf("Expected hello, got: %s", msg.Data)
+	}
+}
+
 func TestHttpReceiver_ConnectionRetryBackoff(t *testing.T) {
 	attempts := 0
 	timestamps := make([]time.Time, 0)
@@ -843,3 +969,232 @@ data: message with comment`+"\n\n")
 		t.Errorf("Message mismatch: %+v", msg)
 	}
 }
+
+func TestParseRetry(t *testing.T) {
+	testCases := []struct {
+		input string
+		retry time.Duration
+	}{
+		{input: "retry: 5000\ndata: hello\n\n", retry: 5 * time.Second},
+		{input: "retry: 0\ndata: hello\n\n", retry: 0},
+		{input: "retry: 5s\ndata: hello\n\n", retry: 0},
+		{input: "retry: -100\ndata: hello\n\n", retry: 0},
+		{input: "retry: 1.5\ndata: hello\n\n", retry: 0},
+		{input: "retry: 99999999999999999999\ndata: hello\n\n", retry: 0},
+	}
+
+	for _, tc := range testCases {
+		msg, ok := <-Parse(strings.NewReader(tc.input))
+		if !ok {
+			t.Fatalf("Expected a message for %q, got channel closed", tc.input)
+		}
+
+		if msg.Data != "hello" {
+			t.Errorf("Expected data hello for %q, got %s", tc.input, msg.Data)
+		}
+
+		if msg.Retry != tc.retry {
+			t.Errorf("Expected retry %s for %q, got %s", tc.retry, tc.input, msg.Retry)
+		}
+	}
+
+	// A message with only retry field should still be delivered
+	msg, ok := <-Parse(strings.NewReader("retry: 250\n\n"))
+	if !ok {
+		t.Fatal("Expected a retry only message, got channel closed")
+	}
+
+	if msg.Retry != 250*time.Millisecond {
+		t.Errorf("Expected retry 250ms, got %s", msg.Retry)
+	}
+}
+
+func TestHttpReceiver_ServerRetryDelay(t *testing.T) {
+	connectionCount := 0
+	var connectedAt []time.Time
+	var mu sync.Mutex
+
+	// First connection advises a retry delay and closes, second connection
+	// fails with a non retryable status and third one succeeds
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		connectionCount++
+		currentConnection := connectionCount
+		connectedAt = append(connectedAt, time.Now())
+		mu.Unlock()
+
+		if currentConnection == 2 {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprintf(w, "retry: 200\nevent: test\ndata: conn%d\n\n", currentConnection)
+	}))
+	defer server.Close()
+
+	receiver, err := NewHttpReceiver(
+		server.URL,
+		WithConnectionMaxRetries(3),
+		WithConnectionInitialDelay(1*time.Millisecond),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create httpReceiver: %v", err)
+	}
+
+	ctx := context.Background()
+
+	msg1, err := receiver.Receive(ctx)
+	if err != nil {
+		t.Fatalf("Failed to receive first message: %v", err)
+	}
+	if msg1.Data != "conn1" {
+		t.Errorf("Expected message from connection 1, got: %s", msg1.Data)
+	}
+
+	if delay := receiver.calculateConnectionBackoff(0); delay != 200*time.Millisecond {
+		t.Errorf("Expected backoff to use server retry delay of 200ms, got %s", delay)
+	}
+
+	msg2, err := receiver.Receive(ctx)
+	if err != nil {
+		t.Fatalf("Failed to receive message after reconnect: %v", err)
+	}
+	if msg2.Data != "conn3" {
+		t.Errorf("Expected message from connection 3, got: %s", msg2.Data)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(connectedAt) != 3 {
+		t.Fatalf("Expected 3 connections, got %d", len(connectedAt))
+	}
+
+	if elapsed := connectedAt[2].Sub(connectedAt[1]); elapsed < 200*time.Millisecond {
+		t.Errorf("Expected reconnection to wait at least 200ms, waited %s", elapsed)
+	}
+}
+
+func TestListen(t *testing.T) {
+	data := "id: 1\nevent: test\ndata: hello\n\n" +
+		"id: 2\nevent: other\ndata: catch all\n\n" +
+		"id: 3\nevent: test\ndata: world\n\n" +
+		"id: 4\nevent: done\ndata: finished\n\n"
+
+	var tests, others, dones []string
+
+	err := Listen(context.Background(), NewReceiver(strings.NewReader(data)), map[string]func(*Message){
+		"test": func(msg *Message) {
+			tests = append(tests, msg.Data)
+		},
+		"done": func(msg *Message) {
+			dones = append(dones, msg.Data)
+		},
+		"": func(msg *Message) {
+			others = append(others, msg.Event+":"+msg.Data)
+		},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if strings.Join(tests, ",") != "hello,world" {
+		t.Errorf("Unexpected test events: %v", tests)
+	}
+	if strings.Join(dones, ",") != "finished" {
+		t.Errorf("Unexpected done events: %v", dones)
+	}
+	if strings.Join(others, ",") != "other:catch all" {
+		t.Errorf("Unexpected catch all events: %v", others)
+	}
+}
+
+func TestListenContextCancel(t *testing.T) {
+	pr, pw := io.Pipe()
+	defer pw.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	go func() {
+		fmt.Fprint(pw, "event: test\ndata: hello\n\n")
+	}()
+
+	var received []string
+	err := Listen(ctx, NewReceiver(pr), map[string]func(*Message){
+		"test": func(msg *Message) {
+			received = append(received, msg.Data)
+			cancel()
+		},
+	})
+	if err != context.Canceled {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if len(received) != 1 || received[0] != "hello" {
+		t.Errorf("Unexpected received events: %v", received)
+	}
+}
+
+func TestIter(t *testing.T) {
+	data := "id: 1\nevent: test\ndata: hello\n\n" +
+		"id: 2\nevent: test\ndata: world\n\n" +
+		"id: 3\nevent: done\ndata: finished\n\n"
+
+	var msgs []*Message
+	for msg, err := range Iter(NewReceiver(strings.NewReader(data)), context.Background()) {
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		msgs = append(msgs, msg)
+	}
+
+	if len(msgs) != 3 {
+		t.Fatalf("Expected 3 messages, got %d", len(msgs))
+	}
+
+	// messages are still owned by the caller after iteration
+	expected := []string{"1:test:hello", "2:test:world", "3:done:finished"}
+	for i, msg := range msgs {
+		if got := msg.Id + ":" + msg.Event + ":" + msg.Data; got != expected[i] {
+			t.Errorf("Expected message %s, got %s", expected[i], got)
+		}
+	}
+}
+
+func TestIterBreak(t *testing.T) {
+	data := "id: 1\ndata: hello\n\n" +
+		"id: 2\ndata: world\n\n"
+
+	count := 0
+	for _, err := range Iter(NewReceiver(strings.NewReader(data)), context.Background()) {
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		count++
+		break
+	}
+
+	if count != 1 {
+		t.Errorf("Expected 1 message, got %d", count)
+	}
+}
+
+func TestIterContextCancel(t *testing.T) {
+	pr, pw := io.Pipe()
+	defer pw.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var errs []error
+	for msg, err := range Iter(NewReceiver(pr), ctx) {
+		if msg != nil {
+			t.Errorf("Expected no message, got %v", msg)
+		}
+		errs = append(errs, err)
+	}
+
+	if len(errs) != 1 || errs[0] != context.Canceled {
+		t.Errorf("Expected a single context.Canceled error, got %v", errs)
+	}
+}
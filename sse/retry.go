@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"io"
 	"math"
+	"math/rand"
 	"net/http"
+	"strconv"
 	"time"
 )
 
@@ -14,7 +16,15 @@ type retryTransport struct {
 	MaxRetries   int
 	InitialDelay time.Duration
 	MaxDelay     time.Duration
-	Headers      map[string]string
+	// Jitter is the fraction of each delay, in [0, 1], which is randomly
+	// subtracted from it, 0 disables jitter and 1 means full jitter
+	Jitter  float64
+	Headers map[string]string
+	// RetryPolicy decides whether a response or an error should be retried,
+	// resp is nil when err is not nil
+	RetryPolicy func(resp *http.Response, err error) bool
+	// OnRetry is called before waiting for each retry, see WithOnRetry
+	OnRetry func(attempt int, delay time.Duration, err error)
 }
 
 type retryTransportOpt func(*retryTransport) error
@@ -49,6 +59,43 @@ func WithMaxDelay(delay time.Duration) retryTransportOpt {
 	}
 }
 
+// WithJitter randomizes each backoff delay to be within [delay*(1-fraction), delay],
+// so many clients failing at the same time don't retry all at once
+func WithJitter(fraction float64) retryTransportOpt {
+	return func(t *retryTransport) error {
+		if fraction < 0 || fraction > 1 {
+			return fmt.Errorf("jitter fraction must be within [0, 1]")
+		}
+		t.Jitter = fraction
+		return nil
+	}
+}
+
+// WithRetryPolicy overrides the default policy, which retries on transport errors,
+// 5xx and 429 status codes
+func WithRetryPolicy(policy func(resp *http.Response, err error) bool) retryTransportOpt {
+	return func(t *retryTransport) error {
+		if policy == nil {
+			return fmt.Errorf("retry policy cannot be nil")
+		}
+		t.RetryPolicy = policy
+		return nil
+	}
+}
+
+// WithOnRetry calls fn before waiting for each retry with the attempt which is retried,
+// starting from 1, the delay until the next attempt and the error of the attempt,
+// a retried status code is reported as an error, e.g. to count the retries in metrics
+func WithOnRetry(fn func(attempt int, delay time.Duration, err error)) retryTransportOpt {
+	return func(t *retryTransport) error {
+		if fn == nil {
+			return fmt.Errorf("on retry hook cannot be nil")
+		}
+		t.OnRetry = fn
+		return nil
+	}
+}
+
 func WithHeaders(headers map[string]string) retryTransportOpt {
 	return func(t *retryTransport) error {
 		if headers == nil {
@@ -71,6 +118,11 @@ func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 	var resp *http.Response
 	var err error
 
+	retryPolicy := t.RetryPolicy
+	if retryPolicy == nil {
+		retryPolicy = defaultRetryPolicy
+	}
+
 	for attempt := 0; attempt <= t.MaxRetries; attempt++ {
 		// Clone the request body if it exists (for retries)
 		var bodyClone io.ReadCloser
@@ -86,8 +138,17 @@ func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 		resp, err = t.Transport.RoundTrip(req)
 
 		// If successful or non-retryable, return
-		if err == nil && !shouldRetry(resp.StatusCode) {
-			return resp, nil
+		if !retryPolicy(resp, err) {
+			return resp, err
+		}
+
+		// Server's Retry-After takes precedence over the computed backoff, it's still
+		// capped by MaxDelay, so a server can't stall the client indefinitely
+		delay := t.calculateBackoff(attempt)
+		if resp != nil {
+			if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+				delay = min(retryAfter, t.MaxDelay)
+			}
 		}
 
 		// Close response body if it exists
@@ -97,10 +158,17 @@ func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 
 		// Don't sleep after the last attempt
 		if attempt < t.MaxRetries {
-			delay := t.calculateBackoff(attempt)
 
 			logger.DebugContext(ctx, "request failed, retrying", "attempt", attempt+1, "delay", delay)
 
+			if t.OnRetry != nil {
+				retryErr := err
+				if retryErr == nil {
+					retryErr = fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+				}
+				t.OnRetry(attempt+1, delay, retryErr)
+			}
+
 			// Use context-aware sleep to respect canchexetion
 			select {
 			case <-req.Context().Done():
@@ -120,7 +188,41 @@ func (t *retryTransport) calculateBackoff(attempt int) time.Duration {
 	if delay > t.MaxDelay {
 		delay = t.MaxDelay
 	}
-	return delay
+	return applyJitter(delay, t.Jitter)
+}
+
+// applyJitter randomly subtracts up to fraction of the delay from it
+func applyJitter(delay time.Duration, fraction float64) time.Duration {
+	if fraction <= 0 {
+		return delay
+	}
+	return delay - time.Duration(rand.Float64()*fraction*float64(delay))
+}
+
+// parseRetryAfter parses the Retry-After header which is either
+// the number of seconds to wait or an http date
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+
+	return 0, false
+}
+
+// defaultRetryPolicy retries on transport errors and the status codes accepted by shouldRetry
+func defaultRetryPolicy(resp *http.Response, err error) bool {
+	return err != nil || shouldRetry(resp.StatusCode)
 }
 
 // shouldRetry determines if a status code should trigger a retry
@@ -137,6 +239,7 @@ func NewRetryClient(opts ...retryTransportOpt) (*http.Client, error) {
 		InitialDelay: 1 * time.Second,
 		MaxDelay:     30 * time.Second,
 		Headers:      make(map[string]string),
+		RetryPolicy:  defaultRetryPolicy,
 	}
 
 	for _, opt := range opts {
@@ -506,3 +506,294 @@ func TestRetryTransportRealWorldScenario(t *testing.T) {
 		t.Errorf("Expected body %q, got %q", expectedBody, string(body))
 	}
 }
+
+func TestWithJitter(t *testing.T) {
+	for _, fraction := range []float64{-0.1, 1.1} {
+		if _, err := NewRetryClient(WithJitter(fraction)); err == nil {
+			t.Errorf("Expected error for jitter fraction %v", fraction)
+		}
+	}
+
+	for _, fraction := range []float64{0, 0.5, 1} {
+		client, err := NewRetryClient(WithJitter(fraction))
+		if err != nil {
+			t.Fatalf("Unexpected error for jitter fraction %v: %v", fraction, err)
+		}
+		if jitter := client.Transport.(*retryTransport).Jitter; jitter != fraction {
+			t.Errorf("Expected jitter %v, got %v", fraction, jitter)
+		}
+	}
+}
+
+func TestRetryTransportJitterBackoff(t *testing.T) {
+	tests := []struct {
+		jitter  float64
+		attempt int
+		min     time.Duration
+		max     time.Duration
+	}{
+		{0, 2, 400 * time.Millisecond, 400 * time.Millisecond},
+		{0.25, 0, 75 * time.Millisecond, 100 * time.Millisecond},
+		{0.5, 2, 200 * time.Millisecond, 400 * time.Millisecond},
+		{0.5, 5, 500 * time.Millisecond, 1 * time.Second}, // Capped at MaxDelay before jitter
+		{1, 3, 0, 800 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("jitter_%v_attempt_%d", tt.jitter, tt.attempt), func(t *testing.T) {
+			transport := &retryTransport{
+				Transport:    http.DefaultTransport,
+				InitialDelay: 100 * time.Millisecond,
+				MaxDelay:     1 * time.Second,
+				Jitter:       tt.jitter,
+			}
+
+			distinct := make(map[time.Duration]struct{})
+			for range 1000 {
+				delay := transport.calculateBackoff(tt.attempt)
+				if delay < tt.min || delay > tt.max {
+					t.Fatalf("Expected delay between %v and %v, got %v", tt.min, tt.max, delay)
+				}
+				distinct[delay] = struct{}{}
+			}
+
+			if tt.jitter > 0 && len(distinct) < 2 {
+				t.Errorf("Expected jittered delays to vary, got %d distinct values", len(distinct))
+			}
+		})
+	}
+}
+
+func TestHttpReceiverJitterBackoff(t *testing.T) {
+	receiver, err := NewHttpReceiver(
+		"http://localhost",
+		WithJitter(0.5),
+		WithConnectionInitialDelay(100*time.Millisecond),
+		WithConnectionMaxDelay(1*time.Second),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create httpReceiver: %v", err)
+	}
+
+	for range 1000 {
+		delay := receiver.calculateConnectionBackoff(1)
+		if delay < 100*time.Millisecond || delay > 200*time.Millisecond {
+			t.Fatalf("Expected delay between 100ms and 200ms, got %v", delay)
+		}
+	}
+}
+
+func TestRetryTransportCustomPolicy(t *testing.T) {
+	requestCount := int32(0)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requestCount, 1) < 3 {
+			w.WriteHeader(http.StatusRequestTimeout)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client, err := NewRetryClient(
+		WithMaxRetries(3),
+		WithInitialDelay(10*time.Millisecond),
+		WithRetryPolicy(func(resp *http.Response, err error) bool {
+			return err != nil || resp.StatusCode == http.StatusRequestTimeout
+		}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", resp.StatusCode)
+	}
+	if count := atomic.LoadInt32(&requestCount); count != 3 {
+		t.Errorf("Expected 3 requests, got %d", count)
+	}
+
+	// default policy doesn't retry 408
+	atomic.StoreInt32(&requestCount, 0)
+
+	client, err = NewRetryClient(WithMaxRetries(3), WithInitialDelay(10*time.Millisecond))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	resp, err = client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusRequestTimeout {
+		t.Errorf("Expected status 408, got %d", resp.StatusCode)
+	}
+	if count := atomic.LoadInt32(&requestCount); count != 1 {
+		t.Errorf("Expected 1 request, got %d", count)
+	}
+
+	if _, err := NewRetryClient(WithRetryPolicy(nil)); err == nil {
+		t.Error("Expected error for nil retry policy")
+	}
+}
+
+func TestRetryTransportRetryAfter(t *testing.T) {
+	requestCount := int32(0)
+	var firstRequest, secondRequest time.Time
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requestCount, 1) == 1 {
+			firstRequest = time.Now()
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		secondRequest = time.Now()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client, err := NewRetryClient(
+		WithMaxRetries(1),
+		WithInitialDelay(10*time.Millisecond),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", resp.StatusCode)
+	}
+
+	if elapsed := secondRequest.Sub(firstRequest); elapsed < 1*time.Second {
+		t.Errorf("Expected Retry-After of 1s to be honored, waited %v", elapsed)
+	}
+}
+
+func TestRetryTransportRetryAfterMaxDelay(t *testing.T) {
+	requestCount := int32(0)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requestCount, 1) == 1 {
+			w.Header().Set("Retry-After", "3600")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	var delays []time.Duration
+	client, err := NewRetryClient(
+		WithMaxRetries(1),
+		WithInitialDelay(10*time.Millisecond),
+		WithMaxDelay(50*time.Millisecond),
+		WithOnRetry(func(attempt int, delay time.Duration, err error) {
+			delays = append(delays, delay)
+		}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	start := time.Now()
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	if fmt.Sprint(delays) != "[50ms]" {
+		t.Errorf("Expected Retry-After to be capped by MaxDelay of 50ms, got %v", delays)
+	}
+
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected the retry to wait at most MaxDelay, waited %v", elapsed)
+	}
+}
+
+func TestRetryTransportOnRetry(t *testing.T) {
+	requestCount := int32(0)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requestCount, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	var attempts []int
+	client, err := NewRetryClient(
+		WithMaxRetries(3),
+		WithInitialDelay(10*time.Millisecond),
+		WithOnRetry(func(attempt int, delay time.Duration, err error) {
+			attempts = append(attempts, attempt)
+			if delay <= 0 {
+				t.Errorf("Expected a positive delay, got %v", delay)
+			}
+			if err == nil || !strings.Contains(err.Error(), "503") {
+				t.Errorf("Expected the error of the 503 status code, got %v", err)
+			}
+		}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	if fmt.Sprint(attempts) != "[1 2]" {
+		t.Errorf("Expected the hook to be called for attempts [1 2], got %v", attempts)
+	}
+
+	if _, err := NewRetryClient(WithOnRetry(nil)); err == nil {
+		t.Error("Expected error for nil on retry hook")
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected time.Duration
+		ok       bool
+	}{
+		{"", 0, false},
+		{"5", 5 * time.Second, true},
+		{"0", 0, true},
+		{"-1", 0, false},
+		{"soon", 0, false},
+		{time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat), 0, true},
+	}
+
+	for _, tt := range tests {
+		delay, ok := parseRetryAfter(tt.value)
+		if ok != tt.ok || delay != tt.expected {
+			t.Errorf("parseRetryAfter(%q): expected (%v, %v), got (%v, %v)", tt.value, tt.expected, tt.ok, delay, ok)
+		}
+	}
+
+	delay, ok := parseRetryAfter(time.Now().Add(10 * time.Second).UTC().Format(http.TimeFormat))
+	if !ok || delay <= 8*time.Second || delay > 10*time.Second {
+		t.Errorf("Expected http date Retry-After around 10s, got (%v, %v)", delay, ok)
+	}
+}
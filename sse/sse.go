@@ -15,6 +15,11 @@ const (
 
 type Pusher interface {
 	Push(msg *Message) error
+	// PushContext is the same as Push, but it returns ctx.Err() if ctx is canceled
+	// before msg is written, e.g. to a stuck client, the pusher can't be used afterward.
+	// The write is never left in flight, if the writer doesn't support write deadlines
+	// and can't be closed, PushContext waits for the write to return
+	PushContext(ctx context.Context, msg *Message) error
 	Close() error
 }
 
@@ -39,6 +44,14 @@ func (pc *pushCloser) Push(msg *Message) error {
 	return pc.push(msg)
 }
 
+// PushContext can't interrupt push, it only checks ctx before calling it
+func (pc *pushCloser) PushContext(ctx context.Context, msg *Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return pc.push(msg)
+}
+
 func (pc *pushCloser) Close() error {
 	return pc.close()
 }
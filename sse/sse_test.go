@@ -1,8 +1,11 @@
 package sse_test
 
 import (
+	"bufio"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -11,6 +14,7 @@ import (
 	"sync"
 	"sync/atomic"
 	"testing"
+	"testing/iotest"
 	"time"
 
 	"github.com/hexe-dev/hexe/sse"
@@ -74,6 +78,60 @@ data: test data
 	}
 }
 
+func TestParseLastMessageWithoutBlankLine(t *testing.T) {
+	parsers := map[string]func(io.Reader) <-chan *sse.Message{
+		"Parse":     sse.Parse,
+		"FastParse": sse.FastParse,
+	}
+
+	testCases := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{
+			name:     "single",
+			input:    "data: last\n",
+			expected: []string{"last"},
+		},
+		{
+			name:     "without new line",
+			input:    "data: last",
+			expected: []string{"last"},
+		},
+		{
+			name:     "after message",
+			input:    "data: first\n\ndata: last\n",
+			expected: []string{"first", "last"},
+		},
+		{
+			name:     "after comment",
+			input:    ": keep alive\n\ndata: last\n",
+			expected: []string{"last"},
+		},
+		{
+			name:     "after extra blank lines",
+			input:    "data: first\n\n\n\ndata: last\n",
+			expected: []string{"first", "last"},
+		},
+	}
+
+	for name, parse := range parsers {
+		for _, tc := range testCases {
+			t.Run(name+"/"+tc.name, func(t *testing.T) {
+				var got []string
+				for msg := range parse(strings.NewReader(tc.input)) {
+					got = append(got, msg.Data)
+				}
+
+				if strings.Join(got, ",") != strings.Join(tc.expected, ",") {
+					t.Errorf("expected %q, got %q", tc.expected, got)
+				}
+			})
+		}
+	}
+}
+
 func TestParseLarge(t *testing.T) {
 	file, err := os.Open("./testdata/test01.txt")
 	if err != nil {
@@ -96,6 +154,101 @@ func TestParseLarge(t *testing.T) {
 	}
 }
 
+func TestParseWithOptionsLargeEvent(t *testing.T) {
+	data := strings.Repeat("a", 128*1024)
+	input := "id: 1\ndata: " + data + "\n\n"
+
+	t.Run("max token size", func(t *testing.T) {
+		ch := sse.ParseWithOptions(strings.NewReader(input), sse.WithChannelBuffer(1), sse.WithMaxTokenSize(256*1024))
+
+		var got []*sse.Message
+		for msg := range ch {
+			got = append(got, msg)
+		}
+
+		if len(got) != 1 {
+			t.Fatalf("expected 1 message, got %d", len(got))
+		}
+		if got[0].Err != nil {
+			t.Fatalf("unexpected error: %v", got[0].Err)
+		}
+		if got[0].Id != "1" || got[0].Data != data {
+			t.Errorf("message mismatch: id %q, data length %d", got[0].Id, len(got[0].Data))
+		}
+	})
+
+	t.Run("default", func(t *testing.T) {
+		ch := sse.ParseWithOptions(strings.NewReader(input))
+
+		var got []*sse.Message
+		for msg := range ch {
+			got = append(got, msg)
+		}
+
+		if len(got) != 1 {
+			t.Fatalf("expected 1 error message, got %d", len(got))
+		}
+		if !errors.Is(got[0].Err, bufio.ErrTooLong) {
+			t.Errorf("expected bufio.ErrTooLong, got %v", got[0].Err)
+		}
+	})
+
+	t.Run("parse", func(t *testing.T) {
+		count := 0
+		for range sse.Parse(strings.NewReader(input)) {
+			count++
+		}
+
+		if count != 0 {
+			t.Errorf("expected no messages, got %d", count)
+		}
+	})
+}
+
+func TestParseWithOptionsReadError(t *testing.T) {
+	errRead := errors.New("connection reset")
+	newReader := func() io.Reader {
+		return io.MultiReader(strings.NewReader("data: first\n\n"), iotest.ErrReader(errRead))
+	}
+
+	t.Run("parse", func(t *testing.T) {
+		var got []*sse.Message
+		for msg := range sse.ParseWithOptions(newReader()) {
+			got = append(got, msg)
+		}
+
+		if len(got) != 2 {
+			t.Fatalf("expected 2 messages, got %d", len(got))
+		}
+		if got[0].Err != nil || got[0].Data != "first" {
+			t.Errorf("first message mismatch: %+v", got[0])
+		}
+		if !errors.Is(got[1].Err, errRead) {
+			t.Errorf("expected read error, got %v", got[1].Err)
+		}
+	})
+
+	t.Run("receiver", func(t *testing.T) {
+		r := sse.NewReceiver(newReader())
+
+		msg, err := r.Receive(context.Background())
+		if err != nil || msg.Data != "first" {
+			t.Fatalf("expected first message, got %v, %v", msg, err)
+		}
+
+		_, err = r.Receive(context.Background())
+		if !errors.Is(err, errRead) {
+			t.Errorf("expected read error, got %v", err)
+		}
+
+		// the stream ends after the error
+		_, err = r.Receive(context.Background())
+		if !errors.Is(err, io.EOF) {
+			t.Errorf("expected io.EOF, got %v", err)
+		}
+	})
+}
+
 func TestPushReceive(t *testing.T) {
 	n := 10
 
@@ -151,6 +304,165 @@ func TestPushReceive(t *testing.T) {
 	}
 }
 
+func TestPushContextCancel(t *testing.T) {
+	// nobody reads from the pipe, so the write blocks like a stuck client
+	pr, pw := io.Pipe()
+	defer pr.Close()
+
+	pusher, err := sse.NewPusher(pw, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pusher.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err = pusher.PushContext(ctx, sse.NewMessage("1", "event", "data"))
+	if err != context.DeadlineExceeded {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("push was not aborted, took %s", elapsed)
+	}
+
+	// the stream has a partial message, so the pusher is closed
+	if err := pusher.Push(sse.NewMessage("2", "event", "data")); err != io.ErrClosedPipe {
+		t.Fatalf("expected io.ErrClosedPipe, got %v", err)
+	}
+}
+
+// blockingWriter blocks the writes until release is closed, it neither
+// supports deadlines nor can be closed to unblock them
+type blockingWriter struct {
+	release chan struct{}
+	writing atomic.Bool
+}
+
+func (w *blockingWriter) Write(p []byte) (int, error) {
+	w.writing.Store(true)
+	defer w.writing.Store(false)
+
+	<-w.release
+	return len(p), nil
+}
+
+func TestPushContextCancelWaitsForWrite(t *testing.T) {
+	w := &blockingWriter{release: make(chan struct{})}
+
+	pusher, err := sse.NewPusher(w, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pusher.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	time.AfterFunc(200*time.Millisecond, func() { close(w.release) })
+
+	err = pusher.PushContext(ctx, sse.NewMessage("1", "event", "data"))
+	if err != context.DeadlineExceeded {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+
+	// the write is not left in flight, e.g. to a ResponseWriter after its handler returns
+	if w.writing.Load() {
+		t.Fatal("push returned while the write is in flight")
+	}
+
+	if err := pusher.Push(sse.NewMessage("2", "event", "data")); err != io.ErrClosedPipe {
+		t.Fatalf("expected io.ErrClosedPipe, got %v", err)
+	}
+}
+
+func TestHttpPushContextCancel(t *testing.T) {
+	pushed := make(chan error, 1)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pusher, err := sse.NewHttpPusher(w, 0)
+		if err != nil {
+			pushed <- err
+			return
+		}
+		defer pusher.Close()
+
+		ctx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+
+		// the client doesn't read the body, so the writes block once the socket buffers are full
+		data := strings.Repeat("x", 1<<20)
+		time.AfterFunc(200*time.Millisecond, cancel)
+
+		for {
+			if err := pusher.PushContext(ctx, sse.NewMessage("1", "event", data)); err != nil {
+				pushed <- err
+				return
+			}
+		}
+	}))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	select {
+	case err := <-pushed:
+		if err != context.Canceled {
+			t.Fatalf("expected context.Canceled, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("push was not aborted")
+	}
+}
+
+func TestHttpPushContextCancelFlush(t *testing.T) {
+	pushed := make(chan error, 1)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pusher, err := sse.NewHttpPusher(w, 0)
+		if err != nil {
+			pushed <- err
+			return
+		}
+		defer pusher.Close()
+
+		ctx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+
+		// the messages fit in the response buffer, so it's the flush that blocks on the stuck client
+		data := strings.Repeat("x", 512)
+		time.AfterFunc(200*time.Millisecond, cancel)
+
+		for {
+			if err := pusher.PushContext(ctx, sse.NewMessage("1", "event", data)); err != nil {
+				pushed <- err
+				return
+			}
+		}
+	}))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	select {
+	case err := <-pushed:
+		if err != context.Canceled {
+			t.Fatalf("expected context.Canceled, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("push was not aborted")
+	}
+}
+
 func TestPusherReceiver(t *testing.T) {
 	n := 10000 // Reduced for faster testing
 	c := 5     // Reduced concurrent connections